@@ -0,0 +1,193 @@
+package app
+
+import (
+    "cursos-api/handlers"
+    "cursos-api/mailer"
+    "cursos-api/middleware"
+    "cursos-api/oidc"
+    "cursos-api/ratelimit"
+    "cursos-api/repository"
+    "cursos-api/scheduler"
+    "cursos-api/services"
+    "cursos-api/storage"
+    "cursos-api/utils"
+    "database/sql"
+    "log"
+    "time"
+)
+
+// Container agrupa las dependencias de la aplicación ya construidas y
+// conectadas entre sí. Es el único lugar donde se decide qué implementación
+// recibe cada servicio.
+type Container struct {
+    DB     *sql.DB
+    Store  storage.BlobStore
+    Mailer mailer.Mailer
+
+    // Repositorios
+    UsuarioRepo     *repository.UsuarioRepository
+    CursoRepo       *repository.CursoRepository
+    InscripcionRepo *repository.InscripcionRepository
+    LeccionRepo     *repository.LeccionRepository
+    ProgresoRepo    *repository.ProgresoRepository
+    EvaluacionRepo  *repository.EvaluacionRepository
+    ResultadoRepo   *repository.ResultadoRepository
+    PreguntaRepo    *repository.PreguntaRepository
+    IntentoRepo     *repository.IntentoRepository
+    CertificadoRepo *repository.CertificadoRepository
+    SesionRepo      *repository.SesionRepository
+    SolicitudRepo   *repository.SolicitudInstructorRepository
+    TokenRepo       *repository.TokenUsuarioRepository
+    MFARepo         *repository.MFARepository
+    IdentidadRepo   *repository.IdentidadRepository
+    CategoriaRepo   *repository.CategoriaRepository
+
+    // Servicios
+    SesionService      *services.SesionService
+    CuentaService      *services.CuentaService
+    MFAService         *services.MFAService
+    AuthService        *services.AuthService
+    UsuarioService     *services.UsuarioService
+    CursoService       *services.CursoService
+    InscripcionService *services.InscripcionService
+    LeccionService     *services.LeccionService
+    CertificadoService *services.CertificadoService
+    ProgresoService    *services.ProgresoService
+    EvaluacionService  *services.EvaluacionService
+    PreguntaService    *services.PreguntaService
+    IntentoService     *services.IntentoService
+    AdminService       *services.AdminService
+    SolicitudService   *services.SolicitudInstructorService
+    OIDCService        *services.OIDCService
+    CategoriaService   *services.CategoriaService
+
+    // Middlewares
+    AuthMiddleware *middleware.Auth
+    RateLimiter    *middleware.RateLimiter
+
+    // Handlers
+    AuthHandler        *handlers.AuthHandler
+    UsuarioHandler     *handlers.UsuarioHandler
+    CursoHandler       *handlers.CursoHandler
+    InscripcionHandler *handlers.InscripcionHandler
+    LeccionHandler     *handlers.LeccionHandler
+    ProgresoHandler    *handlers.ProgresoHandler
+    EvaluacionHandler  *handlers.EvaluacionHandler
+    PreguntaHandler    *handlers.PreguntaHandler
+    IntentoHandler     *handlers.IntentoHandler
+    CertificadoHandler *handlers.CertificadoHandler
+    AdminHandler       *handlers.AdminHandler
+    SolicitudHandler   *handlers.SolicitudInstructorHandler
+    MFAHandler         *handlers.MFAHandler
+    JWKSHandler        *handlers.JWKSHandler
+    OIDCHandler        *handlers.OIDCHandler
+    CategoriaHandler   *handlers.CategoriaHandler
+}
+
+// NewContainer construye todas las dependencias sobre la conexión indicada.
+// Las claves JWT deben estar cargadas antes (utils.InitJWT).
+func NewContainer(db *sql.DB, store storage.BlobStore, mail mailer.Mailer) *Container {
+    c := &Container{DB: db, Store: store, Mailer: mail}
+
+    // Repositorios
+    c.UsuarioRepo = repository.NewUsuarioRepository(db)
+    c.CursoRepo = repository.NewCursoRepository(db)
+    c.InscripcionRepo = repository.NewInscripcionRepository(db)
+    c.LeccionRepo = repository.NewLeccionRepository(db)
+    c.ProgresoRepo = repository.NewProgresoRepository(db)
+    c.EvaluacionRepo = repository.NewEvaluacionRepository(db)
+    c.ResultadoRepo = repository.NewResultadoRepository(db)
+    c.PreguntaRepo = repository.NewPreguntaRepository(db)
+    c.IntentoRepo = repository.NewIntentoRepository(db)
+    c.CertificadoRepo = repository.NewCertificadoRepository(db)
+    c.SesionRepo = repository.NewSesionRepository(db)
+    c.SolicitudRepo = repository.NewSolicitudInstructorRepository(db)
+    c.TokenRepo = repository.NewTokenUsuarioRepository(db)
+    c.MFARepo = repository.NewMFARepository(db)
+    c.IdentidadRepo = repository.NewIdentidadRepository(db)
+    c.CategoriaRepo = repository.NewCategoriaRepository(db)
+
+    // Servicios
+    c.SesionService = services.NewSesionService(c.SesionRepo, c.UsuarioRepo)
+    c.CuentaService = services.NewCuentaService(c.UsuarioRepo, c.TokenRepo, c.SesionService, mail)
+    c.MFAService = services.NewMFAService(c.MFARepo, c.UsuarioRepo)
+    loginIPLimiter := ratelimit.NewSlidingWindow(
+        utils.GetEnvInt("LOGIN_IP_MAX_FAILED_ATTEMPTS", 20),
+        utils.GetEnvDuration("LOGIN_IP_WINDOW", 15*time.Minute),
+    )
+    c.AuthService = services.NewAuthService(c.UsuarioRepo, c.SesionService, c.CuentaService, c.MFAService, loginIPLimiter)
+    c.UsuarioService = services.NewUsuarioService(c.UsuarioRepo, c.SesionService, c.CuentaService)
+    c.CursoService = services.NewCursoService(c.CursoRepo, c.UsuarioRepo, c.LeccionRepo)
+    c.InscripcionService = services.NewInscripcionService(c.InscripcionRepo, c.CursoRepo)
+    c.LeccionService = services.NewLeccionService(c.LeccionRepo, c.CursoRepo, c.InscripcionRepo)
+    c.CertificadoService = services.NewCertificadoService(c.CertificadoRepo, c.InscripcionRepo, c.EvaluacionRepo, store)
+    c.ProgresoService = services.NewProgresoService(c.ProgresoRepo, c.InscripcionRepo, c.LeccionRepo, c.CertificadoService)
+    c.EvaluacionService = services.NewEvaluacionService(c.EvaluacionRepo, c.ResultadoRepo, c.PreguntaRepo,
+        c.CursoRepo, c.InscripcionRepo, c.CertificadoService)
+    c.PreguntaService = services.NewPreguntaService(c.PreguntaRepo, c.EvaluacionService)
+    c.IntentoService = services.NewIntentoService(c.IntentoRepo, c.PreguntaRepo, c.EvaluacionService, c.CertificadoService)
+    c.AdminService = services.NewAdminService(c.UsuarioRepo, c.CursoRepo, c.SesionService)
+    c.SolicitudService = services.NewSolicitudInstructorService(c.SolicitudRepo, c.UsuarioRepo)
+    c.CategoriaService = services.NewCategoriaService(c.CategoriaRepo, c.CursoRepo)
+    // Una configuración OIDC incompleta no impide arrancar: se avisa y el
+    // login externo queda deshabilitado
+    proveedores, err := oidc.ProvidersFromEnv(services.AppBaseURL())
+    if err != nil {
+        log.Printf("⚠️  Login OIDC deshabilitado: %v\n", err)
+        proveedores = map[string]*oidc.Provider{}
+    }
+    c.OIDCService = services.NewOIDCService(proveedores, c.IdentidadRepo, c.UsuarioRepo, c.TokenRepo, c.AuthService, c.CuentaService)
+
+    // Middlewares
+    c.AuthMiddleware = middleware.NewAuth(c.SesionService)
+    // Con varias instancias, ratelimit.NewRedisStore comparte los límites
+    c.RateLimiter = middleware.NewRateLimiter(ratelimit.NewMemoryStore())
+
+    // Handlers
+    c.AuthHandler = handlers.NewAuthHandler(c.AuthService, c.CuentaService)
+    c.UsuarioHandler = handlers.NewUsuarioHandler(c.UsuarioService)
+    c.CursoHandler = handlers.NewCursoHandler(c.CursoService)
+    c.InscripcionHandler = handlers.NewInscripcionHandler(c.InscripcionService)
+    c.LeccionHandler = handlers.NewLeccionHandler(c.LeccionService)
+    c.ProgresoHandler = handlers.NewProgresoHandler(c.ProgresoService)
+    c.EvaluacionHandler = handlers.NewEvaluacionHandler(c.EvaluacionService)
+    c.PreguntaHandler = handlers.NewPreguntaHandler(c.PreguntaService)
+    c.IntentoHandler = handlers.NewIntentoHandler(c.IntentoService)
+    c.CertificadoHandler = handlers.NewCertificadoHandler(c.CertificadoService)
+    c.AdminHandler = handlers.NewAdminHandler(c.AdminService)
+    c.SolicitudHandler = handlers.NewSolicitudInstructorHandler(c.SolicitudService)
+    c.MFAHandler = handlers.NewMFAHandler(c.MFAService)
+    c.JWKSHandler = handlers.NewJWKSHandler(utils.JWTKeyRing())
+    c.OIDCHandler = handlers.NewOIDCHandler(c.OIDCService)
+    c.CategoriaHandler = handlers.NewCategoriaHandler(c.CategoriaService)
+
+    return c
+}
+
+// Tareas devuelve los trabajos periódicos que se ejecutan junto al servidor
+func (c *Container) Tareas() []scheduler.Tarea {
+    return []scheduler.Tarea{
+        {
+            Nombre:    "cursos-programados",
+            Intervalo: utils.GetEnvDuration("CURSOS_PROGRAMADOS_INTERVAL", time.Minute),
+            Ejecutar: func(ahora time.Time) error {
+                cambios, err := c.CursoService.ProcesarProgramados(ahora)
+                if cambios > 0 {
+                    log.Printf("📅 Publicación programada: %d cambios de estado aplicados\n", cambios)
+                }
+                return err
+            },
+        },
+        {
+            Nombre:    "purga-eliminados",
+            Intervalo: utils.GetEnvDuration("PURGA_ELIMINADOS_INTERVAL", time.Hour),
+            Ejecutar: func(ahora time.Time) error {
+                usuarios, cursos, err := c.AdminService.PurgarEliminados(ahora)
+                if usuarios > 0 || cursos > 0 {
+                    log.Printf("🗑️  Purga: %d usuarios y %d cursos borrados definitivamente\n", usuarios, cursos)
+                }
+                return err
+            },
+        },
+    }
+}
@@ -9,9 +9,8 @@ import (
     _ "github.com/lib/pq"
 )
 
-var DB *sql.DB
-
-func ConnectDB() {
+// ConnectDB abre la conexión a PostgreSQL configurada por variables de entorno
+func ConnectDB() *sql.DB {
     host := os.Getenv("DB_HOST")
     port := os.Getenv("DB_PORT")
     user := os.Getenv("DB_USER")
@@ -24,22 +23,17 @@ func ConnectDB() {
         host, port, user, password, dbname, sslmode,
     )
 
-    var err error
-    DB, err = sql.Open("postgres", connStr)
+    db, err := sql.Open("postgres", connStr)
     if err != nil {
         log.Fatal("Error al conectar a la base de datos:", err)
     }
 
-    err = DB.Ping()
+    err = db.Ping()
     if err != nil {
         log.Fatal("Error al hacer ping a la base de datos:", err)
     }
 
     log.Println("✅ Conexión exitosa a la base de datos")
-}
 
-func CloseDB() {
-    if DB != nil {
-        DB.Close()
-    }
+    return db
 }
@@ -0,0 +1,324 @@
+package database
+
+import (
+    "context"
+    "crypto/sha256"
+    "database/sql"
+    "embed"
+    "encoding/hex"
+    "fmt"
+    "io/fs"
+    "path"
+    "regexp"
+    "sort"
+    "strconv"
+    "time"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// Clave del advisory lock que serializa las migraciones entre instancias
+const migrationLockKey = 72_410_118
+
+// migrationFile reconoce nombres del tipo 0001_descripcion.up.sql / .down.sql
+var migrationFile = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration es una migración versionada con sus scripts de subida y bajada
+type Migration struct {
+    Version  int
+    Nombre   string
+    Up       string
+    Down     string
+    Checksum string
+}
+
+// MigrationStatus describe si una migración está aplicada en la base de datos
+type MigrationStatus struct {
+    Version    int
+    Nombre     string
+    Aplicada   bool
+    AplicadaAt *time.Time
+}
+
+// Migrator aplica y revierte las migraciones embebidas en el binario
+type Migrator struct {
+    db          *sql.DB
+    migraciones []Migration
+}
+
+// NewMigrator crea un migrador con las migraciones embebidas
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+    migraciones, err := loadMigrations(migrationsFS, "migrations")
+    if err != nil {
+        return nil, err
+    }
+
+    return &Migrator{db: db, migraciones: migraciones}, nil
+}
+
+// loadMigrations lee y ordena por versión los scripts de un directorio
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+    entries, err := fs.ReadDir(fsys, dir)
+    if err != nil {
+        return nil, err
+    }
+
+    porVersion := make(map[int]*Migration)
+    for _, entry := range entries {
+        if entry.IsDir() {
+            continue
+        }
+
+        match := migrationFile.FindStringSubmatch(entry.Name())
+        if match == nil {
+            return nil, fmt.Errorf("nombre de migración inválido: %s", entry.Name())
+        }
+
+        version, _ := strconv.Atoi(match[1])
+        contenido, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+        if err != nil {
+            return nil, err
+        }
+
+        m, ok := porVersion[version]
+        if !ok {
+            m = &Migration{Version: version, Nombre: match[2]}
+            porVersion[version] = m
+        } else if m.Nombre != match[2] {
+            return nil, fmt.Errorf("versión de migración duplicada: %d", version)
+        }
+
+        if match[3] == "up" {
+            m.Up = string(contenido)
+            sum := sha256.Sum256(contenido)
+            m.Checksum = hex.EncodeToString(sum[:])
+        } else {
+            m.Down = string(contenido)
+        }
+    }
+
+    migraciones := make([]Migration, 0, len(porVersion))
+    for _, m := range porVersion {
+        if m.Up == "" {
+            return nil, fmt.Errorf("la migración %d no tiene script de subida", m.Version)
+        }
+        migraciones = append(migraciones, *m)
+    }
+
+    sort.Slice(migraciones, func(i, j int) bool {
+        return migraciones[i].Version < migraciones[j].Version
+    })
+
+    return migraciones, nil
+}
+
+// Up aplica todas las migraciones pendientes y devuelve las aplicadas
+func (m *Migrator) Up() ([]Migration, error) {
+    var aplicadas []Migration
+
+    err := m.withLock(func(conn *sql.Conn) error {
+        estado, err := m.verificar(conn)
+        if err != nil {
+            return err
+        }
+
+        for _, migracion := range m.migraciones {
+            if _, ok := estado[migracion.Version]; ok {
+                continue
+            }
+
+            if err := m.aplicar(conn, migracion); err != nil {
+                return err
+            }
+            aplicadas = append(aplicadas, migracion)
+        }
+
+        return nil
+    })
+
+    return aplicadas, err
+}
+
+// Down revierte las últimas migraciones aplicadas (pasos > 0)
+func (m *Migrator) Down(pasos int) ([]Migration, error) {
+    if pasos <= 0 {
+        return nil, fmt.Errorf("el número de pasos debe ser mayor a 0")
+    }
+
+    var revertidas []Migration
+
+    err := m.withLock(func(conn *sql.Conn) error {
+        estado, err := m.verificar(conn)
+        if err != nil {
+            return err
+        }
+
+        for i := len(m.migraciones) - 1; i >= 0 && len(revertidas) < pasos; i-- {
+            migracion := m.migraciones[i]
+            if _, ok := estado[migracion.Version]; !ok {
+                continue
+            }
+
+            if migracion.Down == "" {
+                return fmt.Errorf("la migración %d no tiene script de bajada", migracion.Version)
+            }
+
+            if err := m.revertir(conn, migracion); err != nil {
+                return err
+            }
+            revertidas = append(revertidas, migracion)
+        }
+
+        return nil
+    })
+
+    return revertidas, err
+}
+
+// Status lista todas las migraciones conocidas indicando si están aplicadas
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+    var lista []MigrationStatus
+
+    err := m.withLock(func(conn *sql.Conn) error {
+        estado, err := m.verificar(conn)
+        if err != nil {
+            return err
+        }
+
+        for _, migracion := range m.migraciones {
+            item := MigrationStatus{Version: migracion.Version, Nombre: migracion.Nombre}
+            if aplicada, ok := estado[migracion.Version]; ok {
+                item.Aplicada = true
+                item.AplicadaAt = &aplicada.aplicadaAt
+            }
+            lista = append(lista, item)
+        }
+
+        return nil
+    })
+
+    return lista, err
+}
+
+// withLock ejecuta fn en una conexión dedicada que mantiene el advisory lock
+func (m *Migrator) withLock(fn func(conn *sql.Conn) error) error {
+    ctx := context.Background()
+
+    conn, err := m.db.Conn(ctx)
+    if err != nil {
+        return err
+    }
+    defer conn.Close()
+
+    if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+        return fmt.Errorf("no se pudo obtener el lock de migraciones: %w", err)
+    }
+    defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", migrationLockKey)
+
+    _, err = conn.ExecContext(ctx, `
+        CREATE TABLE IF NOT EXISTS schema_migrations (
+            version INTEGER PRIMARY KEY,
+            nombre VARCHAR(255) NOT NULL,
+            checksum CHAR(64) NOT NULL,
+            aplicada_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+        )
+    `)
+    if err != nil {
+        return err
+    }
+
+    return fn(conn)
+}
+
+type migracionAplicada struct {
+    checksum   string
+    aplicadaAt time.Time
+}
+
+// verificar lee las migraciones aplicadas y comprueba que coincidan con las
+// embebidas: una migración modificada tras aplicarse o desconocida es un error
+func (m *Migrator) verificar(conn *sql.Conn) (map[int]migracionAplicada, error) {
+    rows, err := conn.QueryContext(context.Background(),
+        "SELECT version, checksum, aplicada_at FROM schema_migrations ORDER BY version")
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    estado := make(map[int]migracionAplicada)
+    for rows.Next() {
+        var version int
+        var aplicada migracionAplicada
+        if err := rows.Scan(&version, &aplicada.checksum, &aplicada.aplicadaAt); err != nil {
+            return nil, err
+        }
+        estado[version] = aplicada
+    }
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
+
+    conocidas := make(map[int]Migration, len(m.migraciones))
+    for _, migracion := range m.migraciones {
+        conocidas[migracion.Version] = migracion
+    }
+
+    for version, aplicada := range estado {
+        migracion, ok := conocidas[version]
+        if !ok {
+            return nil, fmt.Errorf("la migración %d está aplicada pero no existe en esta versión de la aplicación", version)
+        }
+        if migracion.Checksum != aplicada.checksum {
+            return nil, fmt.Errorf("la migración %d_%s fue modificada después de aplicarse (checksum distinto)",
+                version, migracion.Nombre)
+        }
+    }
+
+    return estado, nil
+}
+
+// aplicar ejecuta el script de subida y lo registra en la misma transacción
+func (m *Migrator) aplicar(conn *sql.Conn, migracion Migration) error {
+    ctx := context.Background()
+
+    tx, err := conn.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    if _, err := tx.ExecContext(ctx, migracion.Up); err != nil {
+        return fmt.Errorf("error al aplicar la migración %d_%s: %w", migracion.Version, migracion.Nombre, err)
+    }
+
+    _, err = tx.ExecContext(ctx,
+        "INSERT INTO schema_migrations (version, nombre, checksum) VALUES ($1, $2, $3)",
+        migracion.Version, migracion.Nombre, migracion.Checksum)
+    if err != nil {
+        return err
+    }
+
+    return tx.Commit()
+}
+
+// revertir ejecuta el script de bajada y elimina el registro de la migración
+func (m *Migrator) revertir(conn *sql.Conn, migracion Migration) error {
+    ctx := context.Background()
+
+    tx, err := conn.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    if _, err := tx.ExecContext(ctx, migracion.Down); err != nil {
+        return fmt.Errorf("error al revertir la migración %d_%s: %w", migracion.Version, migracion.Nombre, err)
+    }
+
+    if _, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = $1", migracion.Version); err != nil {
+        return err
+    }
+
+    return tx.Commit()
+}
@@ -0,0 +1,17 @@
+-- ============================================
+-- 0001: revierte el esquema inicial
+-- ============================================
+
+DROP TABLE IF EXISTS certificados CASCADE;
+DROP TABLE IF EXISTS intentos_evaluacion CASCADE;
+DROP TABLE IF EXISTS opciones_pregunta CASCADE;
+DROP TABLE IF EXISTS preguntas CASCADE;
+DROP TABLE IF EXISTS resultados_evaluacion CASCADE;
+DROP TABLE IF EXISTS evaluaciones CASCADE;
+DROP TABLE IF EXISTS progreso_lecciones CASCADE;
+DROP TABLE IF EXISTS lecciones CASCADE;
+DROP TABLE IF EXISTS inscripciones CASCADE;
+DROP TABLE IF EXISTS cursos CASCADE;
+DROP TABLE IF EXISTS usuarios CASCADE;
+
+DROP FUNCTION IF EXISTS update_updated_at_column();
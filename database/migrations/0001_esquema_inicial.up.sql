@@ -0,0 +1,195 @@
+-- ============================================
+-- 0001: esquema inicial de Gestión de Cursos
+-- Es idempotente (IF NOT EXISTS) para adoptar las bases creadas con el
+-- antiguo database/init.sql: conserva sus tablas y datos, crea lo que falte
+-- y a partir de aquí el resto de migraciones se aplican con normalidad.
+-- ============================================
+
+-- ============================================
+-- TABLA: usuarios
+-- ============================================
+CREATE TABLE IF NOT EXISTS usuarios (
+    id SERIAL PRIMARY KEY,
+    nombre VARCHAR(100) NOT NULL,
+    email VARCHAR(100) UNIQUE NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    rol VARCHAR(20) NOT NULL CHECK (rol IN ('instructor', 'alumno')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ============================================
+-- TABLA: cursos
+-- ============================================
+CREATE TABLE IF NOT EXISTS cursos (
+    id SERIAL PRIMARY KEY,
+    nombre VARCHAR(200) NOT NULL,
+    descripcion TEXT,
+    duracion_horas INTEGER NOT NULL,
+    instructor_id INTEGER NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+    activo BOOLEAN DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ============================================
+-- TABLA: inscripciones
+-- ============================================
+CREATE TABLE IF NOT EXISTS inscripciones (
+    id SERIAL PRIMARY KEY,
+    usuario_id INTEGER NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+    curso_id INTEGER NOT NULL REFERENCES cursos(id) ON DELETE CASCADE,
+    fecha_inscripcion TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    estado VARCHAR(20) NOT NULL DEFAULT 'activo' CHECK (estado IN ('activo', 'completado', 'cancelado')),
+    progreso_porcentaje NUMERIC(5,2) NOT NULL DEFAULT 0 CHECK (progreso_porcentaje BETWEEN 0 AND 100),
+    UNIQUE (usuario_id, curso_id)
+);
+
+-- ============================================
+-- TABLA: lecciones
+-- ============================================
+CREATE TABLE IF NOT EXISTS lecciones (
+    id SERIAL PRIMARY KEY,
+    curso_id INTEGER NOT NULL REFERENCES cursos(id) ON DELETE CASCADE,
+    titulo VARCHAR(200) NOT NULL,
+    contenido TEXT,
+    orden INTEGER NOT NULL CHECK (orden > 0),
+    duracion_minutos INTEGER NOT NULL DEFAULT 0 CHECK (duracion_minutos >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    -- Diferido para poder reordenar dentro de una transacción
+    UNIQUE (curso_id, orden) DEFERRABLE INITIALLY DEFERRED
+);
+
+-- ============================================
+-- TABLA: progreso_lecciones
+-- ============================================
+CREATE TABLE IF NOT EXISTS progreso_lecciones (
+    id SERIAL PRIMARY KEY,
+    inscripcion_id INTEGER NOT NULL REFERENCES inscripciones(id) ON DELETE CASCADE,
+    leccion_id INTEGER NOT NULL REFERENCES lecciones(id) ON DELETE CASCADE,
+    completada BOOLEAN NOT NULL DEFAULT false,
+    fecha_completado TIMESTAMP,
+    UNIQUE (inscripcion_id, leccion_id)
+);
+
+-- ============================================
+-- TABLA: evaluaciones
+-- ============================================
+CREATE TABLE IF NOT EXISTS evaluaciones (
+    id SERIAL PRIMARY KEY,
+    curso_id INTEGER NOT NULL REFERENCES cursos(id) ON DELETE CASCADE,
+    titulo VARCHAR(200) NOT NULL,
+    descripcion TEXT,
+    calificacion_minima NUMERIC(5,2) NOT NULL CHECK (calificacion_minima BETWEEN 0 AND 100),
+    max_intentos INTEGER NOT NULL DEFAULT 0 CHECK (max_intentos >= 0),            -- 0 = ilimitados
+    tiempo_limite_minutos INTEGER NOT NULL DEFAULT 0 CHECK (tiempo_limite_minutos >= 0), -- 0 = sin límite
+    requerida BOOLEAN NOT NULL DEFAULT true,                                     -- necesaria para el certificado
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ============================================
+-- TABLA: resultados_evaluacion
+-- ============================================
+CREATE TABLE IF NOT EXISTS resultados_evaluacion (
+    id SERIAL PRIMARY KEY,
+    evaluacion_id INTEGER NOT NULL REFERENCES evaluaciones(id) ON DELETE CASCADE,
+    usuario_id INTEGER NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+    calificacion NUMERIC(5,2) NOT NULL CHECK (calificacion BETWEEN 0 AND 100),
+    aprobado BOOLEAN NOT NULL,
+    fecha_evaluacion TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ============================================
+-- TABLA: preguntas
+-- ============================================
+CREATE TABLE IF NOT EXISTS preguntas (
+    id SERIAL PRIMARY KEY,
+    evaluacion_id INTEGER NOT NULL REFERENCES evaluaciones(id) ON DELETE CASCADE,
+    enunciado TEXT NOT NULL,
+    tipo VARCHAR(20) NOT NULL CHECK (tipo IN ('opcion_unica', 'opcion_multiple', 'verdadero_falso', 'numerica')),
+    peso NUMERIC(6,2) NOT NULL DEFAULT 1 CHECK (peso > 0),
+    orden INTEGER NOT NULL DEFAULT 0,
+    respuesta_booleana BOOLEAN,
+    respuesta_numerica NUMERIC(14,4),
+    tolerancia NUMERIC(14,4) NOT NULL DEFAULT 0 CHECK (tolerancia >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ============================================
+-- TABLA: opciones_pregunta
+-- ============================================
+CREATE TABLE IF NOT EXISTS opciones_pregunta (
+    id SERIAL PRIMARY KEY,
+    pregunta_id INTEGER NOT NULL REFERENCES preguntas(id) ON DELETE CASCADE,
+    texto TEXT NOT NULL,
+    es_correcta BOOLEAN NOT NULL DEFAULT false,
+    orden INTEGER NOT NULL DEFAULT 0
+);
+
+-- ============================================
+-- TABLA: intentos_evaluacion
+-- ============================================
+CREATE TABLE IF NOT EXISTS intentos_evaluacion (
+    id SERIAL PRIMARY KEY,
+    evaluacion_id INTEGER NOT NULL REFERENCES evaluaciones(id) ON DELETE CASCADE,
+    usuario_id INTEGER NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+    iniciado_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expira_at TIMESTAMP,
+    enviado_at TIMESTAMP,
+    respuestas JSONB,
+    calificacion NUMERIC(5,2),
+    resultado_id INTEGER REFERENCES resultados_evaluacion(id) ON DELETE SET NULL
+);
+
+-- ============================================
+-- TABLA: certificados
+-- ============================================
+CREATE TABLE IF NOT EXISTS certificados (
+    id SERIAL PRIMARY KEY,
+    inscripcion_id INTEGER NOT NULL UNIQUE REFERENCES inscripciones(id) ON DELETE CASCADE,
+    codigo_certificado VARCHAR(64) NOT NULL UNIQUE,
+    fecha_emision TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    url_pdf VARCHAR(500)
+);
+
+-- ============================================
+-- ÍNDICES para mejorar rendimiento
+-- ============================================
+CREATE INDEX IF NOT EXISTS idx_usuarios_email ON usuarios(email);
+CREATE INDEX IF NOT EXISTS idx_usuarios_rol ON usuarios(rol);
+CREATE INDEX IF NOT EXISTS idx_cursos_instructor ON cursos(instructor_id);
+CREATE INDEX IF NOT EXISTS idx_cursos_activo ON cursos(activo);
+CREATE INDEX IF NOT EXISTS idx_inscripciones_usuario ON inscripciones(usuario_id);
+CREATE INDEX IF NOT EXISTS idx_inscripciones_curso ON inscripciones(curso_id);
+CREATE INDEX IF NOT EXISTS idx_lecciones_curso ON lecciones(curso_id);
+CREATE INDEX IF NOT EXISTS idx_progreso_lecciones_inscripcion ON progreso_lecciones(inscripcion_id);
+CREATE INDEX IF NOT EXISTS idx_evaluaciones_curso ON evaluaciones(curso_id);
+CREATE INDEX IF NOT EXISTS idx_resultados_evaluacion ON resultados_evaluacion(evaluacion_id);
+CREATE INDEX IF NOT EXISTS idx_resultados_usuario ON resultados_evaluacion(usuario_id);
+CREATE INDEX IF NOT EXISTS idx_preguntas_evaluacion ON preguntas(evaluacion_id);
+CREATE INDEX IF NOT EXISTS idx_opciones_pregunta ON opciones_pregunta(pregunta_id);
+CREATE INDEX IF NOT EXISTS idx_intentos_evaluacion_usuario ON intentos_evaluacion(evaluacion_id, usuario_id);
+
+-- ============================================
+-- FUNCIÓN para actualizar updated_at automáticamente
+-- ============================================
+CREATE OR REPLACE FUNCTION update_updated_at_column()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = CURRENT_TIMESTAMP;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Triggers para actualizar updated_at
+DROP TRIGGER IF EXISTS update_usuarios_updated_at ON usuarios;
+CREATE TRIGGER update_usuarios_updated_at
+    BEFORE UPDATE ON usuarios
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_cursos_updated_at ON cursos;
+CREATE TRIGGER update_cursos_updated_at
+    BEFORE UPDATE ON cursos
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,33 @@
+-- ============================================
+-- 0002: sesiones y refresh tokens rotativos
+-- ============================================
+
+-- ============================================
+-- TABLA: sesiones
+-- Una sesión agrupa la familia de refresh tokens emitidos desde un login;
+-- revocarla invalida todos sus tokens y los tokens de acceso que la citan.
+-- ============================================
+CREATE TABLE sesiones (
+    id VARCHAR(64) PRIMARY KEY,
+    usuario_id INTEGER NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revocada_at TIMESTAMP,
+    motivo_revocacion VARCHAR(50)
+);
+
+-- ============================================
+-- TABLA: refresh_tokens
+-- Solo se guarda el hash SHA-256 del token. Cada uso lo marca como usado y
+-- emite el siguiente de la familia.
+-- ============================================
+CREATE TABLE refresh_tokens (
+    id SERIAL PRIMARY KEY,
+    sesion_id VARCHAR(64) NOT NULL REFERENCES sesiones(id) ON DELETE CASCADE,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    expira_at TIMESTAMP NOT NULL,
+    usado_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_sesiones_usuario ON sesiones(usuario_id);
+CREATE INDEX idx_refresh_tokens_sesion ON refresh_tokens(sesion_id);
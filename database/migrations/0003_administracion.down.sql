@@ -0,0 +1,20 @@
+-- La restricción original no admite el rol admin. En lugar de degradar en
+-- silencio a los administradores, la reversión se niega mientras exista
+-- alguno: hay que cambiarles el rol a mano antes de revertir.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM usuarios WHERE rol = 'admin') THEN
+        RAISE EXCEPTION 'existen usuarios admin; cámbiales el rol antes de revertir';
+    END IF;
+END $$;
+
+DROP INDEX IF EXISTS idx_usuarios_activo;
+
+ALTER TABLE cursos DROP COLUMN IF EXISTS bloqueado_at;
+ALTER TABLE cursos DROP COLUMN IF EXISTS motivo_bloqueo;
+ALTER TABLE cursos DROP COLUMN IF EXISTS bloqueado;
+
+ALTER TABLE usuarios DROP COLUMN IF EXISTS activo;
+
+ALTER TABLE usuarios DROP CONSTRAINT usuarios_rol_check;
+ALTER TABLE usuarios ADD CONSTRAINT usuarios_rol_check CHECK (rol IN ('instructor', 'alumno'));
@@ -0,0 +1,17 @@
+-- ============================================
+-- 0003: rol admin, usuarios deshabilitados y moderación de cursos
+-- ============================================
+
+ALTER TABLE usuarios DROP CONSTRAINT usuarios_rol_check;
+ALTER TABLE usuarios ADD CONSTRAINT usuarios_rol_check CHECK (rol IN ('instructor', 'alumno', 'admin'));
+
+-- Un usuario deshabilitado no puede iniciar sesión ni renovar tokens
+ALTER TABLE usuarios ADD COLUMN activo BOOLEAN NOT NULL DEFAULT true;
+
+-- Un curso bloqueado por un admin queda oculto para los alumnos y su
+-- instructor no puede volver a activarlo
+ALTER TABLE cursos ADD COLUMN bloqueado BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE cursos ADD COLUMN motivo_bloqueo TEXT;
+ALTER TABLE cursos ADD COLUMN bloqueado_at TIMESTAMP;
+
+CREATE INDEX idx_usuarios_activo ON usuarios(activo);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS solicitudes_instructor;
@@ -0,0 +1,20 @@
+-- ============================================
+-- 0004: solicitudes para ser instructor
+-- El registro público solo crea alumnos; un alumno solicita ser instructor y
+-- un admin aprueba (cambia su rol) o rechaza la solicitud.
+-- ============================================
+CREATE TABLE solicitudes_instructor (
+    id SERIAL PRIMARY KEY,
+    usuario_id INTEGER NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+    motivo TEXT NOT NULL,
+    estado VARCHAR(20) NOT NULL DEFAULT 'pendiente' CHECK (estado IN ('pendiente', 'aprobada', 'rechazada')),
+    revisado_por INTEGER REFERENCES usuarios(id) ON DELETE SET NULL,
+    comentario_revision TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revisada_at TIMESTAMP
+);
+
+-- Un usuario solo puede tener una solicitud pendiente a la vez
+CREATE UNIQUE INDEX idx_solicitudes_instructor_pendiente
+    ON solicitudes_instructor(usuario_id) WHERE estado = 'pendiente';
+CREATE INDEX idx_solicitudes_instructor_estado ON solicitudes_instructor(estado);
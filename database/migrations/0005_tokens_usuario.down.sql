@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS tokens_usuario;
+
+ALTER TABLE usuarios DROP COLUMN IF EXISTS email_verificado;
@@ -0,0 +1,25 @@
+-- ============================================
+-- 0005: verificación de email y restablecimiento de contraseña
+-- ============================================
+
+ALTER TABLE usuarios ADD COLUMN email_verificado BOOLEAN NOT NULL DEFAULT false;
+
+-- Las cuentas existentes se consideran verificadas para no bloquearlas
+UPDATE usuarios SET email_verificado = true;
+
+-- ============================================
+-- TABLA: tokens_usuario
+-- Tokens de un solo uso enviados por email. Solo se guarda el hash SHA-256;
+-- usado_at impide reutilizarlos.
+-- ============================================
+CREATE TABLE tokens_usuario (
+    id SERIAL PRIMARY KEY,
+    usuario_id INTEGER NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+    tipo VARCHAR(30) NOT NULL CHECK (tipo IN ('verificacion_email', 'reset_password')),
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    expira_at TIMESTAMP NOT NULL,
+    usado_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_tokens_usuario_usuario ON tokens_usuario(usuario_id, tipo);
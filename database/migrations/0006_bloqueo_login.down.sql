@@ -0,0 +1,4 @@
+ALTER TABLE usuarios DROP COLUMN IF EXISTS login_bloqueado_hasta;
+ALTER TABLE usuarios DROP COLUMN IF EXISTS bloqueos_login;
+ALTER TABLE usuarios DROP COLUMN IF EXISTS ultimo_intento_fallido_at;
+ALTER TABLE usuarios DROP COLUMN IF EXISTS intentos_fallidos;
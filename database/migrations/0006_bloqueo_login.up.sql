@@ -0,0 +1,10 @@
+-- ============================================
+-- 0006: protección contra fuerza bruta en el login
+-- intentos_fallidos cuenta los fallos dentro de la ventana (se reinicia si el
+-- último fallo es anterior a ella); bloqueos_login cuenta los bloqueos
+-- consecutivos para que cada uno dure más que el anterior.
+-- ============================================
+ALTER TABLE usuarios ADD COLUMN intentos_fallidos INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE usuarios ADD COLUMN ultimo_intento_fallido_at TIMESTAMP;
+ALTER TABLE usuarios ADD COLUMN bloqueos_login INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE usuarios ADD COLUMN login_bloqueado_hasta TIMESTAMP;
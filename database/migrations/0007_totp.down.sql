@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS codigos_recuperacion;
+
+ALTER TABLE usuarios DROP COLUMN IF EXISTS totp_ultimo_paso;
+ALTER TABLE usuarios DROP COLUMN IF EXISTS totp_habilitado;
+ALTER TABLE usuarios DROP COLUMN IF EXISTS totp_secret;
@@ -0,0 +1,23 @@
+-- ============================================
+-- 0007: autenticación en dos pasos (TOTP)
+-- totp_secret se guarda al iniciar el alta y totp_habilitado se activa al
+-- confirmarla con un código. totp_ultimo_paso es el último paso de tiempo
+-- aceptado, para que un mismo código no sirva dos veces.
+-- ============================================
+ALTER TABLE usuarios ADD COLUMN totp_secret VARCHAR(64);
+ALTER TABLE usuarios ADD COLUMN totp_habilitado BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE usuarios ADD COLUMN totp_ultimo_paso BIGINT NOT NULL DEFAULT 0;
+
+-- ============================================
+-- TABLA: codigos_recuperacion
+-- Códigos de un solo uso para entrar sin la app de autenticación. Solo se
+-- guarda el hash SHA-256.
+-- ============================================
+CREATE TABLE codigos_recuperacion (
+    id SERIAL PRIMARY KEY,
+    usuario_id INTEGER NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+    codigo_hash CHAR(64) NOT NULL,
+    usado_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(usuario_id, codigo_hash)
+);
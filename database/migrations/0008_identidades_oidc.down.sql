@@ -0,0 +1,7 @@
+DELETE FROM tokens_usuario WHERE tipo = 'oidc_login';
+ALTER TABLE tokens_usuario DROP CONSTRAINT tokens_usuario_tipo_check;
+ALTER TABLE tokens_usuario ADD CONSTRAINT tokens_usuario_tipo_check
+    CHECK (tipo IN ('verificacion_email', 'reset_password'));
+
+DROP TABLE IF EXISTS oidc_estados;
+DROP TABLE IF EXISTS user_identities;
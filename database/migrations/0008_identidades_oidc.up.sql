@@ -0,0 +1,39 @@
+-- ============================================
+-- 0008: login con proveedores de identidad externos (OIDC)
+-- ============================================
+
+-- ============================================
+-- TABLA: user_identities
+-- Identidades externas (proveedor + subject del id_token) vinculadas a un
+-- usuario. Un usuario puede tener varias, una por proveedor.
+-- ============================================
+CREATE TABLE user_identities (
+    id SERIAL PRIMARY KEY,
+    usuario_id INTEGER NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+    proveedor VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ultimo_login_at TIMESTAMP,
+    UNIQUE(proveedor, subject),
+    UNIQUE(usuario_id, proveedor)
+);
+
+-- ============================================
+-- TABLA: oidc_estados
+-- Logins OIDC en curso: el state (solo su hash), el nonce y el code_verifier
+-- de PKCE, que se recuperan una sola vez en el callback.
+-- ============================================
+CREATE TABLE oidc_estados (
+    state_hash CHAR(64) PRIMARY KEY,
+    proveedor VARCHAR(50) NOT NULL,
+    nonce VARCHAR(64) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    expira_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Código de un solo uso con el que el frontend canjea un login OIDC por tokens
+ALTER TABLE tokens_usuario DROP CONSTRAINT tokens_usuario_tipo_check;
+ALTER TABLE tokens_usuario ADD CONSTRAINT tokens_usuario_tipo_check
+    CHECK (tipo IN ('verificacion_email', 'reset_password', 'oidc_login'));
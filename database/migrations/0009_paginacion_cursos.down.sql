@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_cursos_duracion_id;
+DROP INDEX IF EXISTS idx_cursos_nombre_id;
+DROP INDEX IF EXISTS idx_cursos_updated_at_id;
+DROP INDEX IF EXISTS idx_cursos_created_at_id;
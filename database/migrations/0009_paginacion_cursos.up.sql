@@ -0,0 +1,9 @@
+-- ============================================
+-- 0009: índices para el listado paginado de cursos
+-- La paginación por cursor ordena por (campo, id); con estos índices cada
+-- página se lee sin recorrer las anteriores.
+-- ============================================
+CREATE INDEX idx_cursos_created_at_id ON cursos(created_at, id);
+CREATE INDEX idx_cursos_updated_at_id ON cursos(updated_at, id);
+CREATE INDEX idx_cursos_nombre_id ON cursos(nombre, id);
+CREATE INDEX idx_cursos_duracion_id ON cursos(duracion_horas, id);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_lecciones_busqueda;
+DROP INDEX IF EXISTS idx_cursos_busqueda;
+
+ALTER TABLE lecciones DROP COLUMN IF EXISTS busqueda;
+ALTER TABLE cursos DROP COLUMN IF EXISTS busqueda;
+
+-- La extensión unaccent se deja instalada: puede existir desde antes de la
+-- migración o usarla otros objetos de la base de datos
+DROP TEXT SEARCH CONFIGURATION IF EXISTS es_unaccent;
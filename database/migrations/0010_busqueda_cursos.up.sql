@@ -0,0 +1,25 @@
+-- ============================================
+-- 0010: búsqueda de texto completo en el catálogo
+-- es_unaccent es la configuración española (stemming y palabras vacías) que
+-- además quita los acentos, así "programacion" encuentra "programación".
+-- Las columnas busqueda las mantiene Postgres al escribir nombre,
+-- descripcion o titulo.
+-- ============================================
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+CREATE TEXT SEARCH CONFIGURATION es_unaccent (COPY = spanish);
+ALTER TEXT SEARCH CONFIGURATION es_unaccent
+    ALTER MAPPING FOR hword, hword_part, word WITH unaccent, spanish_stem;
+
+-- El nombre pesa más que la descripción al ordenar por relevancia
+ALTER TABLE cursos ADD COLUMN busqueda tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('es_unaccent', COALESCE(nombre, '')), 'A') ||
+    setweight(to_tsvector('es_unaccent', COALESCE(descripcion, '')), 'B')
+) STORED;
+
+ALTER TABLE lecciones ADD COLUMN busqueda tsvector GENERATED ALWAYS AS (
+    to_tsvector('es_unaccent', COALESCE(titulo, ''))
+) STORED;
+
+CREATE INDEX idx_cursos_busqueda ON cursos USING GIN (busqueda);
+CREATE INDEX idx_lecciones_busqueda ON lecciones USING GIN (busqueda);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS curso_etiquetas;
+DROP TABLE IF EXISTS etiquetas;
+DROP TABLE IF EXISTS curso_categorias;
+DROP TABLE IF EXISTS categorias;
@@ -0,0 +1,47 @@
+-- ============================================
+-- 0011: categorías jerárquicas y etiquetas de cursos
+-- ============================================
+
+-- ============================================
+-- TABLA: categorias
+-- Árbol de categorías gestionado por los administradores. Una categoría con
+-- subcategorías no se puede borrar hasta mover o borrar las hijas.
+-- ============================================
+CREATE TABLE categorias (
+    id SERIAL PRIMARY KEY,
+    nombre VARCHAR(100) NOT NULL,
+    slug VARCHAR(100) NOT NULL UNIQUE,
+    descripcion TEXT,
+    padre_id INTEGER REFERENCES categorias(id) ON DELETE RESTRICT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (padre_id IS NULL OR padre_id <> id)
+);
+
+CREATE INDEX idx_categorias_padre ON categorias(padre_id);
+
+CREATE TABLE curso_categorias (
+    curso_id INTEGER NOT NULL REFERENCES cursos(id) ON DELETE CASCADE,
+    categoria_id INTEGER NOT NULL REFERENCES categorias(id) ON DELETE CASCADE,
+    PRIMARY KEY (curso_id, categoria_id)
+);
+
+CREATE INDEX idx_curso_categorias_categoria ON curso_categorias(categoria_id);
+
+-- ============================================
+-- TABLA: etiquetas
+-- Etiquetas libres que crean los instructores al asignarlas. El nombre se
+-- guarda normalizado (minúsculas, espacios simples).
+-- ============================================
+CREATE TABLE etiquetas (
+    id SERIAL PRIMARY KEY,
+    nombre VARCHAR(40) NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE curso_etiquetas (
+    curso_id INTEGER NOT NULL REFERENCES cursos(id) ON DELETE CASCADE,
+    etiqueta_id INTEGER NOT NULL REFERENCES etiquetas(id) ON DELETE CASCADE,
+    PRIMARY KEY (curso_id, etiqueta_id)
+);
+
+CREATE INDEX idx_curso_etiquetas_etiqueta ON curso_etiquetas(etiqueta_id);
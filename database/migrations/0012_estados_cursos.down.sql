@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS cursos_historial_estados;
+
+DROP INDEX IF EXISTS idx_cursos_despublicar_at;
+DROP INDEX IF EXISTS idx_cursos_publicar_at;
+DROP INDEX IF EXISTS idx_cursos_estado;
+
+ALTER TABLE cursos DROP COLUMN IF EXISTS despublicar_at;
+ALTER TABLE cursos DROP COLUMN IF EXISTS publicar_at;
+ALTER TABLE cursos DROP COLUMN IF EXISTS publicado_at;
+ALTER TABLE cursos DROP COLUMN IF EXISTS estado;
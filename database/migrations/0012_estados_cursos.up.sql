@@ -0,0 +1,39 @@
+-- ============================================
+-- 0012: ciclo de publicación de cursos
+-- estado sustituye al interruptor activo, que se mantiene como derivado
+-- (activo = estado publicado) para las consultas del catálogo.
+-- publicar_at y despublicar_at son cambios programados que aplica el proceso
+-- en segundo plano.
+-- ============================================
+ALTER TABLE cursos ADD COLUMN estado VARCHAR(20) NOT NULL DEFAULT 'borrador'
+    CHECK (estado IN ('borrador', 'en_revision', 'publicado', 'archivado'));
+ALTER TABLE cursos ADD COLUMN publicado_at TIMESTAMP;
+ALTER TABLE cursos ADD COLUMN publicar_at TIMESTAMP;
+ALTER TABLE cursos ADD COLUMN despublicar_at TIMESTAMP;
+
+-- Los cursos existentes conservan su visibilidad: los activos quedan
+-- publicados y los inactivos archivados (su instructor puede reactivarlos)
+UPDATE cursos
+SET estado = CASE WHEN activo THEN 'publicado' ELSE 'archivado' END,
+    publicado_at = CASE WHEN activo THEN created_at END;
+
+CREATE INDEX idx_cursos_estado ON cursos(estado);
+CREATE INDEX idx_cursos_publicar_at ON cursos(publicar_at) WHERE publicar_at IS NOT NULL;
+CREATE INDEX idx_cursos_despublicar_at ON cursos(despublicar_at) WHERE despublicar_at IS NOT NULL;
+
+-- ============================================
+-- TABLA: cursos_historial_estados
+-- Cada cambio de estado de un curso. usuario_id es NULL cuando lo aplicó el
+-- proceso de publicación programada.
+-- ============================================
+CREATE TABLE cursos_historial_estados (
+    id SERIAL PRIMARY KEY,
+    curso_id INTEGER NOT NULL REFERENCES cursos(id) ON DELETE CASCADE,
+    estado_anterior VARCHAR(20) NOT NULL,
+    estado_nuevo VARCHAR(20) NOT NULL,
+    usuario_id INTEGER REFERENCES usuarios(id) ON DELETE SET NULL,
+    motivo TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_cursos_historial_curso ON cursos_historial_estados(curso_id, created_at);
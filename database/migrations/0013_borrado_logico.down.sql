@@ -0,0 +1,19 @@
+-- Al quitar la columna las filas eliminadas volverían a ser visibles, y
+-- borrarlas aquí se llevaría en cascada sus certificados. Antes de revertir
+-- hay que restaurarlas o esperar a que la purga las elimine.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM cursos WHERE deleted_at IS NOT NULL)
+        OR EXISTS (SELECT 1 FROM usuarios WHERE deleted_at IS NOT NULL) THEN
+        RAISE EXCEPTION 'hay usuarios o cursos eliminados pendientes de purga; restáuralos o púrgalos antes de revertir';
+    END IF;
+END $$;
+
+DROP INDEX IF EXISTS idx_cursos_deleted_at;
+DROP INDEX IF EXISTS idx_usuarios_deleted_at;
+DROP INDEX IF EXISTS idx_usuarios_email_vigente;
+
+ALTER TABLE usuarios ADD CONSTRAINT usuarios_email_key UNIQUE (email);
+
+ALTER TABLE cursos DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE usuarios DROP COLUMN IF EXISTS deleted_at;
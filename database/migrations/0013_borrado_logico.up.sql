@@ -0,0 +1,16 @@
+-- ============================================
+-- 0013: borrado lógico de usuarios y cursos
+-- deleted_at marca las filas eliminadas, que las consultas excluyen. Se
+-- pueden restaurar dentro del plazo de retención; después el proceso de
+-- purga las borra definitivamente (y con ellas, en cascada, sus datos).
+-- ============================================
+ALTER TABLE usuarios ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE cursos ADD COLUMN deleted_at TIMESTAMP;
+
+-- Un usuario eliminado no retiene su email: se puede registrar otra cuenta
+-- con él, y restaurar el eliminado falla mientras exista esa otra cuenta
+ALTER TABLE usuarios DROP CONSTRAINT usuarios_email_key;
+CREATE UNIQUE INDEX idx_usuarios_email_vigente ON usuarios(email) WHERE deleted_at IS NULL;
+
+CREATE INDEX idx_usuarios_deleted_at ON usuarios(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_cursos_deleted_at ON cursos(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE resultados_evaluacion DROP COLUMN IF EXISTS calificado_por;
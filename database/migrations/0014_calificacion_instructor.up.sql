@@ -0,0 +1,8 @@
+-- ============================================
+-- 0014: calificación manual por el instructor
+-- calificado_por guarda qué instructor registró la nota. Los resultados que
+-- presenta el propio alumno y los de intentos lo dejan en NULL. Los
+-- resultados existentes no se tocan: para el certificado solo cuentan los de
+-- intentos o los registrados por un instructor.
+-- ============================================
+ALTER TABLE resultados_evaluacion ADD COLUMN calificado_por INTEGER REFERENCES usuarios(id) ON DELETE SET NULL;
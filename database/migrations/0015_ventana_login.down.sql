@@ -0,0 +1,4 @@
+ALTER TABLE usuarios ADD COLUMN intentos_fallidos INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE usuarios ADD COLUMN ultimo_intento_fallido_at TIMESTAMP;
+
+DROP TABLE IF EXISTS login_fallidos;
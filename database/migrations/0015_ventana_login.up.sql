@@ -0,0 +1,16 @@
+-- ============================================
+-- 0015: ventana deslizante de logins fallidos
+-- Cada fallo es una fila, así que el bloqueo cuenta exactamente los fallos de
+-- la ventana en lugar de reiniciar el contador tras una pausa. bloqueos_login
+-- decae con el tiempo a partir de login_bloqueado_hasta.
+-- ============================================
+CREATE TABLE login_fallidos (
+    id SERIAL PRIMARY KEY,
+    usuario_id INTEGER NOT NULL REFERENCES usuarios(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_login_fallidos_usuario ON login_fallidos(usuario_id, created_at);
+
+ALTER TABLE usuarios DROP COLUMN IF EXISTS intentos_fallidos;
+ALTER TABLE usuarios DROP COLUMN IF EXISTS ultimo_intento_fallido_at;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_usuarios_email_vigente;
+CREATE UNIQUE INDEX idx_usuarios_email_vigente ON usuarios(email) WHERE deleted_at IS NULL;
@@ -0,0 +1,21 @@
+-- ============================================
+-- 0016: el email es único sin distinguir mayúsculas
+-- La API normaliza los emails a minúsculas y los busca con lower(email), así
+-- que "Ana@example.com" y "ana@example.com" son la misma cuenta. Si ya hay
+-- cuentas vigentes que solo difieren en mayúsculas la migración falla: hay
+-- que resolver los duplicados a mano antes de aplicarla.
+-- ============================================
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT lower(email) FROM usuarios
+        WHERE deleted_at IS NULL
+        GROUP BY lower(email)
+        HAVING COUNT(*) > 1
+    ) THEN
+        RAISE EXCEPTION 'hay usuarios vigentes cuyo email solo difiere en mayúsculas';
+    END IF;
+END $$;
+
+DROP INDEX IF EXISTS idx_usuarios_email_vigente;
+CREATE UNIQUE INDEX idx_usuarios_email_vigente ON usuarios(lower(email)) WHERE deleted_at IS NULL;
@@ -0,0 +1,28 @@
+-- ============================================
+-- DATOS DE PRUEBA (solo desarrollo)
+-- Ejecutar después de aplicar las migraciones:
+--   psql -U postgres -d cursos_db -f database/seed.sql
+-- ============================================
+
+-- Nota: Las contraseñas deben ser hasheadas en la aplicación
+-- Estos son solo ejemplos, usar el endpoint /api/auth/register en producción
+
+INSERT INTO usuarios (nombre, email, password_hash, rol) VALUES
+('Juan Pérez', 'juan.instructor@example.com', '$2a$10$ejemplo_hash_contraseña', 'instructor'),
+('María García', 'maria.instructor@example.com', '$2a$10$ejemplo_hash_contraseña', 'instructor'),
+('Carlos López', 'carlos.alumno@example.com', '$2a$10$ejemplo_hash_contraseña', 'alumno'),
+('Ana Martínez', 'ana.alumno@example.com', '$2a$10$ejemplo_hash_contraseña', 'alumno')
+ON CONFLICT (lower(email)) WHERE deleted_at IS NULL DO NOTHING;
+
+-- Insertar cursos de prueba (solo si la tabla está vacía)
+INSERT INTO cursos (nombre, descripcion, duracion_horas, instructor_id, activo, estado, publicado_at)
+SELECT c.nombre, c.descripcion, c.duracion_horas, u.id, c.estado = 'publicado', c.estado,
+       CASE WHEN c.estado = 'publicado' THEN CURRENT_TIMESTAMP END
+FROM (VALUES
+    ('Introducción a Go', 'Aprende los fundamentos del lenguaje de programación Go', 40, 'juan.instructor@example.com', 'publicado'),
+    ('Desarrollo Web con React', 'Construcción de aplicaciones web modernas con React', 60, 'juan.instructor@example.com', 'publicado'),
+    ('Bases de Datos PostgreSQL', 'Diseño y administración de bases de datos relacionales', 30, 'maria.instructor@example.com', 'publicado'),
+    ('Arquitectura de Software', 'Patrones y mejores prácticas en arquitectura de software', 50, 'maria.instructor@example.com', 'borrador')
+) AS c(nombre, descripcion, duracion_horas, instructor_email, estado)
+JOIN usuarios u ON u.email = c.instructor_email AND u.deleted_at IS NULL
+WHERE NOT EXISTS (SELECT 1 FROM cursos);
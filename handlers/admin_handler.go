@@ -0,0 +1,190 @@
+package handlers
+
+import (
+    "cursos-api/middleware"
+    "cursos-api/models"
+    "cursos-api/services"
+    "cursos-api/utils"
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "github.com/gorilla/mux"
+)
+
+type AdminHandler struct {
+    adminService *services.AdminService
+}
+
+func NewAdminHandler(adminService *services.AdminService) *AdminHandler {
+    return &AdminHandler{
+        adminService: adminService,
+    }
+}
+
+// SearchUsuarios lista usuarios filtrando por ?q=, ?rol= y ?activo=
+func (h *AdminHandler) SearchUsuarios(w http.ResponseWriter, r *http.Request) {
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    query := r.URL.Query()
+    filtro := models.UsuarioFiltro{
+        Query: query.Get("q"),
+        Rol:   query.Get("rol"),
+    }
+
+    if valor := query.Get("activo"); valor != "" {
+        activo, err := strconv.ParseBool(valor)
+        if err != nil {
+            respondError(w, http.StatusBadRequest, "Valor de activo inválido")
+            return
+        }
+        filtro.Activo = &activo
+    }
+
+    if valor := query.Get("eliminados"); valor != "" {
+        eliminados, err := strconv.ParseBool(valor)
+        if err != nil {
+            respondError(w, http.StatusBadRequest, "Valor de eliminados inválido")
+            return
+        }
+        filtro.Eliminados = eliminados
+    }
+
+    usuarios, err := h.adminService.SearchUsuarios(filtro, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusForbidden, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, usuarios)
+}
+
+// SetUsuarioActivo habilita o deshabilita un usuario
+func (h *AdminHandler) SetUsuarioActivo(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+    id, err := strconv.Atoi(vars["id"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID inválido")
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    var req struct {
+        Activo *bool `json:"activo"`
+    }
+
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Activo == nil {
+        respondError(w, http.StatusBadRequest, "Datos inválidos")
+        return
+    }
+
+    usuario, err := h.adminService.SetUsuarioActivo(id, *req.Activo, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    message := "Usuario habilitado exitosamente"
+    if !usuario.Activo {
+        message = "Usuario deshabilitado exitosamente"
+    }
+
+    respondJSON(w, http.StatusOK, map[string]interface{}{
+        "message": message,
+        "usuario": usuario,
+    })
+}
+
+// RestaurarUsuario recupera un usuario eliminado y los cursos que se
+// eliminaron con él
+func (h *AdminHandler) RestaurarUsuario(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID inválido")
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    usuario, err := h.adminService.RestaurarUsuario(id, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]interface{}{
+        "message": "Usuario restaurado exitosamente",
+        "usuario": usuario,
+    })
+}
+
+// ReasignarInstructor cambia el instructor de un curso
+func (h *AdminHandler) ReasignarInstructor(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+    id, err := strconv.Atoi(vars["id"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID inválido")
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    var req struct {
+        InstructorID int `json:"instructor_id"`
+    }
+
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        respondError(w, http.StatusBadRequest, "Datos inválidos")
+        return
+    }
+
+    curso, err := h.adminService.ReasignarInstructor(id, req.InstructorID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]interface{}{
+        "message": "Curso reasignado exitosamente",
+        "curso":   curso,
+    })
+}
+
+// ModerarCurso bloquea o desbloquea un curso
+func (h *AdminHandler) ModerarCurso(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+    id, err := strconv.Atoi(vars["id"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID inválido")
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    var req struct {
+        Bloqueado *bool  `json:"bloqueado"`
+        Motivo    string `json:"motivo"`
+    }
+
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Bloqueado == nil {
+        respondError(w, http.StatusBadRequest, "Datos inválidos")
+        return
+    }
+
+    curso, err := h.adminService.ModerarCurso(id, *req.Bloqueado, req.Motivo, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    message := "Curso desbloqueado exitosamente"
+    if curso.Bloqueado {
+        message = "Curso bloqueado exitosamente"
+    }
+
+    respondJSON(w, http.StatusOK, map[string]interface{}{
+        "message": message,
+        "curso":   curso,
+    })
+}
@@ -6,16 +6,21 @@ import (
     "cursos-api/services"
     "cursos-api/utils"
     "encoding/json"
+    "errors"
+    "math"
     "net/http"
+    "strconv"
 )
 
 type AuthHandler struct {
-    authService *services.AuthService
+    authService   *services.AuthService
+    cuentaService *services.CuentaService
 }
 
-func NewAuthHandler() *AuthHandler {
+func NewAuthHandler(authService *services.AuthService, cuentaService *services.CuentaService) *AuthHandler {
     return &AuthHandler{
-        authService: services.NewAuthService(),
+        authService:   authService,
+        cuentaService: cuentaService,
     }
 }
 
@@ -29,22 +34,32 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    // Registrar usuario y generar token
-    usuario, token, err := h.authService.Register(&req)
+    // Registrar usuario y generar tokens
+    usuario, tokens, err := h.authService.Register(&req)
     if err != nil {
         respondError(w, http.StatusBadRequest, err.Error())
         return
     }
 
-    // Responder JSON con usuario y token
+    // Sin tokens cuando el login exige verificar antes el email
+    if tokens == nil {
+        respondJSON(w, http.StatusCreated, map[string]interface{}{
+            "message": "Usuario registrado exitosamente, revisa tu correo para verificar tu email",
+            "usuario": usuario,
+        })
+        return
+    }
+
+    // Responder JSON con usuario y tokens
     respondJSON(w, http.StatusCreated, map[string]interface{}{
-        "message": "Usuario registrado exitosamente",
-        "usuario": usuario,
-        "token":   token,
+        "message":       "Usuario registrado exitosamente",
+        "usuario":       usuario,
+        "token":         tokens.Token,
+        "refresh_token": tokens.RefreshToken,
+        "expires_in":    tokens.ExpiresIn,
     })
 }
 
-
 // Login maneja el inicio de sesión
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
     var req models.LoginRequest
@@ -54,15 +69,152 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    response, err := h.authService.Login(&req)
+    response, err := h.authService.Login(&req, utils.ClientIP(r))
     if err != nil {
-        respondError(w, http.StatusUnauthorized, err.Error())
+        respondLoginError(w, err)
+        return
+    }
+
+    respondJSON(w, http.StatusOK, response)
+}
+
+// LoginMFA completa el login de un usuario con 2FA
+func (h *AuthHandler) LoginMFA(w http.ResponseWriter, r *http.Request) {
+    var req models.LoginMFARequest
+
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        respondError(w, http.StatusBadRequest, "Datos inválidos")
+        return
+    }
+
+    response, err := h.authService.CompletarLoginMFA(&req, utils.ClientIP(r))
+    if err != nil {
+        respondLoginError(w, err)
         return
     }
 
     respondJSON(w, http.StatusOK, response)
 }
 
+// respondLoginError responde 429 con Retry-After si el login está limitado y
+// 401 en otro caso
+func respondLoginError(w http.ResponseWriter, err error) {
+    var limitado *services.LoginLimitadoError
+    if errors.As(err, &limitado) {
+        w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(limitado.RetryAfter.Seconds()))))
+        respondError(w, http.StatusTooManyRequests, err.Error())
+        return
+    }
+
+    respondError(w, http.StatusUnauthorized, err.Error())
+}
+
+// Refresh renueva el token de acceso rotando el refresh token
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+    var req models.RefreshRequest
+
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        respondError(w, http.StatusBadRequest, "Datos inválidos")
+        return
+    }
+
+    tokens, err := h.authService.Refresh(&req)
+    if err != nil {
+        respondError(w, http.StatusUnauthorized, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, tokens)
+}
+
+// Logout cierra la sesión actual (o todas con {"todas": true})
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    var req struct {
+        Todas bool `json:"todas"`
+    }
+
+    // El body es opcional
+    if r.ContentLength != 0 {
+        if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+            respondError(w, http.StatusBadRequest, "Datos inválidos")
+            return
+        }
+    }
+
+    if err := h.authService.Logout(claims.UserID, claims.SesionID, req.Todas); err != nil {
+        respondError(w, http.StatusInternalServerError, "Error al cerrar la sesión")
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]string{
+        "message": "Sesión cerrada exitosamente",
+    })
+}
+
+// VerifyEmail verifica el email con el token del enlace enviado (?token=)
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+    if err := h.cuentaService.VerificarEmail(r.URL.Query().Get("token")); err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]string{
+        "message": "Email verificado exitosamente",
+    })
+}
+
+// ResendVerification reenvía el enlace de verificación al usuario autenticado
+func (h *AuthHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    if err := h.cuentaService.ReenviarVerificacion(claims.UserID); err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]string{
+        "message": "Enlace de verificación enviado",
+    })
+}
+
+// ForgotPassword envía un enlace para restablecer la contraseña. Responde
+// siempre igual para no revelar qué emails están registrados.
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+    var req models.ForgotPasswordRequest
+
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+        respondError(w, http.StatusBadRequest, "Email requerido")
+        return
+    }
+
+    h.cuentaService.SolicitarResetPassword(req.Email)
+
+    respondJSON(w, http.StatusOK, map[string]string{
+        "message": "Si el email está registrado, recibirás un enlace para restablecer la contraseña",
+    })
+}
+
+// ResetPassword establece una contraseña nueva con el token recibido por email
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+    var req models.ResetPasswordRequest
+
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        respondError(w, http.StatusBadRequest, "Datos inválidos")
+        return
+    }
+
+    if err := h.cuentaService.ResetPassword(&req); err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]string{
+        "message": "Contraseña restablecida exitosamente, inicia sesión de nuevo",
+    })
+}
+
 // GetProfile obtiene el perfil del usuario autenticado
 func (h *AuthHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
     claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
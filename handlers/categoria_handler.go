@@ -0,0 +1,171 @@
+package handlers
+
+import (
+    "cursos-api/middleware"
+    "cursos-api/models"
+    "cursos-api/services"
+    "cursos-api/utils"
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "github.com/gorilla/mux"
+)
+
+type CategoriaHandler struct {
+    categoriaService *services.CategoriaService
+}
+
+func NewCategoriaHandler(categoriaService *services.CategoriaService) *CategoriaHandler {
+    return &CategoriaHandler{
+        categoriaService: categoriaService,
+    }
+}
+
+// GetArbol devuelve el árbol de categorías (público)
+func (h *CategoriaHandler) GetArbol(w http.ResponseWriter, r *http.Request) {
+    arbol, err := h.categoriaService.Arbol()
+    if err != nil {
+        respondError(w, http.StatusInternalServerError, "Error al obtener categorías")
+        return
+    }
+
+    respondJSON(w, http.StatusOK, arbol)
+}
+
+// GetEtiquetas devuelve las etiquetas en uso en el catálogo (público)
+func (h *CategoriaHandler) GetEtiquetas(w http.ResponseWriter, r *http.Request) {
+    etiquetas, err := h.categoriaService.Etiquetas()
+    if err != nil {
+        respondError(w, http.StatusInternalServerError, "Error al obtener etiquetas")
+        return
+    }
+
+    respondJSON(w, http.StatusOK, etiquetas)
+}
+
+// Create crea una categoría (admin)
+func (h *CategoriaHandler) Create(w http.ResponseWriter, r *http.Request) {
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    var req models.CategoriaRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        respondError(w, http.StatusBadRequest, "Datos inválidos")
+        return
+    }
+
+    categoria, err := h.categoriaService.Create(req, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusCreated, map[string]interface{}{
+        "message":   "Categoría creada exitosamente",
+        "categoria": categoria,
+    })
+}
+
+// Update actualiza o mueve una categoría (admin)
+func (h *CategoriaHandler) Update(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID inválido")
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    var req models.CategoriaRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        respondError(w, http.StatusBadRequest, "Datos inválidos")
+        return
+    }
+
+    categoria, err := h.categoriaService.Update(id, req, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]interface{}{
+        "message":   "Categoría actualizada exitosamente",
+        "categoria": categoria,
+    })
+}
+
+// Delete elimina una categoría sin subcategorías (admin)
+func (h *CategoriaHandler) Delete(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID inválido")
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    if err := h.categoriaService.Delete(id, claims.Rol); err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]string{
+        "message": "Categoría eliminada exitosamente",
+    })
+}
+
+// AsignarCategorias reemplaza las categorías de un curso
+func (h *CategoriaHandler) AsignarCategorias(w http.ResponseWriter, r *http.Request) {
+    cursoID, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID inválido")
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    var req models.AsignarCategoriasRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        respondError(w, http.StatusBadRequest, "Datos inválidos")
+        return
+    }
+
+    curso, err := h.categoriaService.AsignarCategorias(cursoID, req.CategoriaIDs, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]interface{}{
+        "message": "Categorías actualizadas exitosamente",
+        "curso":   curso,
+    })
+}
+
+// AsignarEtiquetas reemplaza las etiquetas de un curso
+func (h *CategoriaHandler) AsignarEtiquetas(w http.ResponseWriter, r *http.Request) {
+    cursoID, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID inválido")
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    var req models.AsignarEtiquetasRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        respondError(w, http.StatusBadRequest, "Datos inválidos")
+        return
+    }
+
+    curso, err := h.categoriaService.AsignarEtiquetas(cursoID, req.Etiquetas, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]interface{}{
+        "message": "Etiquetas actualizadas exitosamente",
+        "curso":   curso,
+    })
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+    "cursos-api/middleware"
+    "cursos-api/services"
+    "cursos-api/utils"
+    "net/http"
+    "strconv"
+
+    "github.com/gorilla/mux"
+)
+
+type CertificadoHandler struct {
+    certificadoService *services.CertificadoService
+}
+
+func NewCertificadoHandler(certificadoService *services.CertificadoService) *CertificadoHandler {
+    return &CertificadoHandler{
+        certificadoService: certificadoService,
+    }
+}
+
+// Emitir emite el certificado del alumno autenticado en un curso
+func (h *CertificadoHandler) Emitir(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+    cursoID, err := strconv.Atoi(vars["id"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID inválido")
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    certificado, err := h.certificadoService.Emitir(cursoID, claims.UserID)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]interface{}{
+        "message":     "Certificado emitido exitosamente",
+        "certificado": certificado,
+    })
+}
+
+// GetMyCertificados obtiene los certificados del alumno autenticado
+func (h *CertificadoHandler) GetMyCertificados(w http.ResponseWriter, r *http.Request) {
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    certificados, err := h.certificadoService.GetMyCertificados(claims.UserID)
+    if err != nil {
+        respondError(w, http.StatusInternalServerError, "Error al obtener certificados")
+        return
+    }
+
+    respondJSON(w, http.StatusOK, certificados)
+}
+
+// DownloadPDF descarga el PDF de un certificado
+func (h *CertificadoHandler) DownloadPDF(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+    id, err := strconv.Atoi(vars["id"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID inválido")
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    pdf, certificado, err := h.certificadoService.GetPDF(id, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusNotFound, err.Error())
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/pdf")
+    w.Header().Set("Content-Disposition", `attachment; filename="certificado-`+certificado.CodigoCertificado+`.pdf"`)
+    w.Header().Set("Content-Length", strconv.Itoa(len(pdf)))
+    w.WriteHeader(http.StatusOK)
+    w.Write(pdf)
+}
+
+// Verify confirma públicamente la validez de un certificado
+func (h *CertificadoHandler) Verify(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+
+    verificacion, err := h.certificadoService.Verify(vars["codigo"])
+    if err != nil {
+        respondError(w, http.StatusNotFound, "Certificado no encontrado")
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]interface{}{
+        "valido":      true,
+        "certificado": verificacion,
+    })
+}
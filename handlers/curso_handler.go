@@ -3,11 +3,17 @@ package handlers
 import (
     "cursos-api/middleware"
     "cursos-api/models"
+    "cursos-api/policy"
     "cursos-api/services"
     "cursos-api/utils"
     "encoding/json"
+    "errors"
+    "fmt"
     "net/http"
+    "net/url"
     "strconv"
+    "strings"
+    "time"
 
     "github.com/gorilla/mux"
 )
@@ -16,9 +22,9 @@ type CursoHandler struct {
     cursoService *services.CursoService
 }
 
-func NewCursoHandler() *CursoHandler {
+func NewCursoHandler(cursoService *services.CursoService) *CursoHandler {
     return &CursoHandler{
-        cursoService: services.NewCursoService(),
+        cursoService: cursoService,
     }
 }
 
@@ -44,17 +50,148 @@ func (h *CursoHandler) Create(w http.ResponseWriter, r *http.Request) {
     })
 }
 
-// GetAll obtiene todos los cursos
+// GetAll lista los cursos visibles para el usuario paginados por cursor.
+// Admite ?activo=, ?estado=, ?instructor_id=, ?categoria_id= (incluye subcategorías),
+// ?etiqueta=, ?duracion_min=, ?duracion_max=, ?desde=, ?hasta=, ?orden= (con
+// "-" delante para descendente), ?limite= y ?cursor=.
 func (h *CursoHandler) GetAll(w http.ResponseWriter, r *http.Request) {
     claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
 
-    cursos, err := h.cursoService.GetAll(claims.Rol, claims.UserID)
+    filtro, err := parseCursoFiltro(r.URL.Query())
     if err != nil {
-        respondError(w, http.StatusInternalServerError, "Error al obtener cursos")
+        respondError(w, http.StatusBadRequest, err.Error())
         return
     }
 
-    respondJSON(w, http.StatusOK, cursos)
+    pagina, err := h.cursoService.List(filtro, claims.Rol, claims.UserID)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, pagina)
+}
+
+// Search busca cursos por texto (?q=) en nombre, descripción y títulos de
+// lecciones, paginando con ?limite= y ?offset=
+func (h *CursoHandler) Search(w http.ResponseWriter, r *http.Request) {
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    query := r.URL.Query()
+    busqueda := models.CursoBusqueda{Texto: query.Get("q")}
+
+    if valor := query.Get("limite"); valor != "" {
+        limite, err := strconv.Atoi(valor)
+        if err != nil {
+            respondError(w, http.StatusBadRequest, "Valor de limite inválido")
+            return
+        }
+        busqueda.Limite = limite
+    }
+
+    if valor := query.Get("offset"); valor != "" {
+        offset, err := strconv.Atoi(valor)
+        if err != nil {
+            respondError(w, http.StatusBadRequest, "Valor de offset inválido")
+            return
+        }
+        busqueda.Offset = offset
+    }
+
+    resultados, err := h.cursoService.Search(busqueda, claims.Rol, claims.UserID)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, resultados)
+}
+
+// parseCursoFiltro lee el filtro del listado de cursos de la query string.
+// Por defecto los cursos van del más reciente al más antiguo.
+func parseCursoFiltro(query url.Values) (models.CursoFiltro, error) {
+    filtro := models.CursoFiltro{Estado: query.Get("estado"), Cursor: query.Get("cursor"), Desc: true}
+
+    if orden := query.Get("orden"); orden != "" {
+        filtro.Orden = strings.TrimPrefix(orden, "-")
+        filtro.Desc = strings.HasPrefix(orden, "-")
+    }
+
+    if valor := query.Get("activo"); valor != "" {
+        activo, err := strconv.ParseBool(valor)
+        if err != nil {
+            return filtro, errors.New("Valor de activo inválido")
+        }
+        filtro.Activo = &activo
+    }
+
+    if valor := query.Get("eliminados"); valor != "" {
+        eliminados, err := strconv.ParseBool(valor)
+        if err != nil {
+            return filtro, errors.New("Valor de eliminados inválido")
+        }
+        filtro.Eliminados = eliminados
+    }
+
+    enteros := []struct {
+        nombre  string
+        destino *int
+    }{
+        {"instructor_id", &filtro.InstructorID},
+        {"categoria_id", &filtro.CategoriaID},
+        {"duracion_min", &filtro.DuracionMin},
+        {"duracion_max", &filtro.DuracionMax},
+        {"limite", &filtro.Limite},
+    }
+    for _, campo := range enteros {
+        if valor := query.Get(campo.nombre); valor != "" {
+            n, err := strconv.Atoi(valor)
+            if err != nil {
+                return filtro, fmt.Errorf("Valor de %s inválido", campo.nombre)
+            }
+            *campo.destino = n
+        }
+    }
+
+    if valor := query.Get("desde"); valor != "" {
+        desde, _, err := parseFechaFiltro(valor)
+        if err != nil {
+            return filtro, errors.New("Valor de desde inválido (AAAA-MM-DD o RFC 3339)")
+        }
+        filtro.CreadoDesde = &desde
+    }
+
+    if valor := query.Get("hasta"); valor != "" {
+        hasta, soloFecha, err := parseFechaFiltro(valor)
+        if err != nil {
+            return filtro, errors.New("Valor de hasta inválido (AAAA-MM-DD o RFC 3339)")
+        }
+        // Una fecha sin hora incluye el día completo
+        if soloFecha {
+            hasta = hasta.AddDate(0, 0, 1)
+        }
+        filtro.CreadoHasta = &hasta
+    }
+
+    // ?etiqueta= se puede repetir o separar por comas; el curso debe tenerlas todas
+    for _, valor := range query["etiqueta"] {
+        for _, etiqueta := range strings.Split(valor, ",") {
+            if etiqueta = services.NormalizarEtiqueta(etiqueta); etiqueta != "" {
+                filtro.Etiquetas = append(filtro.Etiquetas, etiqueta)
+            }
+        }
+    }
+
+    return filtro, nil
+}
+
+// parseFechaFiltro acepta una fecha (AAAA-MM-DD) o una fecha y hora RFC 3339
+func parseFechaFiltro(valor string) (time.Time, bool, error) {
+    if fecha, err := time.Parse("2006-01-02", valor); err == nil {
+        return fecha, true, nil
+    }
+    fecha, err := time.Parse(time.RFC3339, valor)
+    return fecha, false, err
 }
 
 // GetByID obtiene un curso por ID
@@ -81,7 +218,7 @@ func (h *CursoHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 func (h *CursoHandler) GetMyCursos(w http.ResponseWriter, r *http.Request) {
     claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
 
-    if claims.Rol != "instructor" {
+    if !policy.Puede(claims.Rol, policy.CursoCreate) {
         respondError(w, http.StatusForbidden, "Solo los instructores pueden acceder a esta ruta")
         return
     }
@@ -146,6 +283,28 @@ func (h *CursoHandler) Delete(w http.ResponseWriter, r *http.Request) {
     })
 }
 
+// Restaurar recupera un curso eliminado dentro del plazo de retención
+func (h *CursoHandler) Restaurar(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID inválido")
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    curso, err := h.cursoService.Restaurar(id, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]interface{}{
+        "message": "Curso restaurado exitosamente",
+        "curso":   curso,
+    })
+}
+
 // ToggleActivo activa o desactiva un curso
 func (h *CursoHandler) ToggleActivo(w http.ResponseWriter, r *http.Request) {
     vars := mux.Vars(r)
@@ -166,6 +325,8 @@ func (h *CursoHandler) ToggleActivo(w http.ResponseWriter, r *http.Request) {
     status := "desactivado"
     if curso.Activo {
         status = "activado"
+    } else if curso.Estado == services.EstadoCursoEnRevision {
+        status = "enviado a revisión"
     }
 
     respondJSON(w, http.StatusOK, map[string]interface{}{
@@ -173,3 +334,79 @@ func (h *CursoHandler) ToggleActivo(w http.ResponseWriter, r *http.Request) {
         "curso":   curso,
     })
 }
+
+// CambiarEstado mueve un curso por su ciclo de publicación (borrador,
+// en_revision, publicado, archivado)
+func (h *CursoHandler) CambiarEstado(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID inválido")
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    var req models.CambiarEstadoCursoRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        respondError(w, http.StatusBadRequest, "Datos inválidos")
+        return
+    }
+
+    curso, err := h.cursoService.CambiarEstado(id, req, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]interface{}{
+        "message": "Estado del curso actualizado exitosamente",
+        "curso":   curso,
+    })
+}
+
+// Programar fija la publicación y la retirada programadas de un curso
+func (h *CursoHandler) Programar(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID inválido")
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    var req models.ProgramacionCursoRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        respondError(w, http.StatusBadRequest, "Datos inválidos (fechas en RFC 3339)")
+        return
+    }
+
+    curso, err := h.cursoService.Programar(id, req, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]interface{}{
+        "message": "Programación del curso actualizada exitosamente",
+        "curso":   curso,
+    })
+}
+
+// GetHistorial devuelve los cambios de estado de un curso
+func (h *CursoHandler) GetHistorial(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(mux.Vars(r)["id"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID inválido")
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    historial, err := h.cursoService.Historial(id, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusForbidden, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, historial)
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+    "cursos-api/middleware"
+    "cursos-api/models"
+    "cursos-api/services"
+    "cursos-api/utils"
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "github.com/gorilla/mux"
+)
+
+type EvaluacionHandler struct {
+    evaluacionService *services.EvaluacionService
+}
+
+func NewEvaluacionHandler(evaluacionService *services.EvaluacionService) *EvaluacionHandler {
+    return &EvaluacionHandler{
+        evaluacionService: evaluacionService,
+    }
+}
+
+// Create crea una evaluación en un curso
+func (h *EvaluacionHandler) Create(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+    cursoID, err := strconv.Atoi(vars["id"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID inválido")
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    var evaluacion models.Evaluacion
+    if err := json.NewDecoder(r.Body).Decode(&evaluacion); err != nil {
+        respondError(w, http.StatusBadRequest, "Datos inválidos")
+        return
+    }
+
+    createdEvaluacion, err := h.evaluacionService.Create(cursoID, &evaluacion, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusCreated, map[string]interface{}{
+        "message":    "Evaluación creada exitosamente",
+        "evaluacion": createdEvaluacion,
+    })
+}
+
+// GetByCurso obtiene las evaluaciones de un curso
+func (h *EvaluacionHandler) GetByCurso(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+    cursoID, err := strconv.Atoi(vars["id"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID inválido")
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    evaluaciones, err := h.evaluacionService.GetByCurso(cursoID, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusForbidden, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, evaluaciones)
+}
+
+// GetByID obtiene una evaluación de un curso
+func (h *EvaluacionHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+    cursoID, evaluacionID, ok := parseEvaluacionVars(w, r)
+    if !ok {
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    evaluacion, err := h.evaluacionService.GetByID(cursoID, evaluacionID, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusNotFound, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, evaluacion)
+}
+
+// Update actualiza una evaluación
+func (h *EvaluacionHandler) Update(w http.ResponseWriter, r *http.Request) {
+    cursoID, evaluacionID, ok := parseEvaluacionVars(w, r)
+    if !ok {
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    var evaluacion models.Evaluacion
+    if err := json.NewDecoder(r.Body).Decode(&evaluacion); err != nil {
+        respondError(w, http.StatusBadRequest, "Datos inválidos")
+        return
+    }
+
+    updatedEvaluacion, err := h.evaluacionService.Update(cursoID, evaluacionID, &evaluacion, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]interface{}{
+        "message":    "Evaluación actualizada exitosamente",
+        "evaluacion": updatedEvaluacion,
+    })
+}
+
+// Delete elimina una evaluación
+func (h *EvaluacionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+    cursoID, evaluacionID, ok := parseEvaluacionVars(w, r)
+    if !ok {
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    err := h.evaluacionService.Delete(cursoID, evaluacionID, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]string{
+        "message": "Evaluación eliminada exitosamente",
+    })
+}
+
+// SubmitResultado registra el resultado del alumno autenticado o, si lo envía
+// el instructor del curso, la calificación del alumno indicado en usuario_id
+func (h *EvaluacionHandler) SubmitResultado(w http.ResponseWriter, r *http.Request) {
+    cursoID, evaluacionID, ok := parseEvaluacionVars(w, r)
+    if !ok {
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    var req struct {
+        UsuarioID    int      `json:"usuario_id"`
+        Calificacion *float64 `json:"calificacion"`
+    }
+
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Calificacion == nil {
+        respondError(w, http.StatusBadRequest, "Datos inválidos")
+        return
+    }
+
+    resultado, err := h.evaluacionService.SubmitResultado(cursoID, evaluacionID, req.UsuarioID, *req.Calificacion, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusCreated, map[string]interface{}{
+        "message":   "Resultado registrado exitosamente",
+        "resultado": resultado,
+    })
+}
+
+// GetResultadosByCurso obtiene los resultados de las evaluaciones de un curso
+func (h *EvaluacionHandler) GetResultadosByCurso(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+    cursoID, err := strconv.Atoi(vars["id"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID inválido")
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    resultados, err := h.evaluacionService.GetResultadosByCurso(cursoID, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusForbidden, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, resultados)
+}
+
+// GetMyResultados obtiene los resultados del alumno autenticado
+func (h *EvaluacionHandler) GetMyResultados(w http.ResponseWriter, r *http.Request) {
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    resultados, err := h.evaluacionService.GetMyResultados(claims.UserID)
+    if err != nil {
+        respondError(w, http.StatusInternalServerError, "Error al obtener resultados")
+        return
+    }
+
+    respondJSON(w, http.StatusOK, resultados)
+}
+
+// parseEvaluacionVars obtiene el ID del curso y de la evaluación de la URL
+func parseEvaluacionVars(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+    vars := mux.Vars(r)
+    cursoID, err := strconv.Atoi(vars["id"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID inválido")
+        return 0, 0, false
+    }
+
+    evaluacionID, err := strconv.Atoi(vars["evaluacionId"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID de evaluación inválido")
+        return 0, 0, false
+    }
+
+    return cursoID, evaluacionID, true
+}
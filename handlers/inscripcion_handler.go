@@ -0,0 +1,100 @@
+package handlers
+
+import (
+    "cursos-api/middleware"
+    "cursos-api/services"
+    "cursos-api/utils"
+    "net/http"
+    "strconv"
+
+    "github.com/gorilla/mux"
+)
+
+type InscripcionHandler struct {
+    inscripcionService *services.InscripcionService
+}
+
+func NewInscripcionHandler(inscripcionService *services.InscripcionService) *InscripcionHandler {
+    return &InscripcionHandler{
+        inscripcionService: inscripcionService,
+    }
+}
+
+// Create inscribe al alumno autenticado en un curso
+func (h *InscripcionHandler) Create(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+    cursoID, err := strconv.Atoi(vars["id"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID inválido")
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    inscripcion, err := h.inscripcionService.Inscribir(cursoID, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusCreated, map[string]interface{}{
+        "message":     "Inscripción realizada exitosamente",
+        "inscripcion": inscripcion,
+    })
+}
+
+// GetMyInscripciones obtiene las inscripciones del alumno autenticado
+func (h *InscripcionHandler) GetMyInscripciones(w http.ResponseWriter, r *http.Request) {
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    inscripciones, err := h.inscripcionService.GetMyInscripciones(claims.UserID)
+    if err != nil {
+        respondError(w, http.StatusInternalServerError, "Error al obtener inscripciones")
+        return
+    }
+
+    respondJSON(w, http.StatusOK, inscripciones)
+}
+
+// GetByCurso obtiene los inscritos de un curso del instructor autenticado
+func (h *InscripcionHandler) GetByCurso(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+    cursoID, err := strconv.Atoi(vars["id"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID inválido")
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    inscripciones, err := h.inscripcionService.GetByCurso(cursoID, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, inscripciones)
+}
+
+// Cancel cancela una inscripción del alumno autenticado
+func (h *InscripcionHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+    id, err := strconv.Atoi(vars["id"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID inválido")
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    inscripcion, err := h.inscripcionService.Cancelar(id, claims.UserID)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]interface{}{
+        "message":     "Inscripción cancelada exitosamente",
+        "inscripcion": inscripcion,
+    })
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+    "cursos-api/middleware"
+    "cursos-api/models"
+    "cursos-api/services"
+    "cursos-api/utils"
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "github.com/gorilla/mux"
+)
+
+type IntentoHandler struct {
+    intentoService *services.IntentoService
+}
+
+func NewIntentoHandler(intentoService *services.IntentoService) *IntentoHandler {
+    return &IntentoHandler{
+        intentoService: intentoService,
+    }
+}
+
+// Iniciar comienza un intento de evaluación para el alumno autenticado
+func (h *IntentoHandler) Iniciar(w http.ResponseWriter, r *http.Request) {
+    cursoID, evaluacionID, ok := parseEvaluacionVars(w, r)
+    if !ok {
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    intento, err := h.intentoService.Iniciar(cursoID, evaluacionID, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusCreated, map[string]interface{}{
+        "message": "Intento iniciado",
+        "intento": intento,
+    })
+}
+
+// Enviar califica las respuestas de un intento
+func (h *IntentoHandler) Enviar(w http.ResponseWriter, r *http.Request) {
+    cursoID, evaluacionID, ok := parseEvaluacionVars(w, r)
+    if !ok {
+        return
+    }
+
+    intentoID, err := strconv.Atoi(mux.Vars(r)["intentoId"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID de intento inválido")
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    var req struct {
+        Respuestas []models.RespuestaPregunta `json:"respuestas"`
+    }
+
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        respondError(w, http.StatusBadRequest, "Datos inválidos")
+        return
+    }
+
+    intento, resultado, err := h.intentoService.Enviar(cursoID, evaluacionID, intentoID, req.Respuestas, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]interface{}{
+        "message":   "Intento calificado exitosamente",
+        "intento":   intento,
+        "resultado": resultado,
+    })
+}
+
+// GetMyIntentos obtiene los intentos del alumno autenticado en una evaluación
+func (h *IntentoHandler) GetMyIntentos(w http.ResponseWriter, r *http.Request) {
+    cursoID, evaluacionID, ok := parseEvaluacionVars(w, r)
+    if !ok {
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    intentos, err := h.intentoService.GetMyIntentos(cursoID, evaluacionID, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusForbidden, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, intentos)
+}
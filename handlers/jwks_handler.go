@@ -0,0 +1,23 @@
+package handlers
+
+import (
+    "cursos-api/utils"
+    "net/http"
+)
+
+type JWKSHandler struct {
+    keys *utils.KeyRing
+}
+
+func NewJWKSHandler(keys *utils.KeyRing) *JWKSHandler {
+    return &JWKSHandler{
+        keys: keys,
+    }
+}
+
+// GetJWKS publica las claves públicas vigentes con las que otros servicios
+// pueden verificar nuestros tokens (incluidas las que aún no firman)
+func (h *JWKSHandler) GetJWKS(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Cache-Control", "public, max-age=300")
+    respondJSON(w, http.StatusOK, h.keys.JWKS())
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+    "cursos-api/middleware"
+    "cursos-api/models"
+    "cursos-api/services"
+    "cursos-api/utils"
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "github.com/gorilla/mux"
+)
+
+type LeccionHandler struct {
+    leccionService *services.LeccionService
+}
+
+func NewLeccionHandler(leccionService *services.LeccionService) *LeccionHandler {
+    return &LeccionHandler{
+        leccionService: leccionService,
+    }
+}
+
+// Create crea una lección en un curso
+func (h *LeccionHandler) Create(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+    cursoID, err := strconv.Atoi(vars["id"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID inválido")
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    var leccion models.Leccion
+    if err := json.NewDecoder(r.Body).Decode(&leccion); err != nil {
+        respondError(w, http.StatusBadRequest, "Datos inválidos")
+        return
+    }
+
+    createdLeccion, err := h.leccionService.Create(cursoID, &leccion, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusCreated, map[string]interface{}{
+        "message": "Lección creada exitosamente",
+        "leccion": createdLeccion,
+    })
+}
+
+// GetByCurso obtiene las lecciones de un curso
+func (h *LeccionHandler) GetByCurso(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+    cursoID, err := strconv.Atoi(vars["id"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID inválido")
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    lecciones, err := h.leccionService.GetByCurso(cursoID, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusForbidden, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, lecciones)
+}
+
+// GetByID obtiene una lección de un curso
+func (h *LeccionHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+    cursoID, leccionID, ok := parseLeccionVars(w, r)
+    if !ok {
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    leccion, err := h.leccionService.GetByID(cursoID, leccionID, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusNotFound, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, leccion)
+}
+
+// Update actualiza una lección
+func (h *LeccionHandler) Update(w http.ResponseWriter, r *http.Request) {
+    cursoID, leccionID, ok := parseLeccionVars(w, r)
+    if !ok {
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    var leccion models.Leccion
+    if err := json.NewDecoder(r.Body).Decode(&leccion); err != nil {
+        respondError(w, http.StatusBadRequest, "Datos inválidos")
+        return
+    }
+
+    updatedLeccion, err := h.leccionService.Update(cursoID, leccionID, &leccion, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]interface{}{
+        "message": "Lección actualizada exitosamente",
+        "leccion": updatedLeccion,
+    })
+}
+
+// Delete elimina una lección
+func (h *LeccionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+    cursoID, leccionID, ok := parseLeccionVars(w, r)
+    if !ok {
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    err := h.leccionService.Delete(cursoID, leccionID, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]string{
+        "message": "Lección eliminada exitosamente",
+    })
+}
+
+// Reorder cambia el orden de las lecciones de un curso
+func (h *LeccionHandler) Reorder(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+    cursoID, err := strconv.Atoi(vars["id"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID inválido")
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    var req struct {
+        Lecciones []int `json:"lecciones"`
+    }
+
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        respondError(w, http.StatusBadRequest, "Datos inválidos")
+        return
+    }
+
+    lecciones, err := h.leccionService.Reorder(cursoID, req.Lecciones, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]interface{}{
+        "message":   "Lecciones reordenadas exitosamente",
+        "lecciones": lecciones,
+    })
+}
+
+// parseLeccionVars obtiene el ID del curso y de la lección de la URL
+func parseLeccionVars(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+    vars := mux.Vars(r)
+    cursoID, err := strconv.Atoi(vars["id"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID inválido")
+        return 0, 0, false
+    }
+
+    leccionID, err := strconv.Atoi(vars["leccionId"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID de lección inválido")
+        return 0, 0, false
+    }
+
+    return cursoID, leccionID, true
+}
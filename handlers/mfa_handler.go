@@ -0,0 +1,114 @@
+package handlers
+
+import (
+    "cursos-api/middleware"
+    "cursos-api/models"
+    "cursos-api/services"
+    "cursos-api/utils"
+    "encoding/json"
+    "net/http"
+)
+
+type MFAHandler struct {
+    mfaService *services.MFAService
+}
+
+func NewMFAHandler(mfaService *services.MFAService) *MFAHandler {
+    return &MFAHandler{
+        mfaService: mfaService,
+    }
+}
+
+// GetEstado indica si el usuario autenticado tiene 2FA y cuántos códigos de
+// recuperación le quedan
+func (h *MFAHandler) GetEstado(w http.ResponseWriter, r *http.Request) {
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    habilitado, restantes, err := h.mfaService.Estado(claims.UserID)
+    if err != nil {
+        respondError(w, http.StatusInternalServerError, "Error al obtener el estado de la autenticación en dos pasos")
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]interface{}{
+        "totp_habilitado":                habilitado,
+        "codigos_recuperacion_restantes": restantes,
+    })
+}
+
+// IniciarTOTP genera el secreto y la URI otpauth:// para la app de autenticación
+func (h *MFAHandler) IniciarTOTP(w http.ResponseWriter, r *http.Request) {
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    alta, err := h.mfaService.IniciarTOTP(claims.UserID)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, alta)
+}
+
+// ActivarTOTP confirma el alta con un código y devuelve los códigos de recuperación
+func (h *MFAHandler) ActivarTOTP(w http.ResponseWriter, r *http.Request) {
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    var req models.CodigoMFARequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        respondError(w, http.StatusBadRequest, "Datos inválidos")
+        return
+    }
+
+    codigos, err := h.mfaService.ActivarTOTP(claims.UserID, req.Codigo)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]interface{}{
+        "message":              "Autenticación en dos pasos activada, guarda los códigos de recuperación",
+        "codigos_recuperacion": codigos,
+    })
+}
+
+// DesactivarTOTP desactiva el 2FA con la contraseña y un código válido
+func (h *MFAHandler) DesactivarTOTP(w http.ResponseWriter, r *http.Request) {
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    var req models.DesactivarTOTPRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        respondError(w, http.StatusBadRequest, "Datos inválidos")
+        return
+    }
+
+    if err := h.mfaService.DesactivarTOTP(claims.UserID, &req); err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]string{
+        "message": "Autenticación en dos pasos desactivada",
+    })
+}
+
+// RegenerarCodigos sustituye los códigos de recuperación por otros nuevos
+func (h *MFAHandler) RegenerarCodigos(w http.ResponseWriter, r *http.Request) {
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    var req models.CodigoMFARequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        respondError(w, http.StatusBadRequest, "Datos inválidos")
+        return
+    }
+
+    codigos, err := h.mfaService.RegenerarCodigos(claims.UserID, req.Codigo)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]interface{}{
+        "message":              "Códigos de recuperación regenerados, los anteriores ya no sirven",
+        "codigos_recuperacion": codigos,
+    })
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+    "crypto/subtle"
+    "cursos-api/middleware"
+    "cursos-api/models"
+    "cursos-api/services"
+    "cursos-api/utils"
+    "encoding/json"
+    "net/http"
+    "net/url"
+    "os"
+    "strings"
+
+    "github.com/gorilla/mux"
+)
+
+// cookieEstadoOIDC guarda el state en el navegador entre la redirección al
+// proveedor y el callback, para que el login solo se complete en el mismo
+// navegador que lo inició
+const cookieEstadoOIDC = "oidc_state"
+
+type OIDCHandler struct {
+    oidcService *services.OIDCService
+}
+
+func NewOIDCHandler(oidcService *services.OIDCService) *OIDCHandler {
+    return &OIDCHandler{
+        oidcService: oidcService,
+    }
+}
+
+// GetProveedores lista los proveedores de identidad disponibles
+func (h *OIDCHandler) GetProveedores(w http.ResponseWriter, r *http.Request) {
+    respondJSON(w, http.StatusOK, map[string]interface{}{
+        "proveedores": h.oidcService.Proveedores(),
+    })
+}
+
+// Iniciar redirige al usuario a la página de login del proveedor
+func (h *OIDCHandler) Iniciar(w http.ResponseWriter, r *http.Request) {
+    authURL, state, err := h.oidcService.IniciarLogin(mux.Vars(r)["proveedor"])
+    if err != nil {
+        respondError(w, http.StatusNotFound, err.Error())
+        return
+    }
+
+    http.SetCookie(w, &http.Cookie{
+        Name:     cookieEstadoOIDC,
+        Value:    state,
+        Path:     "/api/auth/oidc",
+        MaxAge:   600,
+        HttpOnly: true,
+        Secure:   strings.HasPrefix(services.AppBaseURL(), "https://"),
+        SameSite: http.SameSiteLaxMode,
+    })
+
+    http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// Callback recibe la vuelta del proveedor. Con OIDC_FRONTEND_REDIRECT_URL
+// redirige al frontend con un código de canje; si no, responde como el login.
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+    query := r.URL.Query()
+    frontend := os.Getenv("OIDC_FRONTEND_REDIRECT_URL")
+
+    // El state se borra pase lo que pase: solo sirve para un intento
+    http.SetCookie(w, &http.Cookie{
+        Name:     cookieEstadoOIDC,
+        Value:    "",
+        Path:     "/api/auth/oidc",
+        MaxAge:   -1,
+        HttpOnly: true,
+    })
+
+    fallo := func(status int, mensaje string) {
+        if frontend != "" {
+            redirigirFrontend(w, r, frontend, "error", mensaje)
+            return
+        }
+        respondError(w, status, mensaje)
+    }
+
+    if errProveedor := query.Get("error"); errProveedor != "" {
+        fallo(http.StatusUnauthorized, "el proveedor rechazó el login: "+errProveedor)
+        return
+    }
+
+    state := query.Get("state")
+    cookie, err := r.Cookie(cookieEstadoOIDC)
+    if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+        fallo(http.StatusBadRequest, "login expirado o inválido, vuelve a intentarlo")
+        return
+    }
+
+    usuario, err := h.oidcService.CompletarLogin(mux.Vars(r)["proveedor"], state, query.Get("code"))
+    if err != nil {
+        fallo(http.StatusUnauthorized, err.Error())
+        return
+    }
+
+    if frontend != "" {
+        codigo, err := h.oidcService.EmitirCodigoCanje(usuario)
+        if err != nil {
+            fallo(http.StatusInternalServerError, "Error al completar el login")
+            return
+        }
+        redirigirFrontend(w, r, frontend, "codigo", codigo)
+        return
+    }
+
+    response, err := h.oidcService.Login(usuario)
+    if err != nil {
+        respondLoginError(w, err)
+        return
+    }
+
+    respondJSON(w, http.StatusOK, response)
+}
+
+// Canjear entrega los tokens (o el desafío 2FA) a cambio del código que
+// recibió el frontend en la redirección
+func (h *OIDCHandler) Canjear(w http.ResponseWriter, r *http.Request) {
+    var req models.OIDCCanjeRequest
+
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        respondError(w, http.StatusBadRequest, "Datos inválidos")
+        return
+    }
+
+    response, err := h.oidcService.Canjear(&req)
+    if err != nil {
+        respondLoginError(w, err)
+        return
+    }
+
+    respondJSON(w, http.StatusOK, response)
+}
+
+// GetIdentidades lista las cuentas externas vinculadas al usuario autenticado
+func (h *OIDCHandler) GetIdentidades(w http.ResponseWriter, r *http.Request) {
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    identidades, err := h.oidcService.Identidades(claims.UserID)
+    if err != nil {
+        respondError(w, http.StatusInternalServerError, "Error al obtener las identidades vinculadas")
+        return
+    }
+
+    respondJSON(w, http.StatusOK, identidades)
+}
+
+// redirigirFrontend redirige a la URL del frontend añadiendo un parámetro
+func redirigirFrontend(w http.ResponseWriter, r *http.Request, frontend, clave, valor string) {
+    destino, err := url.Parse(frontend)
+    if err != nil {
+        respondError(w, http.StatusInternalServerError, "OIDC_FRONTEND_REDIRECT_URL inválida")
+        return
+    }
+
+    params := destino.Query()
+    params.Set(clave, valor)
+    destino.RawQuery = params.Encode()
+
+    http.Redirect(w, r, destino.String(), http.StatusFound)
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+    "cursos-api/middleware"
+    "cursos-api/models"
+    "cursos-api/services"
+    "cursos-api/utils"
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "github.com/gorilla/mux"
+)
+
+type PreguntaHandler struct {
+    preguntaService *services.PreguntaService
+}
+
+func NewPreguntaHandler(preguntaService *services.PreguntaService) *PreguntaHandler {
+    return &PreguntaHandler{
+        preguntaService: preguntaService,
+    }
+}
+
+// Create agrega una pregunta a una evaluación
+func (h *PreguntaHandler) Create(w http.ResponseWriter, r *http.Request) {
+    cursoID, evaluacionID, ok := parseEvaluacionVars(w, r)
+    if !ok {
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    var pregunta models.Pregunta
+    if err := json.NewDecoder(r.Body).Decode(&pregunta); err != nil {
+        respondError(w, http.StatusBadRequest, "Datos inválidos")
+        return
+    }
+
+    createdPregunta, err := h.preguntaService.Create(cursoID, evaluacionID, &pregunta, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusCreated, map[string]interface{}{
+        "message":  "Pregunta creada exitosamente",
+        "pregunta": createdPregunta,
+    })
+}
+
+// GetByEvaluacion obtiene las preguntas de una evaluación
+func (h *PreguntaHandler) GetByEvaluacion(w http.ResponseWriter, r *http.Request) {
+    cursoID, evaluacionID, ok := parseEvaluacionVars(w, r)
+    if !ok {
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    preguntas, err := h.preguntaService.GetByEvaluacion(cursoID, evaluacionID, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusForbidden, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, preguntas)
+}
+
+// Update actualiza una pregunta
+func (h *PreguntaHandler) Update(w http.ResponseWriter, r *http.Request) {
+    cursoID, evaluacionID, ok := parseEvaluacionVars(w, r)
+    if !ok {
+        return
+    }
+
+    preguntaID, err := strconv.Atoi(mux.Vars(r)["preguntaId"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID de pregunta inválido")
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    var pregunta models.Pregunta
+    if err := json.NewDecoder(r.Body).Decode(&pregunta); err != nil {
+        respondError(w, http.StatusBadRequest, "Datos inválidos")
+        return
+    }
+
+    updatedPregunta, err := h.preguntaService.Update(cursoID, evaluacionID, preguntaID, &pregunta, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]interface{}{
+        "message":  "Pregunta actualizada exitosamente",
+        "pregunta": updatedPregunta,
+    })
+}
+
+// Delete elimina una pregunta
+func (h *PreguntaHandler) Delete(w http.ResponseWriter, r *http.Request) {
+    cursoID, evaluacionID, ok := parseEvaluacionVars(w, r)
+    if !ok {
+        return
+    }
+
+    preguntaID, err := strconv.Atoi(mux.Vars(r)["preguntaId"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID de pregunta inválido")
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    err = h.preguntaService.Delete(cursoID, evaluacionID, preguntaID, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]string{
+        "message": "Pregunta eliminada exitosamente",
+    })
+}
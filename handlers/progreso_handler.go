@@ -0,0 +1,76 @@
+package handlers
+
+import (
+    "cursos-api/middleware"
+    "cursos-api/services"
+    "cursos-api/utils"
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "github.com/gorilla/mux"
+)
+
+type ProgresoHandler struct {
+    progresoService *services.ProgresoService
+}
+
+func NewProgresoHandler(progresoService *services.ProgresoService) *ProgresoHandler {
+    return &ProgresoHandler{
+        progresoService: progresoService,
+    }
+}
+
+// MarcarLeccion marca una lección como completada o no completada
+func (h *ProgresoHandler) MarcarLeccion(w http.ResponseWriter, r *http.Request) {
+    cursoID, leccionID, ok := parseLeccionVars(w, r)
+    if !ok {
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    var req struct {
+        Completada *bool `json:"completada"`
+    }
+
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Completada == nil {
+        respondError(w, http.StatusBadRequest, "Datos inválidos")
+        return
+    }
+
+    progreso, inscripcion, err := h.progresoService.MarcarLeccion(cursoID, leccionID, *req.Completada, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]interface{}{
+        "message":     "Progreso actualizado exitosamente",
+        "progreso":    progreso,
+        "inscripcion": inscripcion,
+    })
+}
+
+// GetProgreso obtiene el progreso de una inscripción del alumno autenticado
+func (h *ProgresoHandler) GetProgreso(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+    id, err := strconv.Atoi(vars["id"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID inválido")
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    inscripcion, progresos, err := h.progresoService.GetProgreso(id, claims.UserID)
+    if err != nil {
+        respondError(w, http.StatusNotFound, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, map[string]interface{}{
+        "inscripcion": inscripcion,
+        "lecciones":   progresos,
+    })
+}
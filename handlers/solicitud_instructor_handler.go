@@ -0,0 +1,123 @@
+package handlers
+
+import (
+    "cursos-api/middleware"
+    "cursos-api/services"
+    "cursos-api/utils"
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "github.com/gorilla/mux"
+)
+
+type SolicitudInstructorHandler struct {
+    solicitudService *services.SolicitudInstructorService
+}
+
+func NewSolicitudInstructorHandler(solicitudService *services.SolicitudInstructorService) *SolicitudInstructorHandler {
+    return &SolicitudInstructorHandler{
+        solicitudService: solicitudService,
+    }
+}
+
+// Solicitar envía una solicitud para ser instructor
+func (h *SolicitudInstructorHandler) Solicitar(w http.ResponseWriter, r *http.Request) {
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    var req struct {
+        Motivo string `json:"motivo"`
+    }
+
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        respondError(w, http.StatusBadRequest, "Datos inválidos")
+        return
+    }
+
+    solicitud, err := h.solicitudService.Solicitar(req.Motivo, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusCreated, map[string]interface{}{
+        "message":   "Solicitud enviada exitosamente",
+        "solicitud": solicitud,
+    })
+}
+
+// GetMisSolicitudes obtiene las solicitudes del usuario autenticado
+func (h *SolicitudInstructorHandler) GetMisSolicitudes(w http.ResponseWriter, r *http.Request) {
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    solicitudes, err := h.solicitudService.GetMisSolicitudes(claims.UserID)
+    if err != nil {
+        respondError(w, http.StatusInternalServerError, "Error al obtener solicitudes")
+        return
+    }
+
+    respondJSON(w, http.StatusOK, solicitudes)
+}
+
+// GetByEstado lista las solicitudes filtrando por ?estado= (pendiente por defecto)
+func (h *SolicitudInstructorHandler) GetByEstado(w http.ResponseWriter, r *http.Request) {
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    solicitudes, err := h.solicitudService.GetByEstado(r.URL.Query().Get("estado"), claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    respondJSON(w, http.StatusOK, solicitudes)
+}
+
+// Aprobar aprueba una solicitud y convierte al solicitante en instructor
+func (h *SolicitudInstructorHandler) Aprobar(w http.ResponseWriter, r *http.Request) {
+    h.resolver(w, r, true)
+}
+
+// Rechazar rechaza una solicitud
+func (h *SolicitudInstructorHandler) Rechazar(w http.ResponseWriter, r *http.Request) {
+    h.resolver(w, r, false)
+}
+
+// resolver aplica la decisión del admin con un comentario opcional
+func (h *SolicitudInstructorHandler) resolver(w http.ResponseWriter, r *http.Request, aprobar bool) {
+    vars := mux.Vars(r)
+    id, err := strconv.Atoi(vars["id"])
+    if err != nil {
+        respondError(w, http.StatusBadRequest, "ID inválido")
+        return
+    }
+
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    var req struct {
+        Comentario string `json:"comentario"`
+    }
+
+    // El cuerpo es opcional
+    if r.ContentLength != 0 {
+        if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+            respondError(w, http.StatusBadRequest, "Datos inválidos")
+            return
+        }
+    }
+
+    solicitud, err := h.solicitudService.Resolver(id, aprobar, req.Comentario, claims.UserID, claims.Rol)
+    if err != nil {
+        respondError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    message := "Solicitud rechazada"
+    if aprobar {
+        message = "Solicitud aprobada, el usuario ahora es instructor"
+    }
+
+    respondJSON(w, http.StatusOK, map[string]interface{}{
+        "message":   message,
+        "solicitud": solicitud,
+    })
+}
@@ -3,6 +3,7 @@ package handlers
 import (
     "cursos-api/middleware"
     "cursos-api/models"
+    "cursos-api/policy"
     "cursos-api/services"
     "cursos-api/utils"
     "encoding/json"
@@ -16,9 +17,9 @@ type UsuarioHandler struct {
     usuarioService *services.UsuarioService
 }
 
-func NewUsuarioHandler() *UsuarioHandler {
+func NewUsuarioHandler(usuarioService *services.UsuarioService) *UsuarioHandler {
     return &UsuarioHandler{
-        usuarioService: services.NewUsuarioService(),
+        usuarioService: usuarioService,
     }
 }
 
@@ -42,6 +43,14 @@ func (h *UsuarioHandler) GetByID(w http.ResponseWriter, r *http.Request) {
         return
     }
 
+    claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+
+    // Un usuario solo puede ver su propio perfil, salvo un admin
+    if !policy.PuedeSobre(claims.Rol, policy.UsuarioReadOwn, claims.UserID, id) {
+        respondError(w, http.StatusForbidden, "No tienes permiso para ver este usuario")
+        return
+    }
+
     usuario, err := h.usuarioService.GetByID(id)
     if err != nil {
         respondError(w, http.StatusNotFound, err.Error())
@@ -62,8 +71,8 @@ func (h *UsuarioHandler) Update(w http.ResponseWriter, r *http.Request) {
 
     claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
 
-    // Un usuario solo puede actualizar su propio perfil
-    if claims.UserID != id {
+    // Un usuario solo puede actualizar su propio perfil, salvo un admin
+    if !policy.PuedeSobre(claims.Rol, policy.UsuarioUpdateOwn, claims.UserID, id) {
         respondError(w, http.StatusForbidden, "No tienes permiso para actualizar este usuario")
         return
     }
@@ -97,8 +106,8 @@ func (h *UsuarioHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
     claims := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
 
-    // Un usuario solo puede eliminar su propio perfil
-    if claims.UserID != id {
+    // Un usuario solo puede eliminar su propio perfil, salvo un admin
+    if !policy.PuedeSobre(claims.Rol, policy.UsuarioDeleteOwn, claims.UserID, id) {
         respondError(w, http.StatusForbidden, "No tienes permiso para eliminar este usuario")
         return
     }
@@ -135,6 +144,6 @@ func (h *UsuarioHandler) ChangePassword(w http.ResponseWriter, r *http.Request)
     }
 
     respondJSON(w, http.StatusOK, map[string]string{
-        "message": "Contraseña actualizada exitosamente",
+        "message": "Contraseña actualizada exitosamente, inicia sesión nuevamente",
     })
 }
@@ -0,0 +1,126 @@
+package mailer
+
+import (
+    "fmt"
+    "log"
+    "mime"
+    "net/smtp"
+    "os"
+    "path/filepath"
+    "regexp"
+    "strings"
+    "time"
+)
+
+// Message es un correo de texto plano
+type Message struct {
+    To      string
+    Subject string
+    Body    string
+}
+
+// Mailer abstrae el envío de correos para poder sustituir el servidor SMTP
+// por otro backend (o por archivos en desarrollo) sin tocar los servicios
+type Mailer interface {
+    Send(msg Message) error
+}
+
+// NewFromEnv crea el mailer configurado por variables de entorno. MAILER=smtp
+// usa SMTP_HOST, SMTP_PORT, SMTP_USER, SMTP_PASSWORD y MAIL_FROM; cualquier
+// otro valor (por defecto "file") escribe los correos en MAIL_DIR.
+func NewFromEnv() Mailer {
+    from := os.Getenv("MAIL_FROM")
+    if from == "" {
+        from = "no-reply@cursos-api.local"
+    }
+
+    if os.Getenv("MAILER") == "smtp" {
+        port := os.Getenv("SMTP_PORT")
+        if port == "" {
+            port = "587"
+        }
+
+        return NewSMTPMailer(os.Getenv("SMTP_HOST"), port, os.Getenv("SMTP_USER"), os.Getenv("SMTP_PASSWORD"), from)
+    }
+
+    dir := os.Getenv("MAIL_DIR")
+    if dir == "" {
+        dir = "./mail_outbox"
+    }
+
+    return NewFileMailer(dir, from)
+}
+
+// SMTPMailer envía los correos a través de un servidor SMTP
+type SMTPMailer struct {
+    addr string
+    auth smtp.Auth
+    from string
+}
+
+func NewSMTPMailer(host, port, user, password, from string) *SMTPMailer {
+    var auth smtp.Auth
+    if user != "" {
+        auth = smtp.PlainAuth("", user, password, host)
+    }
+
+    return &SMTPMailer{
+        addr: host + ":" + port,
+        auth: auth,
+        from: from,
+    }
+}
+
+// Send envía el mensaje; net/smtp usa STARTTLS si el servidor lo ofrece
+func (m *SMTPMailer) Send(msg Message) error {
+    return smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, formatear(m.from, msg))
+}
+
+// FileMailer guarda cada correo como un archivo .eml y lo registra en el log.
+// Pensado para desarrollo local: no envía nada.
+type FileMailer struct {
+    dir  string
+    from string
+}
+
+func NewFileMailer(dir, from string) *FileMailer {
+    return &FileMailer{dir: dir, from: from}
+}
+
+// nombreInvalido son los caracteres que no se usan en el nombre del archivo
+var nombreInvalido = regexp.MustCompile(`[^a-zA-Z0-9@._-]`)
+
+// Send escribe el mensaje en el directorio configurado
+func (m *FileMailer) Send(msg Message) error {
+    if err := os.MkdirAll(m.dir, 0o755); err != nil {
+        return err
+    }
+
+    nombre := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102T150405.000000000"), nombreInvalido.ReplaceAllString(msg.To, "_"))
+    path := filepath.Join(m.dir, nombre)
+
+    if err := os.WriteFile(path, formatear(m.from, msg), 0o600); err != nil {
+        return err
+    }
+
+    log.Printf("✉️  Correo para %s (%s) guardado en %s\n", msg.To, msg.Subject, path)
+    return nil
+}
+
+// saltosDeLinea evita que un valor inyecte cabeceras adicionales
+var saltosDeLinea = strings.NewReplacer("\r", "", "\n", "")
+
+// formatear construye el mensaje RFC 5322 con cabeceras mínimas
+func formatear(from string, msg Message) []byte {
+    var b strings.Builder
+    fmt.Fprintf(&b, "From: %s\r\n", saltosDeLinea.Replace(from))
+    fmt.Fprintf(&b, "To: %s\r\n", saltosDeLinea.Replace(msg.To))
+    fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", saltosDeLinea.Replace(msg.Subject)))
+    fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+    b.WriteString("MIME-Version: 1.0\r\n")
+    b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+    b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+    b.WriteString("\r\n")
+    b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+    return []byte(b.String())
+}
@@ -1,12 +1,25 @@
 package main
 
 import (
+    "context"
+    "cursos-api/app"
     "cursos-api/config"
+    "cursos-api/database"
+    "cursos-api/mailer"
     "cursos-api/middleware"
+    "cursos-api/oidc"
     "cursos-api/routes"
+    "cursos-api/scheduler"
+    "cursos-api/storage"
+    "cursos-api/utils"
+    "database/sql"
+    "flag"
     "log"
     "net/http"
     "os"
+    "path/filepath"
+    "strconv"
+    "time"
 
     "github.com/joho/godotenv"
 )
@@ -18,12 +31,58 @@ func main() {
         log.Println("⚠️  No se encontró archivo .env, usando variables de entorno del sistema")
     }
 
+    // Generar una clave de firma de JWT o levantar el IdP de pruebas no
+    // necesita la base de datos
+    if len(os.Args) > 1 {
+        switch os.Args[1] {
+        case "generate-jwt-key":
+            runGenerateJWTKey(os.Args[2:])
+            return
+        case "mock-idp":
+            runMockIdP(os.Args[2:])
+            return
+        }
+    }
+
     // Conectar a la base de datos
-    config.ConnectDB()
-    defer config.CloseDB()
+    db := config.ConnectDB()
+    defer db.Close()
+
+    // Subcomandos: go run main.go migrate [up|down [n]|status]
+    //             go run main.go create-admin -nombre ... -email ... [-password ...]
+    //             go run main.go generate-jwt-key [-alg EdDSA|RS256] [-activa AAAA-MM-DD]
+    //             go run main.go mock-idp [-addr :9000] [-issuer http://localhost:9000]
+    if len(os.Args) > 1 {
+        switch os.Args[1] {
+        case "migrate":
+            runMigrate(db, os.Args[2:])
+        case "create-admin":
+            runCreateAdmin(db, os.Args[2:])
+        default:
+            log.Fatalf("Subcomando desconocido: %s (disponibles: migrate, create-admin, generate-jwt-key, mock-idp)", os.Args[1])
+        }
+        return
+    }
 
-    // Configurar rutas
-    router := routes.SetupRoutes()
+    // Aplicar migraciones pendientes al iniciar (DB_AUTO_MIGRATE=false lo desactiva)
+    if os.Getenv("DB_AUTO_MIGRATE") != "false" {
+        runMigrate(db, []string{"up"})
+    }
+
+    // Sin una clave de firma activa la API no arranca
+    if err := utils.InitJWT(); err != nil {
+        log.Fatal("Error al cargar las claves JWT (genera una con: go run main.go generate-jwt-key): ", err)
+    }
+
+    // Construir dependencias y configurar rutas
+    container := app.NewContainer(db, storage.NewFromEnv(), mailer.NewFromEnv())
+    router := routes.SetupRoutes(container)
+
+    // Tareas en segundo plano; SCHEDULER_ENABLED=false las desactiva en esta
+    // instancia
+    if os.Getenv("SCHEDULER_ENABLED") != "false" {
+        scheduler.Iniciar(context.Background(), container.Tareas()...)
+    }
 
     // Aplicar middleware CORS
     handler := middleware.CORS(router)
@@ -38,8 +97,142 @@ func main() {
     log.Printf("🚀 Servidor iniciado en http://localhost:%s\n", port)
     log.Printf("📚 API de Gestión de Cursos\n")
     log.Printf("📖 Documentación: http://localhost:%s/health\n", port)
-    
+
     if err := http.ListenAndServe(":"+port, handler); err != nil {
         log.Fatal("Error al iniciar el servidor:", err)
     }
 }
+
+// runMigrate ejecuta el subcomando de migraciones
+func runMigrate(db *sql.DB, args []string) {
+    migrator, err := database.NewMigrator(db)
+    if err != nil {
+        log.Fatal("Error al cargar migraciones:", err)
+    }
+
+    accion := "up"
+    if len(args) > 0 {
+        accion = args[0]
+    }
+
+    switch accion {
+    case "up":
+        aplicadas, err := migrator.Up()
+        for _, m := range aplicadas {
+            log.Printf("⬆️  Migración aplicada: %04d_%s\n", m.Version, m.Nombre)
+        }
+        if err != nil {
+            log.Fatal("Error al aplicar migraciones:", err)
+        }
+        if len(aplicadas) == 0 {
+            log.Println("✅ Base de datos al día, no hay migraciones pendientes")
+        }
+
+    case "down":
+        pasos := 1
+        if len(args) > 1 {
+            pasos, err = strconv.Atoi(args[1])
+            if err != nil {
+                log.Fatal("Número de pasos inválido:", args[1])
+            }
+        }
+
+        revertidas, err := migrator.Down(pasos)
+        for _, m := range revertidas {
+            log.Printf("⬇️  Migración revertida: %04d_%s\n", m.Version, m.Nombre)
+        }
+        if err != nil {
+            log.Fatal("Error al revertir migraciones:", err)
+        }
+
+    case "status":
+        estado, err := migrator.Status()
+        if err != nil {
+            log.Fatal("Error al obtener el estado de las migraciones:", err)
+        }
+        for _, m := range estado {
+            if m.Aplicada {
+                log.Printf("✅ %04d_%s (aplicada %s)\n", m.Version, m.Nombre, m.AplicadaAt.Format("2006-01-02 15:04:05"))
+            } else {
+                log.Printf("⏳ %04d_%s (pendiente)\n", m.Version, m.Nombre)
+            }
+        }
+
+    default:
+        log.Fatal("Uso: go run main.go migrate [up|down [n]|status]")
+    }
+}
+
+// runCreateAdmin crea una cuenta de administrador. La contraseña puede venir
+// del flag -password o de ADMIN_PASSWORD para no dejarla en el historial.
+func runCreateAdmin(db *sql.DB, args []string) {
+    fs := flag.NewFlagSet("create-admin", flag.ExitOnError)
+    nombre := fs.String("nombre", "", "nombre del administrador")
+    email := fs.String("email", "", "email del administrador")
+    password := fs.String("password", os.Getenv("ADMIN_PASSWORD"), "contraseña (por defecto ADMIN_PASSWORD)")
+    fs.Parse(args)
+
+    container := app.NewContainer(db, storage.NewFromEnv(), mailer.NewFromEnv())
+    admin, err := container.AdminService.CrearAdmin(*nombre, *email, *password)
+    if err != nil {
+        log.Fatal("Error al crear el administrador: ", err)
+    }
+
+    log.Printf("👤 Administrador creado: %s <%s> (id %d)\n", admin.Nombre, admin.Email, admin.ID)
+}
+
+// runGenerateJWTKey genera una clave privada de firma de JWT en JWT_KEYS_DIR.
+// Con -activa en el futuro la clave se publica ya en el JWKS pero no firma
+// hasta esa fecha, lo que permite programar la rotación.
+func runGenerateJWTKey(args []string) {
+    fs := flag.NewFlagSet("generate-jwt-key", flag.ExitOnError)
+    alg := fs.String("alg", utils.AlgEdDSA, "algoritmo de firma (EdDSA o RS256)")
+    dir := fs.String("dir", utils.GetEnv("JWT_KEYS_DIR", "./keys"), "directorio de claves (por defecto JWT_KEYS_DIR)")
+    activa := fs.String("activa", time.Now().UTC().Format("2006-01-02"), "fecha desde la que firma (AAAA-MM-DD, UTC)")
+    fs.Parse(args)
+
+    activaAt, err := time.Parse("2006-01-02", *activa)
+    if err != nil {
+        log.Fatal("Fecha de activación inválida: ", *activa)
+    }
+
+    pemBytes, err := utils.GenerateJWTKey(*alg)
+    if err != nil {
+        log.Fatal("Error al generar la clave: ", err)
+    }
+
+    nombre, err := utils.NombreArchivoClave(activaAt)
+    if err != nil {
+        log.Fatal("Error al generar la clave: ", err)
+    }
+
+    if err := os.MkdirAll(*dir, 0o700); err != nil {
+        log.Fatal("Error al crear el directorio de claves: ", err)
+    }
+
+    ruta := filepath.Join(*dir, nombre)
+    if err := os.WriteFile(ruta, pemBytes, 0o600); err != nil {
+        log.Fatal("Error al guardar la clave: ", err)
+    }
+
+    log.Printf("🔑 Clave %s generada en %s (firma desde %s)\n", *alg, ruta, activaAt.Format("2006-01-02"))
+}
+
+// runMockIdP levanta un proveedor OIDC de pruebas para probar el login
+// externo en local sin un proveedor real
+func runMockIdP(args []string) {
+    fs := flag.NewFlagSet("mock-idp", flag.ExitOnError)
+    addr := fs.String("addr", ":9000", "dirección en la que escuchar")
+    issuer := fs.String("issuer", "http://localhost:9000", "issuer (URL pública del proveedor)")
+    fs.Parse(args)
+
+    idp, err := oidc.NewMockIdP(*issuer)
+    if err != nil {
+        log.Fatal("Error al crear el proveedor de pruebas: ", err)
+    }
+
+    log.Printf("🪪  Mock IdP en %s (issuer %s)\n", *addr, *issuer)
+    if err := http.ListenAndServe(*addr, idp.Handler()); err != nil {
+        log.Fatal("Error al iniciar el proveedor de pruebas: ", err)
+    }
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
     "context"
+    "cursos-api/policy"
     "cursos-api/utils"
     "net/http"
     "strings"
@@ -11,8 +12,22 @@ type contextKey string
 
 const UserContextKey contextKey = "user"
 
-// AuthMiddleware verifica el token JWT
-func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
+// SessionChecker indica si la sesión citada por un token sigue vigente
+type SessionChecker interface {
+    SesionActiva(sesionID string) (bool, error)
+}
+
+// Auth agrupa los middlewares de autenticación y autorización
+type Auth struct {
+    sesiones SessionChecker
+}
+
+func NewAuth(sesiones SessionChecker) *Auth {
+    return &Auth{sesiones: sesiones}
+}
+
+// AuthMiddleware verifica el token JWT y que su sesión no haya sido revocada
+func (a *Auth) AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
     return func(w http.ResponseWriter, r *http.Request) {
         authHeader := r.Header.Get("Authorization")
         if authHeader == "" {
@@ -33,18 +48,28 @@ func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
             return
         }
 
+        activa, err := a.sesiones.SesionActiva(claims.SesionID)
+        if err != nil {
+            http.Error(w, `{"error":"Error al verificar la sesión"}`, http.StatusInternalServerError)
+            return
+        }
+        if !activa {
+            http.Error(w, `{"error":"Sesión revocada"}`, http.StatusUnauthorized)
+            return
+        }
+
         // Agregar claims al contexto
         ctx := context.WithValue(r.Context(), UserContextKey, claims)
         next.ServeHTTP(w, r.WithContext(ctx))
     }
 }
 
-// RoleMiddleware verifica que el usuario tenga un rol específico
-func RoleMiddleware(requiredRole string, next http.HandlerFunc) http.HandlerFunc {
-    return AuthMiddleware(func(w http.ResponseWriter, r *http.Request) {
+// PermissionMiddleware verifica que el rol del usuario tenga el permiso indicado
+func (a *Auth) PermissionMiddleware(permiso policy.Permiso, next http.HandlerFunc) http.HandlerFunc {
+    return a.AuthMiddleware(func(w http.ResponseWriter, r *http.Request) {
         claims := r.Context().Value(UserContextKey).(*utils.Claims)
 
-        if claims.Rol != requiredRole {
+        if !policy.Puede(claims.Rol, permiso) {
             http.Error(w, `{"error":"Acceso no autorizado"}`, http.StatusForbidden)
             return
         }
@@ -57,8 +82,9 @@ func RoleMiddleware(requiredRole string, next http.HandlerFunc) http.HandlerFunc
 func CORS(next http.Handler) http.Handler {
     return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
         w.Header().Set("Access-Control-Allow-Origin", "*")
-        w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+        w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
         w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+        w.Header().Set("Access-Control-Expose-Headers", "RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, Retry-After")
 
         if r.Method == "OPTIONS" {
             w.WriteHeader(http.StatusOK)
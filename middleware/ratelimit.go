@@ -0,0 +1,77 @@
+package middleware
+
+import (
+    "cursos-api/ratelimit"
+    "cursos-api/utils"
+    "fmt"
+    "log"
+    "math"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// RateLimiter limita la frecuencia de peticiones con token buckets guardados
+// en un ratelimit.Store
+type RateLimiter struct {
+    store ratelimit.Store
+}
+
+func NewRateLimiter(store ratelimit.Store) *RateLimiter {
+    return &RateLimiter{store: store}
+}
+
+// Middleware aplica el límite a todas las rutas de un router (router.Use).
+// nombre separa los buckets de límites distintos.
+func (l *RateLimiter) Middleware(nombre string, limit ratelimit.Limit) func(http.Handler) http.Handler {
+    return func(next http.Handler) http.Handler {
+        return l.Limit(nombre, limit, next.ServeHTTP)
+    }
+}
+
+// Limit aplica el límite a un handler. Si la petición ya pasó por otro límite,
+// las cabeceras RateLimit-* reflejan el último aplicado.
+func (l *RateLimiter) Limit(nombre string, limit ratelimit.Limit, next http.HandlerFunc) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        result, err := l.store.Take(nombre+":"+clienteRateLimit(r), limit)
+        if err != nil {
+            // Un fallo del store no debe tumbar la API
+            log.Printf("⚠️  Error en el rate limit %q: %v\n", nombre, err)
+            next.ServeHTTP(w, r)
+            return
+        }
+
+        h := w.Header()
+        h.Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+        h.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+        h.Set("RateLimit-Reset", strconv.Itoa(segundosHacia(result.Reset)))
+        h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Requests, segundosHacia(limit.Period)))
+
+        if !result.Allowed {
+            h.Set("Retry-After", strconv.Itoa(segundosHacia(result.RetryAfter)))
+            http.Error(w, `{"error":"Demasiadas peticiones, inténtalo de nuevo más tarde"}`, http.StatusTooManyRequests)
+            return
+        }
+
+        next.ServeHTTP(w, r)
+    }
+}
+
+// clienteRateLimit identifica a quien hace la petición: el usuario del token
+// si es válido o, si no, la IP. No consulta la sesión: basta con que el token
+// esté firmado para agrupar las peticiones por usuario.
+func clienteRateLimit(r *http.Request) string {
+    if token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "); token != "" {
+        if claims, err := utils.ValidateJWT(token); err == nil {
+            return "usuario:" + strconv.Itoa(claims.UserID)
+        }
+    }
+
+    return "ip:" + utils.ClientIP(r)
+}
+
+// segundosHacia redondea una duración a segundos enteros hacia arriba
+func segundosHacia(d time.Duration) int {
+    return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+    "cursos-api/ratelimit"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+)
+
+// storeFalso devuelve resultados fijos y anota las claves consultadas
+type storeFalso struct {
+    result ratelimit.Result
+    err    error
+    keys   []string
+}
+
+func (s *storeFalso) Take(key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+    s.keys = append(s.keys, key)
+    return s.result, s.err
+}
+
+func servir(l *RateLimiter, limit ratelimit.Limit) (*httptest.ResponseRecorder, bool) {
+    llamado := false
+    handler := l.Limit("api", limit, func(w http.ResponseWriter, r *http.Request) {
+        llamado = true
+    })
+
+    r := httptest.NewRequest(http.MethodGet, "/api/cursos", nil)
+    r.RemoteAddr = "10.0.0.1:1234"
+    w := httptest.NewRecorder()
+    handler(w, r)
+    return w, llamado
+}
+
+func TestRateLimiterAdmite(t *testing.T) {
+    store := &storeFalso{result: ratelimit.Result{Allowed: true, Remaining: 9, Reset: 1500 * time.Millisecond}}
+    w, llamado := servir(NewRateLimiter(store), ratelimit.PerMinute(10))
+
+    if !llamado || w.Code != http.StatusOK {
+        t.Fatalf("la petición admitida respondió %d (handler llamado: %v)", w.Code, llamado)
+    }
+    if len(store.keys) != 1 || store.keys[0] != "api:ip:10.0.0.1" {
+        t.Fatalf("claves = %v, se esperaba [api:ip:10.0.0.1]", store.keys)
+    }
+
+    cabeceras := map[string]string{
+        "RateLimit-Limit":     "10",
+        "RateLimit-Remaining": "9",
+        "RateLimit-Reset":     "2",
+        "RateLimit-Policy":    "10;w=60",
+        "Retry-After":         "",
+    }
+    for nombre, esperado := range cabeceras {
+        if got := w.Header().Get(nombre); got != esperado {
+            t.Fatalf("%s = %q, se esperaba %q", nombre, got, esperado)
+        }
+    }
+}
+
+func TestRateLimiterRechaza(t *testing.T) {
+    store := &storeFalso{result: ratelimit.Result{Allowed: false, Reset: time.Minute, RetryAfter: 200 * time.Millisecond}}
+    w, llamado := servir(NewRateLimiter(store), ratelimit.PerMinute(10))
+
+    if llamado {
+        t.Fatal("el handler se ejecutó con el límite agotado")
+    }
+    if w.Code != http.StatusTooManyRequests {
+        t.Fatalf("código %d, se esperaba 429", w.Code)
+    }
+    if got := w.Header().Get("Retry-After"); got != "1" {
+        t.Fatalf("Retry-After = %q, se esperaba 1", got)
+    }
+}
+
+func TestRateLimiterFalloDelStore(t *testing.T) {
+    store := &storeFalso{err: errors.New("redis caído")}
+    w, llamado := servir(NewRateLimiter(store), ratelimit.PerMinute(10))
+
+    if !llamado || w.Code != http.StatusOK {
+        t.Fatalf("un fallo del store bloqueó la petición (código %d)", w.Code)
+    }
+}
@@ -3,26 +3,174 @@ package models
 import "time"
 
 type Usuario struct {
-    ID           int       `json:"id"`
-    Nombre       string    `json:"nombre"`
-    Email        string    `json:"email"`
-    Password     string    `json:"password,omitempty"`
-    PasswordHash string    `json:"-"`
-    Rol          string    `json:"rol"` // "instructor" o "alumno"
-    CreatedAt    time.Time `json:"created_at"`
-    UpdatedAt    time.Time `json:"updated_at"`
+    ID              int       `json:"id"`
+    Nombre          string    `json:"nombre"`
+    Email           string    `json:"email"`
+    Password        string    `json:"password,omitempty"`
+    PasswordHash    string    `json:"-"`
+    Rol             string    `json:"rol"` // "instructor", "alumno" o "admin"
+    Activo          bool      `json:"activo"`
+    EmailVerificado bool      `json:"email_verificado"`
+    TOTPHabilitado  bool      `json:"totp_habilitado"`
+    CreatedAt       time.Time `json:"created_at"`
+    UpdatedAt       time.Time `json:"updated_at"`
+
+    // LoginBloqueadoHasta es el fin del bloqueo por intentos fallidos de login
+    LoginBloqueadoHasta *time.Time `json:"-"`
+
+    // DeletedAt es el momento del borrado lógico; solo se rellena al listar
+    // los usuarios eliminados
+    DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// UsuarioFiltro son los criterios de búsqueda de usuarios para administración
+type UsuarioFiltro struct {
+    Query      string // coincidencia parcial en nombre o email
+    Rol        string
+    Activo     *bool
+    Eliminados bool // solo los eliminados pendientes de purga, en lugar de los vigentes
+}
+
+// CursoFiltro son los criterios del listado paginado de cursos. Los campos
+// vacíos no filtran.
+type CursoFiltro struct {
+    Activo       *bool
+    Estado       string
+    SoloCatalogo bool // solo activos y no bloqueados por moderación
+    Eliminados   bool // solo los eliminados pendientes de purga, en lugar de los vigentes
+    InstructorID int
+    DuracionMin  int
+    DuracionMax  int
+    CreadoDesde  *time.Time
+    CreadoHasta  *time.Time // exclusivo
+    CategoriaID  int        // la categoría o cualquiera de sus subcategorías
+    Etiquetas    []string   // debe tener todas
+    Orden        string     // campo de ordenación
+    Desc         bool
+    Limite       int
+    Cursor       string // next_cursor de la página anterior
+}
+
+// PaginaCursos es una página del listado de cursos. Total cuenta todos los
+// cursos que cumplen el filtro, no solo los de la página.
+type PaginaCursos struct {
+    Items      []Curso `json:"items"`
+    Total      int     `json:"total"`
+    Limite     int     `json:"limite"`
+    NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+// CursoBusqueda es una búsqueda de texto en los cursos. SoloCatalogo e
+// InstructorID restringen los cursos visibles como en CursoFiltro.
+type CursoBusqueda struct {
+    Texto        string
+    SoloCatalogo bool
+    InstructorID int
+    Limite       int
+    Offset       int
+}
+
+// ResultadoBusquedaCurso es un curso encontrado con su relevancia y los
+// textos donde aparece la búsqueda resaltados con <mark> (HTML ya escapado)
+type ResultadoBusquedaCurso struct {
+    Curso
+    Relevancia            float64  `json:"relevancia"`
+    NombreResaltado       string   `json:"nombre_resaltado"`
+    Fragmento             string   `json:"fragmento"`
+    LeccionesCoincidentes []string `json:"lecciones_coincidentes,omitempty"`
+}
+
+// PaginaBusquedaCursos es una página de resultados, de más a menos relevante
+type PaginaBusquedaCursos struct {
+    Items  []ResultadoBusquedaCurso `json:"items"`
+    Total  int                      `json:"total"`
+    Limite int                      `json:"limite"`
+    Offset int                      `json:"offset"`
 }
 
 type Curso struct {
-    ID            int       `json:"id"`
-    Nombre        string    `json:"nombre"`
-    Descripcion   string    `json:"descripcion"`
-    DuracionHoras int       `json:"duracion_horas"`
-    InstructorID  int       `json:"instructor_id"`
-    Instructor    *Usuario  `json:"instructor,omitempty"`
-    Activo        bool      `json:"activo"`
-    CreatedAt     time.Time `json:"created_at"`
-    UpdatedAt     time.Time `json:"updated_at"`
+    ID            int            `json:"id"`
+    Nombre        string         `json:"nombre"`
+    Descripcion   string         `json:"descripcion"`
+    DuracionHoras int            `json:"duracion_horas"`
+    InstructorID  int            `json:"instructor_id"`
+    Instructor    *Usuario       `json:"instructor,omitempty"`
+    Activo        bool           `json:"activo"`
+    Estado        string         `json:"estado"`
+    PublicadoAt   *time.Time     `json:"publicado_at,omitempty"`
+    PublicarAt    *time.Time     `json:"publicar_at,omitempty"`
+    DespublicarAt *time.Time     `json:"despublicar_at,omitempty"`
+    Bloqueado     bool           `json:"bloqueado"`
+    MotivoBloqueo string         `json:"motivo_bloqueo,omitempty"`
+    BloqueadoAt   *time.Time     `json:"bloqueado_at,omitempty"`
+    Categorias    []CategoriaRef `json:"categorias,omitempty"`
+    Etiquetas     []string       `json:"etiquetas,omitempty"`
+    CreatedAt     time.Time      `json:"created_at"`
+    UpdatedAt     time.Time      `json:"updated_at"`
+    DeletedAt     *time.Time     `json:"deleted_at,omitempty"`
+}
+
+// CambioEstadoCurso es una entrada del historial de estados de un curso
+type CambioEstadoCurso struct {
+    ID             int       `json:"id"`
+    CursoID        int       `json:"curso_id"`
+    EstadoAnterior string    `json:"estado_anterior"`
+    EstadoNuevo    string    `json:"estado_nuevo"`
+    UsuarioID      *int      `json:"usuario_id"` // nil si lo aplicó la publicación programada
+    Motivo         string    `json:"motivo,omitempty"`
+    CreatedAt      time.Time `json:"created_at"`
+}
+
+type CambiarEstadoCursoRequest struct {
+    Estado string `json:"estado"`
+    Motivo string `json:"motivo"`
+}
+
+// ProgramacionCursoRequest programa la publicación y la retirada de un curso.
+// Un campo a null cancela esa programación.
+type ProgramacionCursoRequest struct {
+    PublicarAt    *time.Time `json:"publicar_at"`
+    DespublicarAt *time.Time `json:"despublicar_at"`
+}
+
+// Categoria es un nodo del árbol de categorías de cursos. En el árbol cada
+// categoría lleva sus subcategorías en Hijas.
+type Categoria struct {
+    ID          int         `json:"id"`
+    Nombre      string      `json:"nombre"`
+    Slug        string      `json:"slug"`
+    Descripcion string      `json:"descripcion,omitempty"`
+    PadreID     *int        `json:"padre_id"`
+    Hijas       []Categoria `json:"hijas,omitempty"`
+    CreatedAt   time.Time   `json:"created_at"`
+}
+
+// CategoriaRef es una categoría asignada a un curso
+type CategoriaRef struct {
+    ID     int    `json:"id"`
+    Nombre string `json:"nombre"`
+    Slug   string `json:"slug"`
+}
+
+// Etiqueta es una etiqueta libre con el número de cursos del catálogo que la usan
+type Etiqueta struct {
+    Nombre string `json:"nombre"`
+    Cursos int    `json:"cursos"`
+}
+
+type CategoriaRequest struct {
+    Nombre      string `json:"nombre"`
+    Slug        string `json:"slug"`
+    Descripcion string `json:"descripcion"`
+    PadreID     *int   `json:"padre_id"`
+}
+
+type AsignarCategoriasRequest struct {
+    CategoriaIDs []int `json:"categoria_ids"`
+}
+
+type AsignarEtiquetasRequest struct {
+    Etiquetas []string `json:"etiquetas"`
 }
 
 type Leccion struct {
@@ -43,6 +191,7 @@ type Inscripcion struct {
     Estado             string    `json:"estado"` // "activo", "completado", "cancelado"
     ProgresoPorcentaje float64   `json:"progreso_porcentaje"`
     Curso              *Curso    `json:"curso,omitempty"`
+    Usuario            *Usuario  `json:"usuario,omitempty"`
 }
 
 type ProgresoLeccion struct {
@@ -54,29 +203,167 @@ type ProgresoLeccion struct {
 }
 
 type Evaluacion struct {
-    ID                 int       `json:"id"`
-    CursoID            int       `json:"curso_id"`
-    Titulo             string    `json:"titulo"`
-    Descripcion        string    `json:"descripcion"`
-    CalificacionMinima float64   `json:"calificacion_minima"`
-    CreatedAt          time.Time `json:"created_at"`
+    ID                  int       `json:"id"`
+    CursoID             int       `json:"curso_id"`
+    Titulo              string    `json:"titulo"`
+    Descripcion         string    `json:"descripcion"`
+    CalificacionMinima  float64   `json:"calificacion_minima"`
+    MaxIntentos         int       `json:"max_intentos"`          // 0 = ilimitados
+    TiempoLimiteMinutos int       `json:"tiempo_limite_minutos"` // 0 = sin límite
+    Requerida           *bool     `json:"requerida,omitempty"`   // necesaria para el certificado, true por defecto
+    CreatedAt           time.Time `json:"created_at"`
+}
+
+type Pregunta struct {
+    ID                int              `json:"id"`
+    EvaluacionID      int              `json:"evaluacion_id"`
+    Enunciado         string           `json:"enunciado"`
+    Tipo              string           `json:"tipo"` // "opcion_unica", "opcion_multiple", "verdadero_falso", "numerica"
+    Peso              float64          `json:"peso"`
+    Orden             int              `json:"orden"`
+    Opciones          []OpcionPregunta `json:"opciones,omitempty"`
+    RespuestaBooleana *bool            `json:"respuesta_booleana,omitempty"`
+    RespuestaNumerica *float64         `json:"respuesta_numerica,omitempty"`
+    Tolerancia        float64          `json:"tolerancia,omitempty"`
+    CreatedAt         time.Time        `json:"created_at"`
+}
+
+type OpcionPregunta struct {
+    ID         int    `json:"id"`
+    PreguntaID int    `json:"pregunta_id"`
+    Texto      string `json:"texto"`
+    EsCorrecta bool   `json:"es_correcta,omitempty"`
+    Orden      int    `json:"orden"`
+}
+
+type IntentoEvaluacion struct {
+    ID           int                 `json:"id"`
+    EvaluacionID int                 `json:"evaluacion_id"`
+    UsuarioID    int                 `json:"usuario_id"`
+    IniciadoAt   time.Time           `json:"iniciado_at"`
+    ExpiraAt     *time.Time          `json:"expira_at,omitempty"`
+    EnviadoAt    *time.Time          `json:"enviado_at,omitempty"`
+    Respuestas   []RespuestaPregunta `json:"respuestas,omitempty"`
+    Calificacion *float64            `json:"calificacion,omitempty"`
+    ResultadoID  *int                `json:"resultado_id,omitempty"`
+    Preguntas    []Pregunta          `json:"preguntas,omitempty"`
+}
+
+type RespuestaPregunta struct {
+    PreguntaID    int      `json:"pregunta_id"`
+    Opciones      []int    `json:"opciones,omitempty"`
+    ValorBooleano *bool    `json:"valor_booleano,omitempty"`
+    ValorNumerico *float64 `json:"valor_numerico,omitempty"`
 }
 
 type ResultadoEvaluacion struct {
-    ID              int       `json:"id"`
-    EvaluacionID    int       `json:"evaluacion_id"`
-    UsuarioID       int       `json:"usuario_id"`
-    Calificacion    float64   `json:"calificacion"`
-    Aprobado        bool      `json:"aprobado"`
-    FechaEvaluacion time.Time `json:"fecha_evaluacion"`
+    ID              int         `json:"id"`
+    EvaluacionID    int         `json:"evaluacion_id"`
+    UsuarioID       int         `json:"usuario_id"`
+    Calificacion    float64     `json:"calificacion"`
+    Aprobado        bool        `json:"aprobado"`
+    FechaEvaluacion time.Time   `json:"fecha_evaluacion"`
+    CalificadoPor   *int        `json:"calificado_por,omitempty"`
+    Evaluacion      *Evaluacion `json:"evaluacion,omitempty"`
+    Usuario         *Usuario    `json:"usuario,omitempty"`
 }
 
 type Certificado struct {
-    ID                 int       `json:"id"`
-    InscripcionID      int       `json:"inscripcion_id"`
-    CodigoCertificado  string    `json:"codigo_certificado"`
-    FechaEmision       time.Time `json:"fecha_emision"`
-    URLPDF             string    `json:"url_pdf,omitempty"`
+    ID                 int          `json:"id"`
+    InscripcionID      int          `json:"inscripcion_id"`
+    CodigoCertificado  string       `json:"codigo_certificado"`
+    FechaEmision       time.Time    `json:"fecha_emision"`
+    URLPDF             string       `json:"url_pdf,omitempty"`
+    Inscripcion        *Inscripcion `json:"inscripcion,omitempty"`
+}
+
+// CertificadoVerificacion es la información pública de un certificado
+type CertificadoVerificacion struct {
+    CodigoCertificado string    `json:"codigo_certificado"`
+    Titular           string    `json:"titular"`
+    Curso             string    `json:"curso"`
+    DuracionHoras     int       `json:"duracion_horas"`
+    Instructor        string    `json:"instructor"`
+    FechaEmision      time.Time `json:"fecha_emision"`
+}
+
+// Sesion agrupa la familia de refresh tokens emitidos desde un login
+type Sesion struct {
+    ID               string     `json:"id"`
+    UsuarioID        int        `json:"usuario_id"`
+    CreatedAt        time.Time  `json:"created_at"`
+    RevocadaAt       *time.Time `json:"revocada_at,omitempty"`
+    MotivoRevocacion string     `json:"motivo_revocacion,omitempty"`
+}
+
+// RefreshToken es un eslabón de la familia de una sesión; solo se guarda su hash
+type RefreshToken struct {
+    ID        int        `json:"id"`
+    SesionID  string     `json:"sesion_id"`
+    TokenHash string     `json:"-"`
+    ExpiraAt  time.Time  `json:"expira_at"`
+    UsadoAt   *time.Time `json:"usado_at,omitempty"`
+    CreatedAt time.Time  `json:"created_at"`
+    Sesion    *Sesion    `json:"sesion,omitempty"`
+}
+
+// SolicitudInstructor es la petición de un alumno para pasar a ser instructor
+type SolicitudInstructor struct {
+    ID                 int        `json:"id"`
+    UsuarioID          int        `json:"usuario_id"`
+    Motivo             string     `json:"motivo"`
+    Estado             string     `json:"estado"` // "pendiente", "aprobada", "rechazada"
+    RevisadoPor        *int       `json:"revisado_por,omitempty"`
+    ComentarioRevision string     `json:"comentario_revision,omitempty"`
+    CreatedAt          time.Time  `json:"created_at"`
+    RevisadaAt         *time.Time `json:"revisada_at,omitempty"`
+    Usuario            *Usuario   `json:"usuario,omitempty"`
+}
+
+// TokenUsuario es un token de un solo uso enviado por email (verificación de
+// email o restablecimiento de contraseña); solo se guarda su hash
+type TokenUsuario struct {
+    ID        int        `json:"id"`
+    UsuarioID int        `json:"usuario_id"`
+    Tipo      string     `json:"tipo"` // "verificacion_email", "reset_password"
+    TokenHash string     `json:"-"`
+    ExpiraAt  time.Time  `json:"expira_at"`
+    UsadoAt   *time.Time `json:"usado_at,omitempty"`
+    CreatedAt time.Time  `json:"created_at"`
+}
+
+// IdentidadExterna es una cuenta de un proveedor OIDC vinculada a un usuario
+type IdentidadExterna struct {
+    ID            int        `json:"id"`
+    UsuarioID     int        `json:"usuario_id"`
+    Proveedor     string     `json:"proveedor"`
+    Subject       string     `json:"subject"`
+    Email         string     `json:"email,omitempty"`
+    CreatedAt     time.Time  `json:"created_at"`
+    UltimoLoginAt *time.Time `json:"ultimo_login_at,omitempty"`
+}
+
+// EstadoOIDC es un login OIDC en curso; solo se guarda el hash del state
+type EstadoOIDC struct {
+    StateHash    string
+    Proveedor    string
+    Nonce        string
+    CodeVerifier string
+    ExpiraAt     time.Time
+}
+
+// ConfigTOTP es el estado de la autenticación en dos pasos de un usuario
+type ConfigTOTP struct {
+    UsuarioID  int
+    Secret     string // vacío si no se ha iniciado el alta
+    Habilitado bool
+    UltimoPaso int64 // último paso de tiempo aceptado
+}
+
+// AltaTOTP es lo que necesita la app de autenticación para dar de alta la cuenta
+type AltaTOTP struct {
+    Secret string `json:"secret"`
+    URI    string `json:"otpauth_uri"`
 }
 
 // DTOs para requests
@@ -89,10 +376,55 @@ type RegisterRequest struct {
     Nombre   string `json:"nombre"`
     Email    string `json:"email"`
     Password string `json:"password"`
-    Rol      string `json:"rol"`
 }
 
+type ForgotPasswordRequest struct {
+    Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+    Token       string `json:"token"`
+    NewPassword string `json:"new_password"`
+}
+
+// LoginMFARequest completa un login con 2FA: el token del desafío y un código
+// TOTP o de recuperación
+type LoginMFARequest struct {
+    MFAToken string `json:"mfa_token"`
+    Codigo   string `json:"codigo"`
+}
+
+// OIDCCanjeRequest canjea el código de un solo uso de un login OIDC por tokens
+type OIDCCanjeRequest struct {
+    Codigo string `json:"codigo"`
+}
+
+type CodigoMFARequest struct {
+    Codigo string `json:"codigo"`
+}
+
+type DesactivarTOTPRequest struct {
+    Password string `json:"password"`
+    Codigo   string `json:"codigo"`
+}
+
+type RefreshRequest struct {
+    RefreshToken string `json:"refresh_token"`
+}
+
+// TokenPair es el par de tokens entregado al iniciar o renovar una sesión
+type TokenPair struct {
+    Token        string `json:"token"`
+    RefreshToken string `json:"refresh_token"`
+    ExpiresIn    int    `json:"expires_in"` // segundos de vida del token de acceso
+}
+
+// LoginResponse lleva los tokens de la sesión o, si el usuario tiene 2FA, el
+// token del desafío MFA con el que completar el login
 type LoginResponse struct {
-    Token   string   `json:"token"`
-    Usuario *Usuario `json:"usuario"`
+    *TokenPair
+    Usuario      *Usuario `json:"usuario,omitempty"`
+    MFARequerido bool     `json:"mfa_requerido,omitempty"`
+    MFAToken     string   `json:"mfa_token,omitempty"`
+    MFAExpiresIn int      `json:"mfa_expires_in,omitempty"`
 }
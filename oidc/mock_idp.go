@@ -0,0 +1,227 @@
+package oidc
+
+import (
+    "crypto/ed25519"
+    "crypto/rand"
+    "crypto/sha256"
+    "encoding/base64"
+    "encoding/hex"
+    "encoding/json"
+    "html/template"
+    "net/http"
+    "net/url"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/golang-jwt/jwt/v5"
+)
+
+// MockIdP es un proveedor OIDC mínimo para desarrollo y pruebas locales. No
+// tiene usuarios ni contraseñas: su página de login pregunta con qué email y
+// nombre entrar. Acepta cualquier client_id y exige PKCE S256.
+type MockIdP struct {
+    issuer  string
+    kid     string
+    clave   ed25519.PrivateKey
+    mu      sync.Mutex
+    codigos map[string]codigoMock
+}
+
+// codigoMock es un código de autorización emitido y pendiente de canjear
+type codigoMock struct {
+    clientID      string
+    redirectURI   string
+    codeChallenge string
+    nonce         string
+    email         string
+    nombre        string
+    expira        time.Time
+}
+
+func NewMockIdP(issuer string) (*MockIdP, error) {
+    _, clave, err := ed25519.GenerateKey(rand.Reader)
+    if err != nil {
+        return nil, err
+    }
+
+    return &MockIdP{
+        issuer:  strings.TrimRight(issuer, "/"),
+        kid:     "mock-" + time.Now().UTC().Format("20060102150405"),
+        clave:   clave,
+        codigos: make(map[string]codigoMock),
+    }, nil
+}
+
+// Handler devuelve las rutas del proveedor
+func (m *MockIdP) Handler() http.Handler {
+    mux := http.NewServeMux()
+    mux.HandleFunc("/.well-known/openid-configuration", m.discovery)
+    mux.HandleFunc("/authorize", m.authorize)
+    mux.HandleFunc("/token", m.token)
+    mux.HandleFunc("/jwks", m.jwks)
+    return mux
+}
+
+func (m *MockIdP) discovery(w http.ResponseWriter, r *http.Request) {
+    escribirJSON(w, http.StatusOK, map[string]interface{}{
+        "issuer":                                m.issuer,
+        "authorization_endpoint":                m.issuer + "/authorize",
+        "token_endpoint":                        m.issuer + "/token",
+        "jwks_uri":                              m.issuer + "/jwks",
+        "response_types_supported":              []string{"code"},
+        "subject_types_supported":               []string{"public"},
+        "id_token_signing_alg_values_supported": []string{"EdDSA"},
+        "code_challenge_methods_supported":      []string{"S256"},
+    })
+}
+
+var paginaLoginMock = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Mock IdP</title></head>
+<body>
+<h1>Mock IdP</h1>
+<p>Proveedor de identidad de pruebas: elige con qué cuenta entrar.</p>
+<form method="POST">
+{{range $k, $v := .}}<input type="hidden" name="{{$k}}" value="{{index $v 0}}">
+{{end}}<p><label>Email <input name="email" type="email" required></label></p>
+<p><label>Nombre <input name="name"></label></p>
+<p><button type="submit">Entrar</button></p>
+</form>
+</body></html>`))
+
+// authorize muestra la página de login (GET) y emite el código (POST)
+func (m *MockIdP) authorize(w http.ResponseWriter, r *http.Request) {
+    if err := r.ParseForm(); err != nil {
+        http.Error(w, "petición inválida", http.StatusBadRequest)
+        return
+    }
+
+    params := url.Values{}
+    for _, campo := range []string{"response_type", "client_id", "redirect_uri", "state", "nonce", "code_challenge", "code_challenge_method", "scope"} {
+        params.Set(campo, r.Form.Get(campo))
+    }
+
+    if params.Get("response_type") != "code" || params.Get("client_id") == "" || params.Get("redirect_uri") == "" {
+        http.Error(w, "faltan response_type=code, client_id o redirect_uri", http.StatusBadRequest)
+        return
+    }
+    if params.Get("code_challenge_method") != "S256" || params.Get("code_challenge") == "" {
+        http.Error(w, "se requiere PKCE con code_challenge_method=S256", http.StatusBadRequest)
+        return
+    }
+
+    if r.Method == http.MethodGet {
+        w.Header().Set("Content-Type", "text/html; charset=utf-8")
+        paginaLoginMock.Execute(w, params)
+        return
+    }
+
+    email := strings.ToLower(strings.TrimSpace(r.Form.Get("email")))
+    if email == "" {
+        http.Error(w, "email requerido", http.StatusBadRequest)
+        return
+    }
+
+    codigo := aleatorioMock()
+    m.mu.Lock()
+    m.codigos[codigo] = codigoMock{
+        clientID:      params.Get("client_id"),
+        redirectURI:   params.Get("redirect_uri"),
+        codeChallenge: params.Get("code_challenge"),
+        nonce:         params.Get("nonce"),
+        email:         email,
+        nombre:        strings.TrimSpace(r.Form.Get("name")),
+        expira:        time.Now().Add(time.Minute),
+    }
+    m.mu.Unlock()
+
+    destino, err := url.Parse(params.Get("redirect_uri"))
+    if err != nil {
+        http.Error(w, "redirect_uri inválida", http.StatusBadRequest)
+        return
+    }
+    query := destino.Query()
+    query.Set("code", codigo)
+    query.Set("state", params.Get("state"))
+    destino.RawQuery = query.Encode()
+
+    http.Redirect(w, r, destino.String(), http.StatusFound)
+}
+
+// token canjea un código por el id_token
+func (m *MockIdP) token(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodPost || r.ParseForm() != nil || r.Form.Get("grant_type") != "authorization_code" {
+        escribirJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+        return
+    }
+
+    m.mu.Lock()
+    codigo, ok := m.codigos[r.Form.Get("code")]
+    delete(m.codigos, r.Form.Get("code"))
+    m.mu.Unlock()
+
+    if !ok || time.Now().After(codigo.expira) ||
+        codigo.clientID != r.Form.Get("client_id") ||
+        codigo.redirectURI != r.Form.Get("redirect_uri") ||
+        CodeChallenge(r.Form.Get("code_verifier")) != codigo.codeChallenge {
+        escribirJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+        return
+    }
+
+    // El subject es estable para cada email
+    sum := sha256.Sum256([]byte(codigo.email))
+    now := time.Now()
+    claims := jwt.MapClaims{
+        "iss":            m.issuer,
+        "sub":            hex.EncodeToString(sum[:8]),
+        "aud":            codigo.clientID,
+        "iat":            now.Unix(),
+        "exp":            now.Add(5 * time.Minute).Unix(),
+        "nonce":          codigo.nonce,
+        "email":          codigo.email,
+        "email_verified": true,
+        "name":           codigo.nombre,
+    }
+
+    idToken := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+    idToken.Header["kid"] = m.kid
+    firmado, err := idToken.SignedString(m.clave)
+    if err != nil {
+        escribirJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+        return
+    }
+
+    escribirJSON(w, http.StatusOK, map[string]interface{}{
+        "access_token": aleatorioMock(),
+        "token_type":   "Bearer",
+        "expires_in":   300,
+        "id_token":     firmado,
+    })
+}
+
+func (m *MockIdP) jwks(w http.ResponseWriter, r *http.Request) {
+    publica := m.clave.Public().(ed25519.PublicKey)
+    escribirJSON(w, http.StatusOK, map[string]interface{}{
+        "keys": []map[string]string{{
+            "kty": "OKP",
+            "crv": "Ed25519",
+            "kid": m.kid,
+            "use": "sig",
+            "alg": "EdDSA",
+            "x":   base64.RawURLEncoding.EncodeToString(publica),
+        }},
+    })
+}
+
+// aleatorioMock genera un valor aleatorio para códigos y tokens del mock
+func aleatorioMock() string {
+    bytes := make([]byte, 24)
+    rand.Read(bytes)
+    return base64.RawURLEncoding.EncodeToString(bytes)
+}
+
+func escribirJSON(w http.ResponseWriter, status int, data interface{}) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    json.NewEncoder(w).Encode(data)
+}
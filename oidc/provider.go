@@ -0,0 +1,342 @@
+// Package oidc implementa el lado cliente del login con OpenID Connect
+// (authorization code + PKCE) contra proveedores de identidad externos, y un
+// proveedor simulado para probarlo en local.
+package oidc
+
+import (
+    "crypto/ecdsa"
+    "crypto/ed25519"
+    "crypto/elliptic"
+    "crypto/rsa"
+    "crypto/sha256"
+    "encoding/base64"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "math/big"
+    "net/http"
+    "net/url"
+    "os"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/golang-jwt/jwt/v5"
+)
+
+// Identity son los datos del usuario que el proveedor asegura en el id_token
+type Identity struct {
+    Subject       string
+    Email         string
+    EmailVerified bool
+    Nombre        string
+}
+
+// Provider es un proveedor de identidad OIDC configurado como cliente
+type Provider struct {
+    Nombre       string
+    Issuer       string
+    ClientID     string
+    ClientSecret string
+    RedirectURL  string
+    Scopes       []string
+
+    client *http.Client
+
+    mu        sync.Mutex
+    discovery *discovery
+    jwks      map[string]interface{} // claves públicas por kid
+    jwksAt    time.Time
+}
+
+// discovery son los endpoints publicados en /.well-known/openid-configuration
+type discovery struct {
+    Issuer                string `json:"issuer"`
+    AuthorizationEndpoint string `json:"authorization_endpoint"`
+    TokenEndpoint         string `json:"token_endpoint"`
+    JWKSURI               string `json:"jwks_uri"`
+}
+
+func NewProvider(nombre, issuer, clientID, clientSecret, redirectURL string) *Provider {
+    return &Provider{
+        Nombre:       nombre,
+        Issuer:       strings.TrimRight(issuer, "/"),
+        ClientID:     clientID,
+        ClientSecret: clientSecret,
+        RedirectURL:  redirectURL,
+        Scopes:       []string{"openid", "email", "profile"},
+        client:       &http.Client{Timeout: 10 * time.Second},
+    }
+}
+
+// ProvidersFromEnv crea los proveedores listados en OIDC_PROVIDERS (nombres
+// separados por comas). Cada uno se configura con OIDC_<NOMBRE>_ISSUER,
+// OIDC_<NOMBRE>_CLIENT_ID y OIDC_<NOMBRE>_CLIENT_SECRET (opcional con PKCE).
+// El callback es <baseURL>/api/auth/oidc/<nombre>/callback.
+func ProvidersFromEnv(baseURL string) (map[string]*Provider, error) {
+    proveedores := make(map[string]*Provider)
+
+    for _, nombre := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+        nombre = strings.ToLower(strings.TrimSpace(nombre))
+        if nombre == "" {
+            continue
+        }
+
+        prefijo := "OIDC_" + strings.ToUpper(nombre) + "_"
+        issuer := os.Getenv(prefijo + "ISSUER")
+        clientID := os.Getenv(prefijo + "CLIENT_ID")
+        if issuer == "" || clientID == "" {
+            return nil, fmt.Errorf("el proveedor OIDC %q necesita %sISSUER y %sCLIENT_ID", nombre, prefijo, prefijo)
+        }
+
+        redirectURL := baseURL + "/api/auth/oidc/" + url.PathEscape(nombre) + "/callback"
+        proveedores[nombre] = NewProvider(nombre, issuer, clientID, os.Getenv(prefijo+"CLIENT_SECRET"), redirectURL)
+    }
+
+    return proveedores, nil
+}
+
+// CodeChallenge calcula el code_challenge S256 de un code_verifier (PKCE, RFC 7636)
+func CodeChallenge(verifier string) string {
+    sum := sha256.Sum256([]byte(verifier))
+    return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL construye la URL del proveedor a la que se redirige al usuario
+func (p *Provider) AuthCodeURL(state, nonce, codeVerifier string) (string, error) {
+    d, err := p.getDiscovery()
+    if err != nil {
+        return "", err
+    }
+
+    params := url.Values{}
+    params.Set("response_type", "code")
+    params.Set("client_id", p.ClientID)
+    params.Set("redirect_uri", p.RedirectURL)
+    params.Set("scope", strings.Join(p.Scopes, " "))
+    params.Set("state", state)
+    params.Set("nonce", nonce)
+    params.Set("code_challenge", CodeChallenge(codeVerifier))
+    params.Set("code_challenge_method", "S256")
+
+    separador := "?"
+    if strings.Contains(d.AuthorizationEndpoint, "?") {
+        separador = "&"
+    }
+    return d.AuthorizationEndpoint + separador + params.Encode(), nil
+}
+
+// Exchange canjea el código de autorización por los tokens del proveedor y
+// devuelve la identidad del id_token ya validado (firma, iss, aud, exp y nonce)
+func (p *Provider) Exchange(code, codeVerifier, nonce string) (*Identity, error) {
+    d, err := p.getDiscovery()
+    if err != nil {
+        return nil, err
+    }
+
+    form := url.Values{}
+    form.Set("grant_type", "authorization_code")
+    form.Set("code", code)
+    form.Set("redirect_uri", p.RedirectURL)
+    form.Set("client_id", p.ClientID)
+    form.Set("code_verifier", codeVerifier)
+    if p.ClientSecret != "" {
+        form.Set("client_secret", p.ClientSecret)
+    }
+
+    resp, err := p.client.PostForm(d.TokenEndpoint, form)
+    if err != nil {
+        return nil, fmt.Errorf("error al contactar con el proveedor: %w", err)
+    }
+    defer resp.Body.Close()
+
+    var tokens struct {
+        IDToken string `json:"id_token"`
+        Error   string `json:"error"`
+    }
+    if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+        return nil, fmt.Errorf("respuesta inválida del proveedor: %w", err)
+    }
+    if resp.StatusCode != http.StatusOK || tokens.IDToken == "" {
+        return nil, fmt.Errorf("el proveedor rechazó el código (%d %s)", resp.StatusCode, tokens.Error)
+    }
+
+    return p.verificarIDToken(tokens.IDToken, nonce)
+}
+
+// idTokenClaims son los claims del id_token que se usan
+type idTokenClaims struct {
+    Nonce         string `json:"nonce"`
+    Email         string `json:"email"`
+    EmailVerified bool   `json:"email_verified"`
+    Name          string `json:"name"`
+    jwt.RegisteredClaims
+}
+
+// verificarIDToken valida el id_token con las claves del JWKS del proveedor
+func (p *Provider) verificarIDToken(idToken, nonce string) (*Identity, error) {
+    d, err := p.getDiscovery()
+    if err != nil {
+        return nil, err
+    }
+
+    claims := &idTokenClaims{}
+
+    _, err = jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+        kid, _ := token.Header["kid"].(string)
+        return p.clavePublica(kid)
+    },
+        jwt.WithValidMethods([]string{"RS256", "ES256", "EdDSA"}),
+        jwt.WithIssuer(d.Issuer),
+        jwt.WithAudience(p.ClientID),
+        jwt.WithExpirationRequired(),
+    )
+    if err != nil {
+        return nil, fmt.Errorf("id_token inválido: %w", err)
+    }
+
+    if claims.Nonce != nonce {
+        return nil, errors.New("id_token inválido: nonce incorrecto")
+    }
+    if claims.Subject == "" {
+        return nil, errors.New("id_token inválido: falta el subject")
+    }
+
+    return &Identity{
+        Subject:       claims.Subject,
+        Email:         strings.ToLower(claims.Email),
+        EmailVerified: claims.EmailVerified,
+        Nombre:        claims.Name,
+    }, nil
+}
+
+// getDiscovery obtiene (una vez) la configuración publicada por el proveedor
+func (p *Provider) getDiscovery() (*discovery, error) {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+
+    if p.discovery != nil {
+        return p.discovery, nil
+    }
+
+    d := &discovery{}
+    if err := p.getJSON(p.Issuer+"/.well-known/openid-configuration", d); err != nil {
+        return nil, fmt.Errorf("error al obtener la configuración OIDC de %s: %w", p.Nombre, err)
+    }
+    if strings.TrimRight(d.Issuer, "/") != p.Issuer {
+        return nil, fmt.Errorf("el issuer publicado por %s no coincide: %s", p.Nombre, d.Issuer)
+    }
+
+    p.discovery = d
+    return d, nil
+}
+
+// clavePublica devuelve la clave del JWKS con el kid indicado. Si no está se
+// vuelve a descargar el JWKS (como mucho una vez por minuto), por si el
+// proveedor rotó sus claves.
+func (p *Provider) clavePublica(kid string) (interface{}, error) {
+    d, err := p.getDiscovery()
+    if err != nil {
+        return nil, err
+    }
+
+    p.mu.Lock()
+    defer p.mu.Unlock()
+
+    if clave, ok := p.jwks[kid]; ok {
+        return clave, nil
+    }
+
+    if time.Since(p.jwksAt) < time.Minute {
+        return nil, errors.New("clave de firma desconocida")
+    }
+    p.jwksAt = time.Now()
+
+    var set struct {
+        Keys []jwk `json:"keys"`
+    }
+    if err := p.getJSON(d.JWKSURI, &set); err != nil {
+        return nil, fmt.Errorf("error al obtener el JWKS de %s: %w", p.Nombre, err)
+    }
+
+    p.jwks = make(map[string]interface{})
+    for _, k := range set.Keys {
+        if k.Use != "" && k.Use != "sig" {
+            continue
+        }
+        if clave, err := k.publica(); err == nil {
+            p.jwks[k.Kid] = clave
+        }
+    }
+
+    clave, ok := p.jwks[kid]
+    if !ok {
+        return nil, errors.New("clave de firma desconocida")
+    }
+    return clave, nil
+}
+
+// getJSON descarga y decodifica un documento JSON
+func (p *Provider) getJSON(url string, destino interface{}) error {
+    resp, err := p.client.Get(url)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        return fmt.Errorf("respuesta %d", resp.StatusCode)
+    }
+
+    return json.NewDecoder(resp.Body).Decode(destino)
+}
+
+// jwk es una clave pública de un JWKS (RFC 7517)
+type jwk struct {
+    Kty string `json:"kty"`
+    Kid string `json:"kid"`
+    Use string `json:"use"`
+    N   string `json:"n"`
+    E   string `json:"e"`
+    Crv string `json:"crv"`
+    X   string `json:"x"`
+    Y   string `json:"y"`
+}
+
+// publica convierte la JWK en una clave pública de crypto
+func (k jwk) publica() (interface{}, error) {
+    decode := base64.RawURLEncoding.DecodeString
+
+    switch {
+    case k.Kty == "RSA":
+        n, err := decode(k.N)
+        if err != nil {
+            return nil, err
+        }
+        e, err := decode(k.E)
+        if err != nil {
+            return nil, err
+        }
+        return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+
+    case k.Kty == "EC" && k.Crv == "P-256":
+        x, err := decode(k.X)
+        if err != nil {
+            return nil, err
+        }
+        y, err := decode(k.Y)
+        if err != nil {
+            return nil, err
+        }
+        return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+
+    case k.Kty == "OKP" && k.Crv == "Ed25519":
+        x, err := decode(k.X)
+        if err != nil || len(x) != ed25519.PublicKeySize {
+            return nil, errors.New("clave Ed25519 inválida")
+        }
+        return ed25519.PublicKey(x), nil
+    }
+
+    return nil, fmt.Errorf("tipo de clave no admitido: %s %s", k.Kty, k.Crv)
+}
@@ -0,0 +1,148 @@
+// Package policy centraliza la autorización: qué permisos tiene cada rol y
+// cómo se resuelven los permisos sobre recursos con dueño.
+//
+// Los permisos siguen el formato recurso:acción[:alcance]. El alcance "own"
+// limita la acción a los recursos del propio usuario y "any" la extiende a
+// todos; tener el permiso "any" implica tener el "own" correspondiente.
+package policy
+
+import "strings"
+
+// Roles de usuario
+const (
+    RolAlumno     = "alumno"
+    RolInstructor = "instructor"
+    RolAdmin      = "admin"
+)
+
+// RolRegistro es el rol de las cuentas creadas con el registro público. El
+// resto de roles solo se obtienen por aprobación (instructor) o con el
+// comando create-admin (admin).
+const RolRegistro = RolAlumno
+
+type Permiso string
+
+// Cursos
+const (
+    CursoCreate       Permiso = "curso:create"
+    CursoReadCatalogo Permiso = "curso:read:catalogo"
+    CursoReadOwn      Permiso = "curso:read:own"
+    CursoReadAny      Permiso = "curso:read:any"
+    CursoUpdateOwn    Permiso = "curso:update:own"
+    CursoUpdateAny    Permiso = "curso:update:any"
+    CursoDeleteOwn    Permiso = "curso:delete:own"
+    CursoDeleteAny    Permiso = "curso:delete:any"
+    CursoReassign     Permiso = "curso:reassign"
+    CursoModerate     Permiso = "curso:moderate"
+    CursoReview       Permiso = "curso:review" // aprobar la publicación de cursos en revisión
+
+    // Contenido del curso: lecciones, evaluaciones, preguntas, inscritos,
+    // resultados y certificados emitidos
+    CursoContentOwn Permiso = "curso:content:own"
+    CursoContentAny Permiso = "curso:content:any"
+)
+
+// Aprendizaje
+const (
+    InscripcionCreate    Permiso = "inscripcion:create"
+    InscripcionManageOwn Permiso = "inscripcion:manage:own"
+    EvaluacionSubmit     Permiso = "evaluacion:submit"
+    CertificadoRequest   Permiso = "certificado:request"
+)
+
+// Usuarios
+const (
+    UsuarioReadOwn   Permiso = "usuario:read:own"
+    UsuarioReadAny   Permiso = "usuario:read:any"
+    UsuarioUpdateOwn Permiso = "usuario:update:own"
+    UsuarioUpdateAny Permiso = "usuario:update:any"
+    UsuarioDeleteOwn Permiso = "usuario:delete:own"
+    UsuarioDeleteAny Permiso = "usuario:delete:any"
+    UsuarioManage    Permiso = "usuario:manage"
+)
+
+// Solicitudes para ser instructor
+const (
+    InstructorApply  Permiso = "instructor:apply"
+    InstructorReview Permiso = "instructor:review"
+)
+
+// Catálogo: categorías de cursos
+const (
+    CategoriaManage Permiso = "categoria:manage"
+)
+
+// permisosUsuario son los permisos comunes a todos los roles sobre su cuenta
+var permisosUsuario = []Permiso{UsuarioReadOwn, UsuarioUpdateOwn, UsuarioDeleteOwn}
+
+// roles define los roles conocidos. Añadir un rol nuevo solo requiere
+// registrarlo aquí con sus permisos.
+var roles = map[string][]Permiso{
+    RolAlumno: append([]Permiso{
+        CursoReadCatalogo,
+        InscripcionCreate,
+        InscripcionManageOwn,
+        EvaluacionSubmit,
+        CertificadoRequest,
+        InstructorApply,
+    }, permisosUsuario...),
+    RolInstructor: append([]Permiso{
+        CursoCreate,
+        CursoReadOwn,
+        CursoUpdateOwn,
+        CursoDeleteOwn,
+        CursoContentOwn,
+    }, permisosUsuario...),
+    RolAdmin: {
+        CursoReadAny,
+        CursoUpdateAny,
+        CursoDeleteAny,
+        CursoContentAny,
+        CursoReassign,
+        CursoModerate,
+        CursoReview,
+        CategoriaManage,
+        UsuarioReadAny,
+        UsuarioUpdateAny,
+        UsuarioDeleteAny,
+        UsuarioManage,
+        InstructorReview,
+    },
+}
+
+// Puede indica si el rol tiene el permiso. Un permiso "own" también se
+// concede con su variante "any".
+func Puede(rol string, permiso Permiso) bool {
+    cualquiera := alcanceAny(permiso)
+    for _, p := range roles[rol] {
+        if p == permiso || (cualquiera != "" && p == cualquiera) {
+            return true
+        }
+    }
+    return false
+}
+
+// PuedeSobre indica si el usuario puede ejercer un permiso "own" sobre un
+// recurso cuyo dueño es duenoID: con la variante "any" sobre cualquiera, con
+// la "own" solo sobre los suyos.
+func PuedeSobre(rol string, permiso Permiso, userID, duenoID int) bool {
+    if cualquiera := alcanceAny(permiso); cualquiera != "" && Puede(rol, cualquiera) {
+        return true
+    }
+    return userID == duenoID && Puede(rol, permiso)
+}
+
+// RolValido indica si el rol existe
+func RolValido(rol string) bool {
+    _, ok := roles[rol]
+    return ok
+}
+
+// alcanceAny devuelve la variante "any" de un permiso "own", o "" si no lo es
+func alcanceAny(permiso Permiso) Permiso {
+    base, ok := strings.CutSuffix(string(permiso), ":own")
+    if !ok {
+        return ""
+    }
+    return Permiso(base + ":any")
+}
@@ -0,0 +1,89 @@
+package ratelimit
+
+import (
+    "fmt"
+    "time"
+)
+
+// RedisClient es lo mínimo que RedisStore necesita de un cliente de Redis (o
+// compatible, como Valkey o KeyDB): ejecutar un script Lua. Así la API no
+// depende de un driver concreto; basta un adaptador sobre el que se use.
+type RedisClient interface {
+    Eval(script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+// tokenBucketScript aplica el token bucket de forma atómica en Redis.
+// ARGV: capacidad, periodo (ms), ahora (ms). Devuelve {admitida, restantes,
+// reset (ms), retry after (ms)}.
+const tokenBucketScript = `
+local capacity = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local rate = capacity / period
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+    tokens = tokens - 1
+    allowed = 1
+else
+    retry = math.ceil((1 - tokens) / rate)
+end
+
+redis.call('HMSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], period)
+
+return {allowed, math.floor(tokens), math.ceil((capacity - tokens) / rate), retry}
+`
+
+// RedisStore guarda los buckets en Redis para compartir los límites entre
+// varias instancias de la API
+type RedisStore struct {
+    client RedisClient
+    prefix string
+    now    func() time.Time // reloj, sustituible en las pruebas
+}
+
+func NewRedisStore(client RedisClient, prefix string) *RedisStore {
+    return &RedisStore{client: client, prefix: prefix, now: time.Now}
+}
+
+// Take consume un token del bucket de la clave si hay disponible
+func (s *RedisStore) Take(key string, limit Limit) (Result, error) {
+    reply, err := s.client.Eval(
+        tokenBucketScript,
+        []string{s.prefix + key},
+        limit.Requests,
+        limit.Period.Milliseconds(),
+        s.now().UnixMilli(),
+    )
+    if err != nil {
+        return Result{}, err
+    }
+
+    valores, ok := reply.([]interface{})
+    if !ok || len(valores) != 4 {
+        return Result{}, fmt.Errorf("respuesta inesperada de redis: %v", reply)
+    }
+
+    enteros := make([]int64, len(valores))
+    for i, v := range valores {
+        n, ok := v.(int64)
+        if !ok {
+            return Result{}, fmt.Errorf("respuesta inesperada de redis: %v", reply)
+        }
+        enteros[i] = n
+    }
+
+    return Result{
+        Allowed:    enteros[0] == 1,
+        Remaining:  int(enteros[1]),
+        Reset:      time.Duration(enteros[2]) * time.Millisecond,
+        RetryAfter: time.Duration(enteros[3]) * time.Millisecond,
+    }, nil
+}
@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+    "testing"
+    "time"
+)
+
+// redisFalso devuelve una respuesta fija y guarda los argumentos del script
+type redisFalso struct {
+    respuesta interface{}
+    keys      []string
+    args      []interface{}
+}
+
+func (r *redisFalso) Eval(script string, keys []string, args ...interface{}) (interface{}, error) {
+    r.keys = keys
+    r.args = args
+    return r.respuesta, nil
+}
+
+func TestRedisStoreTake(t *testing.T) {
+    reloj := nuevoReloj()
+    client := &redisFalso{respuesta: []interface{}{int64(0), int64(0), int64(3000), int64(1000)}}
+    s := NewRedisStore(client, "rl:")
+    s.now = reloj.Now
+
+    result := tomar(t, s, "ip:1", Limit{Requests: 3, Period: 3 * time.Second})
+
+    if len(client.keys) != 1 || client.keys[0] != "rl:ip:1" {
+        t.Fatalf("keys = %v, se esperaba [rl:ip:1]", client.keys)
+    }
+    if len(client.args) != 3 || client.args[0] != 3 || client.args[1] != int64(3000) || client.args[2] != reloj.Now().UnixMilli() {
+        t.Fatalf("args = %v, se esperaba [3 3000 %d]", client.args, reloj.Now().UnixMilli())
+    }
+
+    esperado := Result{Allowed: false, Remaining: 0, Reset: 3 * time.Second, RetryAfter: time.Second}
+    if result != esperado {
+        t.Fatalf("Take = %+v, se esperaba %+v", result, esperado)
+    }
+}
+
+func TestRedisStoreRespuestaInesperada(t *testing.T) {
+    for _, respuesta := range []interface{}{
+        "OK",
+        []interface{}{int64(1), int64(2)},
+        []interface{}{int64(1), "2", int64(0), int64(0)},
+    } {
+        s := NewRedisStore(&redisFalso{respuesta: respuesta}, "rl:")
+        if _, err := s.Take("ip:1", PerMinute(10)); err == nil {
+            t.Fatalf("Take aceptó la respuesta %v", respuesta)
+        }
+    }
+}
@@ -0,0 +1,97 @@
+// Package ratelimit contiene limitadores de frecuencia. SlidingWindow y
+// MemoryStore guardan el estado en memoria del proceso: con varias instancias
+// cada una aplica su propio límite, salvo que se use RedisStore.
+package ratelimit
+
+import (
+    "sync"
+    "time"
+)
+
+// SlidingWindow limita los eventos por clave (por ejemplo una IP) a un máximo
+// dentro de una ventana deslizante. Guarda la hora de cada evento, así que
+// está pensado para límites bajos como los intentos de login fallidos.
+type SlidingWindow struct {
+    mu        sync.Mutex
+    limit     int
+    window    time.Duration
+    events    map[string][]time.Time
+    lastSweep time.Time
+    now       func() time.Time // reloj, sustituible en las pruebas
+}
+
+func NewSlidingWindow(limit int, window time.Duration) *SlidingWindow {
+    return &SlidingWindow{
+        limit:     limit,
+        window:    window,
+        events:    make(map[string][]time.Time),
+        lastSweep: time.Now(),
+        now:       time.Now,
+    }
+}
+
+// Exceeded indica si la clave alcanzó el límite y, en ese caso, cuánto falta
+// para que el evento más antiguo salga de la ventana
+func (w *SlidingWindow) Exceeded(key string) (bool, time.Duration) {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+
+    now := w.now()
+    events := w.prune(key, now)
+    if len(events) < w.limit {
+        return false, 0
+    }
+
+    return true, events[len(events)-w.limit].Add(w.window).Sub(now)
+}
+
+// Add registra un evento para la clave
+func (w *SlidingWindow) Add(key string) {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+
+    now := w.now()
+    events := append(w.prune(key, now), now)
+
+    // Basta con recordar los últimos limit eventos
+    if len(events) > w.limit {
+        events = events[len(events)-w.limit:]
+    }
+    w.events[key] = events
+
+    w.sweep(now)
+}
+
+// prune descarta los eventos de la clave que ya salieron de la ventana.
+// Requiere tener el lock tomado.
+func (w *SlidingWindow) prune(key string, now time.Time) []time.Time {
+    events := w.events[key]
+    desde := now.Add(-w.window)
+
+    i := 0
+    for i < len(events) && !events[i].After(desde) {
+        i++
+    }
+    events = events[i:]
+
+    if len(events) == 0 {
+        delete(w.events, key)
+        return nil
+    }
+
+    w.events[key] = events
+    return events
+}
+
+// sweep elimina las claves sin eventos recientes una vez por ventana para que
+// el mapa no crezca sin límite. Requiere tener el lock tomado.
+func (w *SlidingWindow) sweep(now time.Time) {
+    if now.Sub(w.lastSweep) < w.window {
+        return
+    }
+
+    for key := range w.events {
+        w.prune(key, now)
+    }
+    w.lastSweep = now
+}
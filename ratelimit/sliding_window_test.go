@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+    "testing"
+    "time"
+)
+
+func nuevaVentana(reloj *relojFalso, limit int, window time.Duration) *SlidingWindow {
+    w := NewSlidingWindow(limit, window)
+    w.now = reloj.Now
+    w.lastSweep = reloj.Now()
+    return w
+}
+
+func TestSlidingWindowLimite(t *testing.T) {
+    reloj := nuevoReloj()
+    w := nuevaVentana(reloj, 3, 10*time.Second)
+
+    for i := 0; i < 3; i++ {
+        if limitado, _ := w.Exceeded("ip:1"); limitado {
+            t.Fatalf("Exceeded antes del evento %d", i+1)
+        }
+        w.Add("ip:1")
+        reloj.Avanzar(2 * time.Second)
+    }
+
+    // Eventos en 0s, 2s y 4s; ahora son las 6s
+    limitado, retryAfter := w.Exceeded("ip:1")
+    if !limitado || retryAfter != 4*time.Second {
+        t.Fatalf("Exceeded = (%v, %v), se esperaba (true, 4s)", limitado, retryAfter)
+    }
+
+    if limitado, _ := w.Exceeded("ip:2"); limitado {
+        t.Fatal("el límite de una clave afectó a otra")
+    }
+}
+
+func TestSlidingWindowExpiracion(t *testing.T) {
+    reloj := nuevoReloj()
+    w := nuevaVentana(reloj, 2, 10*time.Second)
+
+    w.Add("ip:1")
+    reloj.Avanzar(5 * time.Second)
+    w.Add("ip:1")
+
+    // Justo antes de que caduque el primer evento sigue limitado
+    reloj.Avanzar(5*time.Second - time.Millisecond)
+    if limitado, retryAfter := w.Exceeded("ip:1"); !limitado || retryAfter != time.Millisecond {
+        t.Fatalf("Exceeded = (%v, %v), se esperaba (true, 1ms)", limitado, retryAfter)
+    }
+
+    // El evento que cumple la ventana ya no cuenta, el segundo sí
+    reloj.Avanzar(time.Millisecond)
+    if limitado, _ := w.Exceeded("ip:1"); limitado {
+        t.Fatal("Exceeded siguió contando un evento fuera de la ventana")
+    }
+    w.Add("ip:1")
+    if limitado, retryAfter := w.Exceeded("ip:1"); !limitado || retryAfter != 5*time.Second {
+        t.Fatalf("Exceeded = (%v, %v), se esperaba (true, 5s)", limitado, retryAfter)
+    }
+}
+
+func TestSlidingWindowGuardaSoloLosUltimos(t *testing.T) {
+    reloj := nuevoReloj()
+    w := nuevaVentana(reloj, 2, 10*time.Second)
+
+    for i := 0; i < 5; i++ {
+        w.Add("ip:1")
+        reloj.Avanzar(time.Second)
+    }
+    if n := len(w.events["ip:1"]); n != 2 {
+        t.Fatalf("se guardaron %d eventos, se esperaban 2", n)
+    }
+
+    // El límite se calcula con los eventos más recientes (3s y 4s)
+    if _, retryAfter := w.Exceeded("ip:1"); retryAfter != 8*time.Second {
+        t.Fatalf("RetryAfter = %v, se esperaba 8s", retryAfter)
+    }
+}
+
+func TestSlidingWindowSweep(t *testing.T) {
+    reloj := nuevoReloj()
+    w := nuevaVentana(reloj, 2, 10*time.Second)
+
+    w.Add("ip:1")
+    reloj.Avanzar(11 * time.Second)
+    w.Add("ip:2")
+
+    if _, ok := w.events["ip:1"]; ok {
+        t.Fatal("sweep no descartó una clave sin eventos recientes")
+    }
+    if _, ok := w.events["ip:2"]; !ok {
+        t.Fatal("sweep descartó una clave con eventos")
+    }
+}
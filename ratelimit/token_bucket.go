@@ -0,0 +1,114 @@
+package ratelimit
+
+import (
+    "math"
+    "sync"
+    "time"
+)
+
+// Limit es la cuota de un token bucket: admite ráfagas de hasta Requests
+// peticiones y repone Requests tokens en cada Period
+type Limit struct {
+    Requests int
+    Period   time.Duration
+}
+
+// PerMinute devuelve un límite de n peticiones por minuto
+func PerMinute(n int) Limit {
+    return Limit{Requests: n, Period: time.Minute}
+}
+
+// rate devuelve los tokens repuestos por segundo
+func (l Limit) rate() float64 {
+    return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Result es el resultado de consumir un token
+type Result struct {
+    Allowed    bool
+    Remaining  int           // tokens que quedan tras la petición
+    Reset      time.Duration // tiempo hasta que el bucket vuelve a estar lleno
+    RetryAfter time.Duration // espera hasta el siguiente token si no se admitió
+}
+
+// Store guarda el estado de los buckets. Take debe ser atómico por clave para
+// que varias instancias puedan compartir un mismo Store (ver RedisStore).
+type Store interface {
+    Take(key string, limit Limit) (Result, error)
+}
+
+// bucket es el estado de un token bucket en memoria
+type bucket struct {
+    tokens float64
+    last   time.Time
+    period time.Duration
+}
+
+// MemoryStore guarda los buckets en memoria del proceso
+type MemoryStore struct {
+    mu        sync.Mutex
+    buckets   map[string]*bucket
+    lastSweep time.Time
+    now       func() time.Time // reloj, sustituible en las pruebas
+}
+
+func NewMemoryStore() *MemoryStore {
+    return &MemoryStore{
+        buckets:   make(map[string]*bucket),
+        lastSweep: time.Now(),
+        now:       time.Now,
+    }
+}
+
+// Take consume un token del bucket de la clave si hay disponible
+func (s *MemoryStore) Take(key string, limit Limit) (Result, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    now := s.now()
+    s.sweep(now)
+
+    b, ok := s.buckets[key]
+    if !ok {
+        b = &bucket{tokens: float64(limit.Requests), last: now}
+        s.buckets[key] = b
+    }
+    b.period = limit.Period
+
+    // Reponer los tokens acumulados desde la última petición
+    capacity := float64(limit.Requests)
+    b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Seconds()*limit.rate())
+    b.last = now
+
+    result := Result{}
+    if b.tokens >= 1 {
+        b.tokens--
+        result.Allowed = true
+    } else {
+        result.RetryAfter = segundos((1 - b.tokens) / limit.rate())
+    }
+    result.Remaining = int(b.tokens)
+    result.Reset = segundos((capacity - b.tokens) / limit.rate())
+
+    return result, nil
+}
+
+// sweep elimina una vez por minuto los buckets que ya se han rellenado, que
+// equivalen a no tener estado. Requiere tener el lock tomado.
+func (s *MemoryStore) sweep(now time.Time) {
+    if now.Sub(s.lastSweep) < time.Minute {
+        return
+    }
+
+    for key, b := range s.buckets {
+        if now.Sub(b.last) >= b.period {
+            delete(s.buckets, key)
+        }
+    }
+    s.lastSweep = now
+}
+
+// segundos convierte segundos fraccionarios en una duración
+func segundos(s float64) time.Duration {
+    return time.Duration(s * float64(time.Second))
+}
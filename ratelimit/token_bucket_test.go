@@ -0,0 +1,125 @@
+package ratelimit
+
+import (
+    "testing"
+    "time"
+)
+
+// relojFalso es un reloj que solo avanza cuando la prueba lo pide
+type relojFalso struct {
+    ahora time.Time
+}
+
+func nuevoReloj() *relojFalso {
+    return &relojFalso{ahora: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func (r *relojFalso) Now() time.Time { return r.ahora }
+
+func (r *relojFalso) Avanzar(d time.Duration) { r.ahora = r.ahora.Add(d) }
+
+func nuevoMemoryStore(reloj *relojFalso) *MemoryStore {
+    s := NewMemoryStore()
+    s.now = reloj.Now
+    s.lastSweep = reloj.Now()
+    return s
+}
+
+// tomar consume un token y falla la prueba si el store devuelve error
+func tomar(t *testing.T, s Store, key string, limit Limit) Result {
+    t.Helper()
+
+    result, err := s.Take(key, limit)
+    if err != nil {
+        t.Fatalf("Take: %v", err)
+    }
+    return result
+}
+
+func TestMemoryStoreRafaga(t *testing.T) {
+    reloj := nuevoReloj()
+    s := nuevoMemoryStore(reloj)
+    limit := Limit{Requests: 3, Period: 3 * time.Second} // un token por segundo
+
+    for restantes := 2; restantes >= 0; restantes-- {
+        result := tomar(t, s, "ip:1", limit)
+        if !result.Allowed || result.Remaining != restantes {
+            t.Fatalf("Take = %+v, se esperaba admitida con %d restantes", result, restantes)
+        }
+    }
+
+    result := tomar(t, s, "ip:1", limit)
+    if result.Allowed {
+        t.Fatal("Take admitió una petición con el bucket vacío")
+    }
+    if result.RetryAfter != time.Second || result.Reset != 3*time.Second {
+        t.Fatalf("Take vacío = %+v, se esperaba RetryAfter 1s y Reset 3s", result)
+    }
+
+    // Cada clave tiene su propio bucket
+    if result := tomar(t, s, "ip:2", limit); !result.Allowed || result.Remaining != 2 {
+        t.Fatalf("Take de otra clave = %+v, se esperaba un bucket lleno", result)
+    }
+}
+
+func TestMemoryStoreReposicion(t *testing.T) {
+    reloj := nuevoReloj()
+    s := nuevoMemoryStore(reloj)
+    limit := Limit{Requests: 3, Period: 3 * time.Second}
+
+    for i := 0; i < 3; i++ {
+        tomar(t, s, "ip:1", limit)
+    }
+
+    // Medio segundo repone medio token: todavía no alcanza
+    reloj.Avanzar(500 * time.Millisecond)
+    result := tomar(t, s, "ip:1", limit)
+    if result.Allowed || result.RetryAfter != 500*time.Millisecond {
+        t.Fatalf("Take tras 500ms = %+v, se esperaba rechazo con RetryAfter 500ms", result)
+    }
+
+    reloj.Avanzar(500 * time.Millisecond)
+    if result := tomar(t, s, "ip:1", limit); !result.Allowed || result.Remaining != 0 {
+        t.Fatalf("Take tras 1s = %+v, se esperaba admitida sin restantes", result)
+    }
+    if result := tomar(t, s, "ip:1", limit); result.Allowed {
+        t.Fatal("Take admitió una segunda petición con un solo token repuesto")
+    }
+
+    // La reposición no supera la capacidad aunque pase mucho tiempo
+    reloj.Avanzar(time.Hour)
+    for i := 0; i < 3; i++ {
+        if result := tomar(t, s, "ip:1", limit); !result.Allowed {
+            t.Fatalf("Take %d tras una hora fue rechazada", i+1)
+        }
+    }
+    if result := tomar(t, s, "ip:1", limit); result.Allowed {
+        t.Fatal("el bucket acumuló más tokens que su capacidad")
+    }
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+    reloj := nuevoReloj()
+    s := nuevoMemoryStore(reloj)
+    limit := Limit{Requests: 2, Period: 10 * time.Second}
+
+    tomar(t, s, "ip:1", limit)
+    reloj.Avanzar(30 * time.Second)
+    tomar(t, s, "ip:2", limit)
+    if len(s.buckets) != 2 {
+        t.Fatalf("hay %d buckets, se esperaban 2", len(s.buckets))
+    }
+
+    // Pasado el minuto se descartan los buckets ya rellenados
+    reloj.Avanzar(31 * time.Second)
+    tomar(t, s, "ip:3", limit)
+    if _, ok := s.buckets["ip:1"]; ok {
+        t.Fatal("sweep no descartó un bucket lleno")
+    }
+    if _, ok := s.buckets["ip:2"]; ok {
+        t.Fatal("sweep no descartó un bucket lleno")
+    }
+    if _, ok := s.buckets["ip:3"]; !ok {
+        t.Fatal("sweep descartó el bucket en uso")
+    }
+}
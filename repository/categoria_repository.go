@@ -0,0 +1,271 @@
+package repository
+
+import (
+    "cursos-api/models"
+    "database/sql"
+    "errors"
+)
+
+type CategoriaRepository struct {
+    db Querier
+}
+
+func NewCategoriaRepository(db Querier) *CategoriaRepository {
+    return &CategoriaRepository{db: db}
+}
+
+const selectCategorias = `
+        SELECT id, nombre, slug, COALESCE(descripcion, ''), padre_id, created_at
+        FROM categorias
+`
+
+// scanCategoria lee una fila de selectCategorias
+func scanCategoria(row interface{ Scan(...interface{}) error }) (*models.Categoria, error) {
+    categoria := &models.Categoria{}
+    var padreID sql.NullInt64
+    err := row.Scan(
+        &categoria.ID,
+        &categoria.Nombre,
+        &categoria.Slug,
+        &categoria.Descripcion,
+        &padreID,
+        &categoria.CreatedAt,
+    )
+    if err != nil {
+        return nil, err
+    }
+
+    if padreID.Valid {
+        id := int(padreID.Int64)
+        categoria.PadreID = &id
+    }
+    return categoria, nil
+}
+
+// GetAll obtiene todas las categorías, sin anidar, ordenadas por nombre
+func (r *CategoriaRepository) GetAll() ([]models.Categoria, error) {
+    rows, err := r.db.Query(selectCategorias + ` ORDER BY nombre, id`)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    categorias := []models.Categoria{}
+    for rows.Next() {
+        categoria, err := scanCategoria(rows)
+        if err != nil {
+            return nil, err
+        }
+        categorias = append(categorias, *categoria)
+    }
+
+    return categorias, rows.Err()
+}
+
+// FindByID busca una categoría por ID
+func (r *CategoriaRepository) FindByID(id int) (*models.Categoria, error) {
+    categoria, err := scanCategoria(r.db.QueryRow(selectCategorias+` WHERE id = $1`, id))
+    if err == sql.ErrNoRows {
+        return nil, errors.New("categoría no encontrada")
+    }
+    return categoria, err
+}
+
+// FindBySlug busca una categoría por su slug
+func (r *CategoriaRepository) FindBySlug(slug string) (*models.Categoria, error) {
+    categoria, err := scanCategoria(r.db.QueryRow(selectCategorias+` WHERE slug = $1`, slug))
+    if err == sql.ErrNoRows {
+        return nil, errors.New("categoría no encontrada")
+    }
+    return categoria, err
+}
+
+// Create crea una categoría
+func (r *CategoriaRepository) Create(categoria *models.Categoria) error {
+    query := `
+        INSERT INTO categorias (nombre, slug, descripcion, padre_id)
+        VALUES ($1, $2, NULLIF($3, ''), $4)
+        RETURNING id, created_at
+    `
+
+    return r.db.QueryRow(
+        query,
+        categoria.Nombre,
+        categoria.Slug,
+        categoria.Descripcion,
+        categoria.PadreID,
+    ).Scan(&categoria.ID, &categoria.CreatedAt)
+}
+
+// Update actualiza una categoría. Con las categorías bloqueadas comprueba que
+// el nuevo padre no sea ella misma ni una de sus subcategorías, así dos
+// cambios simultáneos no pueden cerrar un ciclo entre los dos.
+func (r *CategoriaRepository) Update(id int, categoria *models.Categoria) error {
+    tx, err := begin(r.db)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    if categoria.PadreID != nil {
+        if _, err := tx.Exec(`SELECT id FROM categorias ORDER BY id FOR UPDATE`); err != nil {
+            return err
+        }
+
+        var ciclo bool
+        err = tx.QueryRow(`
+            WITH RECURSIVE ancestros AS (
+                SELECT id, padre_id FROM categorias WHERE id = $1
+                UNION
+                SELECT cat.id, cat.padre_id FROM categorias cat INNER JOIN ancestros a ON cat.id = a.padre_id
+            )
+            SELECT EXISTS (SELECT 1 FROM ancestros WHERE id = $2)
+        `, *categoria.PadreID, id).Scan(&ciclo)
+        if err != nil {
+            return err
+        }
+
+        if ciclo {
+            return errors.New("una categoría no puede colgar de sí misma ni de sus subcategorías")
+        }
+    }
+
+    query := `
+        UPDATE categorias
+        SET nombre = $1, slug = $2, descripcion = NULLIF($3, ''), padre_id = $4
+        WHERE id = $5
+        RETURNING created_at
+    `
+
+    err = tx.QueryRow(
+        query,
+        categoria.Nombre,
+        categoria.Slug,
+        categoria.Descripcion,
+        categoria.PadreID,
+        id,
+    ).Scan(&categoria.CreatedAt)
+
+    if err == sql.ErrNoRows {
+        return errors.New("categoría no encontrada")
+    }
+    if err != nil {
+        return err
+    }
+
+    categoria.ID = id
+    return tx.Commit()
+}
+
+// Delete elimina una categoría y la quita de los cursos que la tenían
+func (r *CategoriaRepository) Delete(id int) error {
+    result, err := r.db.Exec(`DELETE FROM categorias WHERE id = $1`, id)
+    if err != nil {
+        return err
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+
+    if rowsAffected == 0 {
+        return errors.New("categoría no encontrada")
+    }
+
+    return nil
+}
+
+// AsignarCategorias reemplaza las categorías de un curso
+func (r *CategoriaRepository) AsignarCategorias(cursoID int, categoriaIDs []int) error {
+    tx, err := begin(r.db)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    if _, err := tx.Exec(`DELETE FROM curso_categorias WHERE curso_id = $1`, cursoID); err != nil {
+        return err
+    }
+
+    for _, categoriaID := range categoriaIDs {
+        _, err := tx.Exec(
+            `INSERT INTO curso_categorias (curso_id, categoria_id) VALUES ($1, $2)`,
+            cursoID, categoriaID,
+        )
+        if err != nil {
+            return err
+        }
+    }
+
+    return tx.Commit()
+}
+
+// AsignarEtiquetas reemplaza las etiquetas de un curso, creando las que aún
+// no existen. Los nombres deben llegar ya normalizados.
+func (r *CategoriaRepository) AsignarEtiquetas(cursoID int, nombres []string) error {
+    tx, err := begin(r.db)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    if _, err := tx.Exec(`DELETE FROM curso_etiquetas WHERE curso_id = $1`, cursoID); err != nil {
+        return err
+    }
+
+    for _, nombre := range nombres {
+        // El DO UPDATE no cambia nada pero hace que RETURNING devuelva el id
+        // también cuando la etiqueta ya existía
+        var etiquetaID int
+        err := tx.QueryRow(`
+            INSERT INTO etiquetas (nombre) VALUES ($1)
+            ON CONFLICT (nombre) DO UPDATE SET nombre = EXCLUDED.nombre
+            RETURNING id
+        `, nombre).Scan(&etiquetaID)
+        if err != nil {
+            return err
+        }
+
+        _, err = tx.Exec(
+            `INSERT INTO curso_etiquetas (curso_id, etiqueta_id) VALUES ($1, $2)`,
+            cursoID, etiquetaID,
+        )
+        if err != nil {
+            return err
+        }
+    }
+
+    return tx.Commit()
+}
+
+// GetEtiquetas obtiene las etiquetas usadas por cursos del catálogo (activos
+// y no bloqueados), de la más usada a la menos
+func (r *CategoriaRepository) GetEtiquetas() ([]models.Etiqueta, error) {
+    query := `
+        SELECT e.nombre, COUNT(*)
+        FROM etiquetas e
+        INNER JOIN curso_etiquetas ce ON ce.etiqueta_id = e.id
+        INNER JOIN cursos c ON c.id = ce.curso_id
+        WHERE c.activo = true AND c.bloqueado = false AND c.deleted_at IS NULL
+        GROUP BY e.nombre
+        ORDER BY COUNT(*) DESC, e.nombre
+    `
+
+    rows, err := r.db.Query(query)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    etiquetas := []models.Etiqueta{}
+    for rows.Next() {
+        var etiqueta models.Etiqueta
+        if err := rows.Scan(&etiqueta.Nombre, &etiqueta.Cursos); err != nil {
+            return nil, err
+        }
+        etiquetas = append(etiquetas, etiqueta)
+    }
+
+    return etiquetas, rows.Err()
+}
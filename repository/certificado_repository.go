@@ -0,0 +1,229 @@
+package repository
+
+import (
+    "cursos-api/models"
+    "database/sql"
+    "errors"
+    "time"
+)
+
+type CertificadoRepository struct {
+    db Querier
+}
+
+func NewCertificadoRepository(db Querier) *CertificadoRepository {
+    return &CertificadoRepository{db: db}
+}
+
+// Create emite un certificado. Si la inscripción ya tiene uno (por ejemplo,
+// emitido en paralelo) se devuelve el existente.
+func (r *CertificadoRepository) Create(certificado *models.Certificado) error {
+    query := `
+        INSERT INTO certificados (inscripcion_id, codigo_certificado, fecha_emision, url_pdf)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (inscripcion_id) DO NOTHING
+        RETURNING id, fecha_emision
+    `
+
+    err := r.db.QueryRow(
+        query,
+        certificado.InscripcionID,
+        certificado.CodigoCertificado,
+        time.Now(),
+        certificado.URLPDF,
+    ).Scan(&certificado.ID, &certificado.FechaEmision)
+
+    if err == sql.ErrNoRows {
+        existing, err := r.FindByInscripcion(certificado.InscripcionID)
+        if err != nil {
+            return err
+        }
+        *certificado = *existing
+        return nil
+    }
+
+    return err
+}
+
+// FindByInscripcion busca el certificado de una inscripción
+func (r *CertificadoRepository) FindByInscripcion(inscripcionID int) (*models.Certificado, error) {
+    query := `
+        SELECT id, inscripcion_id, codigo_certificado, fecha_emision, COALESCE(url_pdf, '')
+        FROM certificados
+        WHERE inscripcion_id = $1
+    `
+
+    certificado := &models.Certificado{}
+    err := r.db.QueryRow(query, inscripcionID).Scan(
+        &certificado.ID,
+        &certificado.InscripcionID,
+        &certificado.CodigoCertificado,
+        &certificado.FechaEmision,
+        &certificado.URLPDF,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, errors.New("certificado no encontrado")
+    }
+
+    return certificado, err
+}
+
+// FindDetalleByID busca un certificado con su alumno, curso e instructor. Un
+// certificado emitido sigue siendo válido aunque su curso se elimine.
+func (r *CertificadoRepository) FindDetalleByID(id int) (*models.Certificado, error) {
+    query := `
+        SELECT ce.id, ce.inscripcion_id, ce.codigo_certificado, ce.fecha_emision, COALESCE(ce.url_pdf, ''),
+               i.id, i.usuario_id, i.curso_id, i.fecha_inscripcion, i.estado, i.progreso_porcentaje,
+               u.id, u.nombre, u.email,
+               c.id, c.nombre, c.duracion_horas, c.instructor_id,
+               ins.id, ins.nombre
+        FROM certificados ce
+        INNER JOIN inscripciones i ON ce.inscripcion_id = i.id
+        INNER JOIN usuarios u ON i.usuario_id = u.id
+        INNER JOIN cursos c ON i.curso_id = c.id
+        INNER JOIN usuarios ins ON c.instructor_id = ins.id
+        WHERE ce.id = $1 AND u.deleted_at IS NULL
+    `
+
+    certificado := &models.Certificado{
+        Inscripcion: &models.Inscripcion{
+            Usuario: &models.Usuario{},
+            Curso:   &models.Curso{Instructor: &models.Usuario{}},
+        },
+    }
+    inscripcion := certificado.Inscripcion
+
+    err := r.db.QueryRow(query, id).Scan(
+        &certificado.ID,
+        &certificado.InscripcionID,
+        &certificado.CodigoCertificado,
+        &certificado.FechaEmision,
+        &certificado.URLPDF,
+        &inscripcion.ID,
+        &inscripcion.UsuarioID,
+        &inscripcion.CursoID,
+        &inscripcion.FechaInscripcion,
+        &inscripcion.Estado,
+        &inscripcion.ProgresoPorcentaje,
+        &inscripcion.Usuario.ID,
+        &inscripcion.Usuario.Nombre,
+        &inscripcion.Usuario.Email,
+        &inscripcion.Curso.ID,
+        &inscripcion.Curso.Nombre,
+        &inscripcion.Curso.DuracionHoras,
+        &inscripcion.Curso.InstructorID,
+        &inscripcion.Curso.Instructor.ID,
+        &inscripcion.Curso.Instructor.Nombre,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, errors.New("certificado no encontrado")
+    }
+
+    return certificado, err
+}
+
+// UpdateURLPDF guarda la URL del PDF de un certificado
+func (r *CertificadoRepository) UpdateURLPDF(id int, url string) error {
+    query := `UPDATE certificados SET url_pdf = $1 WHERE id = $2`
+
+    result, err := r.db.Exec(query, url, id)
+    if err != nil {
+        return err
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+
+    if rowsAffected == 0 {
+        return errors.New("certificado no encontrado")
+    }
+
+    return nil
+}
+
+// FindVerificacionByCodigo obtiene los datos públicos de un certificado por su código
+func (r *CertificadoRepository) FindVerificacionByCodigo(codigo string) (*models.CertificadoVerificacion, error) {
+    query := `
+        SELECT ce.codigo_certificado, u.nombre, c.nombre, c.duracion_horas, ins.nombre, ce.fecha_emision
+        FROM certificados ce
+        INNER JOIN inscripciones i ON ce.inscripcion_id = i.id
+        INNER JOIN usuarios u ON i.usuario_id = u.id
+        INNER JOIN cursos c ON i.curso_id = c.id
+        INNER JOIN usuarios ins ON c.instructor_id = ins.id
+        WHERE ce.codigo_certificado = $1 AND u.deleted_at IS NULL
+    `
+
+    verificacion := &models.CertificadoVerificacion{}
+    err := r.db.QueryRow(query, codigo).Scan(
+        &verificacion.CodigoCertificado,
+        &verificacion.Titular,
+        &verificacion.Curso,
+        &verificacion.DuracionHoras,
+        &verificacion.Instructor,
+        &verificacion.FechaEmision,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, errors.New("certificado no encontrado")
+    }
+
+    return verificacion, err
+}
+
+// GetByUsuario obtiene los certificados de un usuario junto con su curso
+func (r *CertificadoRepository) GetByUsuario(usuarioID int) ([]models.Certificado, error) {
+    query := `
+        SELECT ce.id, ce.inscripcion_id, ce.codigo_certificado, ce.fecha_emision, COALESCE(ce.url_pdf, ''),
+               i.id, i.usuario_id, i.curso_id, i.fecha_inscripcion, i.estado, i.progreso_porcentaje,
+               c.id, c.nombre, c.descripcion, c.duracion_horas, c.instructor_id, c.activo, c.created_at, c.updated_at
+        FROM certificados ce
+        INNER JOIN inscripciones i ON ce.inscripcion_id = i.id
+        INNER JOIN cursos c ON i.curso_id = c.id
+        WHERE i.usuario_id = $1
+        ORDER BY ce.fecha_emision DESC
+    `
+
+    rows, err := r.db.Query(query, usuarioID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var certificados []models.Certificado
+    for rows.Next() {
+        var certificado models.Certificado
+        certificado.Inscripcion = &models.Inscripcion{Curso: &models.Curso{}}
+
+        err := rows.Scan(
+            &certificado.ID,
+            &certificado.InscripcionID,
+            &certificado.CodigoCertificado,
+            &certificado.FechaEmision,
+            &certificado.URLPDF,
+            &certificado.Inscripcion.ID,
+            &certificado.Inscripcion.UsuarioID,
+            &certificado.Inscripcion.CursoID,
+            &certificado.Inscripcion.FechaInscripcion,
+            &certificado.Inscripcion.Estado,
+            &certificado.Inscripcion.ProgresoPorcentaje,
+            &certificado.Inscripcion.Curso.ID,
+            &certificado.Inscripcion.Curso.Nombre,
+            &certificado.Inscripcion.Curso.Descripcion,
+            &certificado.Inscripcion.Curso.DuracionHoras,
+            &certificado.Inscripcion.Curso.InstructorID,
+            &certificado.Inscripcion.Curso.Activo,
+            &certificado.Inscripcion.Curso.CreatedAt,
+            &certificado.Inscripcion.Curso.UpdatedAt,
+        )
+        if err != nil {
+            return nil, err
+        }
+        certificados = append(certificados, certificado)
+    }
+
+    return certificados, nil
+}
@@ -0,0 +1,80 @@
+package repository_test
+
+import (
+    "cursos-api/database"
+    "cursos-api/repository"
+    "cursos-api/repository/repotest"
+    "database/sql"
+    "fmt"
+    "os"
+    "testing"
+
+    _ "github.com/lib/pq"
+)
+
+// abrirDB conecta con la base de datos de pruebas configurada con las mismas
+// variables DB_* que la API y le aplica las migraciones. Sin DB_HOST la
+// prueba se omite.
+func abrirDB(t *testing.T) *sql.DB {
+    t.Helper()
+
+    host := os.Getenv("DB_HOST")
+    if host == "" {
+        t.Skip("DB_HOST no configurado: se omite el contrato de Postgres")
+    }
+
+    sslmode := os.Getenv("DB_SSLMODE")
+    if sslmode == "" {
+        sslmode = "disable"
+    }
+
+    connStr := fmt.Sprintf(
+        "host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+        host, os.Getenv("DB_PORT"), os.Getenv("DB_USER"), os.Getenv("DB_PASSWORD"), os.Getenv("DB_NAME"), sslmode,
+    )
+
+    db, err := sql.Open("postgres", connStr)
+    if err != nil {
+        t.Fatalf("sql.Open: %v", err)
+    }
+    t.Cleanup(func() { db.Close() })
+
+    if err := db.Ping(); err != nil {
+        t.Fatalf("Ping: %v", err)
+    }
+
+    migrator, err := database.NewMigrator(db)
+    if err != nil {
+        t.Fatalf("NewMigrator: %v", err)
+    }
+    if _, err := migrator.Up(); err != nil {
+        t.Fatalf("Up: %v", err)
+    }
+
+    return db
+}
+
+// factoryTx abre una transacción por caso y la revierte al terminar, de modo
+// que los casos no se ven entre sí ni dejan datos en la base
+func factoryTx(db *sql.DB) repotest.Factory {
+    return func(t *testing.T) repotest.Repos {
+        tx, err := db.Begin()
+        if err != nil {
+            t.Fatalf("Begin: %v", err)
+        }
+        t.Cleanup(func() { tx.Rollback() })
+
+        return repotest.Repos{
+            Usuarios: repository.NewUsuarioRepository(tx),
+            Cursos:   repository.NewCursoRepository(tx),
+        }
+    }
+}
+
+func TestUsuarioRepositoryContract(t *testing.T) {
+    repotest.RunUsuarioRepositoryContract(t, factoryTx(abrirDB(t)))
+}
+
+func TestCursoRepositoryContract(t *testing.T) {
+    repotest.RunCursoRepositoryContract(t, factoryTx(abrirDB(t)))
+}
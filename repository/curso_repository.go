@@ -1,67 +1,81 @@
 package repository
 
 import (
-    "cursos-api/config"
     "cursos-api/models"
     "database/sql"
+    "encoding/json"
     "errors"
+    "fmt"
+    "html"
+    "strconv"
+    "strings"
     "time"
 )
 
-type CursoRepository struct{}
+type CursoRepository struct {
+    db Querier
+}
 
-func NewCursoRepository() *CursoRepository {
-    return &CursoRepository{}
+func NewCursoRepository(db Querier) *CursoRepository {
+    return &CursoRepository{db: db}
 }
 
-// Create crea un nuevo curso
+// Create crea un nuevo curso. Sin estado explícito queda publicado o
+// archivado según activo.
 func (r *CursoRepository) Create(curso *models.Curso) error {
     query := `
-        INSERT INTO cursos (nombre, descripcion, duracion_horas, instructor_id, activo, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5, $6, $7)
-        RETURNING id, created_at, updated_at
+        INSERT INTO cursos (nombre, descripcion, duracion_horas, instructor_id, activo, estado, publicado_at, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5,
+                COALESCE(NULLIF($6, ''), CASE WHEN $5 THEN 'publicado' ELSE 'archivado' END),
+                CASE WHEN $5 THEN $7::timestamp END, $7, $7)
+        RETURNING id, estado, publicado_at, created_at, updated_at
     `
     
     now := time.Now()
-    err := config.DB.QueryRow(
+    err := r.db.QueryRow(
         query,
         curso.Nombre,
         curso.Descripcion,
         curso.DuracionHoras,
         curso.InstructorID,
         curso.Activo,
+        curso.Estado,
         now,
-        now,
-    ).Scan(&curso.ID, &curso.CreatedAt, &curso.UpdatedAt)
+    ).Scan(&curso.ID, &curso.Estado, &curso.PublicadoAt, &curso.CreatedAt, &curso.UpdatedAt)
 
     return err
 }
 
-// FindByID busca un curso por ID
-func (r *CursoRepository) FindByID(id int) (*models.Curso, error) {
-    query := `
-        SELECT c.id, c.nombre, c.descripcion, c.duracion_horas, c.instructor_id, c.activo, c.created_at, c.updated_at,
-               u.id, u.nombre, u.email, u.rol
+// columnasTaxonomia son las categorías y etiquetas del curso c como JSON
+const columnasTaxonomia = `
+               (SELECT COALESCE(json_agg(json_build_object('id', cat.id, 'nombre', cat.nombre, 'slug', cat.slug) ORDER BY cat.nombre), '[]')
+                FROM curso_categorias cc INNER JOIN categorias cat ON cat.id = cc.categoria_id
+                WHERE cc.curso_id = c.id),
+               (SELECT COALESCE(json_agg(e.nombre ORDER BY e.nombre), '[]')
+                FROM curso_etiquetas ce INNER JOIN etiquetas e ON e.id = ce.etiqueta_id
+                WHERE ce.curso_id = c.id)`
+
+// columnasCurso son las columnas del curso c y de su instructor u que lee
+// camposCurso
+const columnasCurso = `
+               c.id, c.nombre, c.descripcion, c.duracion_horas, c.instructor_id, c.activo,
+               c.estado, c.publicado_at, c.publicar_at, c.despublicar_at,
+               c.bloqueado, COALESCE(c.motivo_bloqueo, ''), c.bloqueado_at, c.created_at, c.updated_at, c.deleted_at,
+               u.id, u.nombre, u.email, u.rol,` + columnasTaxonomia
+
+// selectCursos es la consulta base de los cursos con los datos públicos de su
+// instructor y su taxonomía. Quien la usa debe excluir los cursos eliminados.
+const selectCursos = `
+        SELECT` + columnasCurso + `
         FROM cursos c
         INNER JOIN usuarios u ON c.instructor_id = u.id
-        WHERE c.id = $1
-    `
-    
-    curso := &models.Curso{Instructor: &models.Usuario{}}
-    err := config.DB.QueryRow(query, id).Scan(
-        &curso.ID,
-        &curso.Nombre,
-        &curso.Descripcion,
-        &curso.DuracionHoras,
-        &curso.InstructorID,
-        &curso.Activo,
-        &curso.CreatedAt,
-        &curso.UpdatedAt,
-        &curso.Instructor.ID,
-        &curso.Instructor.Nombre,
-        &curso.Instructor.Email,
-        &curso.Instructor.Rol,
-    )
+`
+
+// FindByID busca un curso por ID
+func (r *CursoRepository) FindByID(id int) (*models.Curso, error) {
+    query := selectCursos + `WHERE c.id = $1 AND c.deleted_at IS NULL`
+
+    curso, err := scanCurso(r.db.QueryRow(query, id))
 
     if err == sql.ErrNoRows {
         return nil, errors.New("curso no encontrado")
@@ -70,107 +84,327 @@ func (r *CursoRepository) FindByID(id int) (*models.Curso, error) {
     return curso, err
 }
 
+// FindEliminado busca un curso eliminado pendiente de purga
+func (r *CursoRepository) FindEliminado(id int) (*models.Curso, error) {
+    query := selectCursos + `WHERE c.id = $1 AND c.deleted_at IS NOT NULL`
+
+    curso, err := scanCurso(r.db.QueryRow(query, id))
+
+    if err == sql.ErrNoRows {
+        return nil, errors.New("curso eliminado no encontrado")
+    }
+
+    return curso, err
+}
+
 // GetAll obtiene todos los cursos
 func (r *CursoRepository) GetAll() ([]models.Curso, error) {
-    query := `
-        SELECT c.id, c.nombre, c.descripcion, c.duracion_horas, c.instructor_id, c.activo, c.created_at, c.updated_at,
-               u.id, u.nombre, u.email, u.rol
-        FROM cursos c
-        INNER JOIN usuarios u ON c.instructor_id = u.id
+    return r.queryCursos(selectCursos + `
+        WHERE c.deleted_at IS NULL
         ORDER BY c.created_at DESC
-    `
-    
-    rows, err := config.DB.Query(query)
+    `)
+}
+
+// GetByInstructor obtiene todos los cursos de un instructor
+func (r *CursoRepository) GetByInstructor(instructorID int) ([]models.Curso, error) {
+    return r.queryCursos(selectCursos+`
+        WHERE c.instructor_id = $1 AND c.deleted_at IS NULL
+        ORDER BY c.created_at DESC
+    `, instructorID)
+}
+
+// GetActivos obtiene todos los cursos activos y no bloqueados por moderación
+func (r *CursoRepository) GetActivos() ([]models.Curso, error) {
+    return r.queryCursos(selectCursos + `
+        WHERE c.activo = true AND c.bloqueado = false AND c.deleted_at IS NULL
+        ORDER BY c.created_at DESC
+    `)
+}
+
+// columnaOrden es un campo por el que se puede ordenar el listado de cursos
+type columnaOrden struct {
+    columna string
+    // valor devuelve el valor del campo de un curso para el cursor
+    valor func(curso *models.Curso) string
+    // parametro convierte el valor del cursor al tipo de la columna
+    parametro func(valor string) (interface{}, error)
+}
+
+// formatoCursorFecha guarda las fechas del cursor con la precisión de Postgres
+const formatoCursorFecha = "2006-01-02T15:04:05.999999"
+
+func parametroFecha(valor string) (interface{}, error) {
+    return time.Parse(formatoCursorFecha, valor)
+}
+
+// columnasOrdenCurso son los campos de ordenación admitidos. Solo estos
+// nombres de columna llegan a interpolarse en el SQL.
+var columnasOrdenCurso = map[string]columnaOrden{
+    "created_at": {
+        columna:   "c.created_at",
+        valor:     func(c *models.Curso) string { return c.CreatedAt.Format(formatoCursorFecha) },
+        parametro: parametroFecha,
+    },
+    "updated_at": {
+        columna:   "c.updated_at",
+        valor:     func(c *models.Curso) string { return c.UpdatedAt.Format(formatoCursorFecha) },
+        parametro: parametroFecha,
+    },
+    "nombre": {
+        columna:   "c.nombre",
+        valor:     func(c *models.Curso) string { return c.Nombre },
+        parametro: func(v string) (interface{}, error) { return v, nil },
+    },
+    "duracion_horas": {
+        columna:   "c.duracion_horas",
+        valor:     func(c *models.Curso) string { return strconv.Itoa(c.DuracionHoras) },
+        parametro: func(v string) (interface{}, error) { return strconv.Atoi(v) },
+    },
+}
+
+// List obtiene una página de cursos filtrada y ordenada. La paginación es por
+// clave (campo de ordenación, id), así que las páginas no se desplazan aunque
+// se creen cursos entre una petición y la siguiente.
+func (r *CursoRepository) List(filtro models.CursoFiltro) (*models.PaginaCursos, error) {
+    orden, ok := columnasOrdenCurso[filtro.Orden]
+    if !ok {
+        return nil, errors.New("campo de ordenación no válido")
+    }
+
+    var condiciones []string
+    var args []interface{}
+    param := func(valor interface{}) string {
+        args = append(args, valor)
+        return "$" + strconv.Itoa(len(args))
+    }
+
+    if filtro.Eliminados {
+        condiciones = append(condiciones, "c.deleted_at IS NOT NULL")
+    } else {
+        condiciones = append(condiciones, "c.deleted_at IS NULL")
+    }
+    if filtro.Activo != nil {
+        condiciones = append(condiciones, "c.activo = "+param(*filtro.Activo))
+    }
+    if filtro.SoloCatalogo {
+        condiciones = append(condiciones, "c.activo = true AND c.bloqueado = false")
+    }
+    if filtro.Estado != "" {
+        condiciones = append(condiciones, "c.estado = "+param(filtro.Estado))
+    }
+    if filtro.InstructorID > 0 {
+        condiciones = append(condiciones, "c.instructor_id = "+param(filtro.InstructorID))
+    }
+    if filtro.DuracionMin > 0 {
+        condiciones = append(condiciones, "c.duracion_horas >= "+param(filtro.DuracionMin))
+    }
+    if filtro.DuracionMax > 0 {
+        condiciones = append(condiciones, "c.duracion_horas <= "+param(filtro.DuracionMax))
+    }
+    if filtro.CreadoDesde != nil {
+        condiciones = append(condiciones, "c.created_at >= "+param(*filtro.CreadoDesde))
+    }
+    if filtro.CreadoHasta != nil {
+        condiciones = append(condiciones, "c.created_at < "+param(*filtro.CreadoHasta))
+    }
+    if filtro.CategoriaID > 0 {
+        condiciones = append(condiciones, `EXISTS (
+            WITH RECURSIVE subarbol AS (
+                SELECT id FROM categorias WHERE id = `+param(filtro.CategoriaID)+`
+                UNION
+                SELECT cat.id FROM categorias cat INNER JOIN subarbol s ON cat.padre_id = s.id
+            )
+            SELECT 1 FROM curso_categorias cc
+            WHERE cc.curso_id = c.id AND cc.categoria_id IN (SELECT id FROM subarbol)
+        )`)
+    }
+    for _, etiqueta := range filtro.Etiquetas {
+        condiciones = append(condiciones, `EXISTS (
+            SELECT 1 FROM curso_etiquetas ce INNER JOIN etiquetas e ON e.id = ce.etiqueta_id
+            WHERE ce.curso_id = c.id AND e.nombre = `+param(etiqueta)+`
+        )`)
+    }
+
+    pagina := &models.PaginaCursos{Items: []models.Curso{}, Limite: filtro.Limite}
+
+    // El total no depende del cursor
+    err := r.db.QueryRow(`SELECT COUNT(*) FROM cursos c `+clausulaWhere(condiciones), args...).Scan(&pagina.Total)
     if err != nil {
         return nil, err
     }
-    defer rows.Close()
 
-    var cursos []models.Curso
-    for rows.Next() {
-        var curso models.Curso
-        curso.Instructor = &models.Usuario{}
-        
-        err := rows.Scan(
-            &curso.ID,
-            &curso.Nombre,
-            &curso.Descripcion,
-            &curso.DuracionHoras,
-            &curso.InstructorID,
-            &curso.Activo,
-            &curso.CreatedAt,
-            &curso.UpdatedAt,
-            &curso.Instructor.ID,
-            &curso.Instructor.Nombre,
-            &curso.Instructor.Email,
-            &curso.Instructor.Rol,
-        )
+    comparador, direccion := ">", "ASC"
+    if filtro.Desc {
+        comparador, direccion = "<", "DESC"
+    }
+
+    if filtro.Cursor != "" {
+        cursor, err := DecodeCursor(filtro.Cursor, filtro.Orden, filtro.Desc)
         if err != nil {
             return nil, err
         }
-        cursos = append(cursos, curso)
+        valor, err := orden.parametro(cursor.Valor)
+        if err != nil {
+            return nil, errors.New("cursor inválido")
+        }
+        condiciones = append(condiciones, fmt.Sprintf("(%s, c.id) %s (%s, %s)",
+            orden.columna, comparador, param(valor), param(cursor.ID)))
     }
 
-    return cursos, nil
+    // Se pide una fila de más para saber si hay página siguiente
+    query := selectCursos + clausulaWhere(condiciones) + fmt.Sprintf("\n        ORDER BY %s %s, c.id %s\n        LIMIT %s",
+        orden.columna, direccion, direccion, param(filtro.Limite+1))
+
+    cursos, err := r.queryCursos(query, args...)
+    if err != nil {
+        return nil, err
+    }
+
+    if len(cursos) > filtro.Limite {
+        cursos = cursos[:filtro.Limite]
+        ultimo := &cursos[len(cursos)-1]
+        pagina.NextCursor = EncodeCursor(Cursor{
+            Orden: filtro.Orden,
+            Desc:  filtro.Desc,
+            Valor: orden.valor(ultimo),
+            ID:    ultimo.ID,
+        })
+    }
+
+    if cursos != nil {
+        pagina.Items = cursos
+    }
+
+    return pagina, nil
 }
 
-// GetByInstructor obtiene todos los cursos de un instructor
-func (r *CursoRepository) GetByInstructor(instructorID int) ([]models.Curso, error) {
-    query := `
-        SELECT c.id, c.nombre, c.descripcion, c.duracion_horas, c.instructor_id, c.activo, c.created_at, c.updated_at,
-               u.id, u.nombre, u.email, u.rol
-        FROM cursos c
-        INNER JOIN usuarios u ON c.instructor_id = u.id
-        WHERE c.instructor_id = $1
-        ORDER BY c.created_at DESC
+// Marcas con las que ts_headline delimita las coincidencias. Son caracteres
+// de uso privado para poder escapar el texto antes de convertirlas en <mark>.
+const (
+    inicioResaltado = "\ue000"
+    finResaltado    = "\ue001"
+)
+
+// Search busca cursos por nombre, descripción y títulos de sus lecciones con
+// la configuración es_unaccent (español, sin acentos) y los ordena por
+// relevancia. El texto admite la sintaxis de websearch_to_tsquery: "frase
+// exacta", or y -excluir.
+func (r *CursoRepository) Search(busqueda models.CursoBusqueda) (*models.PaginaBusquedaCursos, error) {
+    args := []interface{}{busqueda.Texto}
+    param := func(valor interface{}) string {
+        args = append(args, valor)
+        return "$" + strconv.Itoa(len(args))
+    }
+
+    condiciones := []string{"(c.busqueda @@ q.query OR lec.curso_id IS NOT NULL)", "c.deleted_at IS NULL"}
+    if busqueda.SoloCatalogo {
+        condiciones = append(condiciones, "c.activo = true AND c.bloqueado = false")
+    }
+    if busqueda.InstructorID > 0 {
+        condiciones = append(condiciones, "c.instructor_id = "+param(busqueda.InstructorID))
+    }
+
+    opcionesTitulo := fmt.Sprintf("StartSel=%s, StopSel=%s, HighlightAll=true", inicioResaltado, finResaltado)
+    opcionesFragmento := fmt.Sprintf(`StartSel=%s, StopSel=%s, MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" … "`,
+        inicioResaltado, finResaltado)
+
+    // Las lecciones que coinciden suman relevancia al curso, a la mitad de peso
+    desde := `
+        WITH q AS (SELECT websearch_to_tsquery('es_unaccent', $1) AS query),
+        lec AS (
+            SELECT l.curso_id,
+                   MAX(ts_rank(l.busqueda, q.query)) AS rank,
+                   json_agg(ts_headline('es_unaccent', l.titulo, q.query, ` + param(opcionesTitulo) + `) ORDER BY l.orden) AS titulos
+            FROM lecciones l, q
+            WHERE l.busqueda @@ q.query
+            GROUP BY l.curso_id
+        )
     `
-    
-    rows, err := config.DB.Query(query, instructorID)
+    joins := `
+        CROSS JOIN q
+        LEFT JOIN lec ON lec.curso_id = c.id
+    `
+
+    pagina := &models.PaginaBusquedaCursos{
+        Items:  []models.ResultadoBusquedaCurso{},
+        Limite: busqueda.Limite,
+        Offset: busqueda.Offset,
+    }
+
+    query := desde + `SELECT COUNT(*) FROM cursos c` + joins + clausulaWhere(condiciones)
+    if err := r.db.QueryRow(query, args...).Scan(&pagina.Total); err != nil {
+        return nil, err
+    }
+    if pagina.Total == 0 {
+        return pagina, nil
+    }
+
+    query = desde + `
+        SELECT` + columnasCurso + `,
+               ts_rank(c.busqueda, q.query) + COALESCE(lec.rank, 0) / 2 AS relevancia,
+               ts_headline('es_unaccent', c.nombre, q.query, ` + param(opcionesTitulo) + `),
+               ts_headline('es_unaccent', COALESCE(c.descripcion, ''), q.query, ` + param(opcionesFragmento) + `),
+               COALESCE(lec.titulos, '[]')
+        FROM cursos c
+        INNER JOIN usuarios u ON c.instructor_id = u.id` + joins + clausulaWhere(condiciones) + `
+        ORDER BY relevancia DESC, c.id
+        LIMIT ` + param(busqueda.Limite) + ` OFFSET ` + param(busqueda.Offset)
+
+    rows, err := r.db.Query(query, args...)
     if err != nil {
         return nil, err
     }
     defer rows.Close()
 
-    var cursos []models.Curso
     for rows.Next() {
-        var curso models.Curso
-        curso.Instructor = &models.Usuario{}
-        
-        err := rows.Scan(
-            &curso.ID,
-            &curso.Nombre,
-            &curso.Descripcion,
-            &curso.DuracionHoras,
-            &curso.InstructorID,
-            &curso.Activo,
-            &curso.CreatedAt,
-            &curso.UpdatedAt,
-            &curso.Instructor.ID,
-            &curso.Instructor.Nombre,
-            &curso.Instructor.Email,
-            &curso.Instructor.Rol,
-        )
+        resultado := models.ResultadoBusquedaCurso{Curso: models.Curso{Instructor: &models.Usuario{}}}
+        var categorias, etiquetas, titulos []byte
+        err := rows.Scan(append(camposCurso(&resultado.Curso, &categorias, &etiquetas),
+            &resultado.Relevancia,
+            &resultado.NombreResaltado,
+            &resultado.Fragmento,
+            &titulos,
+        )...)
         if err != nil {
             return nil, err
         }
-        cursos = append(cursos, curso)
+
+        if err := taxonomia(&resultado.Curso, categorias, etiquetas); err != nil {
+            return nil, err
+        }
+        if err := json.Unmarshal(titulos, &resultado.LeccionesCoincidentes); err != nil {
+            return nil, err
+        }
+        for i, titulo := range resultado.LeccionesCoincidentes {
+            resultado.LeccionesCoincidentes[i] = resaltadoHTML(titulo)
+        }
+        resultado.NombreResaltado = resaltadoHTML(resultado.NombreResaltado)
+        resultado.Fragmento = resaltadoHTML(resultado.Fragmento)
+
+        pagina.Items = append(pagina.Items, resultado)
     }
 
-    return cursos, nil
+    return pagina, rows.Err()
 }
 
-// GetActivos obtiene todos los cursos activos
-func (r *CursoRepository) GetActivos() ([]models.Curso, error) {
-    query := `
-        SELECT c.id, c.nombre, c.descripcion, c.duracion_horas, c.instructor_id, c.activo, c.created_at, c.updated_at,
-               u.id, u.nombre, u.email, u.rol
-        FROM cursos c
-        INNER JOIN usuarios u ON c.instructor_id = u.id
-        WHERE c.activo = true
-        ORDER BY c.created_at DESC
-    `
-    
-    rows, err := config.DB.Query(query)
+// resaltadoHTML escapa el texto de ts_headline y convierte sus marcas en <mark>
+func resaltadoHTML(texto string) string {
+    texto = html.EscapeString(texto)
+    texto = strings.ReplaceAll(texto, inicioResaltado, "<mark>")
+    return strings.ReplaceAll(texto, finResaltado, "</mark>")
+}
+
+// clausulaWhere une las condiciones en una cláusula WHERE (vacía si no hay ninguna)
+func clausulaWhere(condiciones []string) string {
+    if len(condiciones) == 0 {
+        return ""
+    }
+    return "WHERE " + strings.Join(condiciones, " AND ")
+}
+
+// queryCursos ejecuta una consulta basada en selectCursos
+func (r *CursoRepository) queryCursos(query string, args ...interface{}) ([]models.Curso, error) {
+    rows, err := r.db.Query(query, args...)
     if err != nil {
         return nil, err
     }
@@ -178,30 +412,65 @@ func (r *CursoRepository) GetActivos() ([]models.Curso, error) {
 
     var cursos []models.Curso
     for rows.Next() {
-        var curso models.Curso
-        curso.Instructor = &models.Usuario{}
-        
-        err := rows.Scan(
-            &curso.ID,
-            &curso.Nombre,
-            &curso.Descripcion,
-            &curso.DuracionHoras,
-            &curso.InstructorID,
-            &curso.Activo,
-            &curso.CreatedAt,
-            &curso.UpdatedAt,
-            &curso.Instructor.ID,
-            &curso.Instructor.Nombre,
-            &curso.Instructor.Email,
-            &curso.Instructor.Rol,
-        )
+        curso, err := scanCurso(rows)
         if err != nil {
             return nil, err
         }
-        cursos = append(cursos, curso)
+        cursos = append(cursos, *curso)
     }
 
-    return cursos, nil
+    return cursos, rows.Err()
+}
+
+// scanCurso lee una fila de selectCursos
+func scanCurso(row interface{ Scan(...interface{}) error }) (*models.Curso, error) {
+    curso := &models.Curso{Instructor: &models.Usuario{}}
+    var categorias, etiquetas []byte
+    if err := row.Scan(camposCurso(curso, &categorias, &etiquetas)...); err != nil {
+        return nil, err
+    }
+
+    if err := taxonomia(curso, categorias, etiquetas); err != nil {
+        return nil, err
+    }
+    return curso, nil
+}
+
+// camposCurso devuelve los destinos del Scan de columnasCurso. La taxonomía
+// queda en crudo para decodificarla con taxonomia.
+func camposCurso(curso *models.Curso, categorias, etiquetas *[]byte) []interface{} {
+    return []interface{}{
+        &curso.ID,
+        &curso.Nombre,
+        &curso.Descripcion,
+        &curso.DuracionHoras,
+        &curso.InstructorID,
+        &curso.Activo,
+        &curso.Estado,
+        &curso.PublicadoAt,
+        &curso.PublicarAt,
+        &curso.DespublicarAt,
+        &curso.Bloqueado,
+        &curso.MotivoBloqueo,
+        &curso.BloqueadoAt,
+        &curso.CreatedAt,
+        &curso.UpdatedAt,
+        &curso.DeletedAt,
+        &curso.Instructor.ID,
+        &curso.Instructor.Nombre,
+        &curso.Instructor.Email,
+        &curso.Instructor.Rol,
+        categorias,
+        etiquetas,
+    }
+}
+
+// taxonomia decodifica las categorías y etiquetas leídas con columnasTaxonomia
+func taxonomia(curso *models.Curso, categorias, etiquetas []byte) error {
+    if err := json.Unmarshal(categorias, &curso.Categorias); err != nil {
+        return err
+    }
+    return json.Unmarshal(etiquetas, &curso.Etiquetas)
 }
 
 // Update actualiza un curso
@@ -209,12 +478,12 @@ func (r *CursoRepository) Update(id int, curso *models.Curso) error {
     query := `
         UPDATE cursos
         SET nombre = $1, descripcion = $2, duracion_horas = $3, instructor_id = $4, activo = $5, updated_at = $6
-        WHERE id = $7
+        WHERE id = $7 AND deleted_at IS NULL
         RETURNING updated_at
     `
     
     now := time.Now()
-    err := config.DB.QueryRow(
+    err := r.db.QueryRow(
         query,
         curso.Nombre,
         curso.Descripcion,
@@ -233,11 +502,12 @@ func (r *CursoRepository) Update(id int, curso *models.Curso) error {
     return err
 }
 
-// Delete elimina un curso
+// Delete elimina lógicamente un curso. Sus lecciones, inscripciones y demás
+// datos se conservan hasta la purga.
 func (r *CursoRepository) Delete(id int) error {
-    query := `DELETE FROM cursos WHERE id = $1`
+    query := `UPDATE cursos SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL`
     
-    result, err := config.DB.Exec(query, id)
+    result, err := r.db.Exec(query, time.Now(), id)
     if err != nil {
         return err
     }
@@ -254,12 +524,232 @@ func (r *CursoRepository) Delete(id int) error {
     return nil
 }
 
+// Restore restaura un curso eliminado
+func (r *CursoRepository) Restore(id int) error {
+    query := `UPDATE cursos SET deleted_at = NULL, updated_at = $1 WHERE id = $2 AND deleted_at IS NOT NULL`
+
+    result, err := r.db.Exec(query, time.Now(), id)
+    if err != nil {
+        return err
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+
+    if rowsAffected == 0 {
+        return errors.New("curso eliminado no encontrado")
+    }
+
+    return nil
+}
+
+// Purgar borra definitivamente los cursos eliminados antes de la fecha
+// indicada, con sus lecciones, inscripciones y demás datos en cascada. Los
+// cursos con certificados emitidos no se purgan: el borrado en cascada se
+// llevaría los certificados de los alumnos y sus códigos de verificación.
+func (r *CursoRepository) Purgar(antes time.Time) (int64, error) {
+    query := `
+        DELETE FROM cursos c
+        WHERE c.deleted_at < $1
+          AND NOT EXISTS (
+              SELECT 1 FROM inscripciones i
+              INNER JOIN certificados ce ON ce.inscripcion_id = i.id
+              WHERE i.curso_id = c.id
+          )
+    `
+
+    result, err := r.db.Exec(query, antes)
+    if err != nil {
+        return 0, err
+    }
+
+    return result.RowsAffected()
+}
+
 // VerifyInstructor verifica que un curso pertenece a un instructor
 func (r *CursoRepository) VerifyInstructor(cursoID, instructorID int) (bool, error) {
-    query := `SELECT EXISTS(SELECT 1 FROM cursos WHERE id = $1 AND instructor_id = $2)`
+    query := `SELECT EXISTS(SELECT 1 FROM cursos WHERE id = $1 AND instructor_id = $2 AND deleted_at IS NULL)`
     
     var exists bool
-    err := config.DB.QueryRow(query, cursoID, instructorID).Scan(&exists)
+    err := r.db.QueryRow(query, cursoID, instructorID).Scan(&exists)
     
     return exists, err
 }
+
+// UpdateInstructor reasigna el instructor de un curso
+func (r *CursoRepository) UpdateInstructor(id, instructorID int) error {
+    query := `
+        UPDATE cursos
+        SET instructor_id = $1, updated_at = $2
+        WHERE id = $3 AND deleted_at IS NULL
+    `
+
+    result, err := r.db.Exec(query, instructorID, time.Now(), id)
+    if err != nil {
+        return err
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+
+    if rowsAffected == 0 {
+        return errors.New("curso no encontrado")
+    }
+
+    return nil
+}
+
+// SetBloqueo bloquea o desbloquea un curso por moderación
+func (r *CursoRepository) SetBloqueo(id int, bloqueado bool, motivo string) error {
+    query := `
+        UPDATE cursos
+        SET bloqueado = $1,
+            motivo_bloqueo = NULLIF($2, ''),
+            bloqueado_at = CASE WHEN $1 THEN $3::timestamp ELSE NULL END,
+            updated_at = $3
+        WHERE id = $4 AND deleted_at IS NULL
+    `
+
+    result, err := r.db.Exec(query, bloqueado, motivo, time.Now(), id)
+    if err != nil {
+        return err
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+
+    if rowsAffected == 0 {
+        return errors.New("curso no encontrado")
+    }
+
+    return nil
+}
+
+// CambiarEstado pasa un curso de cambio.EstadoAnterior a cambio.EstadoNuevo y
+// lo anota en el historial. Falla si entretanto el curso cambió de estado. El
+// cambio cancela la publicación programada, y también la retirada programada
+// salvo cuando el curso se publica.
+func (r *CursoRepository) CambiarEstado(cambio *models.CambioEstadoCurso) error {
+    tx, err := begin(r.db)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    now := time.Now()
+    result, err := tx.Exec(`
+        UPDATE cursos
+        SET estado = $1,
+            activo = ($1 = 'publicado'),
+            publicado_at = CASE WHEN $1 = 'publicado' THEN $2::timestamp ELSE publicado_at END,
+            publicar_at = NULL,
+            despublicar_at = CASE WHEN $1 = 'publicado' THEN despublicar_at END,
+            updated_at = $2
+        WHERE id = $3 AND estado = $4 AND deleted_at IS NULL
+    `, cambio.EstadoNuevo, now, cambio.CursoID, cambio.EstadoAnterior)
+    if err != nil {
+        return err
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+
+    if rowsAffected == 0 {
+        return errors.New("curso no encontrado o su estado ha cambiado")
+    }
+
+    err = tx.QueryRow(`
+        INSERT INTO cursos_historial_estados (curso_id, estado_anterior, estado_nuevo, usuario_id, motivo, created_at)
+        VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)
+        RETURNING id, created_at
+    `, cambio.CursoID, cambio.EstadoAnterior, cambio.EstadoNuevo, cambio.UsuarioID, cambio.Motivo, now).Scan(&cambio.ID, &cambio.CreatedAt)
+    if err != nil {
+        return err
+    }
+
+    return tx.Commit()
+}
+
+// Programar fija (o con nil cancela) la publicación y la retirada programadas
+func (r *CursoRepository) Programar(id int, publicarAt, despublicarAt *time.Time) error {
+    result, err := r.db.Exec(
+        `UPDATE cursos SET publicar_at = $1, despublicar_at = $2 WHERE id = $3 AND deleted_at IS NULL`,
+        publicarAt, despublicarAt, id,
+    )
+    if err != nil {
+        return err
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+
+    if rowsAffected == 0 {
+        return errors.New("curso no encontrado")
+    }
+
+    return nil
+}
+
+// GetProgramados obtiene los cursos con una publicación o retirada programada
+// que ya ha vencido
+func (r *CursoRepository) GetProgramados(hasta time.Time) ([]models.Curso, error) {
+    return r.queryCursos(selectCursos+`
+        WHERE c.deleted_at IS NULL
+          AND ((c.publicar_at <= $1 AND c.estado IN ('en_revision', 'archivado'))
+            OR (c.despublicar_at <= $1 AND c.estado = 'publicado'))
+        ORDER BY c.id
+    `, hasta)
+}
+
+// GetHistorial obtiene los cambios de estado de un curso, del más antiguo al
+// más reciente
+func (r *CursoRepository) GetHistorial(cursoID int) ([]models.CambioEstadoCurso, error) {
+    query := `
+        SELECT id, curso_id, estado_anterior, estado_nuevo, usuario_id, COALESCE(motivo, ''), created_at
+        FROM cursos_historial_estados
+        WHERE curso_id = $1
+        ORDER BY created_at, id
+    `
+
+    rows, err := r.db.Query(query, cursoID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    historial := []models.CambioEstadoCurso{}
+    for rows.Next() {
+        var cambio models.CambioEstadoCurso
+        var usuarioID sql.NullInt64
+        err := rows.Scan(
+            &cambio.ID,
+            &cambio.CursoID,
+            &cambio.EstadoAnterior,
+            &cambio.EstadoNuevo,
+            &usuarioID,
+            &cambio.Motivo,
+            &cambio.CreatedAt,
+        )
+        if err != nil {
+            return nil, err
+        }
+
+        if usuarioID.Valid {
+            id := int(usuarioID.Int64)
+            cambio.UsuarioID = &id
+        }
+        historial = append(historial, cambio)
+    }
+
+    return historial, rows.Err()
+}
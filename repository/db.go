@@ -0,0 +1,47 @@
+package repository
+
+import "database/sql"
+
+// Querier es la interfaz común de *sql.DB y *sql.Tx: los repositorios pueden
+// construirse sobre la conexión o dentro de una transacción abierta
+type Querier interface {
+    Exec(query string, args ...interface{}) (sql.Result, error)
+    Query(query string, args ...interface{}) (*sql.Rows, error)
+    QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// txScope es la transacción usada por una operación de un repositorio
+type txScope struct {
+    Querier
+    tx *sql.Tx
+}
+
+// begin abre una transacción si q es una conexión. Si q ya es una transacción
+// la operación se une a ella y Commit/Rollback quedan en manos de quien la abrió.
+func begin(q Querier) (*txScope, error) {
+    db, ok := q.(interface{ Begin() (*sql.Tx, error) })
+    if !ok {
+        return &txScope{Querier: q}, nil
+    }
+
+    tx, err := db.Begin()
+    if err != nil {
+        return nil, err
+    }
+
+    return &txScope{Querier: tx, tx: tx}, nil
+}
+
+func (t *txScope) Commit() error {
+    if t.tx == nil {
+        return nil
+    }
+    return t.tx.Commit()
+}
+
+func (t *txScope) Rollback() error {
+    if t.tx == nil {
+        return nil
+    }
+    return t.tx.Rollback()
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+    "cursos-api/models"
+    "database/sql"
+    "errors"
+    "time"
+)
+
+type EvaluacionRepository struct {
+    db Querier
+}
+
+func NewEvaluacionRepository(db Querier) *EvaluacionRepository {
+    return &EvaluacionRepository{db: db}
+}
+
+// Create crea una nueva evaluación
+func (r *EvaluacionRepository) Create(evaluacion *models.Evaluacion) error {
+    query := `
+        INSERT INTO evaluaciones (curso_id, titulo, descripcion, calificacion_minima, max_intentos, tiempo_limite_minutos, requerida, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING id, created_at
+    `
+
+    err := r.db.QueryRow(
+        query,
+        evaluacion.CursoID,
+        evaluacion.Titulo,
+        evaluacion.Descripcion,
+        evaluacion.CalificacionMinima,
+        evaluacion.MaxIntentos,
+        evaluacion.TiempoLimiteMinutos,
+        evaluacion.Requerida,
+        time.Now(),
+    ).Scan(&evaluacion.ID, &evaluacion.CreatedAt)
+
+    return err
+}
+
+// FindByID busca una evaluación por ID
+func (r *EvaluacionRepository) FindByID(id int) (*models.Evaluacion, error) {
+    query := `
+        SELECT id, curso_id, titulo, descripcion, calificacion_minima, max_intentos, tiempo_limite_minutos, requerida, created_at
+        FROM evaluaciones
+        WHERE id = $1
+    `
+
+    evaluacion := &models.Evaluacion{}
+    err := r.db.QueryRow(query, id).Scan(
+        &evaluacion.ID,
+        &evaluacion.CursoID,
+        &evaluacion.Titulo,
+        &evaluacion.Descripcion,
+        &evaluacion.CalificacionMinima,
+        &evaluacion.MaxIntentos,
+        &evaluacion.TiempoLimiteMinutos,
+        &evaluacion.Requerida,
+        &evaluacion.CreatedAt,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, errors.New("evaluación no encontrada")
+    }
+
+    return evaluacion, err
+}
+
+// GetByCurso obtiene las evaluaciones de un curso
+func (r *EvaluacionRepository) GetByCurso(cursoID int) ([]models.Evaluacion, error) {
+    query := `
+        SELECT id, curso_id, titulo, descripcion, calificacion_minima, max_intentos, tiempo_limite_minutos, requerida, created_at
+        FROM evaluaciones
+        WHERE curso_id = $1
+        ORDER BY created_at ASC
+    `
+
+    rows, err := r.db.Query(query, cursoID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var evaluaciones []models.Evaluacion
+    for rows.Next() {
+        var evaluacion models.Evaluacion
+        err := rows.Scan(
+            &evaluacion.ID,
+            &evaluacion.CursoID,
+            &evaluacion.Titulo,
+            &evaluacion.Descripcion,
+            &evaluacion.CalificacionMinima,
+            &evaluacion.MaxIntentos,
+            &evaluacion.TiempoLimiteMinutos,
+            &evaluacion.Requerida,
+            &evaluacion.CreatedAt,
+        )
+        if err != nil {
+            return nil, err
+        }
+        evaluaciones = append(evaluaciones, evaluacion)
+    }
+
+    return evaluaciones, nil
+}
+
+// Update actualiza una evaluación
+func (r *EvaluacionRepository) Update(id int, evaluacion *models.Evaluacion) error {
+    query := `
+        UPDATE evaluaciones
+        SET titulo = $1, descripcion = $2, calificacion_minima = $3, max_intentos = $4, tiempo_limite_minutos = $5, requerida = $6
+        WHERE id = $7
+        RETURNING curso_id, created_at
+    `
+
+    err := r.db.QueryRow(
+        query,
+        evaluacion.Titulo,
+        evaluacion.Descripcion,
+        evaluacion.CalificacionMinima,
+        evaluacion.MaxIntentos,
+        evaluacion.TiempoLimiteMinutos,
+        evaluacion.Requerida,
+        id,
+    ).Scan(&evaluacion.CursoID, &evaluacion.CreatedAt)
+
+    if err == sql.ErrNoRows {
+        return errors.New("evaluación no encontrada")
+    }
+
+    evaluacion.ID = id
+    return err
+}
+
+// Delete elimina una evaluación
+func (r *EvaluacionRepository) Delete(id int) error {
+    query := `DELETE FROM evaluaciones WHERE id = $1`
+
+    result, err := r.db.Exec(query, id)
+    if err != nil {
+        return err
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+
+    if rowsAffected == 0 {
+        return errors.New("evaluación no encontrada")
+    }
+
+    return nil
+}
+
+// CountRequeridasPendientes cuenta las evaluaciones requeridas de un curso que
+// el usuario todavía no ha aprobado. Solo cuentan los resultados calificados en
+// el servidor mediante un intento o registrados por el instructor.
+func (r *EvaluacionRepository) CountRequeridasPendientes(cursoID, usuarioID int) (int, error) {
+    query := `
+        SELECT COUNT(*)
+        FROM evaluaciones e
+        WHERE e.curso_id = $1
+          AND e.requerida = true
+          AND NOT EXISTS (
+              SELECT 1 FROM resultados_evaluacion r
+              WHERE r.evaluacion_id = e.id AND r.usuario_id = $2 AND r.aprobado = true
+                AND (
+                    (r.calificado_por IS NOT NULL AND r.calificado_por <> r.usuario_id)
+                    OR EXISTS (SELECT 1 FROM intentos_evaluacion i WHERE i.resultado_id = r.id)
+                )
+          )
+    `
+
+    var count int
+    err := r.db.QueryRow(query, cursoID, usuarioID).Scan(&count)
+
+    return count, err
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+    "cursos-api/models"
+    "database/sql"
+    "errors"
+    "time"
+)
+
+type IdentidadRepository struct {
+    db Querier
+}
+
+func NewIdentidadRepository(db Querier) *IdentidadRepository {
+    return &IdentidadRepository{db: db}
+}
+
+// CreateEstado guarda un login OIDC en curso y, de paso, borra los caducados
+func (r *IdentidadRepository) CreateEstado(estado *models.EstadoOIDC) error {
+    now := time.Now()
+    if _, err := r.db.Exec(`DELETE FROM oidc_estados WHERE expira_at < $1`, now); err != nil {
+        return err
+    }
+
+    _, err := r.db.Exec(`
+        INSERT INTO oidc_estados (state_hash, proveedor, nonce, code_verifier, expira_at, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+    `, estado.StateHash, estado.Proveedor, estado.Nonce, estado.CodeVerifier, estado.ExpiraAt, now)
+
+    return err
+}
+
+// ConsumirEstado recupera y borra un login OIDC en curso. Un state solo se
+// puede usar una vez.
+func (r *IdentidadRepository) ConsumirEstado(stateHash string) (*models.EstadoOIDC, error) {
+    query := `
+        DELETE FROM oidc_estados
+        WHERE state_hash = $1 AND expira_at > $2
+        RETURNING state_hash, proveedor, nonce, code_verifier, expira_at
+    `
+
+    estado := &models.EstadoOIDC{}
+    err := r.db.QueryRow(query, stateHash, time.Now()).Scan(
+        &estado.StateHash,
+        &estado.Proveedor,
+        &estado.Nonce,
+        &estado.CodeVerifier,
+        &estado.ExpiraAt,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, errors.New("login expirado o inválido, vuelve a intentarlo")
+    }
+
+    return estado, err
+}
+
+// FindByProveedorSubject busca la identidad de un proveedor por su subject
+func (r *IdentidadRepository) FindByProveedorSubject(proveedor, subject string) (*models.IdentidadExterna, error) {
+    query := `
+        SELECT id, usuario_id, proveedor, subject, COALESCE(email, ''), created_at, ultimo_login_at
+        FROM user_identities
+        WHERE proveedor = $1 AND subject = $2
+    `
+
+    identidad := &models.IdentidadExterna{}
+    err := r.db.QueryRow(query, proveedor, subject).Scan(
+        &identidad.ID,
+        &identidad.UsuarioID,
+        &identidad.Proveedor,
+        &identidad.Subject,
+        &identidad.Email,
+        &identidad.CreatedAt,
+        &identidad.UltimoLoginAt,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, errors.New("identidad no encontrada")
+    }
+
+    return identidad, err
+}
+
+// GetByUsuario obtiene las identidades externas vinculadas a un usuario
+func (r *IdentidadRepository) GetByUsuario(usuarioID int) ([]models.IdentidadExterna, error) {
+    query := `
+        SELECT id, usuario_id, proveedor, subject, COALESCE(email, ''), created_at, ultimo_login_at
+        FROM user_identities
+        WHERE usuario_id = $1
+        ORDER BY created_at
+    `
+
+    rows, err := r.db.Query(query, usuarioID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var identidades []models.IdentidadExterna
+    for rows.Next() {
+        var identidad models.IdentidadExterna
+        err := rows.Scan(
+            &identidad.ID,
+            &identidad.UsuarioID,
+            &identidad.Proveedor,
+            &identidad.Subject,
+            &identidad.Email,
+            &identidad.CreatedAt,
+            &identidad.UltimoLoginAt,
+        )
+        if err != nil {
+            return nil, err
+        }
+        identidades = append(identidades, identidad)
+    }
+
+    return identidades, nil
+}
+
+// Vincular vincula una identidad externa a un usuario existente
+func (r *IdentidadRepository) Vincular(identidad *models.IdentidadExterna) error {
+    return insertIdentidad(r.db, identidad)
+}
+
+// CrearConUsuario da de alta el usuario y su identidad externa en una sola
+// transacción. Si usuario.EmailVerificado es true el email queda verificado.
+func (r *IdentidadRepository) CrearConUsuario(usuario *models.Usuario, identidad *models.IdentidadExterna) error {
+    tx, err := begin(r.db)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    usuarios := NewUsuarioRepository(tx)
+    verificado := usuario.EmailVerificado
+    if err := usuarios.Create(usuario); err != nil {
+        return err
+    }
+
+    if verificado {
+        if err := usuarios.MarcarEmailVerificado(usuario.ID); err != nil {
+            return err
+        }
+        usuario.EmailVerificado = true
+    }
+
+    identidad.UsuarioID = usuario.ID
+    if err := insertIdentidad(tx, identidad); err != nil {
+        return err
+    }
+
+    return tx.Commit()
+}
+
+// RegistrarLogin anota la fecha del último login con la identidad
+func (r *IdentidadRepository) RegistrarLogin(id int) error {
+    _, err := r.db.Exec(`UPDATE user_identities SET ultimo_login_at = $1 WHERE id = $2`, time.Now(), id)
+    return err
+}
+
+// insertIdentidad inserta una identidad externa
+func insertIdentidad(q Querier, identidad *models.IdentidadExterna) error {
+    now := time.Now()
+    err := q.QueryRow(`
+        INSERT INTO user_identities (usuario_id, proveedor, subject, email, created_at, ultimo_login_at)
+        VALUES ($1, $2, $3, NULLIF($4, ''), $5, $5)
+        RETURNING id, created_at, ultimo_login_at
+    `, identidad.UsuarioID, identidad.Proveedor, identidad.Subject, identidad.Email, now).Scan(
+        &identidad.ID,
+        &identidad.CreatedAt,
+        &identidad.UltimoLoginAt,
+    )
+
+    return err
+}
@@ -0,0 +1,231 @@
+package repository
+
+import (
+    "cursos-api/models"
+    "database/sql"
+    "errors"
+    "time"
+)
+
+type InscripcionRepository struct {
+    db Querier
+}
+
+func NewInscripcionRepository(db Querier) *InscripcionRepository {
+    return &InscripcionRepository{db: db}
+}
+
+// Create crea una nueva inscripción
+func (r *InscripcionRepository) Create(inscripcion *models.Inscripcion) error {
+    query := `
+        INSERT INTO inscripciones (usuario_id, curso_id, fecha_inscripcion, estado, progreso_porcentaje)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id, fecha_inscripcion
+    `
+
+    err := r.db.QueryRow(
+        query,
+        inscripcion.UsuarioID,
+        inscripcion.CursoID,
+        time.Now(),
+        inscripcion.Estado,
+        inscripcion.ProgresoPorcentaje,
+    ).Scan(&inscripcion.ID, &inscripcion.FechaInscripcion)
+
+    return err
+}
+
+// FindByID busca una inscripción por ID
+func (r *InscripcionRepository) FindByID(id int) (*models.Inscripcion, error) {
+    query := `
+        SELECT id, usuario_id, curso_id, fecha_inscripcion, estado, progreso_porcentaje
+        FROM inscripciones
+        WHERE id = $1
+    `
+
+    inscripcion := &models.Inscripcion{}
+    err := r.db.QueryRow(query, id).Scan(
+        &inscripcion.ID,
+        &inscripcion.UsuarioID,
+        &inscripcion.CursoID,
+        &inscripcion.FechaInscripcion,
+        &inscripcion.Estado,
+        &inscripcion.ProgresoPorcentaje,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, errors.New("inscripción no encontrada")
+    }
+
+    return inscripcion, err
+}
+
+// FindByUsuarioAndCurso busca la inscripción de un usuario en un curso
+func (r *InscripcionRepository) FindByUsuarioAndCurso(usuarioID, cursoID int) (*models.Inscripcion, error) {
+    query := `
+        SELECT id, usuario_id, curso_id, fecha_inscripcion, estado, progreso_porcentaje
+        FROM inscripciones
+        WHERE usuario_id = $1 AND curso_id = $2
+    `
+
+    inscripcion := &models.Inscripcion{}
+    err := r.db.QueryRow(query, usuarioID, cursoID).Scan(
+        &inscripcion.ID,
+        &inscripcion.UsuarioID,
+        &inscripcion.CursoID,
+        &inscripcion.FechaInscripcion,
+        &inscripcion.Estado,
+        &inscripcion.ProgresoPorcentaje,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, errors.New("inscripción no encontrada")
+    }
+
+    return inscripcion, err
+}
+
+// GetByUsuario obtiene las inscripciones de un usuario junto con su curso
+func (r *InscripcionRepository) GetByUsuario(usuarioID int) ([]models.Inscripcion, error) {
+    query := `
+        SELECT i.id, i.usuario_id, i.curso_id, i.fecha_inscripcion, i.estado, i.progreso_porcentaje,
+               c.id, c.nombre, c.descripcion, c.duracion_horas, c.instructor_id, c.activo, c.created_at, c.updated_at
+        FROM inscripciones i
+        INNER JOIN cursos c ON i.curso_id = c.id
+        WHERE i.usuario_id = $1 AND c.deleted_at IS NULL
+        ORDER BY i.fecha_inscripcion DESC
+    `
+
+    rows, err := r.db.Query(query, usuarioID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var inscripciones []models.Inscripcion
+    for rows.Next() {
+        var inscripcion models.Inscripcion
+        inscripcion.Curso = &models.Curso{}
+
+        err := rows.Scan(
+            &inscripcion.ID,
+            &inscripcion.UsuarioID,
+            &inscripcion.CursoID,
+            &inscripcion.FechaInscripcion,
+            &inscripcion.Estado,
+            &inscripcion.ProgresoPorcentaje,
+            &inscripcion.Curso.ID,
+            &inscripcion.Curso.Nombre,
+            &inscripcion.Curso.Descripcion,
+            &inscripcion.Curso.DuracionHoras,
+            &inscripcion.Curso.InstructorID,
+            &inscripcion.Curso.Activo,
+            &inscripcion.Curso.CreatedAt,
+            &inscripcion.Curso.UpdatedAt,
+        )
+        if err != nil {
+            return nil, err
+        }
+        inscripciones = append(inscripciones, inscripcion)
+    }
+
+    return inscripciones, nil
+}
+
+// GetByCurso obtiene los alumnos inscritos en un curso
+func (r *InscripcionRepository) GetByCurso(cursoID int) ([]models.Inscripcion, error) {
+    query := `
+        SELECT i.id, i.usuario_id, i.curso_id, i.fecha_inscripcion, i.estado, i.progreso_porcentaje,
+               u.id, u.nombre, u.email, u.rol
+        FROM inscripciones i
+        INNER JOIN usuarios u ON i.usuario_id = u.id
+        WHERE i.curso_id = $1 AND u.deleted_at IS NULL
+        ORDER BY i.fecha_inscripcion DESC
+    `
+
+    rows, err := r.db.Query(query, cursoID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var inscripciones []models.Inscripcion
+    for rows.Next() {
+        var inscripcion models.Inscripcion
+        inscripcion.Usuario = &models.Usuario{}
+
+        err := rows.Scan(
+            &inscripcion.ID,
+            &inscripcion.UsuarioID,
+            &inscripcion.CursoID,
+            &inscripcion.FechaInscripcion,
+            &inscripcion.Estado,
+            &inscripcion.ProgresoPorcentaje,
+            &inscripcion.Usuario.ID,
+            &inscripcion.Usuario.Nombre,
+            &inscripcion.Usuario.Email,
+            &inscripcion.Usuario.Rol,
+        )
+        if err != nil {
+            return nil, err
+        }
+        inscripciones = append(inscripciones, inscripcion)
+    }
+
+    return inscripciones, nil
+}
+
+// UpdateEstado actualiza el estado de una inscripción
+func (r *InscripcionRepository) UpdateEstado(id int, estado string) error {
+    query := `UPDATE inscripciones SET estado = $1 WHERE id = $2`
+
+    result, err := r.db.Exec(query, estado, id)
+    if err != nil {
+        return err
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+
+    if rowsAffected == 0 {
+        return errors.New("inscripción no encontrada")
+    }
+
+    return nil
+}
+
+// Reactivar vuelve a activar una inscripción cancelada conservando el progreso
+// registrado. El porcentaje se recalcula en la misma transacción porque las
+// lecciones pueden haber cambiado mientras estaba cancelada; si ya las tiene
+// todas completadas queda directamente en "completado".
+func (r *InscripcionRepository) Reactivar(inscripcion *models.Inscripcion) error {
+    tx, err := begin(r.db)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    query := `
+        UPDATE inscripciones
+        SET estado = 'activo', fecha_inscripcion = $2
+        WHERE id = $1 AND estado = 'cancelado'
+        RETURNING fecha_inscripcion
+    `
+
+    err = tx.QueryRow(query, inscripcion.ID, time.Now()).Scan(&inscripcion.FechaInscripcion)
+    if err == sql.ErrNoRows {
+        return errors.New("inscripción no encontrada o no está cancelada")
+    }
+    if err != nil {
+        return err
+    }
+
+    err = tx.QueryRow(queryRecalcularProgreso("i.id = $1"), inscripcion.ID).Scan(&inscripcion.ProgresoPorcentaje, &inscripcion.Estado)
+    if err != nil {
+        return err
+    }
+
+    return tx.Commit()
+}
@@ -0,0 +1,230 @@
+package repository
+
+import (
+    "cursos-api/models"
+    "database/sql"
+    "encoding/json"
+    "errors"
+    "time"
+)
+
+type IntentoRepository struct {
+    db Querier
+}
+
+func NewIntentoRepository(db Querier) *IntentoRepository {
+    return &IntentoRepository{db: db}
+}
+
+// Create inicia un intento verificando, con la evaluación bloqueada, que el
+// usuario no haya alcanzado maxIntentos (0 = ilimitados). Si mientras tanto
+// otra petición abrió un intento, lo devuelve en intento en lugar de crear
+// otro, así dos inicios simultáneos no consumen dos intentos.
+func (r *IntentoRepository) Create(intento *models.IntentoEvaluacion, maxIntentos int) error {
+    tx, err := begin(r.db)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    _, err = tx.Exec(`SELECT id FROM evaluaciones WHERE id = $1 FOR UPDATE`, intento.EvaluacionID)
+    if err != nil {
+        return err
+    }
+
+    abierto, err := scanIntento(tx.QueryRow(queryIntentoAbierto, intento.UsuarioID, intento.EvaluacionID, time.Now()))
+    if err == nil {
+        *intento = *abierto
+        return tx.Commit()
+    }
+    if err != sql.ErrNoRows {
+        return err
+    }
+
+    if maxIntentos > 0 {
+        var count int
+        err = tx.QueryRow(
+            `SELECT COUNT(*) FROM intentos_evaluacion WHERE evaluacion_id = $1 AND usuario_id = $2`,
+            intento.EvaluacionID, intento.UsuarioID,
+        ).Scan(&count)
+        if err != nil {
+            return err
+        }
+
+        if count >= maxIntentos {
+            return errors.New("has alcanzado el número máximo de intentos")
+        }
+    }
+
+    query := `
+        INSERT INTO intentos_evaluacion (evaluacion_id, usuario_id, iniciado_at, expira_at)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id
+    `
+
+    err = tx.QueryRow(
+        query,
+        intento.EvaluacionID,
+        intento.UsuarioID,
+        intento.IniciadoAt,
+        intento.ExpiraAt,
+    ).Scan(&intento.ID)
+    if err != nil {
+        return err
+    }
+
+    return tx.Commit()
+}
+
+// FindByID busca un intento por ID
+func (r *IntentoRepository) FindByID(id int) (*models.IntentoEvaluacion, error) {
+    query := `
+        SELECT id, evaluacion_id, usuario_id, iniciado_at, expira_at, enviado_at, respuestas, calificacion, resultado_id
+        FROM intentos_evaluacion
+        WHERE id = $1
+    `
+
+    intento, err := scanIntento(r.db.QueryRow(query, id))
+    if err == sql.ErrNoRows {
+        return nil, errors.New("intento no encontrado")
+    }
+
+    return intento, err
+}
+
+// queryIntentoAbierto busca el intento sin enviar y no vencido de un usuario
+// en una evaluación
+const queryIntentoAbierto = `
+        SELECT id, evaluacion_id, usuario_id, iniciado_at, expira_at, enviado_at, respuestas, calificacion, resultado_id
+        FROM intentos_evaluacion
+        WHERE usuario_id = $1 AND evaluacion_id = $2
+          AND enviado_at IS NULL
+          AND (expira_at IS NULL OR expira_at > $3)
+        ORDER BY iniciado_at DESC
+        LIMIT 1
+    `
+
+// FindAbierto busca el intento sin enviar y no vencido de un usuario en una evaluación
+func (r *IntentoRepository) FindAbierto(usuarioID, evaluacionID int) (*models.IntentoEvaluacion, error) {
+    intento, err := scanIntento(r.db.QueryRow(queryIntentoAbierto, usuarioID, evaluacionID, time.Now()))
+    if err == sql.ErrNoRows {
+        return nil, errors.New("intento no encontrado")
+    }
+
+    return intento, err
+}
+
+// GetByUsuarioAndEvaluacion obtiene los intentos de un usuario en una evaluación
+func (r *IntentoRepository) GetByUsuarioAndEvaluacion(usuarioID, evaluacionID int) ([]models.IntentoEvaluacion, error) {
+    query := `
+        SELECT id, evaluacion_id, usuario_id, iniciado_at, expira_at, enviado_at, respuestas, calificacion, resultado_id
+        FROM intentos_evaluacion
+        WHERE usuario_id = $1 AND evaluacion_id = $2
+        ORDER BY iniciado_at DESC
+    `
+
+    rows, err := r.db.Query(query, usuarioID, evaluacionID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var intentos []models.IntentoEvaluacion
+    for rows.Next() {
+        intento, err := scanIntento(rows)
+        if err != nil {
+            return nil, err
+        }
+        intentos = append(intentos, *intento)
+    }
+
+    return intentos, nil
+}
+
+// Finalizar registra el resultado de un intento y lo marca como enviado en una
+// sola transacción. Falla si el intento ya había sido enviado.
+func (r *IntentoRepository) Finalizar(intento *models.IntentoEvaluacion, resultado *models.ResultadoEvaluacion) error {
+    respuestas, err := json.Marshal(intento.Respuestas)
+    if err != nil {
+        return err
+    }
+
+    tx, err := begin(r.db)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    now := time.Now()
+
+    err = tx.QueryRow(`
+        INSERT INTO resultados_evaluacion (evaluacion_id, usuario_id, calificacion, aprobado, fecha_evaluacion)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id, fecha_evaluacion
+    `,
+        resultado.EvaluacionID,
+        resultado.UsuarioID,
+        resultado.Calificacion,
+        resultado.Aprobado,
+        now,
+    ).Scan(&resultado.ID, &resultado.FechaEvaluacion)
+    if err != nil {
+        return err
+    }
+
+    result, err := tx.Exec(`
+        UPDATE intentos_evaluacion
+        SET enviado_at = $1, respuestas = $2, calificacion = $3, resultado_id = $4
+        WHERE id = $5 AND enviado_at IS NULL
+    `, now, respuestas, resultado.Calificacion, resultado.ID, intento.ID)
+    if err != nil {
+        return err
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+
+    if rowsAffected == 0 {
+        return errors.New("el intento ya fue enviado")
+    }
+
+    if err := tx.Commit(); err != nil {
+        return err
+    }
+
+    intento.EnviadoAt = &now
+    intento.Calificacion = &resultado.Calificacion
+    intento.ResultadoID = &resultado.ID
+    return nil
+}
+
+// scanIntento lee un intento desde una fila
+func scanIntento(row interface{ Scan(...interface{}) error }) (*models.IntentoEvaluacion, error) {
+    intento := &models.IntentoEvaluacion{}
+    var respuestas []byte
+
+    err := row.Scan(
+        &intento.ID,
+        &intento.EvaluacionID,
+        &intento.UsuarioID,
+        &intento.IniciadoAt,
+        &intento.ExpiraAt,
+        &intento.EnviadoAt,
+        &respuestas,
+        &intento.Calificacion,
+        &intento.ResultadoID,
+    )
+    if err != nil {
+        return nil, err
+    }
+
+    if len(respuestas) > 0 {
+        if err := json.Unmarshal(respuestas, &intento.Respuestas); err != nil {
+            return nil, err
+        }
+    }
+
+    return intento, nil
+}
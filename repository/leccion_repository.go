@@ -0,0 +1,215 @@
+package repository
+
+import (
+    "cursos-api/models"
+    "database/sql"
+    "errors"
+    "time"
+)
+
+type LeccionRepository struct {
+    db Querier
+}
+
+func NewLeccionRepository(db Querier) *LeccionRepository {
+    return &LeccionRepository{db: db}
+}
+
+// Create crea una nueva lección en la posición indicada por Orden.
+// Si Orden es 0 o excede el final, la lección se agrega al final del curso.
+// En la misma transacción recalcula el progreso de los inscritos.
+func (r *LeccionRepository) Create(leccion *models.Leccion) error {
+    tx, err := begin(r.db)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    var maxOrden int
+    err = tx.QueryRow(`SELECT COALESCE(MAX(orden), 0) FROM lecciones WHERE curso_id = $1`, leccion.CursoID).Scan(&maxOrden)
+    if err != nil {
+        return err
+    }
+
+    if leccion.Orden <= 0 || leccion.Orden > maxOrden {
+        leccion.Orden = maxOrden + 1
+    } else {
+        // Desplazar las lecciones siguientes para abrir espacio
+        _, err = tx.Exec(`UPDATE lecciones SET orden = orden + 1 WHERE curso_id = $1 AND orden >= $2`, leccion.CursoID, leccion.Orden)
+        if err != nil {
+            return err
+        }
+    }
+
+    query := `
+        INSERT INTO lecciones (curso_id, titulo, contenido, orden, duracion_minutos, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id, created_at
+    `
+
+    err = tx.QueryRow(
+        query,
+        leccion.CursoID,
+        leccion.Titulo,
+        leccion.Contenido,
+        leccion.Orden,
+        leccion.DuracionMinutos,
+        time.Now(),
+    ).Scan(&leccion.ID, &leccion.CreatedAt)
+    if err != nil {
+        return err
+    }
+
+    if err := NewProgresoRepository(tx).RecalcularCurso(leccion.CursoID); err != nil {
+        return err
+    }
+
+    return tx.Commit()
+}
+
+// FindByID busca una lección por ID
+func (r *LeccionRepository) FindByID(id int) (*models.Leccion, error) {
+    query := `
+        SELECT id, curso_id, titulo, contenido, orden, duracion_minutos, created_at
+        FROM lecciones
+        WHERE id = $1
+    `
+
+    leccion := &models.Leccion{}
+    err := r.db.QueryRow(query, id).Scan(
+        &leccion.ID,
+        &leccion.CursoID,
+        &leccion.Titulo,
+        &leccion.Contenido,
+        &leccion.Orden,
+        &leccion.DuracionMinutos,
+        &leccion.CreatedAt,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, errors.New("lección no encontrada")
+    }
+
+    return leccion, err
+}
+
+// GetByCurso obtiene las lecciones de un curso en orden
+func (r *LeccionRepository) GetByCurso(cursoID int) ([]models.Leccion, error) {
+    query := `
+        SELECT id, curso_id, titulo, contenido, orden, duracion_minutos, created_at
+        FROM lecciones
+        WHERE curso_id = $1
+        ORDER BY orden ASC
+    `
+
+    rows, err := r.db.Query(query, cursoID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var lecciones []models.Leccion
+    for rows.Next() {
+        var leccion models.Leccion
+        err := rows.Scan(
+            &leccion.ID,
+            &leccion.CursoID,
+            &leccion.Titulo,
+            &leccion.Contenido,
+            &leccion.Orden,
+            &leccion.DuracionMinutos,
+            &leccion.CreatedAt,
+        )
+        if err != nil {
+            return nil, err
+        }
+        lecciones = append(lecciones, leccion)
+    }
+
+    return lecciones, nil
+}
+
+// Update actualiza el contenido de una lección (el orden se cambia con Reorder)
+func (r *LeccionRepository) Update(id int, leccion *models.Leccion) error {
+    query := `
+        UPDATE lecciones
+        SET titulo = $1, contenido = $2, duracion_minutos = $3
+        WHERE id = $4
+        RETURNING curso_id, orden, created_at
+    `
+
+    err := r.db.QueryRow(
+        query,
+        leccion.Titulo,
+        leccion.Contenido,
+        leccion.DuracionMinutos,
+        id,
+    ).Scan(&leccion.CursoID, &leccion.Orden, &leccion.CreatedAt)
+
+    if err == sql.ErrNoRows {
+        return errors.New("lección no encontrada")
+    }
+
+    leccion.ID = id
+    return err
+}
+
+// Delete elimina una lección, compacta el orden de las siguientes y recalcula
+// el progreso de los inscritos
+func (r *LeccionRepository) Delete(id int) error {
+    tx, err := begin(r.db)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    var cursoID, orden int
+    err = tx.QueryRow(`DELETE FROM lecciones WHERE id = $1 RETURNING curso_id, orden`, id).Scan(&cursoID, &orden)
+    if err == sql.ErrNoRows {
+        return errors.New("lección no encontrada")
+    }
+    if err != nil {
+        return err
+    }
+
+    _, err = tx.Exec(`UPDATE lecciones SET orden = orden - 1 WHERE curso_id = $1 AND orden > $2`, cursoID, orden)
+    if err != nil {
+        return err
+    }
+
+    if err := NewProgresoRepository(tx).RecalcularCurso(cursoID); err != nil {
+        return err
+    }
+
+    return tx.Commit()
+}
+
+// Reorder asigna el orden de las lecciones de un curso según la posición de sus IDs
+func (r *LeccionRepository) Reorder(cursoID int, leccionIDs []int) error {
+    tx, err := begin(r.db)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    for i, leccionID := range leccionIDs {
+        result, err := tx.Exec(
+            `UPDATE lecciones SET orden = $1 WHERE id = $2 AND curso_id = $3`,
+            i+1, leccionID, cursoID,
+        )
+        if err != nil {
+            return err
+        }
+
+        rowsAffected, err := result.RowsAffected()
+        if err != nil {
+            return err
+        }
+
+        if rowsAffected == 0 {
+            return errors.New("lección no encontrada")
+        }
+    }
+
+    return tx.Commit()
+}
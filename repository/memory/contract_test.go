@@ -0,0 +1,24 @@
+package memory_test
+
+import (
+    "cursos-api/repository/memory"
+    "cursos-api/repository/repotest"
+    "testing"
+)
+
+// nuevosRepos crea un almacenamiento vacío por caso
+func nuevosRepos(t *testing.T) repotest.Repos {
+    store := memory.NewStore()
+    return repotest.Repos{
+        Usuarios: store.Usuarios(),
+        Cursos:   store.Cursos(),
+    }
+}
+
+func TestUsuarioRepositoryContract(t *testing.T) {
+    repotest.RunUsuarioRepositoryContract(t, nuevosRepos)
+}
+
+func TestCursoRepositoryContract(t *testing.T) {
+    repotest.RunCursoRepositoryContract(t, nuevosRepos)
+}
@@ -0,0 +1,588 @@
+package memory
+
+import (
+    "cursos-api/models"
+    "cursos-api/repository"
+    "errors"
+    "html"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+type CursoRepository struct {
+    store *Store
+}
+
+// Create crea un nuevo curso. Sin estado explícito queda publicado o
+// archivado según activo.
+func (r *CursoRepository) Create(curso *models.Curso) error {
+    s := r.store
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    if _, ok := s.usuarioVigente(curso.InstructorID); !ok {
+        return errors.New("el instructor no existe")
+    }
+
+    now := time.Now()
+    s.cursoID++
+    curso.ID = s.cursoID
+    if curso.Estado == "" {
+        curso.Estado = "archivado"
+        if curso.Activo {
+            curso.Estado = "publicado"
+        }
+    }
+    curso.PublicadoAt = nil
+    if curso.Activo {
+        curso.PublicadoAt = &now
+    }
+    curso.CreatedAt = now
+    curso.UpdatedAt = now
+    curso.DeletedAt = nil
+
+    guardado := *curso
+    guardado.Instructor = nil
+    s.cursos[curso.ID] = guardado
+    return nil
+}
+
+// FindByID busca un curso por ID
+func (r *CursoRepository) FindByID(id int) (*models.Curso, error) {
+    s := r.store
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+
+    curso, ok := s.cursoVigente(id)
+    if !ok {
+        return nil, errors.New("curso no encontrado")
+    }
+
+    return s.conInstructor(curso), nil
+}
+
+// FindEliminado busca un curso eliminado pendiente de purga
+func (r *CursoRepository) FindEliminado(id int) (*models.Curso, error) {
+    s := r.store
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+
+    curso, ok := s.cursos[id]
+    if !ok || curso.DeletedAt == nil {
+        return nil, errors.New("curso eliminado no encontrado")
+    }
+
+    return s.conInstructor(curso), nil
+}
+
+// GetAll obtiene todos los cursos
+func (r *CursoRepository) GetAll() ([]models.Curso, error) {
+    return r.filtrar(func(models.Curso) bool { return true }), nil
+}
+
+// GetByInstructor obtiene todos los cursos de un instructor
+func (r *CursoRepository) GetByInstructor(instructorID int) ([]models.Curso, error) {
+    return r.filtrar(func(c models.Curso) bool { return c.InstructorID == instructorID }), nil
+}
+
+// GetActivos obtiene todos los cursos activos y no bloqueados por moderación
+func (r *CursoRepository) GetActivos() ([]models.Curso, error) {
+    return r.filtrar(func(c models.Curso) bool { return c.Activo && !c.Bloqueado }), nil
+}
+
+// List obtiene una página de cursos filtrada y ordenada, con la misma
+// paginación por clave (campo de ordenación, id) que Postgres
+func (r *CursoRepository) List(filtro models.CursoFiltro) (*models.PaginaCursos, error) {
+    valor, ok := valoresOrden[filtro.Orden]
+    if !ok {
+        return nil, errors.New("campo de ordenación no válido")
+    }
+
+    cursos := r.filtrarEliminados(filtro.Eliminados, func(c models.Curso) bool {
+        return (filtro.Activo == nil || c.Activo == *filtro.Activo) &&
+            (filtro.Estado == "" || c.Estado == filtro.Estado) &&
+            (!filtro.SoloCatalogo || (c.Activo && !c.Bloqueado)) &&
+            (filtro.InstructorID == 0 || c.InstructorID == filtro.InstructorID) &&
+            (filtro.DuracionMin == 0 || c.DuracionHoras >= filtro.DuracionMin) &&
+            (filtro.DuracionMax == 0 || c.DuracionHoras <= filtro.DuracionMax) &&
+            (filtro.CreadoDesde == nil || !c.CreatedAt.Before(*filtro.CreadoDesde)) &&
+            (filtro.CreadoHasta == nil || c.CreatedAt.Before(*filtro.CreadoHasta)) &&
+            tieneTaxonomia(c, filtro.CategoriaID, filtro.Etiquetas)
+    })
+
+    // comparar ordena por (campo, id) en el sentido pedido
+    comparar := func(a models.Curso, b models.Curso) int {
+        cmp := valor(a, b)
+        if cmp == 0 {
+            cmp = a.ID - b.ID
+        }
+        if filtro.Desc {
+            return -cmp
+        }
+        return cmp
+    }
+    sort.SliceStable(cursos, func(i, j int) bool { return comparar(cursos[i], cursos[j]) < 0 })
+
+    pagina := &models.PaginaCursos{Items: []models.Curso{}, Total: len(cursos), Limite: filtro.Limite}
+
+    if filtro.Cursor != "" {
+        cursor, err := repository.DecodeCursor(filtro.Cursor, filtro.Orden, filtro.Desc)
+        if err != nil {
+            return nil, err
+        }
+        ultimo, err := cursoDeCursor(filtro.Orden, cursor)
+        if err != nil {
+            return nil, err
+        }
+
+        siguientes := cursos[:0]
+        for _, curso := range cursos {
+            if comparar(curso, ultimo) > 0 {
+                siguientes = append(siguientes, curso)
+            }
+        }
+        cursos = siguientes
+    }
+
+    if len(cursos) > filtro.Limite {
+        cursos = cursos[:filtro.Limite]
+        ultimo := cursos[len(cursos)-1]
+        pagina.NextCursor = repository.EncodeCursor(repository.Cursor{
+            Orden: filtro.Orden,
+            Desc:  filtro.Desc,
+            Valor: valorCursor(filtro.Orden, ultimo),
+            ID:    ultimo.ID,
+        })
+    }
+
+    pagina.Items = append(pagina.Items, cursos...)
+    return pagina, nil
+}
+
+// Search busca cursos cuyo nombre o descripción contienen todas las palabras
+// del texto, sin distinguir mayúsculas ni acentos. Es una aproximación de la
+// búsqueda de Postgres: sin stemming, sintaxis de consulta ni lecciones.
+func (r *CursoRepository) Search(busqueda models.CursoBusqueda) (*models.PaginaBusquedaCursos, error) {
+    terminos := strings.Fields(normalizar(busqueda.Texto))
+
+    var resultados []models.ResultadoBusquedaCurso
+    for _, curso := range r.filtrar(func(c models.Curso) bool {
+        return (!busqueda.SoloCatalogo || (c.Activo && !c.Bloqueado)) &&
+            (busqueda.InstructorID == 0 || c.InstructorID == busqueda.InstructorID)
+    }) {
+        nombre, descripcion := normalizar(curso.Nombre), normalizar(curso.Descripcion)
+
+        relevancia := 0.0
+        for _, termino := range terminos {
+            enNombre, enDescripcion := strings.Count(nombre, termino), strings.Count(descripcion, termino)
+            if enNombre+enDescripcion == 0 {
+                relevancia = 0
+                break
+            }
+            relevancia += float64(2*enNombre + enDescripcion)
+        }
+        if relevancia == 0 {
+            continue
+        }
+
+        resultados = append(resultados, models.ResultadoBusquedaCurso{
+            Curso:           curso,
+            Relevancia:      relevancia,
+            NombreResaltado: resaltar(curso.Nombre, terminos),
+            Fragmento:       resaltar(curso.Descripcion, terminos),
+        })
+    }
+
+    sort.SliceStable(resultados, func(i, j int) bool { return resultados[i].Relevancia > resultados[j].Relevancia })
+
+    pagina := &models.PaginaBusquedaCursos{
+        Items:  []models.ResultadoBusquedaCurso{},
+        Total:  len(resultados),
+        Limite: busqueda.Limite,
+        Offset: busqueda.Offset,
+    }
+    if busqueda.Offset < len(resultados) {
+        resultados = resultados[busqueda.Offset:]
+        if len(resultados) > busqueda.Limite {
+            resultados = resultados[:busqueda.Limite]
+        }
+        pagina.Items = append(pagina.Items, resultados...)
+    }
+
+    return pagina, nil
+}
+
+// sinAcentos quita los acentos del español sin cambiar la longitud en runas
+var sinAcentos = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n")
+
+// normalizar pasa el texto a minúsculas y sin acentos
+func normalizar(texto string) string {
+    return sinAcentos.Replace(strings.ToLower(texto))
+}
+
+// resaltar escapa el texto y marca con <mark> las apariciones de los términos
+func resaltar(texto string, terminos []string) string {
+    original := []rune(texto)
+    normalizado := []rune(normalizar(texto))
+    if len(original) != len(normalizado) {
+        return html.EscapeString(texto)
+    }
+
+    marcado := make([]bool, len(original))
+    for _, termino := range terminos {
+        t := []rune(termino)
+        for i := 0; i+len(t) <= len(normalizado); i++ {
+            if string(normalizado[i:i+len(t)]) == termino {
+                for j := i; j < i+len(t); j++ {
+                    marcado[j] = true
+                }
+            }
+        }
+    }
+
+    var b strings.Builder
+    for i, r := range original {
+        if marcado[i] && (i == 0 || !marcado[i-1]) {
+            b.WriteString("<mark>")
+        }
+        b.WriteString(html.EscapeString(string(r)))
+        if marcado[i] && (i == len(original)-1 || !marcado[i+1]) {
+            b.WriteString("</mark>")
+        }
+    }
+    return b.String()
+}
+
+// valoresOrden comparan dos cursos por cada campo de ordenación admitido
+var valoresOrden = map[string]func(a, b models.Curso) int{
+    "created_at":     func(a, b models.Curso) int { return a.CreatedAt.Compare(b.CreatedAt) },
+    "updated_at":     func(a, b models.Curso) int { return a.UpdatedAt.Compare(b.UpdatedAt) },
+    "nombre":         func(a, b models.Curso) int { return strings.Compare(a.Nombre, b.Nombre) },
+    "duracion_horas": func(a, b models.Curso) int { return a.DuracionHoras - b.DuracionHoras },
+}
+
+// formatoCursorFecha guarda las fechas del cursor sin perder precisión
+const formatoCursorFecha = time.RFC3339Nano
+
+// tieneTaxonomia indica si el curso tiene la categoría y todas las etiquetas
+// pedidas. El almacenamiento en memoria no guarda el árbol de categorías, así
+// que solo se comparan las asignadas directamente al curso.
+func tieneTaxonomia(c models.Curso, categoriaID int, etiquetas []string) bool {
+    if categoriaID > 0 {
+        encontrada := false
+        for _, categoria := range c.Categorias {
+            if categoria.ID == categoriaID {
+                encontrada = true
+                break
+            }
+        }
+        if !encontrada {
+            return false
+        }
+    }
+
+    for _, etiqueta := range etiquetas {
+        encontrada := false
+        for _, e := range c.Etiquetas {
+            if e == etiqueta {
+                encontrada = true
+                break
+            }
+        }
+        if !encontrada {
+            return false
+        }
+    }
+    return true
+}
+
+// valorCursor devuelve el valor del campo de ordenación de un curso
+func valorCursor(orden string, curso models.Curso) string {
+    switch orden {
+    case "created_at":
+        return curso.CreatedAt.Format(formatoCursorFecha)
+    case "updated_at":
+        return curso.UpdatedAt.Format(formatoCursorFecha)
+    case "nombre":
+        return curso.Nombre
+    default:
+        return strconv.Itoa(curso.DuracionHoras)
+    }
+}
+
+// cursoDeCursor reconstruye la clave del último curso entregado
+func cursoDeCursor(orden string, cursor *repository.Cursor) (models.Curso, error) {
+    curso := models.Curso{ID: cursor.ID}
+
+    var err error
+    switch orden {
+    case "created_at":
+        curso.CreatedAt, err = time.Parse(formatoCursorFecha, cursor.Valor)
+    case "updated_at":
+        curso.UpdatedAt, err = time.Parse(formatoCursorFecha, cursor.Valor)
+    case "nombre":
+        curso.Nombre = cursor.Valor
+    default:
+        curso.DuracionHoras, err = strconv.Atoi(cursor.Valor)
+    }
+    if err != nil {
+        return curso, errors.New("cursor inválido")
+    }
+
+    return curso, nil
+}
+
+// Update actualiza un curso
+func (r *CursoRepository) Update(id int, curso *models.Curso) error {
+    s := r.store
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    actual, ok := s.cursoVigente(id)
+    if !ok {
+        return errors.New("curso no encontrado")
+    }
+
+    if _, ok := s.usuarioVigente(curso.InstructorID); !ok {
+        return errors.New("el instructor no existe")
+    }
+
+    actual.Nombre = curso.Nombre
+    actual.Descripcion = curso.Descripcion
+    actual.DuracionHoras = curso.DuracionHoras
+    actual.InstructorID = curso.InstructorID
+    actual.Activo = curso.Activo
+    actual.UpdatedAt = time.Now()
+    s.cursos[id] = actual
+
+    curso.ID = id
+    curso.UpdatedAt = actual.UpdatedAt
+    return nil
+}
+
+// Delete elimina lógicamente un curso
+func (r *CursoRepository) Delete(id int) error {
+    s := r.store
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    curso, ok := s.cursoVigente(id)
+    if !ok {
+        return errors.New("curso no encontrado")
+    }
+
+    now := time.Now()
+    curso.DeletedAt = &now
+    s.cursos[id] = curso
+    return nil
+}
+
+// Restore restaura un curso eliminado
+func (r *CursoRepository) Restore(id int) error {
+    s := r.store
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    curso, ok := s.cursos[id]
+    if !ok || curso.DeletedAt == nil {
+        return errors.New("curso eliminado no encontrado")
+    }
+
+    curso.DeletedAt = nil
+    curso.UpdatedAt = time.Now()
+    s.cursos[id] = curso
+    return nil
+}
+
+// Purgar borra definitivamente los cursos eliminados antes de la fecha
+// indicada, con su historial de estados
+func (r *CursoRepository) Purgar(antes time.Time) (int64, error) {
+    s := r.store
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    var purgados int64
+    for id, curso := range s.cursos {
+        if curso.DeletedAt != nil && curso.DeletedAt.Before(antes) {
+            delete(s.cursos, id)
+            delete(s.historial, id)
+            purgados++
+        }
+    }
+
+    return purgados, nil
+}
+
+// VerifyInstructor verifica que un curso pertenece a un instructor
+func (r *CursoRepository) VerifyInstructor(cursoID, instructorID int) (bool, error) {
+    s := r.store
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+
+    curso, ok := s.cursoVigente(cursoID)
+    return ok && curso.InstructorID == instructorID, nil
+}
+
+// UpdateInstructor reasigna el instructor de un curso
+func (r *CursoRepository) UpdateInstructor(id, instructorID int) error {
+    s := r.store
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    curso, ok := s.cursoVigente(id)
+    if !ok {
+        return errors.New("curso no encontrado")
+    }
+
+    if _, ok := s.usuarioVigente(instructorID); !ok {
+        return errors.New("el instructor no existe")
+    }
+
+    curso.InstructorID = instructorID
+    curso.UpdatedAt = time.Now()
+    s.cursos[id] = curso
+    return nil
+}
+
+// SetBloqueo bloquea o desbloquea un curso por moderación
+func (r *CursoRepository) SetBloqueo(id int, bloqueado bool, motivo string) error {
+    s := r.store
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    curso, ok := s.cursoVigente(id)
+    if !ok {
+        return errors.New("curso no encontrado")
+    }
+
+    now := time.Now()
+    curso.Bloqueado = bloqueado
+    curso.MotivoBloqueo = motivo
+    curso.BloqueadoAt = nil
+    if bloqueado {
+        curso.BloqueadoAt = &now
+    }
+    curso.UpdatedAt = now
+    s.cursos[id] = curso
+    return nil
+}
+
+// CambiarEstado pasa un curso de cambio.EstadoAnterior a cambio.EstadoNuevo
+// y lo anota en el historial, con las mismas reglas que en Postgres
+func (r *CursoRepository) CambiarEstado(cambio *models.CambioEstadoCurso) error {
+    s := r.store
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    curso, ok := s.cursoVigente(cambio.CursoID)
+    if !ok || curso.Estado != cambio.EstadoAnterior {
+        return errors.New("curso no encontrado o su estado ha cambiado")
+    }
+
+    now := time.Now()
+    publicado := cambio.EstadoNuevo == "publicado"
+    curso.Estado = cambio.EstadoNuevo
+    curso.Activo = publicado
+    if publicado {
+        curso.PublicadoAt = &now
+    } else {
+        curso.DespublicarAt = nil
+    }
+    curso.PublicarAt = nil
+    curso.UpdatedAt = now
+    s.cursos[cambio.CursoID] = curso
+
+    s.cambioID++
+    cambio.ID = s.cambioID
+    cambio.CreatedAt = now
+    s.historial[cambio.CursoID] = append(s.historial[cambio.CursoID], *cambio)
+    return nil
+}
+
+// Programar fija (o con nil cancela) la publicación y la retirada programadas
+func (r *CursoRepository) Programar(id int, publicarAt, despublicarAt *time.Time) error {
+    s := r.store
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    curso, ok := s.cursoVigente(id)
+    if !ok {
+        return errors.New("curso no encontrado")
+    }
+
+    curso.PublicarAt = publicarAt
+    curso.DespublicarAt = despublicarAt
+    s.cursos[id] = curso
+    return nil
+}
+
+// GetProgramados obtiene los cursos con una publicación o retirada programada
+// que ya ha vencido
+func (r *CursoRepository) GetProgramados(hasta time.Time) ([]models.Curso, error) {
+    vencida := func(t *time.Time) bool { return t != nil && !t.After(hasta) }
+
+    cursos := r.filtrar(func(c models.Curso) bool {
+        return (vencida(c.PublicarAt) && (c.Estado == "en_revision" || c.Estado == "archivado")) ||
+            (vencida(c.DespublicarAt) && c.Estado == "publicado")
+    })
+    sort.Slice(cursos, func(i, j int) bool { return cursos[i].ID < cursos[j].ID })
+    return cursos, nil
+}
+
+// GetHistorial obtiene los cambios de estado de un curso, del más antiguo al
+// más reciente
+func (r *CursoRepository) GetHistorial(cursoID int) ([]models.CambioEstadoCurso, error) {
+    s := r.store
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+
+    return append([]models.CambioEstadoCurso{}, s.historial[cursoID]...), nil
+}
+
+// filtrar devuelve los cursos vigentes que cumplen la condición, del más
+// reciente al más antiguo
+func (r *CursoRepository) filtrar(incluir func(models.Curso) bool) []models.Curso {
+    return r.filtrarEliminados(false, incluir)
+}
+
+// filtrarEliminados es filtrar sobre los cursos vigentes o sobre los eliminados
+func (r *CursoRepository) filtrarEliminados(eliminados bool, incluir func(models.Curso) bool) []models.Curso {
+    s := r.store
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+
+    var cursos []models.Curso
+    for _, curso := range s.cursos {
+        if (curso.DeletedAt != nil) == eliminados && incluir(curso) {
+            cursos = append(cursos, *s.conInstructor(curso))
+        }
+    }
+
+    sort.Slice(cursos, func(i, j int) bool {
+        return masReciente(cursos[i].CreatedAt, cursos[i].ID, cursos[j].CreatedAt, cursos[j].ID)
+    })
+
+    return cursos
+}
+
+// cursoVigente devuelve el curso si existe y no está eliminado. Requiere
+// tener el lock tomado.
+func (s *Store) cursoVigente(id int) (models.Curso, bool) {
+    curso, ok := s.cursos[id]
+    return curso, ok && curso.DeletedAt == nil
+}
+
+// conInstructor completa los datos públicos del instructor como hace el JOIN
+// de Postgres. Requiere tener el lock tomado.
+func (s *Store) conInstructor(curso models.Curso) *models.Curso {
+    instructor := s.usuarios[curso.InstructorID]
+    curso.Instructor = &models.Usuario{
+        ID:     instructor.ID,
+        Nombre: instructor.Nombre,
+        Email:  instructor.Email,
+        Rol:    instructor.Rol,
+    }
+    return &curso
+}
@@ -0,0 +1,58 @@
+// Package memory implementa en memoria los repositorios de usuarios y cursos.
+// Reproduce las reglas que en Postgres imponen las restricciones del esquema
+// (email único, instructor existente, borrado en cascada) para poder probar
+// los servicios sin base de datos.
+package memory
+
+import (
+    "cursos-api/models"
+    "cursos-api/services"
+    "sync"
+    "time"
+)
+
+// Store es el almacenamiento compartido por los repositorios en memoria. Es
+// seguro para uso concurrente.
+type Store struct {
+    mu          sync.RWMutex
+    usuarios    map[int]models.Usuario
+    cursos      map[int]models.Curso
+    historial   map[int][]models.CambioEstadoCurso // por curso
+    loginFallos map[int]loginFallos
+    usuarioID   int
+    cursoID     int
+    cambioID    int
+}
+
+// loginFallos son los fallos de login dentro de la ventana y el nivel de
+// bloqueos de un usuario, que en Postgres viven en login_fallidos y en una
+// columna de usuarios no expuesta en el modelo
+type loginFallos struct {
+    fallos   []time.Time
+    bloqueos int
+}
+
+func NewStore() *Store {
+    return &Store{
+        usuarios:    make(map[int]models.Usuario),
+        cursos:      make(map[int]models.Curso),
+        historial:   make(map[int][]models.CambioEstadoCurso),
+        loginFallos: make(map[int]loginFallos),
+    }
+}
+
+// Usuarios devuelve el repositorio de usuarios sobre este almacenamiento
+func (s *Store) Usuarios() *UsuarioRepository {
+    return &UsuarioRepository{store: s}
+}
+
+// Cursos devuelve el repositorio de cursos sobre este almacenamiento
+func (s *Store) Cursos() *CursoRepository {
+    return &CursoRepository{store: s}
+}
+
+// Los repositorios en memoria cumplen los mismos contratos que los de Postgres
+var (
+    _ services.UsuarioRepository = (*UsuarioRepository)(nil)
+    _ services.CursoRepository   = (*CursoRepository)(nil)
+)
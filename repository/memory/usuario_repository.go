@@ -0,0 +1,400 @@
+package memory
+
+import (
+    "cursos-api/models"
+    "cursos-api/repository"
+    "errors"
+    "sort"
+    "strings"
+    "time"
+)
+
+type UsuarioRepository struct {
+    store *Store
+}
+
+// Create crea un nuevo usuario
+func (r *UsuarioRepository) Create(usuario *models.Usuario) error {
+    s := r.store
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    if s.emailEnUso(usuario.Email, 0) {
+        return errors.New("el email ya está registrado")
+    }
+
+    now := time.Now()
+    s.usuarioID++
+    usuario.ID = s.usuarioID
+    usuario.Activo = true
+    usuario.EmailVerificado = false
+    usuario.TOTPHabilitado = false
+    usuario.CreatedAt = now
+    usuario.UpdatedAt = now
+
+    s.usuarios[usuario.ID] = *usuario
+    return nil
+}
+
+// FindByEmail busca un usuario por email sin distinguir mayúsculas
+func (r *UsuarioRepository) FindByEmail(email string) (*models.Usuario, error) {
+    s := r.store
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+
+    for _, usuario := range s.usuarios {
+        if strings.EqualFold(usuario.Email, email) && usuario.DeletedAt == nil {
+            return &usuario, nil
+        }
+    }
+
+    return nil, errors.New("usuario no encontrado")
+}
+
+// FindByID busca un usuario por ID
+func (r *UsuarioRepository) FindByID(id int) (*models.Usuario, error) {
+    s := r.store
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+
+    usuario, ok := s.usuarioVigente(id)
+    if !ok {
+        return nil, errors.New("usuario no encontrado")
+    }
+
+    return &usuario, nil
+}
+
+// FindEliminado busca un usuario eliminado pendiente de purga (sin el hash
+// de la contraseña)
+func (r *UsuarioRepository) FindEliminado(id int) (*models.Usuario, error) {
+    s := r.store
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+
+    usuario, ok := s.usuarios[id]
+    if !ok || usuario.DeletedAt == nil {
+        return nil, errors.New("usuario eliminado no encontrado")
+    }
+
+    usuario.PasswordHash = ""
+    return &usuario, nil
+}
+
+// GetAll obtiene todos los usuarios (sin el hash de la contraseña)
+func (r *UsuarioRepository) GetAll() ([]models.Usuario, error) {
+    return r.listar(false), nil
+}
+
+// listar devuelve los usuarios vigentes o los eliminados, sin el hash de la
+// contraseña y del más reciente al más antiguo
+func (r *UsuarioRepository) listar(eliminados bool) []models.Usuario {
+    s := r.store
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+
+    var usuarios []models.Usuario
+    for _, usuario := range s.usuarios {
+        if (usuario.DeletedAt != nil) != eliminados {
+            continue
+        }
+        usuario.PasswordHash = ""
+        usuarios = append(usuarios, usuario)
+    }
+
+    sort.Slice(usuarios, func(i, j int) bool {
+        return masReciente(usuarios[i].CreatedAt, usuarios[i].ID, usuarios[j].CreatedAt, usuarios[j].ID)
+    })
+
+    return usuarios
+}
+
+// Update actualiza nombre, email y rol de un usuario
+func (r *UsuarioRepository) Update(id int, usuario *models.Usuario) error {
+    s := r.store
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    actual, ok := s.usuarioVigente(id)
+    if !ok {
+        return errors.New("usuario no encontrado")
+    }
+
+    if s.emailEnUso(usuario.Email, id) {
+        return errors.New("el email ya está registrado")
+    }
+
+    // Cambiar el email anula su verificación
+    if !strings.EqualFold(actual.Email, usuario.Email) {
+        actual.EmailVerificado = false
+    }
+
+    actual.Nombre = usuario.Nombre
+    actual.Email = usuario.Email
+    actual.Rol = usuario.Rol
+    actual.UpdatedAt = time.Now()
+    s.usuarios[id] = actual
+
+    usuario.ID = id
+    usuario.EmailVerificado = actual.EmailVerificado
+    usuario.UpdatedAt = actual.UpdatedAt
+    return nil
+}
+
+// Delete elimina lógicamente un usuario y sus cursos vigentes, con la misma
+// marca de borrado para restaurarlos juntos
+func (r *UsuarioRepository) Delete(id int) error {
+    s := r.store
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    usuario, ok := s.usuarioVigente(id)
+    if !ok {
+        return errors.New("usuario no encontrado")
+    }
+
+    now := time.Now()
+    usuario.DeletedAt = &now
+    s.usuarios[id] = usuario
+    for cursoID, curso := range s.cursos {
+        if curso.InstructorID == id && curso.DeletedAt == nil {
+            curso.DeletedAt = &now
+            s.cursos[cursoID] = curso
+        }
+    }
+
+    return nil
+}
+
+// Restore restaura un usuario eliminado y los cursos que se eliminaron con él
+func (r *UsuarioRepository) Restore(id int) error {
+    s := r.store
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    usuario, ok := s.usuarios[id]
+    if !ok || usuario.DeletedAt == nil {
+        return errors.New("usuario eliminado no encontrado")
+    }
+
+    if s.emailEnUso(usuario.Email, id) {
+        return errors.New("el email ya está registrado")
+    }
+
+    deletedAt := *usuario.DeletedAt
+    usuario.DeletedAt = nil
+    usuario.UpdatedAt = time.Now()
+    s.usuarios[id] = usuario
+    for cursoID, curso := range s.cursos {
+        if curso.InstructorID == id && curso.DeletedAt != nil && curso.DeletedAt.Equal(deletedAt) {
+            curso.DeletedAt = nil
+            s.cursos[cursoID] = curso
+        }
+    }
+
+    return nil
+}
+
+// Purgar borra definitivamente los usuarios eliminados antes de la fecha
+// indicada y, en cascada, sus cursos
+func (r *UsuarioRepository) Purgar(antes time.Time) (int64, error) {
+    s := r.store
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    var purgados int64
+    for id, usuario := range s.usuarios {
+        if usuario.DeletedAt != nil && usuario.DeletedAt.Before(antes) {
+            s.borrarUsuario(id)
+            purgados++
+        }
+    }
+
+    return purgados, nil
+}
+
+// borrarUsuario borra un usuario y, en cascada, sus cursos. En el historial
+// de estados de los demás cursos sus cambios quedan sin usuario. Requiere
+// tener el lock tomado.
+func (s *Store) borrarUsuario(id int) {
+    delete(s.usuarios, id)
+    delete(s.loginFallos, id)
+    for cursoID, curso := range s.cursos {
+        if curso.InstructorID == id {
+            delete(s.cursos, cursoID)
+            delete(s.historial, cursoID)
+        }
+    }
+    for _, cambios := range s.historial {
+        for i := range cambios {
+            if cambios[i].UsuarioID != nil && *cambios[i].UsuarioID == id {
+                cambios[i].UsuarioID = nil
+            }
+        }
+    }
+}
+
+// Search busca usuarios por nombre o email, rol y estado. Con Eliminados
+// busca entre los eliminados en lugar de entre los vigentes.
+func (r *UsuarioRepository) Search(filtro models.UsuarioFiltro) ([]models.Usuario, error) {
+    usuarios := r.listar(filtro.Eliminados)
+
+    query := strings.ToLower(filtro.Query)
+    var resultado []models.Usuario
+    for _, usuario := range usuarios {
+        if query != "" && !strings.Contains(strings.ToLower(usuario.Nombre), query) &&
+            !strings.Contains(strings.ToLower(usuario.Email), query) {
+            continue
+        }
+        if filtro.Rol != "" && usuario.Rol != filtro.Rol {
+            continue
+        }
+        if filtro.Activo != nil && usuario.Activo != *filtro.Activo {
+            continue
+        }
+        resultado = append(resultado, usuario)
+    }
+
+    return resultado, nil
+}
+
+// SetActivo habilita o deshabilita un usuario
+func (r *UsuarioRepository) SetActivo(id int, activo bool) error {
+    s := r.store
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    usuario, ok := s.usuarioVigente(id)
+    if !ok {
+        return errors.New("usuario no encontrado")
+    }
+
+    usuario.Activo = activo
+    usuario.UpdatedAt = time.Now()
+    s.usuarios[id] = usuario
+    return nil
+}
+
+// UpdatePassword actualiza la contraseña de un usuario
+func (r *UsuarioRepository) UpdatePassword(id int, newPasswordHash string) error {
+    s := r.store
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    usuario, ok := s.usuarioVigente(id)
+    if !ok {
+        return errors.New("usuario no encontrado")
+    }
+
+    usuario.PasswordHash = newPasswordHash
+    usuario.UpdatedAt = time.Now()
+    s.usuarios[id] = usuario
+    return nil
+}
+
+// MarcarEmailVerificado marca el email de un usuario como verificado
+func (r *UsuarioRepository) MarcarEmailVerificado(id int) error {
+    s := r.store
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    usuario, ok := s.usuarioVigente(id)
+    if !ok {
+        return errors.New("usuario no encontrado")
+    }
+
+    usuario.EmailVerificado = true
+    usuario.UpdatedAt = time.Now()
+    s.usuarios[id] = usuario
+    return nil
+}
+
+// RegistrarLoginFallido anota un intento fallido de login y devuelve los fallos
+// dentro de la ventana y los bloqueos consecutivos vigentes, ya descontado el
+// decaimiento. Los fallos anteriores a la ventana se descartan.
+func (r *UsuarioRepository) RegistrarLoginFallido(id int, ventana, decaimiento time.Duration) (int, int, error) {
+    s := r.store
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    usuario, ok := s.usuarioVigente(id)
+    if !ok {
+        return 0, 0, errors.New("usuario no encontrado")
+    }
+
+    now := time.Now()
+    limite := now.Add(-ventana)
+    registro := s.loginFallos[id]
+
+    vigentes := registro.fallos[:0]
+    for _, fallo := range registro.fallos {
+        if fallo.After(limite) {
+            vigentes = append(vigentes, fallo)
+        }
+    }
+    registro.fallos = append(vigentes, now)
+    s.loginFallos[id] = registro
+
+    return len(registro.fallos), repository.BloqueosVigentes(registro.bloqueos, usuario.LoginBloqueadoHasta, now, decaimiento), nil
+}
+
+// BloquearLogin bloquea el login del usuario hasta la fecha indicada, fija el
+// nivel de bloqueos consecutivos y descarta los fallos acumulados
+func (r *UsuarioRepository) BloquearLogin(id int, hasta time.Time, bloqueos int) error {
+    s := r.store
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    usuario, ok := s.usuarioVigente(id)
+    if !ok {
+        return errors.New("usuario no encontrado")
+    }
+
+    usuario.LoginBloqueadoHasta = &hasta
+    s.usuarios[id] = usuario
+    s.loginFallos[id] = loginFallos{bloqueos: bloqueos}
+    return nil
+}
+
+// ResetLoginFallidos borra los fallos y bloqueos tras un login correcto
+func (r *UsuarioRepository) ResetLoginFallidos(id int) error {
+    s := r.store
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    usuario, ok := s.usuarios[id]
+    if !ok {
+        return nil
+    }
+
+    usuario.LoginBloqueadoHasta = nil
+    s.usuarios[id] = usuario
+    delete(s.loginFallos, id)
+    return nil
+}
+
+// usuarioVigente devuelve el usuario si existe y no está eliminado. Requiere
+// tener el lock tomado.
+func (s *Store) usuarioVigente(id int) (models.Usuario, bool) {
+    usuario, ok := s.usuarios[id]
+    return usuario, ok && usuario.DeletedAt == nil
+}
+
+// emailEnUso indica si otro usuario vigente distinto de exceptoID tiene el
+// email, sin distinguir mayúsculas. Requiere tener el lock tomado.
+func (s *Store) emailEnUso(email string, exceptoID int) bool {
+    for id, usuario := range s.usuarios {
+        if id != exceptoID && strings.EqualFold(usuario.Email, email) && usuario.DeletedAt == nil {
+            return true
+        }
+    }
+    return false
+}
+
+// masReciente ordena por fecha de creación descendente y, a igual fecha, por ID
+func masReciente(a time.Time, aID int, b time.Time, bID int) bool {
+    if !a.Equal(b) {
+        return a.After(b)
+    }
+    return aID > bID
+}
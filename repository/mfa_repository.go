@@ -0,0 +1,210 @@
+package repository
+
+import (
+    "cursos-api/models"
+    "database/sql"
+    "errors"
+    "time"
+)
+
+type MFARepository struct {
+    db Querier
+}
+
+func NewMFARepository(db Querier) *MFARepository {
+    return &MFARepository{db: db}
+}
+
+// GetConfigTOTP obtiene el estado TOTP de un usuario
+func (r *MFARepository) GetConfigTOTP(usuarioID int) (*models.ConfigTOTP, error) {
+    query := `
+        SELECT id, COALESCE(totp_secret, ''), totp_habilitado, totp_ultimo_paso
+        FROM usuarios
+        WHERE id = $1 AND deleted_at IS NULL
+    `
+
+    config := &models.ConfigTOTP{}
+    err := r.db.QueryRow(query, usuarioID).Scan(
+        &config.UsuarioID,
+        &config.Secret,
+        &config.Habilitado,
+        &config.UltimoPaso,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, errors.New("usuario no encontrado")
+    }
+
+    return config, err
+}
+
+// IniciarTOTP guarda el secreto de un alta TOTP pendiente de confirmar
+func (r *MFARepository) IniciarTOTP(usuarioID int, secret string) error {
+    query := `
+        UPDATE usuarios
+        SET totp_secret = $1, totp_ultimo_paso = 0, updated_at = $2
+        WHERE id = $3 AND NOT totp_habilitado
+    `
+
+    result, err := r.db.Exec(query, secret, time.Now(), usuarioID)
+    if err != nil {
+        return err
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+
+    if rowsAffected == 0 {
+        return errors.New("la autenticación en dos pasos ya está activada")
+    }
+
+    return nil
+}
+
+// HabilitarTOTP activa el TOTP del usuario con el paso del código que lo
+// confirmó y reemplaza sus códigos de recuperación
+func (r *MFARepository) HabilitarTOTP(usuarioID int, paso int64, codigoHashes []string) error {
+    tx, err := begin(r.db)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    result, err := tx.Exec(`
+        UPDATE usuarios
+        SET totp_habilitado = true, totp_ultimo_paso = $1, updated_at = $2
+        WHERE id = $3 AND totp_secret IS NOT NULL AND NOT totp_habilitado
+    `, paso, time.Now(), usuarioID)
+    if err != nil {
+        return err
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+
+    if rowsAffected == 0 {
+        return errors.New("no hay un alta de autenticación en dos pasos pendiente")
+    }
+
+    if err := reemplazarCodigos(tx, usuarioID, codigoHashes); err != nil {
+        return err
+    }
+
+    return tx.Commit()
+}
+
+// DeshabilitarTOTP desactiva el TOTP del usuario y borra su secreto y sus
+// códigos de recuperación
+func (r *MFARepository) DeshabilitarTOTP(usuarioID int) error {
+    tx, err := begin(r.db)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    _, err = tx.Exec(`
+        UPDATE usuarios
+        SET totp_secret = NULL, totp_habilitado = false, totp_ultimo_paso = 0, updated_at = $1
+        WHERE id = $2
+    `, time.Now(), usuarioID)
+    if err != nil {
+        return err
+    }
+
+    if err := reemplazarCodigos(tx, usuarioID, nil); err != nil {
+        return err
+    }
+
+    return tx.Commit()
+}
+
+// RegistrarPasoTOTP acepta un paso de tiempo solo si es posterior al último
+// aceptado, de modo que cada código TOTP se use una sola vez
+func (r *MFARepository) RegistrarPasoTOTP(usuarioID int, paso int64) (bool, error) {
+    result, err := r.db.Exec(`
+        UPDATE usuarios
+        SET totp_ultimo_paso = $1
+        WHERE id = $2 AND totp_ultimo_paso < $1
+    `, paso, usuarioID)
+    if err != nil {
+        return false, err
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return false, err
+    }
+
+    return rowsAffected == 1, nil
+}
+
+// ReemplazarCodigosRecuperacion sustituye todos los códigos de recuperación
+// del usuario por los indicados
+func (r *MFARepository) ReemplazarCodigosRecuperacion(usuarioID int, codigoHashes []string) error {
+    tx, err := begin(r.db)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    if err := reemplazarCodigos(tx, usuarioID, codigoHashes); err != nil {
+        return err
+    }
+
+    return tx.Commit()
+}
+
+// ConsumirCodigoRecuperacion marca como usado un código sin usar del usuario.
+// Devuelve false si el código no existe o ya se usó.
+func (r *MFARepository) ConsumirCodigoRecuperacion(usuarioID int, codigoHash string) (bool, error) {
+    result, err := r.db.Exec(`
+        UPDATE codigos_recuperacion
+        SET usado_at = $1
+        WHERE usuario_id = $2 AND codigo_hash = $3 AND usado_at IS NULL
+    `, time.Now(), usuarioID, codigoHash)
+    if err != nil {
+        return false, err
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return false, err
+    }
+
+    return rowsAffected == 1, nil
+}
+
+// ContarCodigosRecuperacion cuenta los códigos de recuperación sin usar
+func (r *MFARepository) ContarCodigosRecuperacion(usuarioID int) (int, error) {
+    var total int
+    err := r.db.QueryRow(`
+        SELECT COUNT(*) FROM codigos_recuperacion
+        WHERE usuario_id = $1 AND usado_at IS NULL
+    `, usuarioID).Scan(&total)
+
+    return total, err
+}
+
+// reemplazarCodigos borra los códigos del usuario e inserta los nuevos
+func reemplazarCodigos(tx Querier, usuarioID int, codigoHashes []string) error {
+    if _, err := tx.Exec(`DELETE FROM codigos_recuperacion WHERE usuario_id = $1`, usuarioID); err != nil {
+        return err
+    }
+
+    now := time.Now()
+    for _, hash := range codigoHashes {
+        _, err := tx.Exec(`
+            INSERT INTO codigos_recuperacion (usuario_id, codigo_hash, created_at)
+            VALUES ($1, $2, $3)
+        `, usuarioID, hash, now)
+        if err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
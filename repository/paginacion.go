@@ -0,0 +1,43 @@
+package repository
+
+import (
+    "encoding/base64"
+    "encoding/json"
+    "errors"
+)
+
+// Cursor es la posición en un listado paginado por clave (keyset): el valor
+// del campo de ordenación y el id de la última fila entregada. Viaja al
+// cliente como una cadena opaca.
+type Cursor struct {
+    Orden string `json:"o"`
+    Desc  bool   `json:"d,omitempty"`
+    Valor string `json:"v"`
+    ID    int    `json:"id"`
+}
+
+// EncodeCursor codifica el cursor para entregarlo como next_cursor
+func EncodeCursor(c Cursor) string {
+    data, _ := json.Marshal(c)
+    return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor decodifica un cursor y comprueba que corresponde al mismo
+// orden del listado que se pide
+func DecodeCursor(valor, orden string, desc bool) (*Cursor, error) {
+    data, err := base64.RawURLEncoding.DecodeString(valor)
+    if err != nil {
+        return nil, errors.New("cursor inválido")
+    }
+
+    cursor := &Cursor{}
+    if err := json.Unmarshal(data, cursor); err != nil || cursor.ID <= 0 {
+        return nil, errors.New("cursor inválido")
+    }
+
+    if cursor.Orden != orden || cursor.Desc != desc {
+        return nil, errors.New("el cursor corresponde a otro orden del listado")
+    }
+
+    return cursor, nil
+}
@@ -0,0 +1,290 @@
+package repository
+
+import (
+    "cursos-api/models"
+    "database/sql"
+    "errors"
+    "time"
+)
+
+type PreguntaRepository struct {
+    db Querier
+}
+
+func NewPreguntaRepository(db Querier) *PreguntaRepository {
+    return &PreguntaRepository{db: db}
+}
+
+// Create crea una pregunta junto con sus opciones
+func (r *PreguntaRepository) Create(pregunta *models.Pregunta) error {
+    tx, err := begin(r.db)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    query := `
+        INSERT INTO preguntas (evaluacion_id, enunciado, tipo, peso, orden, respuesta_booleana, respuesta_numerica, tolerancia, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+        RETURNING id, created_at
+    `
+
+    err = tx.QueryRow(
+        query,
+        pregunta.EvaluacionID,
+        pregunta.Enunciado,
+        pregunta.Tipo,
+        pregunta.Peso,
+        pregunta.Orden,
+        pregunta.RespuestaBooleana,
+        pregunta.RespuestaNumerica,
+        pregunta.Tolerancia,
+        time.Now(),
+    ).Scan(&pregunta.ID, &pregunta.CreatedAt)
+    if err != nil {
+        return err
+    }
+
+    if err := insertOpciones(tx, pregunta); err != nil {
+        return err
+    }
+
+    return tx.Commit()
+}
+
+// FindByID busca una pregunta por ID con sus opciones
+func (r *PreguntaRepository) FindByID(id int) (*models.Pregunta, error) {
+    query := `
+        SELECT id, evaluacion_id, enunciado, tipo, peso, orden, respuesta_booleana, respuesta_numerica, tolerancia, created_at
+        FROM preguntas
+        WHERE id = $1
+    `
+
+    pregunta := &models.Pregunta{}
+    err := r.db.QueryRow(query, id).Scan(
+        &pregunta.ID,
+        &pregunta.EvaluacionID,
+        &pregunta.Enunciado,
+        &pregunta.Tipo,
+        &pregunta.Peso,
+        &pregunta.Orden,
+        &pregunta.RespuestaBooleana,
+        &pregunta.RespuestaNumerica,
+        &pregunta.Tolerancia,
+        &pregunta.CreatedAt,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, errors.New("pregunta no encontrada")
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    opciones, err := r.getOpciones(pregunta.ID)
+    if err != nil {
+        return nil, err
+    }
+    pregunta.Opciones = opciones
+
+    return pregunta, nil
+}
+
+// GetByEvaluacion obtiene las preguntas de una evaluación con sus opciones
+func (r *PreguntaRepository) GetByEvaluacion(evaluacionID int) ([]models.Pregunta, error) {
+    query := `
+        SELECT id, evaluacion_id, enunciado, tipo, peso, orden, respuesta_booleana, respuesta_numerica, tolerancia, created_at
+        FROM preguntas
+        WHERE evaluacion_id = $1
+        ORDER BY orden ASC, id ASC
+    `
+
+    rows, err := r.db.Query(query, evaluacionID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var preguntas []models.Pregunta
+    indices := make(map[int]int)
+    for rows.Next() {
+        var pregunta models.Pregunta
+        err := rows.Scan(
+            &pregunta.ID,
+            &pregunta.EvaluacionID,
+            &pregunta.Enunciado,
+            &pregunta.Tipo,
+            &pregunta.Peso,
+            &pregunta.Orden,
+            &pregunta.RespuestaBooleana,
+            &pregunta.RespuestaNumerica,
+            &pregunta.Tolerancia,
+            &pregunta.CreatedAt,
+        )
+        if err != nil {
+            return nil, err
+        }
+        indices[pregunta.ID] = len(preguntas)
+        preguntas = append(preguntas, pregunta)
+    }
+
+    if len(preguntas) == 0 {
+        return preguntas, nil
+    }
+
+    // Cargar todas las opciones de la evaluación en una sola consulta
+    opcionesQuery := `
+        SELECT o.id, o.pregunta_id, o.texto, o.es_correcta, o.orden
+        FROM opciones_pregunta o
+        INNER JOIN preguntas p ON o.pregunta_id = p.id
+        WHERE p.evaluacion_id = $1
+        ORDER BY o.orden ASC, o.id ASC
+    `
+
+    opcionesRows, err := r.db.Query(opcionesQuery, evaluacionID)
+    if err != nil {
+        return nil, err
+    }
+    defer opcionesRows.Close()
+
+    for opcionesRows.Next() {
+        var opcion models.OpcionPregunta
+        err := opcionesRows.Scan(&opcion.ID, &opcion.PreguntaID, &opcion.Texto, &opcion.EsCorrecta, &opcion.Orden)
+        if err != nil {
+            return nil, err
+        }
+        i := indices[opcion.PreguntaID]
+        preguntas[i].Opciones = append(preguntas[i].Opciones, opcion)
+    }
+
+    return preguntas, nil
+}
+
+// CountByEvaluacion cuenta las preguntas de una evaluación
+func (r *PreguntaRepository) CountByEvaluacion(evaluacionID int) (int, error) {
+    query := `SELECT COUNT(*) FROM preguntas WHERE evaluacion_id = $1`
+
+    var count int
+    err := r.db.QueryRow(query, evaluacionID).Scan(&count)
+
+    return count, err
+}
+
+// Update actualiza una pregunta y reemplaza sus opciones
+func (r *PreguntaRepository) Update(id int, pregunta *models.Pregunta) error {
+    tx, err := begin(r.db)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    query := `
+        UPDATE preguntas
+        SET enunciado = $1, tipo = $2, peso = $3, orden = $4, respuesta_booleana = $5, respuesta_numerica = $6, tolerancia = $7
+        WHERE id = $8
+        RETURNING evaluacion_id, created_at
+    `
+
+    err = tx.QueryRow(
+        query,
+        pregunta.Enunciado,
+        pregunta.Tipo,
+        pregunta.Peso,
+        pregunta.Orden,
+        pregunta.RespuestaBooleana,
+        pregunta.RespuestaNumerica,
+        pregunta.Tolerancia,
+        id,
+    ).Scan(&pregunta.EvaluacionID, &pregunta.CreatedAt)
+
+    if err == sql.ErrNoRows {
+        return errors.New("pregunta no encontrada")
+    }
+    if err != nil {
+        return err
+    }
+
+    pregunta.ID = id
+
+    _, err = tx.Exec(`DELETE FROM opciones_pregunta WHERE pregunta_id = $1`, id)
+    if err != nil {
+        return err
+    }
+
+    if err := insertOpciones(tx, pregunta); err != nil {
+        return err
+    }
+
+    return tx.Commit()
+}
+
+// Delete elimina una pregunta
+func (r *PreguntaRepository) Delete(id int) error {
+    query := `DELETE FROM preguntas WHERE id = $1`
+
+    result, err := r.db.Exec(query, id)
+    if err != nil {
+        return err
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+
+    if rowsAffected == 0 {
+        return errors.New("pregunta no encontrada")
+    }
+
+    return nil
+}
+
+// getOpciones obtiene las opciones de una pregunta
+func (r *PreguntaRepository) getOpciones(preguntaID int) ([]models.OpcionPregunta, error) {
+    query := `
+        SELECT id, pregunta_id, texto, es_correcta, orden
+        FROM opciones_pregunta
+        WHERE pregunta_id = $1
+        ORDER BY orden ASC, id ASC
+    `
+
+    rows, err := r.db.Query(query, preguntaID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var opciones []models.OpcionPregunta
+    for rows.Next() {
+        var opcion models.OpcionPregunta
+        err := rows.Scan(&opcion.ID, &opcion.PreguntaID, &opcion.Texto, &opcion.EsCorrecta, &opcion.Orden)
+        if err != nil {
+            return nil, err
+        }
+        opciones = append(opciones, opcion)
+    }
+
+    return opciones, nil
+}
+
+// insertOpciones inserta las opciones de una pregunta dentro de una transacción
+func insertOpciones(tx Querier, pregunta *models.Pregunta) error {
+    query := `
+        INSERT INTO opciones_pregunta (pregunta_id, texto, es_correcta, orden)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id
+    `
+
+    for i := range pregunta.Opciones {
+        opcion := &pregunta.Opciones[i]
+        opcion.PreguntaID = pregunta.ID
+        opcion.Orden = i + 1
+
+        err := tx.QueryRow(query, opcion.PreguntaID, opcion.Texto, opcion.EsCorrecta, opcion.Orden).Scan(&opcion.ID)
+        if err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+    "cursos-api/models"
+    "database/sql"
+    "errors"
+    "time"
+)
+
+type ProgresoRepository struct {
+    db Querier
+}
+
+func NewProgresoRepository(db Querier) *ProgresoRepository {
+    return &ProgresoRepository{db: db}
+}
+
+// MarcarLeccion registra una lección como completada o no completada y, en la
+// misma transacción, recalcula el progreso de la inscripción ponderado por la
+// duración de cada lección. Solo se informa 100% cuando todas las lecciones
+// están completadas; en ese momento la inscripción pasa a "completado".
+func (r *ProgresoRepository) MarcarLeccion(inscripcionID, leccionID int, completada bool) (*models.ProgresoLeccion, *models.Inscripcion, error) {
+    tx, err := begin(r.db)
+    if err != nil {
+        return nil, nil, err
+    }
+    defer tx.Rollback()
+
+    // Bloquear la inscripción para serializar recálculos concurrentes
+    inscripcion := &models.Inscripcion{}
+    err = tx.QueryRow(`
+        SELECT id, usuario_id, curso_id, fecha_inscripcion, estado, progreso_porcentaje
+        FROM inscripciones
+        WHERE id = $1
+        FOR UPDATE
+    `, inscripcionID).Scan(
+        &inscripcion.ID,
+        &inscripcion.UsuarioID,
+        &inscripcion.CursoID,
+        &inscripcion.FechaInscripcion,
+        &inscripcion.Estado,
+        &inscripcion.ProgresoPorcentaje,
+    )
+    if err == sql.ErrNoRows {
+        return nil, nil, errors.New("inscripción no encontrada")
+    }
+    if err != nil {
+        return nil, nil, err
+    }
+
+    // El estado se vuelve a comprobar con la fila ya bloqueada
+    if inscripcion.Estado != "activo" {
+        return nil, nil, errors.New("la inscripción está '" + inscripcion.Estado + "' y no admite cambios de progreso")
+    }
+
+    var fechaCompletado *time.Time
+    if completada {
+        now := time.Now()
+        fechaCompletado = &now
+    }
+
+    progreso := &models.ProgresoLeccion{}
+    err = tx.QueryRow(`
+        INSERT INTO progreso_lecciones (inscripcion_id, leccion_id, completada, fecha_completado)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (inscripcion_id, leccion_id)
+        DO UPDATE SET completada = EXCLUDED.completada, fecha_completado = EXCLUDED.fecha_completado
+        RETURNING id, inscripcion_id, leccion_id, completada, fecha_completado
+    `, inscripcionID, leccionID, completada, fechaCompletado).Scan(
+        &progreso.ID,
+        &progreso.InscripcionID,
+        &progreso.LeccionID,
+        &progreso.Completada,
+        &progreso.FechaCompletado,
+    )
+    if err != nil {
+        return nil, nil, err
+    }
+
+    err = tx.QueryRow(queryRecalcularProgreso("i.id = $1"), inscripcionID).Scan(&inscripcion.ProgresoPorcentaje, &inscripcion.Estado)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    if err := tx.Commit(); err != nil {
+        return nil, nil, err
+    }
+
+    return progreso, inscripcion, nil
+}
+
+// RecalcularCurso recalcula el progreso de las inscripciones activas y
+// completadas de un curso tras agregar o quitar lecciones. Una inscripción
+// activa que queda con todo completado pasa a "completado"; una completada
+// conserva su estado aunque su porcentaje baje.
+func (r *ProgresoRepository) RecalcularCurso(cursoID int) error {
+    tx, err := begin(r.db)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    // Bloquear antes de calcular para no pisar un MarcarLeccion concurrente
+    _, err = tx.Exec(`
+        SELECT id FROM inscripciones
+        WHERE curso_id = $1 AND estado IN ('activo', 'completado')
+        ORDER BY id
+        FOR UPDATE
+    `, cursoID)
+    if err != nil {
+        return err
+    }
+
+    _, err = tx.Exec(queryRecalcularProgreso("i.curso_id = $1 AND i.estado IN ('activo', 'completado')"), cursoID)
+    if err != nil {
+        return err
+    }
+
+    return tx.Commit()
+}
+
+// queryRecalcularProgreso arma la consulta que recalcula el progreso de las
+// inscripciones que cumplen la condición (sobre el alias i), ponderado por la
+// duración de cada lección, y devuelve su porcentaje y estado. Si ninguna
+// lección tiene duración se pondera por cantidad de lecciones. Las lecciones
+// sin duración no pesan en el porcentaje, así que mientras quede alguna
+// pendiente el progreso se limita a 99.99. Las filas deben estar bloqueadas.
+func queryRecalcularProgreso(condicion string) string {
+    return `
+        WITH totales AS (
+            SELECT i.id,
+                   COALESCE(SUM(l.duracion_minutos), 0) AS minutos_total,
+                   COALESCE(SUM(l.duracion_minutos) FILTER (WHERE p.completada), 0) AS minutos_completados,
+                   COUNT(l.id) AS lecciones_total,
+                   COUNT(l.id) FILTER (WHERE p.completada) AS lecciones_completadas
+            FROM inscripciones i
+            LEFT JOIN lecciones l ON l.curso_id = i.curso_id
+            LEFT JOIN progreso_lecciones p ON p.leccion_id = l.id AND p.inscripcion_id = i.id
+            WHERE ` + condicion + `
+            GROUP BY i.id
+        )
+        UPDATE inscripciones
+        SET progreso_porcentaje = calculo.porcentaje,
+            estado = CASE WHEN calculo.porcentaje >= 100 AND estado = 'activo' THEN 'completado' ELSE estado END
+        FROM (
+            SELECT id,
+                   CASE
+                       WHEN lecciones_total > 0 AND lecciones_completadas = lecciones_total THEN 100
+                       WHEN minutos_total > 0 THEN LEAST(ROUND(100.0 * minutos_completados / minutos_total, 2), 99.99)
+                       WHEN lecciones_total > 0 THEN ROUND(100.0 * lecciones_completadas / lecciones_total, 2)
+                       ELSE 0
+                   END AS porcentaje
+            FROM totales
+        ) AS calculo
+        WHERE inscripciones.id = calculo.id
+        RETURNING progreso_porcentaje, estado
+    `
+}
+
+// GetByInscripcion obtiene el progreso registrado de una inscripción
+func (r *ProgresoRepository) GetByInscripcion(inscripcionID int) ([]models.ProgresoLeccion, error) {
+    query := `
+        SELECT p.id, p.inscripcion_id, p.leccion_id, p.completada, p.fecha_completado
+        FROM progreso_lecciones p
+        INNER JOIN lecciones l ON p.leccion_id = l.id
+        WHERE p.inscripcion_id = $1
+        ORDER BY l.orden ASC
+    `
+
+    rows, err := r.db.Query(query, inscripcionID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var progresos []models.ProgresoLeccion
+    for rows.Next() {
+        var progreso models.ProgresoLeccion
+        err := rows.Scan(
+            &progreso.ID,
+            &progreso.InscripcionID,
+            &progreso.LeccionID,
+            &progreso.Completada,
+            &progreso.FechaCompletado,
+        )
+        if err != nil {
+            return nil, err
+        }
+        progresos = append(progresos, progreso)
+    }
+
+    return progresos, nil
+}
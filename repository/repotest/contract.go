@@ -0,0 +1,1138 @@
+// Package repotest contiene la batería de contrato que toda implementación de
+// los repositorios de usuarios y cursos debe cumplir, sea Postgres o memoria.
+//
+// Uso desde una prueba:
+//
+//	repotest.RunUsuarioRepositoryContract(t, factory)
+//	repotest.RunCursoRepositoryContract(t, factory)
+//
+// Para Postgres la factory puede abrir una transacción por caso, construir
+// los repositorios sobre ella (repository.NewUsuarioRepository(tx)) y
+// registrar el rollback con t.Cleanup.
+package repotest
+
+import (
+    "cursos-api/models"
+    "cursos-api/repository"
+    "cursos-api/services"
+    "encoding/base64"
+    "strings"
+    "testing"
+    "time"
+)
+
+// Repos son los repositorios bajo prueba; deben compartir almacenamiento
+type Repos struct {
+    Usuarios services.UsuarioRepository
+    Cursos   services.CursoRepository
+}
+
+// Factory crea repositorios vacíos. Se invoca una vez por caso.
+type Factory func(t *testing.T) Repos
+
+// RunUsuarioRepositoryContract verifica el comportamiento de UsuarioRepository
+func RunUsuarioRepositoryContract(t *testing.T, factory Factory) {
+    t.Run("Create asigna ID y se puede buscar por ID y email", func(t *testing.T) {
+        repos := factory(t)
+        usuario := crearUsuario(t, repos, "ana@example.com", "alumno")
+
+        if usuario.ID == 0 || usuario.CreatedAt.IsZero() || usuario.UpdatedAt.IsZero() {
+            t.Fatalf("Create no asignó ID y fechas: %+v", usuario)
+        }
+        if !usuario.Activo {
+            t.Fatal("Create no dejó al usuario activo")
+        }
+
+        porID, err := repos.Usuarios.FindByID(usuario.ID)
+        if err != nil {
+            t.Fatalf("FindByID: %v", err)
+        }
+        if porID.Email != usuario.Email || porID.Nombre != usuario.Nombre || porID.Rol != usuario.Rol ||
+            porID.PasswordHash != usuario.PasswordHash {
+            t.Fatalf("FindByID devolvió %+v, se esperaba %+v", porID, usuario)
+        }
+
+        porEmail, err := repos.Usuarios.FindByEmail(usuario.Email)
+        if err != nil {
+            t.Fatalf("FindByEmail: %v", err)
+        }
+        if porEmail.ID != usuario.ID {
+            t.Fatalf("FindByEmail devolvió el usuario %d, se esperaba %d", porEmail.ID, usuario.ID)
+        }
+    })
+
+    t.Run("Create rechaza emails duplicados", func(t *testing.T) {
+        repos := factory(t)
+        crearUsuario(t, repos, "ana@example.com", "alumno")
+
+        duplicado := &models.Usuario{Nombre: "Otra", Email: "ana@example.com", PasswordHash: "hash", Rol: "alumno"}
+        if err := repos.Usuarios.Create(duplicado); err == nil {
+            t.Fatal("Create aceptó un email duplicado")
+        }
+
+        // El email no distingue mayúsculas
+        duplicado = &models.Usuario{Nombre: "Otra", Email: "Ana@Example.com", PasswordHash: "hash", Rol: "alumno"}
+        if err := repos.Usuarios.Create(duplicado); err == nil {
+            t.Fatal("Create aceptó un email duplicado con otras mayúsculas")
+        }
+        if _, err := repos.Usuarios.FindByEmail("ANA@example.com"); err != nil {
+            t.Fatalf("FindByEmail con otras mayúsculas: %v", err)
+        }
+    })
+
+    t.Run("Find falla si el usuario no existe", func(t *testing.T) {
+        repos := factory(t)
+
+        if _, err := repos.Usuarios.FindByID(999999); err == nil {
+            t.Fatal("FindByID no devolvió error")
+        }
+        if _, err := repos.Usuarios.FindByEmail("nadie@example.com"); err == nil {
+            t.Fatal("FindByEmail no devolvió error")
+        }
+    })
+
+    t.Run("GetAll lista los usuarios sin el hash de la contraseña", func(t *testing.T) {
+        repos := factory(t)
+        a := crearUsuario(t, repos, "a@example.com", "alumno")
+        b := crearUsuario(t, repos, "b@example.com", "instructor")
+
+        usuarios, err := repos.Usuarios.GetAll()
+        if err != nil {
+            t.Fatalf("GetAll: %v", err)
+        }
+
+        encontrados := 0
+        for _, usuario := range usuarios {
+            if usuario.PasswordHash != "" {
+                t.Fatalf("GetAll expuso el hash del usuario %d", usuario.ID)
+            }
+            if usuario.ID == a.ID || usuario.ID == b.ID {
+                encontrados++
+            }
+        }
+        if encontrados != 2 {
+            t.Fatalf("GetAll devolvió %d de los 2 usuarios creados", encontrados)
+        }
+    })
+
+    t.Run("Update modifica nombre, email y rol pero no la contraseña", func(t *testing.T) {
+        repos := factory(t)
+        usuario := crearUsuario(t, repos, "ana@example.com", "alumno")
+
+        cambios := &models.Usuario{Nombre: "Ana María", Email: "anamaria@example.com", Rol: "instructor"}
+        if err := repos.Usuarios.Update(usuario.ID, cambios); err != nil {
+            t.Fatalf("Update: %v", err)
+        }
+        if cambios.ID != usuario.ID {
+            t.Fatalf("Update no asignó el ID: %d", cambios.ID)
+        }
+
+        actualizado, err := repos.Usuarios.FindByID(usuario.ID)
+        if err != nil {
+            t.Fatalf("FindByID: %v", err)
+        }
+        if actualizado.Nombre != "Ana María" || actualizado.Email != "anamaria@example.com" || actualizado.Rol != "instructor" {
+            t.Fatalf("Update no aplicó los cambios: %+v", actualizado)
+        }
+        if actualizado.PasswordHash != usuario.PasswordHash {
+            t.Fatal("Update modificó la contraseña")
+        }
+    })
+
+    t.Run("Update falla con un email de otro usuario o un usuario inexistente", func(t *testing.T) {
+        repos := factory(t)
+        crearUsuario(t, repos, "a@example.com", "alumno")
+        b := crearUsuario(t, repos, "b@example.com", "alumno")
+
+        if err := repos.Usuarios.Update(b.ID, &models.Usuario{Nombre: "B", Email: "a@example.com", Rol: "alumno"}); err == nil {
+            t.Fatal("Update aceptó un email en uso")
+        }
+
+        repos = factory(t)
+        if err := repos.Usuarios.Update(999999, &models.Usuario{Nombre: "X", Email: "x@example.com", Rol: "alumno"}); err == nil {
+            t.Fatal("Update de un usuario inexistente no devolvió error")
+        }
+    })
+
+    t.Run("UpdatePassword reemplaza el hash", func(t *testing.T) {
+        repos := factory(t)
+        usuario := crearUsuario(t, repos, "ana@example.com", "alumno")
+
+        if err := repos.Usuarios.UpdatePassword(usuario.ID, "nuevo-hash"); err != nil {
+            t.Fatalf("UpdatePassword: %v", err)
+        }
+
+        actualizado, err := repos.Usuarios.FindByID(usuario.ID)
+        if err != nil {
+            t.Fatalf("FindByID: %v", err)
+        }
+        if actualizado.PasswordHash != "nuevo-hash" {
+            t.Fatalf("UpdatePassword no cambió el hash: %q", actualizado.PasswordHash)
+        }
+
+        if err := repos.Usuarios.UpdatePassword(999999, "hash"); err == nil {
+            t.Fatal("UpdatePassword de un usuario inexistente no devolvió error")
+        }
+    })
+
+    t.Run("Search filtra por texto, rol y estado", func(t *testing.T) {
+        repos := factory(t)
+        ana := crearUsuario(t, repos, "ana@example.com", "alumno")
+        juan := crearUsuario(t, repos, "juan@example.com", "instructor")
+        luis := crearUsuario(t, repos, "luis@example.com", "alumno")
+
+        if err := repos.Usuarios.SetActivo(luis.ID, false); err != nil {
+            t.Fatalf("SetActivo: %v", err)
+        }
+
+        porTexto, err := repos.Usuarios.Search(models.UsuarioFiltro{Query: "JUAN"})
+        if err != nil {
+            t.Fatalf("Search: %v", err)
+        }
+        verificarUsuarios(t, "Search por texto", porTexto, []int{juan.ID}, []int{ana.ID, luis.ID})
+
+        alumnos, err := repos.Usuarios.Search(models.UsuarioFiltro{Rol: "alumno"})
+        if err != nil {
+            t.Fatalf("Search: %v", err)
+        }
+        verificarUsuarios(t, "Search por rol", alumnos, []int{ana.ID, luis.ID}, []int{juan.ID})
+
+        inactivo := false
+        deshabilitados, err := repos.Usuarios.Search(models.UsuarioFiltro{Activo: &inactivo})
+        if err != nil {
+            t.Fatalf("Search: %v", err)
+        }
+        verificarUsuarios(t, "Search por estado", deshabilitados, []int{luis.ID}, []int{ana.ID, juan.ID})
+    })
+
+    t.Run("SetActivo deshabilita y habilita usuarios", func(t *testing.T) {
+        repos := factory(t)
+        usuario := crearUsuario(t, repos, "ana@example.com", "alumno")
+
+        if err := repos.Usuarios.SetActivo(usuario.ID, false); err != nil {
+            t.Fatalf("SetActivo: %v", err)
+        }
+        actualizado, err := repos.Usuarios.FindByID(usuario.ID)
+        if err != nil {
+            t.Fatalf("FindByID: %v", err)
+        }
+        if actualizado.Activo {
+            t.Fatal("SetActivo(false) no deshabilitó al usuario")
+        }
+
+        if err := repos.Usuarios.SetActivo(999999, true); err == nil {
+            t.Fatal("SetActivo de un usuario inexistente no devolvió error")
+        }
+    })
+
+    t.Run("MarcarEmailVerificado verifica el email y cambiarlo lo anula", func(t *testing.T) {
+        repos := factory(t)
+        usuario := crearUsuario(t, repos, "ana@example.com", "alumno")
+        if usuario.EmailVerificado {
+            t.Fatal("un usuario nuevo no debe tener el email verificado")
+        }
+
+        if err := repos.Usuarios.MarcarEmailVerificado(usuario.ID); err != nil {
+            t.Fatalf("MarcarEmailVerificado: %v", err)
+        }
+
+        // Cambiar solo el nombre conserva la verificación
+        cambios := &models.Usuario{Nombre: "Ana María", Email: "ana@example.com", Rol: "alumno"}
+        if err := repos.Usuarios.Update(usuario.ID, cambios); err != nil {
+            t.Fatalf("Update: %v", err)
+        }
+        actualizado, err := repos.Usuarios.FindByID(usuario.ID)
+        if err != nil {
+            t.Fatalf("FindByID: %v", err)
+        }
+        if !actualizado.EmailVerificado {
+            t.Fatal("Update sin cambio de email anuló la verificación")
+        }
+
+        cambios = &models.Usuario{Nombre: "Ana María", Email: "anamaria@example.com", Rol: "alumno"}
+        if err := repos.Usuarios.Update(usuario.ID, cambios); err != nil {
+            t.Fatalf("Update: %v", err)
+        }
+        actualizado, err = repos.Usuarios.FindByID(usuario.ID)
+        if err != nil {
+            t.Fatalf("FindByID: %v", err)
+        }
+        if actualizado.EmailVerificado || cambios.EmailVerificado {
+            t.Fatal("cambiar el email no anuló la verificación")
+        }
+
+        if err := repos.Usuarios.MarcarEmailVerificado(999999); err == nil {
+            t.Fatal("MarcarEmailVerificado de un usuario inexistente no devolvió error")
+        }
+    })
+
+    t.Run("RegistrarLoginFallido cuenta fallos y BloquearLogin los convierte en bloqueo", func(t *testing.T) {
+        repos := factory(t)
+        usuario := crearUsuario(t, repos, "ana@example.com", "alumno")
+
+        for esperado := 1; esperado <= 3; esperado++ {
+            intentos, bloqueos, err := repos.Usuarios.RegistrarLoginFallido(usuario.ID, time.Hour, time.Hour)
+            if err != nil {
+                t.Fatalf("RegistrarLoginFallido: %v", err)
+            }
+            if intentos != esperado || bloqueos != 0 {
+                t.Fatalf("RegistrarLoginFallido = (%d, %d), se esperaba (%d, 0)", intentos, bloqueos, esperado)
+            }
+        }
+
+        hasta := time.Now().Add(time.Minute).Truncate(time.Second)
+        if err := repos.Usuarios.BloquearLogin(usuario.ID, hasta, 1); err != nil {
+            t.Fatalf("BloquearLogin: %v", err)
+        }
+        bloqueado, err := repos.Usuarios.FindByEmail(usuario.Email)
+        if err != nil {
+            t.Fatalf("FindByEmail: %v", err)
+        }
+        if bloqueado.LoginBloqueadoHasta == nil || !bloqueado.LoginBloqueadoHasta.Equal(hasta) {
+            t.Fatalf("BloquearLogin no guardó el fin del bloqueo: %v", bloqueado.LoginBloqueadoHasta)
+        }
+
+        // El bloqueo reinicia los fallos y cuenta para el siguiente
+        intentos, bloqueos, err := repos.Usuarios.RegistrarLoginFallido(usuario.ID, time.Hour, time.Hour)
+        if err != nil {
+            t.Fatalf("RegistrarLoginFallido: %v", err)
+        }
+        if intentos != 1 || bloqueos != 1 {
+            t.Fatalf("RegistrarLoginFallido tras el bloqueo = (%d, %d), se esperaba (1, 1)", intentos, bloqueos)
+        }
+
+        if err := repos.Usuarios.ResetLoginFallidos(usuario.ID); err != nil {
+            t.Fatalf("ResetLoginFallidos: %v", err)
+        }
+        limpio, err := repos.Usuarios.FindByID(usuario.ID)
+        if err != nil {
+            t.Fatalf("FindByID: %v", err)
+        }
+        if limpio.LoginBloqueadoHasta != nil {
+            t.Fatal("ResetLoginFallidos no quitó el bloqueo")
+        }
+        intentos, bloqueos, err = repos.Usuarios.RegistrarLoginFallido(usuario.ID, time.Hour, time.Hour)
+        if err != nil || intentos != 1 || bloqueos != 0 {
+            t.Fatalf("RegistrarLoginFallido tras el reset = (%d, %d, %v), se esperaba (1, 0)", intentos, bloqueos, err)
+        }
+
+        if _, _, err := repos.Usuarios.RegistrarLoginFallido(999999, time.Hour, time.Hour); err == nil {
+            t.Fatal("RegistrarLoginFallido de un usuario inexistente no devolvió error")
+        }
+    })
+
+    t.Run("RegistrarLoginFallido cuenta solo los fallos dentro de la ventana", func(t *testing.T) {
+        repos := factory(t)
+        usuario := crearUsuario(t, repos, "ana@example.com", "alumno")
+        ventana := 200 * time.Millisecond
+
+        // Cada fallo sale de la ventana por separado: el segundo sigue dentro
+        // cuando el primero ya caducó
+        for i, esperado := range []int{1, 2, 2} {
+            if i > 0 {
+                time.Sleep(120 * time.Millisecond)
+            }
+            intentos, _, err := repos.Usuarios.RegistrarLoginFallido(usuario.ID, ventana, time.Hour)
+            if err != nil {
+                t.Fatalf("RegistrarLoginFallido: %v", err)
+            }
+            if intentos != esperado {
+                t.Fatalf("fallo %d: RegistrarLoginFallido contó %d fallos, se esperaban %d", i+1, intentos, esperado)
+            }
+        }
+    })
+
+    t.Run("Los bloqueos consecutivos decaen con el tiempo", func(t *testing.T) {
+        repos := factory(t)
+        usuario := crearUsuario(t, repos, "ana@example.com", "alumno")
+
+        casos := []struct {
+            desde    time.Duration
+            esperado int
+        }{
+            {desde: -time.Minute, esperado: 3},
+            {desde: 30 * time.Minute, esperado: 3},
+            {desde: 90 * time.Minute, esperado: 2},
+            {desde: 10 * time.Hour, esperado: 0},
+        }
+
+        for _, caso := range casos {
+            hasta := time.Now().Add(-caso.desde)
+            if err := repos.Usuarios.BloquearLogin(usuario.ID, hasta, 3); err != nil {
+                t.Fatalf("BloquearLogin: %v", err)
+            }
+            _, bloqueos, err := repos.Usuarios.RegistrarLoginFallido(usuario.ID, time.Hour, time.Hour)
+            if err != nil {
+                t.Fatalf("RegistrarLoginFallido: %v", err)
+            }
+            if bloqueos != caso.esperado {
+                t.Fatalf("%v después del bloqueo quedan %d bloqueos, se esperaban %d", caso.desde, bloqueos, caso.esperado)
+            }
+        }
+    })
+
+    t.Run("Delete elimina el usuario y sus cursos", func(t *testing.T) {
+        repos := factory(t)
+        instructor := crearUsuario(t, repos, "juan@example.com", "instructor")
+        curso := crearCurso(t, repos, instructor.ID, "Go", true)
+
+        if err := repos.Usuarios.Delete(instructor.ID); err != nil {
+            t.Fatalf("Delete: %v", err)
+        }
+        if _, err := repos.Usuarios.FindByID(instructor.ID); err == nil {
+            t.Fatal("el usuario sigue existiendo después de Delete")
+        }
+        if _, err := repos.Usuarios.FindByEmail("juan@example.com"); err == nil {
+            t.Fatal("FindByEmail encontró un usuario eliminado")
+        }
+        if _, err := repos.Cursos.FindByID(curso.ID); err == nil {
+            t.Fatal("los cursos del usuario no se eliminaron en cascada")
+        }
+        if err := repos.Usuarios.Delete(instructor.ID); err == nil {
+            t.Fatal("Delete de un usuario inexistente no devolvió error")
+        }
+    })
+
+    t.Run("Delete es lógico y libera el email", func(t *testing.T) {
+        repos := factory(t)
+        ana := crearUsuario(t, repos, "ana@example.com", "alumno")
+        luis := crearUsuario(t, repos, "luis@example.com", "alumno")
+
+        if err := repos.Usuarios.Delete(ana.ID); err != nil {
+            t.Fatalf("Delete: %v", err)
+        }
+
+        eliminada, err := repos.Usuarios.FindEliminado(ana.ID)
+        if err != nil {
+            t.Fatalf("FindEliminado: %v", err)
+        }
+        if eliminada.Email != "ana@example.com" || eliminada.DeletedAt == nil {
+            t.Fatalf("FindEliminado = %+v", eliminada)
+        }
+        if _, err := repos.Usuarios.FindEliminado(luis.ID); err == nil {
+            t.Fatal("FindEliminado encontró un usuario vigente")
+        }
+
+        todos, err := repos.Usuarios.GetAll()
+        if err != nil {
+            t.Fatalf("GetAll: %v", err)
+        }
+        verificarUsuarios(t, "GetAll", todos, []int{luis.ID}, []int{ana.ID})
+
+        eliminados, err := repos.Usuarios.Search(models.UsuarioFiltro{Eliminados: true})
+        if err != nil {
+            t.Fatalf("Search: %v", err)
+        }
+        verificarUsuarios(t, "Search de eliminados", eliminados, []int{ana.ID}, []int{luis.ID})
+
+        if err := repos.Usuarios.SetActivo(ana.ID, false); err == nil {
+            t.Fatal("SetActivo de un usuario eliminado no devolvió error")
+        }
+
+        // El email de la cuenta eliminada queda libre
+        crearUsuario(t, repos, "ana@example.com", "alumno")
+    })
+
+    t.Run("Restore recupera el usuario con los cursos eliminados con él", func(t *testing.T) {
+        repos := factory(t)
+        juan := crearUsuario(t, repos, "juan@example.com", "instructor")
+        vigente := crearCurso(t, repos, juan.ID, "Go", true)
+        eliminadoAntes := crearCurso(t, repos, juan.ID, "SQL", true)
+
+        if err := repos.Cursos.Delete(eliminadoAntes.ID); err != nil {
+            t.Fatalf("Delete: %v", err)
+        }
+        if err := repos.Usuarios.Delete(juan.ID); err != nil {
+            t.Fatalf("Delete: %v", err)
+        }
+        if err := repos.Usuarios.Restore(juan.ID); err != nil {
+            t.Fatalf("Restore: %v", err)
+        }
+
+        if _, err := repos.Usuarios.FindByID(juan.ID); err != nil {
+            t.Fatalf("FindByID tras Restore: %v", err)
+        }
+        if _, err := repos.Cursos.FindByID(vigente.ID); err != nil {
+            t.Fatalf("el curso eliminado con el usuario no se restauró: %v", err)
+        }
+        if _, err := repos.Cursos.FindByID(eliminadoAntes.ID); err == nil {
+            t.Fatal("Restore recuperó un curso eliminado antes que el usuario")
+        }
+        if err := repos.Usuarios.Restore(juan.ID); err == nil {
+            t.Fatal("Restore de un usuario vigente no devolvió error")
+        }
+    })
+
+    t.Run("Purgar borra los usuarios eliminados antes de la fecha", func(t *testing.T) {
+        repos := factory(t)
+        juan := crearUsuario(t, repos, "juan@example.com", "instructor")
+        ana := crearUsuario(t, repos, "ana@example.com", "alumno")
+        curso := crearCurso(t, repos, juan.ID, "Go", true)
+
+        antes := time.Now().Add(-time.Minute)
+        if err := repos.Usuarios.Delete(juan.ID); err != nil {
+            t.Fatalf("Delete: %v", err)
+        }
+
+        purgados, err := repos.Usuarios.Purgar(antes)
+        if err != nil || purgados != 0 {
+            t.Fatalf("Purgar antes del borrado = %d, %v", purgados, err)
+        }
+
+        purgados, err = repos.Usuarios.Purgar(time.Now().Add(time.Minute))
+        if err != nil || purgados != 1 {
+            t.Fatalf("Purgar = %d, %v", purgados, err)
+        }
+        if _, err := repos.Usuarios.FindEliminado(juan.ID); err == nil {
+            t.Fatal("el usuario sigue pendiente de purga")
+        }
+        if _, err := repos.Cursos.FindEliminado(curso.ID); err == nil {
+            t.Fatal("los cursos del usuario no se purgaron en cascada")
+        }
+        if _, err := repos.Usuarios.FindByID(ana.ID); err != nil {
+            t.Fatalf("Purgar borró un usuario vigente: %v", err)
+        }
+    })
+}
+
+// RunCursoRepositoryContract verifica el comportamiento de CursoRepository
+func RunCursoRepositoryContract(t *testing.T, factory Factory) {
+    t.Run("Create asigna ID y FindByID incluye al instructor", func(t *testing.T) {
+        repos := factory(t)
+        instructor := crearUsuario(t, repos, "juan@example.com", "instructor")
+        curso := crearCurso(t, repos, instructor.ID, "Introducción a Go", true)
+
+        if curso.ID == 0 || curso.CreatedAt.IsZero() {
+            t.Fatalf("Create no asignó ID y fechas: %+v", curso)
+        }
+
+        encontrado, err := repos.Cursos.FindByID(curso.ID)
+        if err != nil {
+            t.Fatalf("FindByID: %v", err)
+        }
+        if encontrado.Nombre != curso.Nombre || encontrado.DuracionHoras != curso.DuracionHoras || !encontrado.Activo {
+            t.Fatalf("FindByID devolvió %+v, se esperaba %+v", encontrado, curso)
+        }
+        if encontrado.Instructor == nil || encontrado.Instructor.ID != instructor.ID ||
+            encontrado.Instructor.Email != instructor.Email {
+            t.Fatalf("FindByID no cargó el instructor: %+v", encontrado.Instructor)
+        }
+        if encontrado.Instructor.PasswordHash != "" {
+            t.Fatal("FindByID expuso el hash del instructor")
+        }
+    })
+
+    t.Run("Create falla si el instructor no existe", func(t *testing.T) {
+        repos := factory(t)
+        curso := &models.Curso{Nombre: "Huérfano", DuracionHoras: 10, InstructorID: 999999, Activo: true}
+        if err := repos.Cursos.Create(curso); err == nil {
+            t.Fatal("Create aceptó un instructor inexistente")
+        }
+    })
+
+    t.Run("FindByID falla si el curso no existe", func(t *testing.T) {
+        repos := factory(t)
+        if _, err := repos.Cursos.FindByID(999999); err == nil {
+            t.Fatal("FindByID no devolvió error")
+        }
+    })
+
+    t.Run("GetAll, GetByInstructor y GetActivos filtran correctamente", func(t *testing.T) {
+        repos := factory(t)
+        juan := crearUsuario(t, repos, "juan@example.com", "instructor")
+        maria := crearUsuario(t, repos, "maria@example.com", "instructor")
+        goActivo := crearCurso(t, repos, juan.ID, "Go", true)
+        reactInactivo := crearCurso(t, repos, juan.ID, "React", false)
+        sqlActivo := crearCurso(t, repos, maria.ID, "SQL", true)
+
+        todos, err := repos.Cursos.GetAll()
+        if err != nil {
+            t.Fatalf("GetAll: %v", err)
+        }
+        verificarIDs(t, "GetAll", todos, []int{goActivo.ID, reactInactivo.ID, sqlActivo.ID}, nil)
+
+        deJuan, err := repos.Cursos.GetByInstructor(juan.ID)
+        if err != nil {
+            t.Fatalf("GetByInstructor: %v", err)
+        }
+        verificarIDs(t, "GetByInstructor", deJuan, []int{goActivo.ID, reactInactivo.ID}, []int{sqlActivo.ID})
+
+        activos, err := repos.Cursos.GetActivos()
+        if err != nil {
+            t.Fatalf("GetActivos: %v", err)
+        }
+        verificarIDs(t, "GetActivos", activos, []int{goActivo.ID, sqlActivo.ID}, []int{reactInactivo.ID})
+    })
+
+    t.Run("List filtra, ordena y pagina por cursor", func(t *testing.T) {
+        repos := factory(t)
+        juan := crearUsuario(t, repos, "juan@example.com", "instructor")
+        maria := crearUsuario(t, repos, "maria@example.com", "instructor")
+        var deJuan []int
+        for _, nombre := range []string{"E", "A", "D", "B", "C"} {
+            deJuan = append(deJuan, crearCurso(t, repos, juan.ID, nombre, true).ID)
+        }
+        inactivo := crearCurso(t, repos, juan.ID, "F", false)
+        deMaria := crearCurso(t, repos, maria.ID, "G", true)
+
+        filtro := models.CursoFiltro{InstructorID: juan.ID, SoloCatalogo: true, Orden: "nombre", Limite: 2}
+        var nombres []string
+        for pagina := 0; ; pagina++ {
+            resultado, err := repos.Cursos.List(filtro)
+            if err != nil {
+                t.Fatalf("List: %v", err)
+            }
+            if resultado.Total != len(deJuan) {
+                t.Fatalf("List devolvió total %d, se esperaba %d", resultado.Total, len(deJuan))
+            }
+            verificarIDs(t, "List", resultado.Items, nil, []int{inactivo.ID, deMaria.ID})
+            for _, curso := range resultado.Items {
+                nombres = append(nombres, curso.Nombre)
+            }
+            if resultado.NextCursor == "" {
+                break
+            }
+            if pagina > len(deJuan) {
+                t.Fatal("List no terminó de paginar")
+            }
+            filtro.Cursor = resultado.NextCursor
+        }
+        if got := strings.Join(nombres, ""); got != "ABCDE" {
+            t.Fatalf("List recorrió %q, se esperaba ABCDE", got)
+        }
+
+        desc, err := repos.Cursos.List(models.CursoFiltro{Orden: "nombre", Desc: true, Limite: 1})
+        if err != nil {
+            t.Fatalf("List: %v", err)
+        }
+        if len(desc.Items) != 1 || desc.Items[0].ID != deMaria.ID || desc.Total != 7 {
+            t.Fatalf("List descendente devolvió %+v", desc)
+        }
+
+        filtro.Desc = true
+        if _, err := repos.Cursos.List(filtro); err == nil {
+            t.Fatal("List aceptó un cursor de otro orden")
+        }
+    })
+
+    t.Run("List pagina por cursor sin saltar ni repetir empates", func(t *testing.T) {
+        repos := factory(t)
+        juan := crearUsuario(t, repos, "juan@example.com", "instructor")
+
+        // Todos duran lo mismo: el orden lo decide el id
+        var ids []int
+        for _, nombre := range []string{"A", "B", "C", "D", "E"} {
+            ids = append(ids, crearCurso(t, repos, juan.ID, nombre, true).ID)
+        }
+
+        for _, limite := range []int{1, 2, 5} {
+            asc := recorrerCursos(t, repos, models.CursoFiltro{Orden: "duracion_horas", Limite: limite})
+            if !mismosIDs(asc, ids) {
+                t.Fatalf("límite %d: List ascendente recorrió %v, se esperaba %v", limite, asc, ids)
+            }
+
+            desc := recorrerCursos(t, repos, models.CursoFiltro{Orden: "duracion_horas", Desc: true, Limite: limite})
+            if !mismosIDs(desc, invertir(ids)) {
+                t.Fatalf("límite %d: List descendente recorrió %v, se esperaba %v", limite, desc, invertir(ids))
+            }
+
+            porFecha := recorrerCursos(t, repos, models.CursoFiltro{Orden: "created_at", Limite: limite})
+            if !mismosIDs(porFecha, ids) {
+                t.Fatalf("límite %d: List por created_at recorrió %v, se esperaba %v", limite, porFecha, ids)
+            }
+        }
+    })
+
+    t.Run("List desempata por id los cursos con el mismo created_at", func(t *testing.T) {
+        repos := factory(t)
+        juan := crearUsuario(t, repos, "juan@example.com", "instructor")
+        var cursos []*models.Curso
+        for _, nombre := range []string{"A", "B", "C"} {
+            cursos = append(cursos, crearCurso(t, repos, juan.ID, nombre, true))
+        }
+        medio, err := repos.Cursos.FindByID(cursos[1].ID)
+        if err != nil {
+            t.Fatalf("FindByID: %v", err)
+        }
+
+        // Un cursor con el created_at del curso del medio y un id menor debe
+        // devolver ese curso: comparten el valor de ordenación y lo supera en id
+        cursor := func(id int, desc bool) string {
+            return repository.EncodeCursor(repository.Cursor{
+                Orden: "created_at",
+                Desc:  desc,
+                Valor: medio.CreatedAt.Format(time.RFC3339Nano),
+                ID:    id,
+            })
+        }
+
+        casos := []struct {
+            nombre   string
+            desc     bool
+            id       int
+            esperado []int
+        }{
+            {"ascendente antes del empate", false, medio.ID - 1, []int{cursos[1].ID, cursos[2].ID}},
+            {"ascendente en el empate", false, medio.ID, []int{cursos[2].ID}},
+            {"descendente antes del empate", true, medio.ID + 1, []int{cursos[1].ID, cursos[0].ID}},
+            {"descendente en el empate", true, medio.ID, []int{cursos[0].ID}},
+        }
+        for _, caso := range casos {
+            pagina, err := repos.Cursos.List(models.CursoFiltro{Orden: "created_at", Desc: caso.desc, Limite: 10, Cursor: cursor(caso.id, caso.desc)})
+            if err != nil {
+                t.Fatalf("%s: List: %v", caso.nombre, err)
+            }
+            var obtenidos []int
+            for _, curso := range pagina.Items {
+                obtenidos = append(obtenidos, curso.ID)
+            }
+            if !mismosIDs(obtenidos, caso.esperado) {
+                t.Fatalf("%s: List devolvió %v, se esperaba %v", caso.nombre, obtenidos, caso.esperado)
+            }
+        }
+    })
+
+    t.Run("List rechaza cursores inválidos, manipulados o de otro orden", func(t *testing.T) {
+        repos := factory(t)
+        juan := crearUsuario(t, repos, "juan@example.com", "instructor")
+        for _, nombre := range []string{"A", "B", "C"} {
+            crearCurso(t, repos, juan.ID, nombre, true)
+        }
+
+        primera, err := repos.Cursos.List(models.CursoFiltro{Orden: "created_at", Limite: 1})
+        if err != nil {
+            t.Fatalf("List: %v", err)
+        }
+        if primera.NextCursor == "" {
+            t.Fatal("List no devolvió next_cursor")
+        }
+
+        codificar := func(json string) string {
+            return base64.RawURLEncoding.EncodeToString([]byte(json))
+        }
+
+        invalidos := []struct {
+            nombre string
+            filtro models.CursoFiltro
+        }{
+            {"no es base64", models.CursoFiltro{Orden: "created_at", Limite: 1, Cursor: "no es un cursor!"}},
+            {"no es JSON", models.CursoFiltro{Orden: "created_at", Limite: 1, Cursor: codificar("cursor")}},
+            {"sin id", models.CursoFiltro{Orden: "created_at", Limite: 1, Cursor: codificar(`{"o":"created_at","v":"2024-01-01T00:00:00Z"}`)}},
+            {"fecha manipulada", models.CursoFiltro{Orden: "created_at", Limite: 1, Cursor: codificar(`{"o":"created_at","v":"ayer","id":1}`)}},
+            {"número manipulado", models.CursoFiltro{Orden: "duracion_horas", Limite: 1, Cursor: codificar(`{"o":"duracion_horas","v":"1; DROP TABLE cursos","id":1}`)}},
+            {"recortado", models.CursoFiltro{Orden: "created_at", Limite: 1, Cursor: primera.NextCursor[:len(primera.NextCursor)/2]}},
+            {"de otro campo", models.CursoFiltro{Orden: "nombre", Limite: 1, Cursor: primera.NextCursor}},
+            {"de otro sentido", models.CursoFiltro{Orden: "created_at", Desc: true, Limite: 1, Cursor: primera.NextCursor}},
+        }
+        for _, caso := range invalidos {
+            if _, err := repos.Cursos.List(caso.filtro); err == nil {
+                t.Fatalf("List aceptó un cursor %s", caso.nombre)
+            }
+        }
+
+        // El cursor original sigue siendo válido en su orden
+        if _, err := repos.Cursos.List(models.CursoFiltro{Orden: "created_at", Limite: 1, Cursor: primera.NextCursor}); err != nil {
+            t.Fatalf("List rechazó un cursor válido: %v", err)
+        }
+    })
+
+    t.Run("Search encuentra sin acentos y respeta la visibilidad", func(t *testing.T) {
+        repos := factory(t)
+        juan := crearUsuario(t, repos, "juan@example.com", "instructor")
+        encontrado := crearCurso(t, repos, juan.ID, "Programación en Go", true)
+        inactivo := crearCurso(t, repos, juan.ID, "Programación avanzada", false)
+        otro := crearCurso(t, repos, juan.ID, "Cocina", true)
+
+        resultado, err := repos.Cursos.Search(models.CursoBusqueda{Texto: "programacion", SoloCatalogo: true, Limite: 10})
+        if err != nil {
+            t.Fatalf("Search: %v", err)
+        }
+        if resultado.Total != 1 || len(resultado.Items) != 1 || resultado.Items[0].ID != encontrado.ID {
+            t.Fatalf("Search devolvió %+v, se esperaba solo el curso %d (excluidos %d y %d)",
+                resultado, encontrado.ID, inactivo.ID, otro.ID)
+        }
+        if !strings.Contains(resultado.Items[0].NombreResaltado, "<mark>Programación</mark>") {
+            t.Fatalf("Search no resaltó la coincidencia: %q", resultado.Items[0].NombreResaltado)
+        }
+
+        todos, err := repos.Cursos.Search(models.CursoBusqueda{Texto: "programación", Limite: 10})
+        if err != nil {
+            t.Fatalf("Search: %v", err)
+        }
+        if todos.Total != 2 {
+            t.Fatalf("Search sin restricciones devolvió %d cursos, se esperaban 2", todos.Total)
+        }
+    })
+
+    t.Run("Update modifica el curso y falla si no existe", func(t *testing.T) {
+        repos := factory(t)
+        juan := crearUsuario(t, repos, "juan@example.com", "instructor")
+        maria := crearUsuario(t, repos, "maria@example.com", "instructor")
+        curso := crearCurso(t, repos, juan.ID, "Go", true)
+
+        cambios := &models.Curso{Nombre: "Go avanzado", Descripcion: "Concurrencia", DuracionHoras: 80, InstructorID: maria.ID}
+        if err := repos.Cursos.Update(curso.ID, cambios); err != nil {
+            t.Fatalf("Update: %v", err)
+        }
+
+        actualizado, err := repos.Cursos.FindByID(curso.ID)
+        if err != nil {
+            t.Fatalf("FindByID: %v", err)
+        }
+        if actualizado.Nombre != "Go avanzado" || actualizado.DuracionHoras != 80 || actualizado.Activo ||
+            actualizado.InstructorID != maria.ID {
+            t.Fatalf("Update no aplicó los cambios: %+v", actualizado)
+        }
+
+        if err := repos.Cursos.Update(999999, cambios); err == nil {
+            t.Fatal("Update de un curso inexistente no devolvió error")
+        }
+    })
+
+    t.Run("VerifyInstructor comprueba la propiedad del curso", func(t *testing.T) {
+        repos := factory(t)
+        juan := crearUsuario(t, repos, "juan@example.com", "instructor")
+        maria := crearUsuario(t, repos, "maria@example.com", "instructor")
+        curso := crearCurso(t, repos, juan.ID, "Go", true)
+
+        esDueno, err := repos.Cursos.VerifyInstructor(curso.ID, juan.ID)
+        if err != nil || !esDueno {
+            t.Fatalf("VerifyInstructor(dueño) = %v, %v", esDueno, err)
+        }
+
+        esDueno, err = repos.Cursos.VerifyInstructor(curso.ID, maria.ID)
+        if err != nil || esDueno {
+            t.Fatalf("VerifyInstructor(otro instructor) = %v, %v", esDueno, err)
+        }
+
+        esDueno, err = repos.Cursos.VerifyInstructor(999999, juan.ID)
+        if err != nil || esDueno {
+            t.Fatalf("VerifyInstructor(curso inexistente) = %v, %v", esDueno, err)
+        }
+    })
+
+    t.Run("UpdateInstructor reasigna el curso", func(t *testing.T) {
+        repos := factory(t)
+        juan := crearUsuario(t, repos, "juan@example.com", "instructor")
+        maria := crearUsuario(t, repos, "maria@example.com", "instructor")
+        curso := crearCurso(t, repos, juan.ID, "Go", true)
+
+        if err := repos.Cursos.UpdateInstructor(curso.ID, maria.ID); err != nil {
+            t.Fatalf("UpdateInstructor: %v", err)
+        }
+
+        esDueno, err := repos.Cursos.VerifyInstructor(curso.ID, maria.ID)
+        if err != nil || !esDueno {
+            t.Fatalf("VerifyInstructor(nuevo instructor) = %v, %v", esDueno, err)
+        }
+
+        if err := repos.Cursos.UpdateInstructor(999999, maria.ID); err == nil {
+            t.Fatal("UpdateInstructor de un curso inexistente no devolvió error")
+        }
+    })
+
+    t.Run("SetBloqueo oculta el curso de los activos", func(t *testing.T) {
+        repos := factory(t)
+        juan := crearUsuario(t, repos, "juan@example.com", "instructor")
+        curso := crearCurso(t, repos, juan.ID, "Go", true)
+
+        if err := repos.Cursos.SetBloqueo(curso.ID, true, "contenido inapropiado"); err != nil {
+            t.Fatalf("SetBloqueo: %v", err)
+        }
+
+        bloqueado, err := repos.Cursos.FindByID(curso.ID)
+        if err != nil {
+            t.Fatalf("FindByID: %v", err)
+        }
+        if !bloqueado.Bloqueado || bloqueado.MotivoBloqueo != "contenido inapropiado" || bloqueado.BloqueadoAt == nil {
+            t.Fatalf("SetBloqueo no registró el bloqueo: %+v", bloqueado)
+        }
+
+        activos, err := repos.Cursos.GetActivos()
+        if err != nil {
+            t.Fatalf("GetActivos: %v", err)
+        }
+        verificarIDs(t, "GetActivos", activos, nil, []int{curso.ID})
+
+        if err := repos.Cursos.SetBloqueo(curso.ID, false, ""); err != nil {
+            t.Fatalf("SetBloqueo: %v", err)
+        }
+        desbloqueado, err := repos.Cursos.FindByID(curso.ID)
+        if err != nil {
+            t.Fatalf("FindByID: %v", err)
+        }
+        if desbloqueado.Bloqueado || desbloqueado.BloqueadoAt != nil {
+            t.Fatalf("SetBloqueo(false) no levantó el bloqueo: %+v", desbloqueado)
+        }
+    })
+
+    t.Run("CambiarEstado sincroniza activo y anota el historial", func(t *testing.T) {
+        repos := factory(t)
+        juan := crearUsuario(t, repos, "juan@example.com", "instructor")
+        curso := &models.Curso{Nombre: "Go", Descripcion: "Curso de Go", DuracionHoras: 40, InstructorID: juan.ID, Estado: "borrador"}
+        if err := repos.Cursos.Create(curso); err != nil {
+            t.Fatalf("Create: %v", err)
+        }
+        if curso.Estado != "borrador" || curso.Activo {
+            t.Fatalf("Create no respetó el estado: %+v", curso)
+        }
+
+        for _, paso := range [][2]string{{"borrador", "en_revision"}, {"en_revision", "publicado"}} {
+            cambio := &models.CambioEstadoCurso{CursoID: curso.ID, EstadoAnterior: paso[0], EstadoNuevo: paso[1], UsuarioID: &juan.ID}
+            if err := repos.Cursos.CambiarEstado(cambio); err != nil {
+                t.Fatalf("CambiarEstado(%s → %s): %v", paso[0], paso[1], err)
+            }
+            if cambio.ID == 0 || cambio.CreatedAt.IsZero() {
+                t.Fatalf("CambiarEstado no completó el cambio: %+v", cambio)
+            }
+        }
+
+        publicado, err := repos.Cursos.FindByID(curso.ID)
+        if err != nil {
+            t.Fatalf("FindByID: %v", err)
+        }
+        if publicado.Estado != "publicado" || !publicado.Activo || publicado.PublicadoAt == nil {
+            t.Fatalf("CambiarEstado no publicó el curso: %+v", publicado)
+        }
+
+        // El estado leído ya no es el actual
+        obsoleto := &models.CambioEstadoCurso{CursoID: curso.ID, EstadoAnterior: "en_revision", EstadoNuevo: "borrador"}
+        if err := repos.Cursos.CambiarEstado(obsoleto); err == nil {
+            t.Fatal("CambiarEstado desde un estado obsoleto no devolvió error")
+        }
+
+        historial, err := repos.Cursos.GetHistorial(curso.ID)
+        if err != nil {
+            t.Fatalf("GetHistorial: %v", err)
+        }
+        if len(historial) != 2 || historial[0].EstadoNuevo != "en_revision" || historial[1].EstadoNuevo != "publicado" ||
+            historial[1].UsuarioID == nil || *historial[1].UsuarioID != juan.ID {
+            t.Fatalf("GetHistorial = %+v", historial)
+        }
+    })
+
+    t.Run("GetProgramados devuelve las programaciones vencidas", func(t *testing.T) {
+        repos := factory(t)
+        juan := crearUsuario(t, repos, "juan@example.com", "instructor")
+        publicar := crearCurso(t, repos, juan.ID, "Go", false)
+        retirar := crearCurso(t, repos, juan.ID, "SQL", true)
+        futuro := crearCurso(t, repos, juan.ID, "React", false)
+
+        ahora := time.Now()
+        pasado, despues := ahora.Add(-time.Minute), ahora.Add(time.Hour)
+        if err := repos.Cursos.Programar(publicar.ID, &pasado, nil); err != nil {
+            t.Fatalf("Programar: %v", err)
+        }
+        if err := repos.Cursos.Programar(retirar.ID, nil, &pasado); err != nil {
+            t.Fatalf("Programar: %v", err)
+        }
+        if err := repos.Cursos.Programar(futuro.ID, &despues, nil); err != nil {
+            t.Fatalf("Programar: %v", err)
+        }
+        if err := repos.Cursos.Programar(999999, &pasado, nil); err == nil {
+            t.Fatal("Programar de un curso inexistente no devolvió error")
+        }
+
+        programados, err := repos.Cursos.GetProgramados(ahora)
+        if err != nil {
+            t.Fatalf("GetProgramados: %v", err)
+        }
+        verificarIDs(t, "GetProgramados", programados, []int{publicar.ID, retirar.ID}, []int{futuro.ID})
+
+        // Publicar cancela la publicación programada
+        cambio := &models.CambioEstadoCurso{CursoID: publicar.ID, EstadoAnterior: "archivado", EstadoNuevo: "publicado"}
+        if err := repos.Cursos.CambiarEstado(cambio); err != nil {
+            t.Fatalf("CambiarEstado: %v", err)
+        }
+        programados, err = repos.Cursos.GetProgramados(ahora)
+        if err != nil {
+            t.Fatalf("GetProgramados: %v", err)
+        }
+        verificarIDs(t, "GetProgramados", programados, []int{retirar.ID}, []int{publicar.ID})
+    })
+
+    t.Run("Delete elimina el curso y falla si no existe", func(t *testing.T) {
+        repos := factory(t)
+        juan := crearUsuario(t, repos, "juan@example.com", "instructor")
+        curso := crearCurso(t, repos, juan.ID, "Go", true)
+
+        if err := repos.Cursos.Delete(curso.ID); err != nil {
+            t.Fatalf("Delete: %v", err)
+        }
+        if _, err := repos.Cursos.FindByID(curso.ID); err == nil {
+            t.Fatal("el curso sigue existiendo después de Delete")
+        }
+        if err := repos.Cursos.Delete(curso.ID); err == nil {
+            t.Fatal("Delete de un curso inexistente no devolvió error")
+        }
+    })
+
+    t.Run("Delete es lógico, Restore lo deshace y Purgar lo hace definitivo", func(t *testing.T) {
+        repos := factory(t)
+        juan := crearUsuario(t, repos, "juan@example.com", "instructor")
+        eliminado := crearCurso(t, repos, juan.ID, "Go", true)
+        vigente := crearCurso(t, repos, juan.ID, "SQL", true)
+
+        if err := repos.Cursos.Delete(eliminado.ID); err != nil {
+            t.Fatalf("Delete: %v", err)
+        }
+
+        activos, err := repos.Cursos.GetActivos()
+        if err != nil {
+            t.Fatalf("GetActivos: %v", err)
+        }
+        verificarIDs(t, "GetActivos", activos, []int{vigente.ID}, []int{eliminado.ID})
+
+        pagina, err := repos.Cursos.List(models.CursoFiltro{Eliminados: true, Orden: "created_at", Limite: 10})
+        if err != nil {
+            t.Fatalf("List: %v", err)
+        }
+        verificarIDs(t, "List de eliminados", pagina.Items, []int{eliminado.ID}, []int{vigente.ID})
+        if pagina.Total != 1 || pagina.Items[0].DeletedAt == nil {
+            t.Fatalf("List de eliminados = %+v", pagina)
+        }
+
+        if ok, _ := repos.Cursos.VerifyInstructor(eliminado.ID, juan.ID); ok {
+            t.Fatal("VerifyInstructor aceptó un curso eliminado")
+        }
+        if err := repos.Cursos.SetBloqueo(eliminado.ID, true, "spam"); err == nil {
+            t.Fatal("SetBloqueo de un curso eliminado no devolvió error")
+        }
+        if _, err := repos.Cursos.FindEliminado(vigente.ID); err == nil {
+            t.Fatal("FindEliminado encontró un curso vigente")
+        }
+
+        if err := repos.Cursos.Restore(eliminado.ID); err != nil {
+            t.Fatalf("Restore: %v", err)
+        }
+        if _, err := repos.Cursos.FindByID(eliminado.ID); err != nil {
+            t.Fatalf("FindByID tras Restore: %v", err)
+        }
+        if err := repos.Cursos.Restore(eliminado.ID); err == nil {
+            t.Fatal("Restore de un curso vigente no devolvió error")
+        }
+
+        if err := repos.Cursos.Delete(eliminado.ID); err != nil {
+            t.Fatalf("Delete: %v", err)
+        }
+        purgados, err := repos.Cursos.Purgar(time.Now().Add(time.Minute))
+        if err != nil || purgados != 1 {
+            t.Fatalf("Purgar = %d, %v", purgados, err)
+        }
+        if _, err := repos.Cursos.FindEliminado(eliminado.ID); err == nil {
+            t.Fatal("el curso sigue pendiente de purga")
+        }
+        if _, err := repos.Cursos.FindByID(vigente.ID); err != nil {
+            t.Fatalf("Purgar borró un curso vigente: %v", err)
+        }
+    })
+}
+
+func crearUsuario(t *testing.T, repos Repos, email, rol string) *models.Usuario {
+    t.Helper()
+
+    usuario := &models.Usuario{Nombre: "Usuario " + email, Email: email, PasswordHash: "hash-" + email, Rol: rol}
+    if err := repos.Usuarios.Create(usuario); err != nil {
+        t.Fatalf("crear usuario %s: %v", email, err)
+    }
+    return usuario
+}
+
+func crearCurso(t *testing.T, repos Repos, instructorID int, nombre string, activo bool) *models.Curso {
+    t.Helper()
+
+    curso := &models.Curso{Nombre: nombre, Descripcion: "Curso de " + nombre, DuracionHoras: 40, InstructorID: instructorID, Activo: activo}
+    if err := repos.Cursos.Create(curso); err != nil {
+        t.Fatalf("crear curso %s: %v", nombre, err)
+    }
+    return curso
+}
+
+// recorrerCursos pagina List hasta el final y devuelve los IDs en orden
+func recorrerCursos(t *testing.T, repos Repos, filtro models.CursoFiltro) []int {
+    t.Helper()
+
+    var ids []int
+    for pagina := 0; ; pagina++ {
+        resultado, err := repos.Cursos.List(filtro)
+        if err != nil {
+            t.Fatalf("List: %v", err)
+        }
+        for _, curso := range resultado.Items {
+            ids = append(ids, curso.ID)
+        }
+        if resultado.NextCursor == "" {
+            return ids
+        }
+        if pagina > resultado.Total {
+            t.Fatal("List no terminó de paginar")
+        }
+        filtro.Cursor = resultado.NextCursor
+    }
+}
+
+// mismosIDs indica si dos listas tienen los mismos IDs en el mismo orden
+func mismosIDs(a, b []int) bool {
+    if len(a) != len(b) {
+        return false
+    }
+    for i := range a {
+        if a[i] != b[i] {
+            return false
+        }
+    }
+    return true
+}
+
+// invertir devuelve una copia de la lista en orden inverso
+func invertir(ids []int) []int {
+    invertidos := make([]int, len(ids))
+    for i, id := range ids {
+        invertidos[len(ids)-1-i] = id
+    }
+    return invertidos
+}
+
+// verificarIDs comprueba que la lista contiene todos los IDs esperados y
+// ninguno de los excluidos
+func verificarIDs(t *testing.T, operacion string, cursos []models.Curso, esperados, excluidos []int) {
+    t.Helper()
+
+    presentes := make(map[int]bool, len(cursos))
+    for _, curso := range cursos {
+        presentes[curso.ID] = true
+    }
+
+    for _, id := range esperados {
+        if !presentes[id] {
+            t.Fatalf("%s no incluyó el curso %d", operacion, id)
+        }
+    }
+    for _, id := range excluidos {
+        if presentes[id] {
+            t.Fatalf("%s incluyó el curso %d", operacion, id)
+        }
+    }
+}
+
+// verificarUsuarios comprueba que la lista contiene todos los IDs esperados y
+// ninguno de los excluidos
+func verificarUsuarios(t *testing.T, operacion string, usuarios []models.Usuario, esperados, excluidos []int) {
+    t.Helper()
+
+    presentes := make(map[int]bool, len(usuarios))
+    for _, usuario := range usuarios {
+        presentes[usuario.ID] = true
+    }
+
+    for _, id := range esperados {
+        if !presentes[id] {
+            t.Fatalf("%s no incluyó el usuario %d", operacion, id)
+        }
+    }
+    for _, id := range excluidos {
+        if presentes[id] {
+            t.Fatalf("%s incluyó el usuario %d", operacion, id)
+        }
+    }
+}
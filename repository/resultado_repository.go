@@ -0,0 +1,132 @@
+package repository
+
+import (
+    "cursos-api/models"
+    "time"
+)
+
+type ResultadoRepository struct {
+    db Querier
+}
+
+func NewResultadoRepository(db Querier) *ResultadoRepository {
+    return &ResultadoRepository{db: db}
+}
+
+// Create registra el resultado de una evaluación
+func (r *ResultadoRepository) Create(resultado *models.ResultadoEvaluacion) error {
+    query := `
+        INSERT INTO resultados_evaluacion (evaluacion_id, usuario_id, calificacion, aprobado, fecha_evaluacion, calificado_por)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id, fecha_evaluacion
+    `
+
+    err := r.db.QueryRow(
+        query,
+        resultado.EvaluacionID,
+        resultado.UsuarioID,
+        resultado.Calificacion,
+        resultado.Aprobado,
+        time.Now(),
+        resultado.CalificadoPor,
+    ).Scan(&resultado.ID, &resultado.FechaEvaluacion)
+
+    return err
+}
+
+// CountByUsuarioAndEvaluacion cuenta los resultados de un usuario en una evaluación
+func (r *ResultadoRepository) CountByUsuarioAndEvaluacion(usuarioID, evaluacionID int) (int, error) {
+    query := `SELECT COUNT(*) FROM resultados_evaluacion WHERE usuario_id = $1 AND evaluacion_id = $2`
+
+    var count int
+    err := r.db.QueryRow(query, usuarioID, evaluacionID).Scan(&count)
+
+    return count, err
+}
+
+// GetByCurso obtiene los resultados de todas las evaluaciones de un curso
+func (r *ResultadoRepository) GetByCurso(cursoID int) ([]models.ResultadoEvaluacion, error) {
+    query := `
+        SELECT r.id, r.evaluacion_id, r.usuario_id, r.calificacion, r.aprobado, r.fecha_evaluacion,
+               e.id, e.curso_id, e.titulo, e.calificacion_minima,
+               u.id, u.nombre, u.email, u.rol
+        FROM resultados_evaluacion r
+        INNER JOIN evaluaciones e ON r.evaluacion_id = e.id
+        INNER JOIN usuarios u ON r.usuario_id = u.id
+        WHERE e.curso_id = $1 AND u.deleted_at IS NULL
+        ORDER BY r.fecha_evaluacion DESC
+    `
+
+    return r.query(query, cursoID)
+}
+
+// GetByUsuarioAndCurso obtiene los resultados de un usuario en un curso
+func (r *ResultadoRepository) GetByUsuarioAndCurso(usuarioID, cursoID int) ([]models.ResultadoEvaluacion, error) {
+    query := `
+        SELECT r.id, r.evaluacion_id, r.usuario_id, r.calificacion, r.aprobado, r.fecha_evaluacion,
+               e.id, e.curso_id, e.titulo, e.calificacion_minima,
+               u.id, u.nombre, u.email, u.rol
+        FROM resultados_evaluacion r
+        INNER JOIN evaluaciones e ON r.evaluacion_id = e.id
+        INNER JOIN usuarios u ON r.usuario_id = u.id
+        WHERE r.usuario_id = $1 AND e.curso_id = $2
+        ORDER BY r.fecha_evaluacion DESC
+    `
+
+    return r.query(query, usuarioID, cursoID)
+}
+
+// GetByUsuario obtiene todos los resultados de un usuario
+func (r *ResultadoRepository) GetByUsuario(usuarioID int) ([]models.ResultadoEvaluacion, error) {
+    query := `
+        SELECT r.id, r.evaluacion_id, r.usuario_id, r.calificacion, r.aprobado, r.fecha_evaluacion,
+               e.id, e.curso_id, e.titulo, e.calificacion_minima,
+               u.id, u.nombre, u.email, u.rol
+        FROM resultados_evaluacion r
+        INNER JOIN evaluaciones e ON r.evaluacion_id = e.id
+        INNER JOIN usuarios u ON r.usuario_id = u.id
+        WHERE r.usuario_id = $1
+        ORDER BY r.fecha_evaluacion DESC
+    `
+
+    return r.query(query, usuarioID)
+}
+
+// query ejecuta una consulta de resultados con su evaluación y usuario
+func (r *ResultadoRepository) query(query string, args ...interface{}) ([]models.ResultadoEvaluacion, error) {
+    rows, err := r.db.Query(query, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var resultados []models.ResultadoEvaluacion
+    for rows.Next() {
+        var resultado models.ResultadoEvaluacion
+        resultado.Evaluacion = &models.Evaluacion{}
+        resultado.Usuario = &models.Usuario{}
+
+        err := rows.Scan(
+            &resultado.ID,
+            &resultado.EvaluacionID,
+            &resultado.UsuarioID,
+            &resultado.Calificacion,
+            &resultado.Aprobado,
+            &resultado.FechaEvaluacion,
+            &resultado.Evaluacion.ID,
+            &resultado.Evaluacion.CursoID,
+            &resultado.Evaluacion.Titulo,
+            &resultado.Evaluacion.CalificacionMinima,
+            &resultado.Usuario.ID,
+            &resultado.Usuario.Nombre,
+            &resultado.Usuario.Email,
+            &resultado.Usuario.Rol,
+        )
+        if err != nil {
+            return nil, err
+        }
+        resultados = append(resultados, resultado)
+    }
+
+    return resultados, nil
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+    "cursos-api/models"
+    "database/sql"
+    "errors"
+    "time"
+)
+
+type SesionRepository struct {
+    db Querier
+}
+
+func NewSesionRepository(db Querier) *SesionRepository {
+    return &SesionRepository{db: db}
+}
+
+// Create crea una sesión junto con su primer refresh token
+func (r *SesionRepository) Create(sesion *models.Sesion, token *models.RefreshToken) error {
+    tx, err := begin(r.db)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    err = tx.QueryRow(`
+        INSERT INTO sesiones (id, usuario_id, created_at)
+        VALUES ($1, $2, $3)
+        RETURNING created_at
+    `, sesion.ID, sesion.UsuarioID, time.Now()).Scan(&sesion.CreatedAt)
+    if err != nil {
+        return err
+    }
+
+    token.SesionID = sesion.ID
+    if err := insertRefreshToken(tx, token); err != nil {
+        return err
+    }
+
+    return tx.Commit()
+}
+
+// FindRefreshToken busca un refresh token por su hash junto con su sesión
+func (r *SesionRepository) FindRefreshToken(tokenHash string) (*models.RefreshToken, error) {
+    query := `
+        SELECT t.id, t.sesion_id, t.token_hash, t.expira_at, t.usado_at, t.created_at,
+               s.id, s.usuario_id, s.created_at, s.revocada_at, COALESCE(s.motivo_revocacion, '')
+        FROM refresh_tokens t
+        INNER JOIN sesiones s ON t.sesion_id = s.id
+        WHERE t.token_hash = $1
+    `
+
+    token := &models.RefreshToken{Sesion: &models.Sesion{}}
+    err := r.db.QueryRow(query, tokenHash).Scan(
+        &token.ID,
+        &token.SesionID,
+        &token.TokenHash,
+        &token.ExpiraAt,
+        &token.UsadoAt,
+        &token.CreatedAt,
+        &token.Sesion.ID,
+        &token.Sesion.UsuarioID,
+        &token.Sesion.CreatedAt,
+        &token.Sesion.RevocadaAt,
+        &token.Sesion.MotivoRevocacion,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, errors.New("refresh token no encontrado")
+    }
+
+    return token, err
+}
+
+// Rotar marca un refresh token como usado y registra su sucesor en la misma
+// transacción. Devuelve false si el token ya había sido usado o su sesión fue
+// revocada, lo que permite detectar reutilizaciones concurrentes.
+func (r *SesionRepository) Rotar(tokenID int, nuevo *models.RefreshToken) (bool, error) {
+    tx, err := begin(r.db)
+    if err != nil {
+        return false, err
+    }
+    defer tx.Rollback()
+
+    result, err := tx.Exec(`
+        UPDATE refresh_tokens t
+        SET usado_at = $1
+        FROM sesiones s
+        WHERE t.id = $2 AND t.usado_at IS NULL
+          AND s.id = t.sesion_id AND s.revocada_at IS NULL
+    `, time.Now(), tokenID)
+    if err != nil {
+        return false, err
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return false, err
+    }
+
+    if rowsAffected == 0 {
+        return false, nil
+    }
+
+    if err := insertRefreshToken(tx, nuevo); err != nil {
+        return false, err
+    }
+
+    return true, tx.Commit()
+}
+
+// Revocar revoca una sesión y con ella toda su familia de tokens
+func (r *SesionRepository) Revocar(sesionID, motivo string) error {
+    _, err := r.db.Exec(`
+        UPDATE sesiones
+        SET revocada_at = $1, motivo_revocacion = $2
+        WHERE id = $3 AND revocada_at IS NULL
+    `, time.Now(), motivo, sesionID)
+
+    return err
+}
+
+// RevocarPorUsuario revoca todas las sesiones abiertas de un usuario
+func (r *SesionRepository) RevocarPorUsuario(usuarioID int, motivo string) error {
+    _, err := r.db.Exec(`
+        UPDATE sesiones
+        SET revocada_at = $1, motivo_revocacion = $2
+        WHERE usuario_id = $3 AND revocada_at IS NULL
+    `, time.Now(), motivo, usuarioID)
+
+    return err
+}
+
+// IsActiva indica si una sesión existe y no fue revocada
+func (r *SesionRepository) IsActiva(sesionID string) (bool, error) {
+    query := `SELECT EXISTS(SELECT 1 FROM sesiones WHERE id = $1 AND revocada_at IS NULL)`
+
+    var activa bool
+    err := r.db.QueryRow(query, sesionID).Scan(&activa)
+
+    return activa, err
+}
+
+// insertRefreshToken inserta un refresh token de una sesión
+func insertRefreshToken(tx Querier, token *models.RefreshToken) error {
+    return tx.QueryRow(`
+        INSERT INTO refresh_tokens (sesion_id, token_hash, expira_at, created_at)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id, created_at
+    `, token.SesionID, token.TokenHash, token.ExpiraAt, time.Now()).Scan(&token.ID, &token.CreatedAt)
+}
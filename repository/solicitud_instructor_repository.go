@@ -0,0 +1,191 @@
+package repository
+
+import (
+    "cursos-api/models"
+    "database/sql"
+    "errors"
+    "time"
+)
+
+type SolicitudInstructorRepository struct {
+    db Querier
+}
+
+func NewSolicitudInstructorRepository(db Querier) *SolicitudInstructorRepository {
+    return &SolicitudInstructorRepository{db: db}
+}
+
+// Create crea una solicitud pendiente
+func (r *SolicitudInstructorRepository) Create(solicitud *models.SolicitudInstructor) error {
+    query := `
+        INSERT INTO solicitudes_instructor (usuario_id, motivo, estado, created_at)
+        VALUES ($1, $2, 'pendiente', $3)
+        RETURNING id, estado, created_at
+    `
+
+    return r.db.QueryRow(query, solicitud.UsuarioID, solicitud.Motivo, time.Now()).Scan(
+        &solicitud.ID,
+        &solicitud.Estado,
+        &solicitud.CreatedAt,
+    )
+}
+
+// FindByID busca una solicitud por ID
+func (r *SolicitudInstructorRepository) FindByID(id int) (*models.SolicitudInstructor, error) {
+    query := `
+        SELECT id, usuario_id, motivo, estado, revisado_por, COALESCE(comentario_revision, ''), created_at, revisada_at
+        FROM solicitudes_instructor
+        WHERE id = $1
+    `
+
+    solicitud := &models.SolicitudInstructor{}
+    err := r.db.QueryRow(query, id).Scan(
+        &solicitud.ID,
+        &solicitud.UsuarioID,
+        &solicitud.Motivo,
+        &solicitud.Estado,
+        &solicitud.RevisadoPor,
+        &solicitud.ComentarioRevision,
+        &solicitud.CreatedAt,
+        &solicitud.RevisadaAt,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, errors.New("solicitud no encontrada")
+    }
+
+    return solicitud, err
+}
+
+// HasPendiente indica si el usuario tiene una solicitud pendiente
+func (r *SolicitudInstructorRepository) HasPendiente(usuarioID int) (bool, error) {
+    query := `SELECT EXISTS(SELECT 1 FROM solicitudes_instructor WHERE usuario_id = $1 AND estado = 'pendiente')`
+
+    var pendiente bool
+    err := r.db.QueryRow(query, usuarioID).Scan(&pendiente)
+
+    return pendiente, err
+}
+
+// GetByUsuario obtiene las solicitudes de un usuario, de la más reciente a la más antigua
+func (r *SolicitudInstructorRepository) GetByUsuario(usuarioID int) ([]models.SolicitudInstructor, error) {
+    query := `
+        SELECT id, usuario_id, motivo, estado, revisado_por, COALESCE(comentario_revision, ''), created_at, revisada_at
+        FROM solicitudes_instructor
+        WHERE usuario_id = $1
+        ORDER BY created_at DESC
+    `
+
+    rows, err := r.db.Query(query, usuarioID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    solicitudes := []models.SolicitudInstructor{}
+    for rows.Next() {
+        var solicitud models.SolicitudInstructor
+        err := rows.Scan(
+            &solicitud.ID,
+            &solicitud.UsuarioID,
+            &solicitud.Motivo,
+            &solicitud.Estado,
+            &solicitud.RevisadoPor,
+            &solicitud.ComentarioRevision,
+            &solicitud.CreatedAt,
+            &solicitud.RevisadaAt,
+        )
+        if err != nil {
+            return nil, err
+        }
+        solicitudes = append(solicitudes, solicitud)
+    }
+
+    return solicitudes, rows.Err()
+}
+
+// GetByEstado obtiene las solicitudes en un estado junto con su solicitante,
+// de la más antigua a la más reciente
+func (r *SolicitudInstructorRepository) GetByEstado(estado string) ([]models.SolicitudInstructor, error) {
+    query := `
+        SELECT s.id, s.usuario_id, s.motivo, s.estado, s.revisado_por, COALESCE(s.comentario_revision, ''), s.created_at, s.revisada_at,
+               u.id, u.nombre, u.email, u.rol
+        FROM solicitudes_instructor s
+        INNER JOIN usuarios u ON s.usuario_id = u.id
+        WHERE s.estado = $1 AND u.deleted_at IS NULL
+        ORDER BY s.created_at
+    `
+
+    rows, err := r.db.Query(query, estado)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    solicitudes := []models.SolicitudInstructor{}
+    for rows.Next() {
+        solicitud := models.SolicitudInstructor{Usuario: &models.Usuario{}}
+        err := rows.Scan(
+            &solicitud.ID,
+            &solicitud.UsuarioID,
+            &solicitud.Motivo,
+            &solicitud.Estado,
+            &solicitud.RevisadoPor,
+            &solicitud.ComentarioRevision,
+            &solicitud.CreatedAt,
+            &solicitud.RevisadaAt,
+            &solicitud.Usuario.ID,
+            &solicitud.Usuario.Nombre,
+            &solicitud.Usuario.Email,
+            &solicitud.Usuario.Rol,
+        )
+        if err != nil {
+            return nil, err
+        }
+        solicitudes = append(solicitudes, solicitud)
+    }
+
+    return solicitudes, rows.Err()
+}
+
+// Resolver marca una solicitud pendiente como aprobada o rechazada. Si se
+// aprueba, el solicitante pasa a tener nuevoRol en la misma transacción.
+func (r *SolicitudInstructorRepository) Resolver(solicitud *models.SolicitudInstructor, nuevoRol string) error {
+    tx, err := begin(r.db)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    err = tx.QueryRow(`
+        UPDATE solicitudes_instructor
+        SET estado = $1, revisado_por = $2, comentario_revision = NULLIF($3, ''), revisada_at = $4
+        WHERE id = $5 AND estado = 'pendiente'
+        RETURNING revisada_at
+    `, solicitud.Estado, solicitud.RevisadoPor, solicitud.ComentarioRevision, time.Now(), solicitud.ID).Scan(&solicitud.RevisadaAt)
+
+    if err == sql.ErrNoRows {
+        return errors.New("la solicitud no está pendiente")
+    }
+    if err != nil {
+        return err
+    }
+
+    if nuevoRol != "" {
+        result, err := tx.Exec(`UPDATE usuarios SET rol = $1, updated_at = $2 WHERE id = $3 AND deleted_at IS NULL`, nuevoRol, time.Now(), solicitud.UsuarioID)
+        if err != nil {
+            return err
+        }
+
+        rowsAffected, err := result.RowsAffected()
+        if err != nil {
+            return err
+        }
+
+        if rowsAffected == 0 {
+            return errors.New("usuario no encontrado")
+        }
+    }
+
+    return tx.Commit()
+}
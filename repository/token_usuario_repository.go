@@ -0,0 +1,73 @@
+package repository
+
+import (
+    "cursos-api/models"
+    "database/sql"
+    "errors"
+    "time"
+)
+
+type TokenUsuarioRepository struct {
+    db Querier
+}
+
+func NewTokenUsuarioRepository(db Querier) *TokenUsuarioRepository {
+    return &TokenUsuarioRepository{db: db}
+}
+
+// Create guarda un token nuevo (solo su hash)
+func (r *TokenUsuarioRepository) Create(token *models.TokenUsuario) error {
+    query := `
+        INSERT INTO tokens_usuario (usuario_id, tipo, token_hash, expira_at, created_at)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id, created_at
+    `
+
+    return r.db.QueryRow(
+        query,
+        token.UsuarioID,
+        token.Tipo,
+        token.TokenHash,
+        token.ExpiraAt,
+        time.Now(),
+    ).Scan(&token.ID, &token.CreatedAt)
+}
+
+// Consumir marca como usado un token vigente del tipo indicado y lo devuelve.
+// La actualización es condicional, así que un token solo se consume una vez.
+func (r *TokenUsuarioRepository) Consumir(tipo, tokenHash string) (*models.TokenUsuario, error) {
+    query := `
+        UPDATE tokens_usuario
+        SET usado_at = $1
+        WHERE token_hash = $2 AND tipo = $3 AND usado_at IS NULL AND expira_at > $1
+        RETURNING id, usuario_id, tipo, token_hash, expira_at, usado_at, created_at
+    `
+
+    token := &models.TokenUsuario{}
+    err := r.db.QueryRow(query, time.Now(), tokenHash, tipo).Scan(
+        &token.ID,
+        &token.UsuarioID,
+        &token.Tipo,
+        &token.TokenHash,
+        &token.ExpiraAt,
+        &token.UsadoAt,
+        &token.CreatedAt,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, errors.New("token inválido, expirado o ya utilizado")
+    }
+
+    return token, err
+}
+
+// InvalidarPorUsuario anula los tokens sin usar de un usuario de un tipo
+func (r *TokenUsuarioRepository) InvalidarPorUsuario(usuarioID int, tipo string) error {
+    _, err := r.db.Exec(`
+        UPDATE tokens_usuario
+        SET usado_at = $1
+        WHERE usuario_id = $2 AND tipo = $3 AND usado_at IS NULL
+    `, time.Now(), usuarioID, tipo)
+
+    return err
+}
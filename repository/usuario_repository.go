@@ -1,17 +1,18 @@
 package repository
 
 import (
-    "cursos-api/config"
     "cursos-api/models"
     "database/sql"
     "errors"
     "time"
 )
 
-type UsuarioRepository struct{}
+type UsuarioRepository struct {
+    db Querier
+}
 
-func NewUsuarioRepository() *UsuarioRepository {
-    return &UsuarioRepository{}
+func NewUsuarioRepository(db Querier) *UsuarioRepository {
+    return &UsuarioRepository{db: db}
 }
 
 // Create crea un nuevo usuario
@@ -19,11 +20,11 @@ func (r *UsuarioRepository) Create(usuario *models.Usuario) error {
     query := `
         INSERT INTO usuarios (nombre, email, password_hash, rol, created_at, updated_at)
         VALUES ($1, $2, $3, $4, $5, $6)
-        RETURNING id, created_at, updated_at
+        RETURNING id, activo, email_verificado, created_at, updated_at
     `
     
     now := time.Now()
-    err := config.DB.QueryRow(
+    err := r.db.QueryRow(
         query,
         usuario.Nombre,
         usuario.Email,
@@ -31,28 +32,32 @@ func (r *UsuarioRepository) Create(usuario *models.Usuario) error {
         usuario.Rol,
         now,
         now,
-    ).Scan(&usuario.ID, &usuario.CreatedAt, &usuario.UpdatedAt)
+    ).Scan(&usuario.ID, &usuario.Activo, &usuario.EmailVerificado, &usuario.CreatedAt, &usuario.UpdatedAt)
 
     return err
 }
 
-// FindByEmail busca un usuario por email
+// FindByEmail busca un usuario por email sin distinguir mayúsculas
 func (r *UsuarioRepository) FindByEmail(email string) (*models.Usuario, error) {
     query := `
-        SELECT id, nombre, email, password_hash, rol, created_at, updated_at
+        SELECT id, nombre, email, password_hash, rol, activo, email_verificado, totp_habilitado, created_at, updated_at, login_bloqueado_hasta
         FROM usuarios
-        WHERE email = $1
+        WHERE lower(email) = lower($1) AND deleted_at IS NULL
     `
     
     usuario := &models.Usuario{}
-    err := config.DB.QueryRow(query, email).Scan(
+    err := r.db.QueryRow(query, email).Scan(
         &usuario.ID,
         &usuario.Nombre,
         &usuario.Email,
         &usuario.PasswordHash,
         &usuario.Rol,
+        &usuario.Activo,
+        &usuario.EmailVerificado,
+        &usuario.TOTPHabilitado,
         &usuario.CreatedAt,
         &usuario.UpdatedAt,
+        &usuario.LoginBloqueadoHasta,
     )
 
     if err == sql.ErrNoRows {
@@ -65,20 +70,24 @@ func (r *UsuarioRepository) FindByEmail(email string) (*models.Usuario, error) {
 // FindByID busca un usuario por ID
 func (r *UsuarioRepository) FindByID(id int) (*models.Usuario, error) {
     query := `
-        SELECT id, nombre, email, password_hash, rol, created_at, updated_at
+        SELECT id, nombre, email, password_hash, rol, activo, email_verificado, totp_habilitado, created_at, updated_at, login_bloqueado_hasta
         FROM usuarios
-        WHERE id = $1
+        WHERE id = $1 AND deleted_at IS NULL
     `
     
     usuario := &models.Usuario{}
-    err := config.DB.QueryRow(query, id).Scan(
+    err := r.db.QueryRow(query, id).Scan(
         &usuario.ID,
         &usuario.Nombre,
         &usuario.Email,
         &usuario.PasswordHash,
         &usuario.Rol,
+        &usuario.Activo,
+        &usuario.EmailVerificado,
+        &usuario.TOTPHabilitado,
         &usuario.CreatedAt,
         &usuario.UpdatedAt,
+        &usuario.LoginBloqueadoHasta,
     )
 
     if err == sql.ErrNoRows {
@@ -88,15 +97,45 @@ func (r *UsuarioRepository) FindByID(id int) (*models.Usuario, error) {
     return usuario, err
 }
 
+// FindEliminado busca un usuario eliminado pendiente de purga
+func (r *UsuarioRepository) FindEliminado(id int) (*models.Usuario, error) {
+    query := `
+        SELECT id, nombre, email, rol, activo, email_verificado, totp_habilitado, created_at, updated_at, deleted_at
+        FROM usuarios
+        WHERE id = $1 AND deleted_at IS NOT NULL
+    `
+
+    usuario := &models.Usuario{}
+    err := r.db.QueryRow(query, id).Scan(
+        &usuario.ID,
+        &usuario.Nombre,
+        &usuario.Email,
+        &usuario.Rol,
+        &usuario.Activo,
+        &usuario.EmailVerificado,
+        &usuario.TOTPHabilitado,
+        &usuario.CreatedAt,
+        &usuario.UpdatedAt,
+        &usuario.DeletedAt,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, errors.New("usuario eliminado no encontrado")
+    }
+
+    return usuario, err
+}
+
 // GetAll obtiene todos los usuarios
 func (r *UsuarioRepository) GetAll() ([]models.Usuario, error) {
     query := `
-        SELECT id, nombre, email, rol, created_at, updated_at
+        SELECT id, nombre, email, rol, activo, email_verificado, totp_habilitado, created_at, updated_at
         FROM usuarios
+        WHERE deleted_at IS NULL
         ORDER BY created_at DESC
     `
     
-    rows, err := config.DB.Query(query)
+    rows, err := r.db.Query(query)
     if err != nil {
         return nil, err
     }
@@ -110,6 +149,9 @@ func (r *UsuarioRepository) GetAll() ([]models.Usuario, error) {
             &usuario.Nombre,
             &usuario.Email,
             &usuario.Rol,
+            &usuario.Activo,
+            &usuario.EmailVerificado,
+            &usuario.TOTPHabilitado,
             &usuario.CreatedAt,
             &usuario.UpdatedAt,
         )
@@ -122,24 +164,26 @@ func (r *UsuarioRepository) GetAll() ([]models.Usuario, error) {
     return usuarios, nil
 }
 
-// Update actualiza un usuario
+// Update actualiza un usuario. Cambiar el email (no solo sus mayúsculas)
+// anula su verificación.
 func (r *UsuarioRepository) Update(id int, usuario *models.Usuario) error {
     query := `
         UPDATE usuarios
-        SET nombre = $1, email = $2, rol = $3, updated_at = $4
-        WHERE id = $5
-        RETURNING updated_at
+        SET nombre = $1, email = $2, rol = $3, updated_at = $4,
+            email_verificado = email_verificado AND lower(email) = lower($2)
+        WHERE id = $5 AND deleted_at IS NULL
+        RETURNING email_verificado, updated_at
     `
     
     now := time.Now()
-    err := config.DB.QueryRow(
+    err := r.db.QueryRow(
         query,
         usuario.Nombre,
         usuario.Email,
         usuario.Rol,
         now,
         id,
-    ).Scan(&usuario.UpdatedAt)
+    ).Scan(&usuario.EmailVerificado, &usuario.UpdatedAt)
 
     if err == sql.ErrNoRows {
         return errors.New("usuario no encontrado")
@@ -149,11 +193,17 @@ func (r *UsuarioRepository) Update(id int, usuario *models.Usuario) error {
     return err
 }
 
-// Delete elimina un usuario
+// Delete elimina lógicamente un usuario y sus cursos vigentes. Los cursos
+// quedan con la misma marca de borrado para restaurarlos junto al usuario.
 func (r *UsuarioRepository) Delete(id int) error {
-    query := `DELETE FROM usuarios WHERE id = $1`
-    
-    result, err := config.DB.Exec(query, id)
+    tx, err := begin(r.db)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    now := time.Now()
+    result, err := tx.Exec(`UPDATE usuarios SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL`, now, id)
     if err != nil {
         return err
     }
@@ -167,7 +217,68 @@ func (r *UsuarioRepository) Delete(id int) error {
         return errors.New("usuario no encontrado")
     }
 
-    return nil
+    _, err = tx.Exec(`UPDATE cursos SET deleted_at = $1 WHERE instructor_id = $2 AND deleted_at IS NULL`, now, id)
+    if err != nil {
+        return err
+    }
+
+    return tx.Commit()
+}
+
+// Restore restaura un usuario eliminado y los cursos que se eliminaron con
+// él. Los cursos que su instructor había eliminado antes siguen eliminados.
+func (r *UsuarioRepository) Restore(id int) error {
+    tx, err := begin(r.db)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    var deletedAt time.Time
+    err = tx.QueryRow(
+        `SELECT deleted_at FROM usuarios WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE`, id,
+    ).Scan(&deletedAt)
+    if err == sql.ErrNoRows {
+        return errors.New("usuario eliminado no encontrado")
+    }
+    if err != nil {
+        return err
+    }
+
+    if _, err := tx.Exec(`UPDATE usuarios SET deleted_at = NULL, updated_at = $1 WHERE id = $2`, time.Now(), id); err != nil {
+        return err
+    }
+
+    _, err = tx.Exec(`UPDATE cursos SET deleted_at = NULL WHERE instructor_id = $1 AND deleted_at = $2`, id, deletedAt)
+    if err != nil {
+        return err
+    }
+
+    return tx.Commit()
+}
+
+// Purgar borra definitivamente los usuarios eliminados antes de la fecha
+// indicada. En cascada se borran sus cursos, inscripciones y demás datos, así
+// que no se purgan los instructores con cursos que tienen certificados
+// emitidos: quedan eliminados para conservar los certificados de los alumnos.
+func (r *UsuarioRepository) Purgar(antes time.Time) (int64, error) {
+    query := `
+        DELETE FROM usuarios u
+        WHERE u.deleted_at < $1
+          AND NOT EXISTS (
+              SELECT 1 FROM cursos c
+              INNER JOIN inscripciones i ON i.curso_id = c.id
+              INNER JOIN certificados ce ON ce.inscripcion_id = i.id
+              WHERE c.instructor_id = u.id
+          )
+    `
+
+    result, err := r.db.Exec(query, antes)
+    if err != nil {
+        return 0, err
+    }
+
+    return result.RowsAffected()
 }
 
 // UpdatePassword actualiza la contraseña de un usuario
@@ -175,10 +286,103 @@ func (r *UsuarioRepository) UpdatePassword(id int, newPasswordHash string) error
     query := `
         UPDATE usuarios
         SET password_hash = $1, updated_at = $2
-        WHERE id = $3
+        WHERE id = $3 AND deleted_at IS NULL
     `
     
-    result, err := config.DB.Exec(query, newPasswordHash, time.Now(), id)
+    result, err := r.db.Exec(query, newPasswordHash, time.Now(), id)
+    if err != nil {
+        return err
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+
+    if rowsAffected == 0 {
+        return errors.New("usuario no encontrado")
+    }
+
+    return nil
+}
+
+// Search busca usuarios por nombre o email, rol y estado. Con Eliminados
+// busca entre los eliminados en lugar de entre los vigentes.
+func (r *UsuarioRepository) Search(filtro models.UsuarioFiltro) ([]models.Usuario, error) {
+    query := `
+        SELECT id, nombre, email, rol, activo, email_verificado, totp_habilitado, created_at, updated_at, deleted_at
+        FROM usuarios
+        WHERE ($1 = '' OR nombre ILIKE '%' || $1 || '%' OR email ILIKE '%' || $1 || '%')
+          AND ($2 = '' OR rol = $2)
+          AND ($3::boolean IS NULL OR activo = $3)
+          AND (deleted_at IS NOT NULL) = $4
+        ORDER BY created_at DESC
+    `
+
+    rows, err := r.db.Query(query, filtro.Query, filtro.Rol, filtro.Activo, filtro.Eliminados)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var usuarios []models.Usuario
+    for rows.Next() {
+        var usuario models.Usuario
+        err := rows.Scan(
+            &usuario.ID,
+            &usuario.Nombre,
+            &usuario.Email,
+            &usuario.Rol,
+            &usuario.Activo,
+            &usuario.EmailVerificado,
+            &usuario.TOTPHabilitado,
+            &usuario.CreatedAt,
+            &usuario.UpdatedAt,
+            &usuario.DeletedAt,
+        )
+        if err != nil {
+            return nil, err
+        }
+        usuarios = append(usuarios, usuario)
+    }
+
+    return usuarios, nil
+}
+
+// SetActivo habilita o deshabilita un usuario
+func (r *UsuarioRepository) SetActivo(id int, activo bool) error {
+    query := `
+        UPDATE usuarios
+        SET activo = $1, updated_at = $2
+        WHERE id = $3 AND deleted_at IS NULL
+    `
+
+    result, err := r.db.Exec(query, activo, time.Now(), id)
+    if err != nil {
+        return err
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+
+    if rowsAffected == 0 {
+        return errors.New("usuario no encontrado")
+    }
+
+    return nil
+}
+
+// MarcarEmailVerificado marca el email de un usuario como verificado
+func (r *UsuarioRepository) MarcarEmailVerificado(id int) error {
+    query := `
+        UPDATE usuarios
+        SET email_verificado = true, updated_at = $1
+        WHERE id = $2 AND deleted_at IS NULL
+    `
+
+    result, err := r.db.Exec(query, time.Now(), id)
     if err != nil {
         return err
     }
@@ -194,3 +398,113 @@ func (r *UsuarioRepository) UpdatePassword(id int, newPasswordHash string) error
 
     return nil
 }
+
+// RegistrarLoginFallido anota un intento fallido de login y devuelve los fallos
+// dentro de la ventana y los bloqueos consecutivos vigentes, ya descontado el
+// decaimiento. Los fallos anteriores a la ventana se descartan.
+func (r *UsuarioRepository) RegistrarLoginFallido(id int, ventana, decaimiento time.Duration) (int, int, error) {
+    tx, err := begin(r.db)
+    if err != nil {
+        return 0, 0, err
+    }
+    defer tx.Rollback()
+
+    // Bloquear el usuario para serializar los fallos concurrentes
+    var bloqueos int
+    var hasta *time.Time
+    err = tx.QueryRow(`
+        SELECT bloqueos_login, login_bloqueado_hasta
+        FROM usuarios
+        WHERE id = $1 AND deleted_at IS NULL
+        FOR UPDATE
+    `, id).Scan(&bloqueos, &hasta)
+    if err == sql.ErrNoRows {
+        return 0, 0, errors.New("usuario no encontrado")
+    }
+    if err != nil {
+        return 0, 0, err
+    }
+
+    now := time.Now()
+    if _, err := tx.Exec(`DELETE FROM login_fallidos WHERE usuario_id = $1 AND created_at <= $2`, id, now.Add(-ventana)); err != nil {
+        return 0, 0, err
+    }
+
+    if _, err := tx.Exec(`INSERT INTO login_fallidos (usuario_id, created_at) VALUES ($1, $2)`, id, now); err != nil {
+        return 0, 0, err
+    }
+
+    var intentos int
+    if err := tx.QueryRow(`SELECT COUNT(*) FROM login_fallidos WHERE usuario_id = $1`, id).Scan(&intentos); err != nil {
+        return 0, 0, err
+    }
+
+    if err := tx.Commit(); err != nil {
+        return 0, 0, err
+    }
+
+    return intentos, BloqueosVigentes(bloqueos, hasta, now, decaimiento), nil
+}
+
+// BloquearLogin bloquea el login del usuario hasta la fecha indicada, fija el
+// nivel de bloqueos consecutivos y descarta los fallos acumulados
+func (r *UsuarioRepository) BloquearLogin(id int, hasta time.Time, bloqueos int) error {
+    tx, err := begin(r.db)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    result, err := tx.Exec(`
+        UPDATE usuarios
+        SET login_bloqueado_hasta = $1, bloqueos_login = $2
+        WHERE id = $3 AND deleted_at IS NULL
+    `, hasta, bloqueos, id)
+    if err != nil {
+        return err
+    }
+
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+
+    if rowsAffected == 0 {
+        return errors.New("usuario no encontrado")
+    }
+
+    if _, err := tx.Exec(`DELETE FROM login_fallidos WHERE usuario_id = $1`, id); err != nil {
+        return err
+    }
+
+    return tx.Commit()
+}
+
+// BloqueosVigentes descuenta de los bloqueos consecutivos uno por cada periodo
+// de decaimiento transcurrido desde que terminó el último bloqueo
+func BloqueosVigentes(bloqueos int, hasta *time.Time, now time.Time, decaimiento time.Duration) int {
+    if hasta == nil || decaimiento <= 0 || !now.After(*hasta) {
+        return bloqueos
+    }
+
+    bloqueos -= int(now.Sub(*hasta) / decaimiento)
+    if bloqueos < 0 {
+        return 0
+    }
+    return bloqueos
+}
+
+// ResetLoginFallidos borra los fallos y bloqueos tras un login correcto
+func (r *UsuarioRepository) ResetLoginFallidos(id int) error {
+    if _, err := r.db.Exec(`DELETE FROM login_fallidos WHERE usuario_id = $1`, id); err != nil {
+        return err
+    }
+
+    _, err := r.db.Exec(`
+        UPDATE usuarios
+        SET bloqueos_login = 0, login_bloqueado_hasta = NULL
+        WHERE id = $1 AND (bloqueos_login > 0 OR login_bloqueado_hasta IS NOT NULL)
+    `, id)
+
+    return err
+}
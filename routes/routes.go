@@ -1,55 +1,194 @@
 package routes
 
 import (
-	"cursos-api/handlers"
-	"cursos-api/middleware"
+	"cursos-api/app"
+	"cursos-api/policy"
+	"cursos-api/ratelimit"
 	"net/http"
 
 	"github.com/gorilla/mux"
 )
 
-func SetupRoutes() *mux.Router {
+func SetupRoutes(c *app.Container) *mux.Router {
     router := mux.NewRouter()
 
+    // Middlewares de autenticación y límites de frecuencia
+    mw := c.AuthMiddleware
+    rl := c.RateLimiter
+
     // Handlers
-    authHandler := handlers.NewAuthHandler()
-    usuarioHandler := handlers.NewUsuarioHandler()
-    cursoHandler := handlers.NewCursoHandler()
+    authHandler := c.AuthHandler
+    usuarioHandler := c.UsuarioHandler
+    cursoHandler := c.CursoHandler
+    inscripcionHandler := c.InscripcionHandler
+    leccionHandler := c.LeccionHandler
+    progresoHandler := c.ProgresoHandler
+    evaluacionHandler := c.EvaluacionHandler
+    preguntaHandler := c.PreguntaHandler
+    intentoHandler := c.IntentoHandler
+    certificadoHandler := c.CertificadoHandler
+    adminHandler := c.AdminHandler
+    solicitudHandler := c.SolicitudHandler
+    mfaHandler := c.MFAHandler
+    oidcHandler := c.OIDCHandler
+    categoriaHandler := c.CategoriaHandler
 
     // API prefix
     api := router.PathPrefix("/api").Subrouter()
 
+    // ============================================
+    // LÍMITES DE FRECUENCIA (por usuario o por IP)
+    // ============================================
+    // Límite general para toda la API; las rutas sensibles o costosas tienen
+    // además uno propio más estricto
+    api.Use(rl.Middleware("api", ratelimit.PerMinute(300)))
+    limiteAuth := ratelimit.PerMinute(10)
+    limiteCorreo := ratelimit.PerMinute(3)
+    limitePublico := ratelimit.PerMinute(60)
+    limitePDF := ratelimit.PerMinute(10)
+
     // ============================================
     // RUTAS PÚBLICAS (sin autenticación)
     // ============================================
-    api.HandleFunc("/auth/register", authHandler.Register).Methods("POST")
-    api.HandleFunc("/auth/login", authHandler.Login).Methods("POST")
+    api.HandleFunc("/auth/register", rl.Limit("auth", limiteAuth, authHandler.Register)).Methods("POST")
+    api.HandleFunc("/auth/login", rl.Limit("auth", limiteAuth, authHandler.Login)).Methods("POST")
+    api.HandleFunc("/auth/login/mfa", rl.Limit("auth", limiteAuth, authHandler.LoginMFA)).Methods("POST")
+    api.HandleFunc("/auth/refresh", rl.Limit("auth", limiteAuth, authHandler.Refresh)).Methods("POST")
+    api.HandleFunc("/auth/verify-email", rl.Limit("auth", limiteAuth, authHandler.VerifyEmail)).Methods("GET")
+    api.HandleFunc("/auth/forgot-password", rl.Limit("correo", limiteCorreo, authHandler.ForgotPassword)).Methods("POST")
+    api.HandleFunc("/auth/reset-password", rl.Limit("auth", limiteAuth, authHandler.ResetPassword)).Methods("POST")
+    api.HandleFunc("/auth/oidc/providers", oidcHandler.GetProveedores).Methods("GET")
+    api.HandleFunc("/auth/oidc/{proveedor}/login", rl.Limit("auth", limiteAuth, oidcHandler.Iniciar)).Methods("GET")
+    api.HandleFunc("/auth/oidc/{proveedor}/callback", rl.Limit("auth", limiteAuth, oidcHandler.Callback)).Methods("GET")
+    api.HandleFunc("/auth/oidc/exchange", rl.Limit("auth", limiteAuth, oidcHandler.Canjear)).Methods("POST")
+    api.HandleFunc("/certificados/verify/{codigo}", rl.Limit("publico", limitePublico, certificadoHandler.Verify)).Methods("GET")
+    api.HandleFunc("/categorias", rl.Limit("publico", limitePublico, categoriaHandler.GetArbol)).Methods("GET")
+    api.HandleFunc("/etiquetas", rl.Limit("publico", limitePublico, categoriaHandler.GetEtiquetas)).Methods("GET")
 
     // ============================================
     // RUTAS PROTEGIDAS (requieren autenticación)
     // ============================================
 
-    // --- Perfil de usuario ---
-    api.HandleFunc("/auth/profile", middleware.AuthMiddleware(authHandler.GetProfile)).Methods("GET")
+    // --- Perfil de usuario y sesión ---
+    api.HandleFunc("/auth/profile", mw.AuthMiddleware(authHandler.GetProfile)).Methods("GET")
+    api.HandleFunc("/auth/logout", mw.AuthMiddleware(authHandler.Logout)).Methods("POST")
+    api.HandleFunc("/auth/verify-email/resend", mw.AuthMiddleware(rl.Limit("correo", limiteCorreo, authHandler.ResendVerification))).Methods("POST")
+    api.HandleFunc("/auth/identities", mw.AuthMiddleware(oidcHandler.GetIdentidades)).Methods("GET")
+
+    // --- Autenticación en dos pasos (TOTP) ---
+    api.HandleFunc("/auth/2fa", mw.AuthMiddleware(mfaHandler.GetEstado)).Methods("GET")
+    api.HandleFunc("/auth/2fa/totp/setup", mw.AuthMiddleware(rl.Limit("auth", limiteAuth, mfaHandler.IniciarTOTP))).Methods("POST")
+    api.HandleFunc("/auth/2fa/totp/enable", mw.AuthMiddleware(rl.Limit("auth", limiteAuth, mfaHandler.ActivarTOTP))).Methods("POST")
+    api.HandleFunc("/auth/2fa/totp/disable", mw.AuthMiddleware(rl.Limit("auth", limiteAuth, mfaHandler.DesactivarTOTP))).Methods("POST")
+    api.HandleFunc("/auth/2fa/recovery-codes", mw.AuthMiddleware(rl.Limit("auth", limiteAuth, mfaHandler.RegenerarCodigos))).Methods("POST")
 
     // --- Usuarios ---
-    api.HandleFunc("/usuarios", middleware.AuthMiddleware(usuarioHandler.GetAll)).Methods("GET")
-    api.HandleFunc("/usuarios/{id}", middleware.AuthMiddleware(usuarioHandler.GetByID)).Methods("GET")
-    api.HandleFunc("/usuarios/{id}", middleware.AuthMiddleware(usuarioHandler.Update)).Methods("PUT")
-    api.HandleFunc("/usuarios/{id}", middleware.AuthMiddleware(usuarioHandler.Delete)).Methods("DELETE")
-    api.HandleFunc("/usuarios/change-password", middleware.AuthMiddleware(usuarioHandler.ChangePassword)).Methods("POST")
+    api.HandleFunc("/usuarios", mw.PermissionMiddleware(policy.UsuarioReadAny, usuarioHandler.GetAll)).Methods("GET")
+    api.HandleFunc("/usuarios/{id}", mw.PermissionMiddleware(policy.UsuarioReadOwn, usuarioHandler.GetByID)).Methods("GET")
+    api.HandleFunc("/usuarios/{id}", mw.PermissionMiddleware(policy.UsuarioUpdateOwn, usuarioHandler.Update)).Methods("PUT")
+    api.HandleFunc("/usuarios/{id}", mw.PermissionMiddleware(policy.UsuarioDeleteOwn, usuarioHandler.Delete)).Methods("DELETE")
+    api.HandleFunc("/usuarios/change-password", mw.AuthMiddleware(usuarioHandler.ChangePassword)).Methods("POST")
 
     // --- Cursos ---
     // Rutas para instructores
-    api.HandleFunc("/cursos", middleware.RoleMiddleware("instructor", cursoHandler.Create)).Methods("POST")
-    api.HandleFunc("/cursos/my-cursos", middleware.RoleMiddleware("instructor", cursoHandler.GetMyCursos)).Methods("GET")
-    api.HandleFunc("/cursos/{id}", middleware.RoleMiddleware("instructor", cursoHandler.Update)).Methods("PUT")
-    api.HandleFunc("/cursos/{id}", middleware.RoleMiddleware("instructor", cursoHandler.Delete)).Methods("DELETE")
-    api.HandleFunc("/cursos/{id}/toggle-activo", middleware.RoleMiddleware("instructor", cursoHandler.ToggleActivo)).Methods("PATCH")
+    api.HandleFunc("/cursos", mw.PermissionMiddleware(policy.CursoCreate, cursoHandler.Create)).Methods("POST")
+    api.HandleFunc("/cursos/my-cursos", mw.PermissionMiddleware(policy.CursoCreate, cursoHandler.GetMyCursos)).Methods("GET")
+    api.HandleFunc("/cursos/{id}", mw.PermissionMiddleware(policy.CursoUpdateOwn, cursoHandler.Update)).Methods("PUT")
+    api.HandleFunc("/cursos/{id}", mw.PermissionMiddleware(policy.CursoDeleteOwn, cursoHandler.Delete)).Methods("DELETE")
+    api.HandleFunc("/cursos/{id}/restaurar", mw.PermissionMiddleware(policy.CursoDeleteOwn, cursoHandler.Restaurar)).Methods("POST")
+    api.HandleFunc("/cursos/{id}/toggle-activo", mw.PermissionMiddleware(policy.CursoUpdateOwn, cursoHandler.ToggleActivo)).Methods("PATCH")
+    // Ciclo de publicación: el instructor del curso y quien revisa cursos;
+    // el servicio comprueba cada transición
+    api.HandleFunc("/cursos/{id}/estado", mw.AuthMiddleware(cursoHandler.CambiarEstado)).Methods("POST")
+    api.HandleFunc("/cursos/{id}/programacion", mw.AuthMiddleware(cursoHandler.Programar)).Methods("PUT")
+    api.HandleFunc("/cursos/{id}/historial", mw.AuthMiddleware(cursoHandler.GetHistorial)).Methods("GET")
+    api.HandleFunc("/cursos/{id}/categorias", mw.PermissionMiddleware(policy.CursoUpdateOwn, categoriaHandler.AsignarCategorias)).Methods("PUT")
+    api.HandleFunc("/cursos/{id}/etiquetas", mw.PermissionMiddleware(policy.CursoUpdateOwn, categoriaHandler.AsignarEtiquetas)).Methods("PUT")
 
     // Rutas disponibles para todos los usuarios autenticados
-    api.HandleFunc("/cursos", middleware.AuthMiddleware(cursoHandler.GetAll)).Methods("GET")
-    api.HandleFunc("/cursos/{id}", middleware.AuthMiddleware(cursoHandler.GetByID)).Methods("GET")
+    api.HandleFunc("/cursos", mw.AuthMiddleware(cursoHandler.GetAll)).Methods("GET")
+    api.HandleFunc("/cursos/search", mw.AuthMiddleware(cursoHandler.Search)).Methods("GET")
+    api.HandleFunc("/cursos/{id}", mw.AuthMiddleware(cursoHandler.GetByID)).Methods("GET")
+
+    // --- Inscripciones ---
+    // Rutas para alumnos
+    api.HandleFunc("/cursos/{id}/inscripciones", mw.PermissionMiddleware(policy.InscripcionCreate, inscripcionHandler.Create)).Methods("POST")
+    api.HandleFunc("/inscripciones/my-inscripciones", mw.PermissionMiddleware(policy.InscripcionManageOwn, inscripcionHandler.GetMyInscripciones)).Methods("GET")
+    api.HandleFunc("/inscripciones/{id}/cancelar", mw.PermissionMiddleware(policy.InscripcionManageOwn, inscripcionHandler.Cancel)).Methods("PATCH")
+
+    // Rutas para instructores
+    api.HandleFunc("/cursos/{id}/inscripciones", mw.PermissionMiddleware(policy.CursoContentOwn, inscripcionHandler.GetByCurso)).Methods("GET")
+
+    // --- Lecciones ---
+    // Rutas para instructores
+    api.HandleFunc("/cursos/{id}/lecciones", mw.PermissionMiddleware(policy.CursoContentOwn, leccionHandler.Create)).Methods("POST")
+    api.HandleFunc("/cursos/{id}/lecciones/orden", mw.PermissionMiddleware(policy.CursoContentOwn, leccionHandler.Reorder)).Methods("PUT")
+    api.HandleFunc("/cursos/{id}/lecciones/{leccionId:[0-9]+}", mw.PermissionMiddleware(policy.CursoContentOwn, leccionHandler.Update)).Methods("PUT")
+    api.HandleFunc("/cursos/{id}/lecciones/{leccionId:[0-9]+}", mw.PermissionMiddleware(policy.CursoContentOwn, leccionHandler.Delete)).Methods("DELETE")
+
+    // Rutas para el instructor del curso y alumnos inscritos
+    api.HandleFunc("/cursos/{id}/lecciones", mw.AuthMiddleware(leccionHandler.GetByCurso)).Methods("GET")
+    api.HandleFunc("/cursos/{id}/lecciones/{leccionId:[0-9]+}", mw.AuthMiddleware(leccionHandler.GetByID)).Methods("GET")
+
+    // --- Progreso ---
+    // Rutas para alumnos
+    api.HandleFunc("/cursos/{id}/lecciones/{leccionId:[0-9]+}/progreso", mw.PermissionMiddleware(policy.InscripcionManageOwn, progresoHandler.MarcarLeccion)).Methods("PUT")
+    api.HandleFunc("/inscripciones/{id}/progreso", mw.PermissionMiddleware(policy.InscripcionManageOwn, progresoHandler.GetProgreso)).Methods("GET")
+
+    // --- Evaluaciones ---
+    // Rutas para instructores
+    api.HandleFunc("/cursos/{id}/evaluaciones", mw.PermissionMiddleware(policy.CursoContentOwn, evaluacionHandler.Create)).Methods("POST")
+    api.HandleFunc("/cursos/{id}/evaluaciones/{evaluacionId:[0-9]+}", mw.PermissionMiddleware(policy.CursoContentOwn, evaluacionHandler.Update)).Methods("PUT")
+    api.HandleFunc("/cursos/{id}/evaluaciones/{evaluacionId:[0-9]+}", mw.PermissionMiddleware(policy.CursoContentOwn, evaluacionHandler.Delete)).Methods("DELETE")
+
+    // Rutas para alumnos
+    api.HandleFunc("/resultados/my-resultados", mw.PermissionMiddleware(policy.EvaluacionSubmit, evaluacionHandler.GetMyResultados)).Methods("GET")
+
+    // Rutas para el instructor del curso y alumnos inscritos
+    api.HandleFunc("/cursos/{id}/evaluaciones", mw.AuthMiddleware(evaluacionHandler.GetByCurso)).Methods("GET")
+    api.HandleFunc("/cursos/{id}/evaluaciones/{evaluacionId:[0-9]+}", mw.AuthMiddleware(evaluacionHandler.GetByID)).Methods("GET")
+    api.HandleFunc("/cursos/{id}/resultados", mw.AuthMiddleware(evaluacionHandler.GetResultadosByCurso)).Methods("GET")
+    api.HandleFunc("/cursos/{id}/evaluaciones/{evaluacionId:[0-9]+}/resultados", mw.AuthMiddleware(evaluacionHandler.SubmitResultado)).Methods("POST")
+
+    // --- Preguntas (banco de preguntas, solo instructores) ---
+    api.HandleFunc("/cursos/{id}/evaluaciones/{evaluacionId:[0-9]+}/preguntas", mw.PermissionMiddleware(policy.CursoContentOwn, preguntaHandler.Create)).Methods("POST")
+    api.HandleFunc("/cursos/{id}/evaluaciones/{evaluacionId:[0-9]+}/preguntas", mw.PermissionMiddleware(policy.CursoContentOwn, preguntaHandler.GetByEvaluacion)).Methods("GET")
+    api.HandleFunc("/cursos/{id}/evaluaciones/{evaluacionId:[0-9]+}/preguntas/{preguntaId:[0-9]+}", mw.PermissionMiddleware(policy.CursoContentOwn, preguntaHandler.Update)).Methods("PUT")
+    api.HandleFunc("/cursos/{id}/evaluaciones/{evaluacionId:[0-9]+}/preguntas/{preguntaId:[0-9]+}", mw.PermissionMiddleware(policy.CursoContentOwn, preguntaHandler.Delete)).Methods("DELETE")
+
+    // --- Intentos (solo alumnos) ---
+    api.HandleFunc("/cursos/{id}/evaluaciones/{evaluacionId:[0-9]+}/intentos", mw.PermissionMiddleware(policy.EvaluacionSubmit, intentoHandler.Iniciar)).Methods("POST")
+    api.HandleFunc("/cursos/{id}/evaluaciones/{evaluacionId:[0-9]+}/intentos", mw.PermissionMiddleware(policy.EvaluacionSubmit, intentoHandler.GetMyIntentos)).Methods("GET")
+    api.HandleFunc("/cursos/{id}/evaluaciones/{evaluacionId:[0-9]+}/intentos/{intentoId:[0-9]+}/enviar", mw.PermissionMiddleware(policy.EvaluacionSubmit, intentoHandler.Enviar)).Methods("POST")
+
+    // --- Certificados (solo alumnos) ---
+    api.HandleFunc("/cursos/{id}/certificado", mw.PermissionMiddleware(policy.CertificadoRequest, rl.Limit("pdf", limitePDF, certificadoHandler.Emitir))).Methods("POST")
+    api.HandleFunc("/certificados/my-certificados", mw.PermissionMiddleware(policy.CertificadoRequest, certificadoHandler.GetMyCertificados)).Methods("GET")
+
+    // Descarga para el alumno titular y el instructor del curso
+    api.HandleFunc("/certificados/{id:[0-9]+}/pdf", mw.AuthMiddleware(rl.Limit("pdf", limitePDF, certificadoHandler.DownloadPDF))).Methods("GET")
+
+    // --- Solicitudes para ser instructor (alumnos) ---
+    api.HandleFunc("/solicitudes-instructor", mw.PermissionMiddleware(policy.InstructorApply, solicitudHandler.Solicitar)).Methods("POST")
+    api.HandleFunc("/solicitudes-instructor/my-solicitudes", mw.AuthMiddleware(solicitudHandler.GetMisSolicitudes)).Methods("GET")
+
+    // --- Administración (solo admins) ---
+    api.HandleFunc("/admin/usuarios", mw.PermissionMiddleware(policy.UsuarioManage, adminHandler.SearchUsuarios)).Methods("GET")
+    api.HandleFunc("/admin/usuarios/{id:[0-9]+}/activo", mw.PermissionMiddleware(policy.UsuarioManage, adminHandler.SetUsuarioActivo)).Methods("PATCH")
+    api.HandleFunc("/admin/usuarios/{id:[0-9]+}/restaurar", mw.PermissionMiddleware(policy.UsuarioManage, adminHandler.RestaurarUsuario)).Methods("POST")
+    api.HandleFunc("/admin/cursos/{id:[0-9]+}/instructor", mw.PermissionMiddleware(policy.CursoReassign, adminHandler.ReasignarInstructor)).Methods("PUT")
+    api.HandleFunc("/admin/cursos/{id:[0-9]+}/moderacion", mw.PermissionMiddleware(policy.CursoModerate, adminHandler.ModerarCurso)).Methods("PATCH")
+    api.HandleFunc("/admin/categorias", mw.PermissionMiddleware(policy.CategoriaManage, categoriaHandler.Create)).Methods("POST")
+    api.HandleFunc("/admin/categorias/{id:[0-9]+}", mw.PermissionMiddleware(policy.CategoriaManage, categoriaHandler.Update)).Methods("PUT")
+    api.HandleFunc("/admin/categorias/{id:[0-9]+}", mw.PermissionMiddleware(policy.CategoriaManage, categoriaHandler.Delete)).Methods("DELETE")
+    api.HandleFunc("/admin/solicitudes-instructor", mw.PermissionMiddleware(policy.InstructorReview, solicitudHandler.GetByEstado)).Methods("GET")
+    api.HandleFunc("/admin/solicitudes-instructor/{id:[0-9]+}/aprobar", mw.PermissionMiddleware(policy.InstructorReview, solicitudHandler.Aprobar)).Methods("PATCH")
+    api.HandleFunc("/admin/solicitudes-instructor/{id:[0-9]+}/rechazar", mw.PermissionMiddleware(policy.InstructorReview, solicitudHandler.Rechazar)).Methods("PATCH")
+
+    // ============================================
+    // CLAVES PÚBLICAS DE FIRMA DE JWT
+    // ============================================
+    router.HandleFunc("/.well-known/jwks.json", c.JWKSHandler.GetJWKS).Methods("GET")
 
     // ============================================
     // RUTA DE SALUD
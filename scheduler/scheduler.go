@@ -0,0 +1,55 @@
+// Package scheduler ejecuta en segundo plano las tareas periódicas de la API
+// (publicación programada de cursos, limpiezas). Cada tarea corre en su
+// propia goroutine y nunca se solapa consigo misma; las tareas deben poder
+// ejecutarse a la vez en varias instancias.
+package scheduler
+
+import (
+    "context"
+    "log"
+    "time"
+)
+
+// Tarea es un trabajo que se repite cada Intervalo
+type Tarea struct {
+    Nombre    string
+    Intervalo time.Duration
+    Ejecutar  func(ahora time.Time) error
+}
+
+// Iniciar lanza las tareas y vuelve enseguida. Cada una se ejecuta una vez al
+// arrancar y después en cada intervalo, hasta que se cancela ctx.
+func Iniciar(ctx context.Context, tareas ...Tarea) {
+    for _, tarea := range tareas {
+        go ejecutar(ctx, tarea)
+    }
+}
+
+func ejecutar(ctx context.Context, tarea Tarea) {
+    ticker := time.NewTicker(tarea.Intervalo)
+    defer ticker.Stop()
+
+    for {
+        ejecutarUna(tarea)
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+// ejecutarUna ejecuta la tarea una vez. Un error o un panic se registran sin
+// detener las siguientes ejecuciones.
+func ejecutarUna(tarea Tarea) {
+    defer func() {
+        if r := recover(); r != nil {
+            log.Printf("⚠️  La tarea %s falló: %v\n", tarea.Nombre, r)
+        }
+    }()
+
+    if err := tarea.Ejecutar(time.Now()); err != nil {
+        log.Printf("⚠️  Error en la tarea %s: %v\n", tarea.Nombre, err)
+    }
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+    "cursos-api/models"
+    "cursos-api/policy"
+    "cursos-api/utils"
+    "errors"
+    "strings"
+    "time"
+)
+
+// retencionEliminados es el plazo durante el que se pueden restaurar los
+// usuarios y cursos eliminados. Pasado ese plazo la purga los borra.
+func retencionEliminados() time.Duration {
+    return utils.GetEnvDuration("SOFT_DELETE_RETENTION", 30*24*time.Hour)
+}
+
+type AdminService struct {
+    usuarioRepo   UsuarioRepository
+    cursoRepo     CursoRepository
+    sesionService *SesionService
+}
+
+func NewAdminService(usuarioRepo UsuarioRepository, cursoRepo CursoRepository, sesionService *SesionService) *AdminService {
+    return &AdminService{
+        usuarioRepo:   usuarioRepo,
+        cursoRepo:     cursoRepo,
+        sesionService: sesionService,
+    }
+}
+
+// CrearAdmin crea una cuenta de administrador. Solo se invoca desde el
+// comando create-admin; no existe endpoint para crear admins.
+func (s *AdminService) CrearAdmin(nombre, email, password string) (*models.Usuario, error) {
+    email = normalizarEmail(email)
+    if nombre == "" || email == "" || password == "" {
+        return nil, errors.New("nombre, email y contraseña son requeridos")
+    }
+
+    if len(password) < 6 {
+        return nil, errors.New("la contraseña debe tener al menos 6 caracteres")
+    }
+
+    existing, _ := s.usuarioRepo.FindByEmail(email)
+    if existing != nil {
+        return nil, errors.New("el email ya está registrado")
+    }
+
+    hashedPassword, err := utils.HashPassword(password)
+    if err != nil {
+        return nil, errors.New("error al procesar la contraseña")
+    }
+
+    usuario := &models.Usuario{
+        Nombre:       nombre,
+        Email:        email,
+        PasswordHash: hashedPassword,
+        Rol:          policy.RolAdmin,
+    }
+
+    if err := s.usuarioRepo.Create(usuario); err != nil {
+        return nil, err
+    }
+
+    // Quien ejecuta create-admin controla la cuenta: no hace falta verificar el email
+    if err := s.usuarioRepo.MarcarEmailVerificado(usuario.ID); err != nil {
+        return nil, err
+    }
+    usuario.EmailVerificado = true
+
+    usuario.PasswordHash = ""
+    return usuario, nil
+}
+
+// SearchUsuarios lista o busca usuarios por nombre/email, rol y estado
+func (s *AdminService) SearchUsuarios(filtro models.UsuarioFiltro, userRol string) ([]models.Usuario, error) {
+    if !policy.Puede(userRol, policy.UsuarioManage) {
+        return nil, errors.New("solo los administradores pueden buscar usuarios")
+    }
+
+    filtro.Query = strings.TrimSpace(filtro.Query)
+    return s.usuarioRepo.Search(filtro)
+}
+
+// SetUsuarioActivo habilita o deshabilita un usuario. Deshabilitarlo cierra
+// todas sus sesiones.
+func (s *AdminService) SetUsuarioActivo(id int, activo bool, userID int, userRol string) (*models.Usuario, error) {
+    if !policy.Puede(userRol, policy.UsuarioManage) {
+        return nil, errors.New("solo los administradores pueden deshabilitar usuarios")
+    }
+
+    if id == userID && !activo {
+        return nil, errors.New("no puedes deshabilitar tu propia cuenta")
+    }
+
+    if err := s.usuarioRepo.SetActivo(id, activo); err != nil {
+        return nil, err
+    }
+
+    if !activo {
+        if err := s.sesionService.RevocarTodas(id, MotivoDeshabilitado); err != nil {
+            return nil, err
+        }
+    }
+
+    usuario, err := s.usuarioRepo.FindByID(id)
+    if err != nil {
+        return nil, err
+    }
+
+    usuario.PasswordHash = ""
+    return usuario, nil
+}
+
+// RestaurarUsuario recupera un usuario eliminado dentro del plazo de
+// retención, junto con los cursos que se eliminaron con él
+func (s *AdminService) RestaurarUsuario(id int, userRol string) (*models.Usuario, error) {
+    if !policy.Puede(userRol, policy.UsuarioManage) {
+        return nil, errors.New("solo los administradores pueden restaurar usuarios")
+    }
+
+    usuario, err := s.usuarioRepo.FindEliminado(id)
+    if err != nil {
+        return nil, err
+    }
+
+    if usuario.DeletedAt.Before(time.Now().Add(-retencionEliminados())) {
+        return nil, errors.New("el plazo para restaurar el usuario ha vencido")
+    }
+
+    // El email se libera al eliminar la cuenta y otra puede haberlo ocupado
+    if existente, _ := s.usuarioRepo.FindByEmail(usuario.Email); existente != nil {
+        return nil, errors.New("ya existe otra cuenta con ese email")
+    }
+
+    if err := s.usuarioRepo.Restore(id); err != nil {
+        return nil, err
+    }
+
+    usuario, err = s.usuarioRepo.FindByID(id)
+    if err != nil {
+        return nil, err
+    }
+
+    usuario.PasswordHash = ""
+    return usuario, nil
+}
+
+// PurgarEliminados borra definitivamente los cursos y usuarios eliminados
+// hace más que el plazo de retención y devuelve cuántos borró de cada uno
+func (s *AdminService) PurgarEliminados(ahora time.Time) (int64, int64, error) {
+    antes := ahora.Add(-retencionEliminados())
+
+    // Primero los cursos: los de un usuario purgado caerían igualmente en
+    // cascada, pero así quedan contados
+    cursos, err := s.cursoRepo.Purgar(antes)
+    if err != nil {
+        return 0, 0, err
+    }
+
+    usuarios, err := s.usuarioRepo.Purgar(antes)
+    if err != nil {
+        return 0, cursos, err
+    }
+
+    return usuarios, cursos, nil
+}
+
+// ReasignarInstructor transfiere un curso a otro instructor activo
+func (s *AdminService) ReasignarInstructor(cursoID, instructorID int, userRol string) (*models.Curso, error) {
+    if !policy.Puede(userRol, policy.CursoReassign) {
+        return nil, errors.New("solo los administradores pueden reasignar cursos")
+    }
+
+    instructor, err := s.usuarioRepo.FindByID(instructorID)
+    if err != nil {
+        return nil, errors.New("instructor no encontrado")
+    }
+
+    if !policy.Puede(instructor.Rol, policy.CursoCreate) {
+        return nil, errors.New("el usuario especificado no es un instructor")
+    }
+
+    if !instructor.Activo {
+        return nil, errors.New("el instructor está deshabilitado")
+    }
+
+    if err := s.cursoRepo.UpdateInstructor(cursoID, instructorID); err != nil {
+        return nil, err
+    }
+
+    return s.cursoRepo.FindByID(cursoID)
+}
+
+// ModerarCurso bloquea o desbloquea un curso. Un curso bloqueado deja de ser
+// visible para los alumnos y su instructor no puede reactivarlo.
+func (s *AdminService) ModerarCurso(cursoID int, bloquear bool, motivo string, userRol string) (*models.Curso, error) {
+    if !policy.Puede(userRol, policy.CursoModerate) {
+        return nil, errors.New("solo los administradores pueden moderar cursos")
+    }
+
+    motivo = strings.TrimSpace(motivo)
+    if bloquear && motivo == "" {
+        return nil, errors.New("el motivo del bloqueo es requerido")
+    }
+    if !bloquear {
+        motivo = ""
+    }
+
+    if err := s.cursoRepo.SetBloqueo(cursoID, bloquear, motivo); err != nil {
+        return nil, err
+    }
+
+    return s.cursoRepo.FindByID(cursoID)
+}
@@ -2,46 +2,80 @@ package services
 
 import (
     "cursos-api/models"
-    "cursos-api/repository"
+    "cursos-api/policy"
+    "cursos-api/ratelimit"
     "cursos-api/utils"
     "errors"
+    "log"
+    "strings"
+    "time"
 )
 
+// LoginLimitadoError indica que el login está bloqueado temporalmente por
+// demasiados intentos fallidos
+type LoginLimitadoError struct {
+    RetryAfter time.Duration
+}
+
+func (e *LoginLimitadoError) Error() string {
+    return "demasiados intentos fallidos, inténtalo de nuevo más tarde"
+}
+
 type AuthService struct {
-    usuarioRepo *repository.UsuarioRepository
+    usuarioRepo   UsuarioRepository
+    sesionService *SesionService
+    cuentaService *CuentaService
+    mfaService    *MFAService
+    ipLimiter     *ratelimit.SlidingWindow
 }
 
-func NewAuthService() *AuthService {
+// NewAuthService crea el servicio; ipLimiter cuenta los logins fallidos por IP
+func NewAuthService(
+    usuarioRepo UsuarioRepository,
+    sesionService *SesionService,
+    cuentaService *CuentaService,
+    mfaService *MFAService,
+    ipLimiter *ratelimit.SlidingWindow,
+) *AuthService {
     return &AuthService{
-        usuarioRepo: repository.NewUsuarioRepository(),
+        usuarioRepo:   usuarioRepo,
+        sesionService: sesionService,
+        cuentaService: cuentaService,
+        mfaService:    mfaService,
+        ipLimiter:     ipLimiter,
     }
 }
 
-// Register registra un nuevo usuario
-func (s *AuthService) Register(req *models.RegisterRequest) (*models.Usuario, string,error) {
+// normalizarEmail quita los espacios y pasa el email a minúsculas, la forma en
+// que se guarda y se compara
+func normalizarEmail(email string) string {
+    return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Register registra un nuevo usuario y le envía el enlace de verificación.
+// Todas las cuentas nuevas son de alumno. Si el login exige el email
+// verificado no se abre sesión y los tokens devueltos son nil.
+func (s *AuthService) Register(req *models.RegisterRequest) (*models.Usuario, *models.TokenPair, error) {
     // Validaciones
+    req.Email = normalizarEmail(req.Email)
     if req.Nombre == "" || req.Email == "" || req.Password == "" {
-        return nil,"" ,errors.New("todos los campos son requeridos")
-    }
-
-    if req.Rol != "instructor" && req.Rol != "alumno" {
-        return nil, "",errors.New("rol inválido, debe ser 'instructor' o 'alumno'")
+        return nil, nil, errors.New("todos los campos son requeridos")
     }
 
     if len(req.Password) < 6 {
-        return nil, "", errors.New("la contraseña debe tener al menos 6 caracteres")
+        return nil, nil, errors.New("la contraseña debe tener al menos 6 caracteres")
     }
 
     // Verificar si el email ya existe
     existingUser, _ := s.usuarioRepo.FindByEmail(req.Email)
     if existingUser != nil {
-        return nil, "",errors.New("el email ya está registrado")
+        return nil, nil, errors.New("el email ya está registrado")
     }
 
     // Hash de la contraseña
     hashedPassword, err := utils.HashPassword(req.Password)
     if err != nil {
-        return nil,"", errors.New("error al procesar la contraseña")
+        return nil, nil, errors.New("error al procesar la contraseña")
     }
 
     // Crear usuario
@@ -49,56 +83,185 @@ func (s *AuthService) Register(req *models.RegisterRequest) (*models.Usuario, st
         Nombre:       req.Nombre,
         Email:        req.Email,
         PasswordHash: hashedPassword,
-        Rol:          req.Rol,
+        Rol:          policy.RolRegistro, // para ser instructor hay que solicitarlo
     }
 
     err = s.usuarioRepo.Create(usuario)
     if err != nil {
-        return nil, "",err
+        return nil, nil, err
     }
 
-    // Generar token JWT
-    token, err := utils.GenerateJWT(usuario.ID, usuario.Email, usuario.Rol)
+    // Un fallo del correo no impide el registro: el enlace se puede reenviar
+    if err := s.cuentaService.EnviarVerificacion(usuario); err != nil {
+        log.Printf("⚠️  No se pudo enviar la verificación de email (usuario %d): %v\n", usuario.ID, err)
+    }
+
+    if VerificacionEmailRequerida() {
+        return usuario, nil, nil
+    }
+
+    // Abrir sesión y generar tokens
+    tokens, err := s.sesionService.Crear(usuario)
     if err != nil {
-        return nil, "", errors.New("error al generar el token")
+        return nil, nil, err
     }
 
-    return usuario, token, nil
+    return usuario, tokens, nil
 }
 
-// Login autentica a un usuario
-func (s *AuthService) Login(req *models.LoginRequest) (*models.LoginResponse, error) {
+// Login autentica a un usuario. Los fallos se cuentan por IP y por cuenta;
+// al superar el límite devuelve un *LoginLimitadoError. Si el usuario tiene
+// 2FA la respuesta no abre sesión: lleva el token del desafío MFA que se
+// completa con CompletarLoginMFA.
+func (s *AuthService) Login(req *models.LoginRequest, ip string) (*models.LoginResponse, error) {
     // Validaciones
+    req.Email = normalizarEmail(req.Email)
     if req.Email == "" || req.Password == "" {
         return nil, errors.New("email y contraseña son requeridos")
     }
 
+    if limitado, retryAfter := s.ipLimiter.Exceeded(ip); limitado {
+        return nil, &LoginLimitadoError{RetryAfter: retryAfter}
+    }
+
     // Buscar usuario
     usuario, err := s.usuarioRepo.FindByEmail(req.Email)
     if err != nil {
+        s.ipLimiter.Add(ip)
         return nil, errors.New("credenciales inválidas")
     }
 
+    if err := verificarBloqueoLogin(usuario); err != nil {
+        return nil, err
+    }
+
     // Verificar contraseña
     if !utils.CheckPasswordHash(req.Password, usuario.PasswordHash) {
+        s.ipLimiter.Add(ip)
+        if err := s.registrarLoginFallido(usuario.ID); err != nil {
+            return nil, err
+        }
         return nil, errors.New("credenciales inválidas")
     }
 
-    // Generar token
-    token, err := utils.GenerateJWT(usuario.ID, usuario.Email, usuario.Rol)
+    return s.completarLogin(usuario)
+}
+
+// LoginExterno inicia la sesión de un usuario ya autenticado por un
+// proveedor de identidad externo. Se aplican las mismas comprobaciones que
+// tras la contraseña, incluido el desafío 2FA.
+func (s *AuthService) LoginExterno(usuario *models.Usuario) (*models.LoginResponse, error) {
+    if err := verificarBloqueoLogin(usuario); err != nil {
+        return nil, err
+    }
+
+    return s.completarLogin(usuario)
+}
+
+// completarLogin comprueba el estado de la cuenta con las credenciales ya
+// verificadas y abre la sesión o, si tiene 2FA, devuelve el desafío MFA
+func (s *AuthService) completarLogin(usuario *models.Usuario) (*models.LoginResponse, error) {
+    if !usuario.Activo {
+        return nil, errors.New("la cuenta está deshabilitada")
+    }
+
+    if VerificacionEmailRequerida() && !usuario.EmailVerificado {
+        return nil, errors.New("debes verificar tu email antes de iniciar sesión")
+    }
+
+    // Con 2FA las credenciales solas no bastan: los fallos se reinician al
+    // completar el segundo paso
+    if usuario.TOTPHabilitado {
+        mfaToken, err := utils.GenerateMFAToken(usuario.ID)
+        if err != nil {
+            return nil, err
+        }
+
+        return &models.LoginResponse{
+            MFARequerido: true,
+            MFAToken:     mfaToken,
+            MFAExpiresIn: int(utils.MFAChallengeTTL().Seconds()),
+        }, nil
+    }
+
+    return s.abrirSesion(usuario)
+}
+
+// CompletarLoginMFA completa el login de un usuario con 2FA con el token del
+// desafío y un código TOTP o de recuperación. Los códigos erróneos cuentan
+// como intentos fallidos de login.
+func (s *AuthService) CompletarLoginMFA(req *models.LoginMFARequest, ip string) (*models.LoginResponse, error) {
+    if req.MFAToken == "" || req.Codigo == "" {
+        return nil, errors.New("token de verificación y código son requeridos")
+    }
+
+    if limitado, retryAfter := s.ipLimiter.Exceeded(ip); limitado {
+        return nil, &LoginLimitadoError{RetryAfter: retryAfter}
+    }
+
+    userID, err := utils.ValidateMFAToken(req.MFAToken)
     if err != nil {
-        return nil, errors.New("error al generar el token")
+        return nil, err
+    }
+
+    usuario, err := s.usuarioRepo.FindByID(userID)
+    if err != nil {
+        return nil, errors.New("token de verificación inválido o expirado")
+    }
+
+    if err := verificarBloqueoLogin(usuario); err != nil {
+        return nil, err
+    }
+
+    if !usuario.Activo {
+        return nil, errors.New("la cuenta está deshabilitada")
+    }
+
+    if err := s.mfaService.Verificar(usuario.ID, req.Codigo); err != nil {
+        s.ipLimiter.Add(ip)
+        if errLimite := s.registrarLoginFallido(usuario.ID); errLimite != nil {
+            return nil, errLimite
+        }
+        return nil, err
+    }
+
+    return s.abrirSesion(usuario)
+}
+
+// abrirSesion reinicia los fallos de login del usuario y abre su sesión
+func (s *AuthService) abrirSesion(usuario *models.Usuario) (*models.LoginResponse, error) {
+    if err := s.usuarioRepo.ResetLoginFallidos(usuario.ID); err != nil {
+        log.Printf("⚠️  No se pudieron reiniciar los fallos de login (usuario %d): %v\n", usuario.ID, err)
+    }
+
+    // Abrir sesión y generar tokens
+    tokens, err := s.sesionService.Crear(usuario)
+    if err != nil {
+        return nil, err
     }
 
     // Limpiar el password hash antes de devolver
     usuario.PasswordHash = ""
 
     return &models.LoginResponse{
-        Token:   token,
-        Usuario: usuario,
+        TokenPair: tokens,
+        Usuario:   usuario,
     }, nil
 }
 
+// Refresh renueva el par de tokens a partir de un refresh token
+func (s *AuthService) Refresh(req *models.RefreshRequest) (*models.TokenPair, error) {
+    return s.sesionService.Refresh(req.RefreshToken)
+}
+
+// Logout cierra la sesión actual o, si todas es true, todas las del usuario
+func (s *AuthService) Logout(userID int, sesionID string, todas bool) error {
+    if todas {
+        return s.sesionService.RevocarTodas(userID, MotivoLogout)
+    }
+    return s.sesionService.Logout(sesionID)
+}
+
 // GetProfile obtiene el perfil de un usuario
 func (s *AuthService) GetProfile(userID int) (*models.Usuario, error) {
     usuario, err := s.usuarioRepo.FindByID(userID)
@@ -111,3 +274,57 @@ func (s *AuthService) GetProfile(userID int) (*models.Usuario, error) {
 
     return usuario, nil
 }
+
+// verificarBloqueoLogin devuelve un *LoginLimitadoError si la cuenta tiene el
+// login bloqueado; mientras dure no se comprueba ninguna credencial
+func verificarBloqueoLogin(usuario *models.Usuario) error {
+    if usuario.LoginBloqueadoHasta != nil {
+        if restante := time.Until(*usuario.LoginBloqueadoHasta); restante > 0 {
+            return &LoginLimitadoError{RetryAfter: restante}
+        }
+    }
+    return nil
+}
+
+// registrarLoginFallido anota un fallo de credenciales de la cuenta y la
+// bloquea al llegar al máximo, devolviendo entonces un *LoginLimitadoError.
+// Cada bloqueo consecutivo dura el doble que el anterior; el nivel baja uno
+// por cada LOGIN_LOCKOUT_DECAY sin bloqueos.
+func (s *AuthService) registrarLoginFallido(usuarioID int) error {
+    intentos, bloqueos, err := s.usuarioRepo.RegistrarLoginFallido(
+        usuarioID,
+        utils.GetEnvDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+        utils.GetEnvDuration("LOGIN_LOCKOUT_DECAY", 24*time.Hour),
+    )
+    if err != nil {
+        log.Printf("⚠️  No se pudo registrar el fallo de login (usuario %d): %v\n", usuarioID, err)
+        return nil
+    }
+
+    if intentos < utils.GetEnvInt("LOGIN_MAX_FAILED_ATTEMPTS", 5) {
+        return nil
+    }
+
+    duracion := duracionBloqueoLogin(bloqueos)
+    if err := s.usuarioRepo.BloquearLogin(usuarioID, time.Now().Add(duracion), bloqueos+1); err != nil {
+        log.Printf("⚠️  No se pudo bloquear el login (usuario %d): %v\n", usuarioID, err)
+    }
+
+    return &LoginLimitadoError{RetryAfter: duracion}
+}
+
+// duracionBloqueoLogin calcula la duración del bloqueo tras los bloqueos
+// previos: LOGIN_LOCKOUT_BASE duplicado en cada uno, hasta LOGIN_LOCKOUT_MAX
+func duracionBloqueoLogin(bloqueosPrevios int) time.Duration {
+    duracion := utils.GetEnvDuration("LOGIN_LOCKOUT_BASE", time.Minute)
+    maximo := utils.GetEnvDuration("LOGIN_LOCKOUT_MAX", time.Hour)
+
+    for i := 0; i < bloqueosPrevios && duracion < maximo; i++ {
+        duracion *= 2
+    }
+
+    if duracion > maximo {
+        return maximo
+    }
+    return duracion
+}
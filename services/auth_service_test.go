@@ -0,0 +1,119 @@
+package services_test
+
+import (
+    "cursos-api/models"
+    "cursos-api/policy"
+    "cursos-api/services"
+    "cursos-api/utils"
+    "errors"
+    "testing"
+)
+
+func TestAuthServiceRegisterCreaAlumnos(t *testing.T) {
+    e := nuevoEntorno(t)
+
+    usuario, tokens, err := e.auth.Register(&models.RegisterRequest{Nombre: "Ana", Email: "ana@example.com", Password: passwordPruebas})
+    if err != nil {
+        t.Fatalf("Register: %v", err)
+    }
+    if usuario.Rol != policy.RolRegistro {
+        t.Fatalf("Register asignó el rol %q, se esperaba %q", usuario.Rol, policy.RolRegistro)
+    }
+    if tokens == nil || tokens.Token == "" {
+        t.Fatal("Register no abrió sesión")
+    }
+
+    claims, err := utils.ValidateJWT(tokens.Token)
+    if err != nil {
+        t.Fatalf("ValidateJWT: %v", err)
+    }
+    if claims.UserID != usuario.ID || claims.Rol != policy.RolRegistro {
+        t.Fatalf("claims = (%d, %q), se esperaba (%d, %q)", claims.UserID, claims.Rol, usuario.ID, policy.RolRegistro)
+    }
+}
+
+func TestAuthServiceRegisterRechazaEmailEnUso(t *testing.T) {
+    e := nuevoEntorno(t)
+    e.crearUsuario(t, "ana@example.com", policy.RolInstructor)
+
+    if _, _, err := e.auth.Register(&models.RegisterRequest{Nombre: "Otra Ana", Email: "ana@example.com", Password: passwordPruebas}); err == nil {
+        t.Fatal("Register aceptó un email en uso")
+    }
+    if _, _, err := e.auth.Register(&models.RegisterRequest{Nombre: "Otra Ana", Email: "Ana@Example.com", Password: passwordPruebas}); err == nil {
+        t.Fatal("Register aceptó un email en uso escrito con mayúsculas")
+    }
+}
+
+func TestAuthServiceNormalizaEmail(t *testing.T) {
+    e := nuevoEntorno(t)
+
+    usuario, _, err := e.auth.Register(&models.RegisterRequest{Nombre: "Ana", Email: " Ana@Example.COM ", Password: passwordPruebas})
+    if err != nil {
+        t.Fatalf("Register: %v", err)
+    }
+    if usuario.Email != "ana@example.com" {
+        t.Fatalf("Register guardó el email %q, se esperaba en minúsculas", usuario.Email)
+    }
+
+    login, err := e.auth.Login(&models.LoginRequest{Email: "ANA@example.com", Password: passwordPruebas}, "10.0.0.1")
+    if err != nil {
+        t.Fatalf("Login con otras mayúsculas: %v", err)
+    }
+    if login.Usuario.ID != usuario.ID {
+        t.Fatalf("Login entró como %d, se esperaba %d", login.Usuario.ID, usuario.ID)
+    }
+}
+
+func TestAuthServiceRegisterValidaCampos(t *testing.T) {
+    e := nuevoEntorno(t)
+
+    invalidos := []models.RegisterRequest{
+        {Email: "ana@example.com", Password: passwordPruebas},
+        {Nombre: "Ana", Password: passwordPruebas},
+        {Nombre: "Ana", Email: "ana@example.com", Password: "corta"},
+    }
+    for _, req := range invalidos {
+        req := req
+        if _, _, err := e.auth.Register(&req); err == nil {
+            t.Fatalf("Register aceptó %+v", req)
+        }
+    }
+}
+
+func TestAuthServiceLoginBloqueaTrasFallos(t *testing.T) {
+    t.Setenv("LOGIN_MAX_FAILED_ATTEMPTS", "3")
+    e := nuevoEntorno(t)
+    ana := e.crearUsuario(t, "ana@example.com", policy.RolAlumno)
+
+    incorrecto := &models.LoginRequest{Email: ana.Email, Password: "incorrecta"}
+    for i := 1; i < 3; i++ {
+        _, err := e.auth.Login(incorrecto, "10.0.0.1")
+        var limitado *services.LoginLimitadoError
+        if err == nil || errors.As(err, &limitado) {
+            t.Fatalf("fallo %d: Login = %v, se esperaba credenciales inválidas", i, err)
+        }
+    }
+
+    _, err := e.auth.Login(incorrecto, "10.0.0.1")
+    var limitado *services.LoginLimitadoError
+    if !errors.As(err, &limitado) || limitado.RetryAfter <= 0 {
+        t.Fatalf("el tercer fallo devolvió %v, se esperaba un bloqueo", err)
+    }
+
+    // Mientras dura el bloqueo ni la contraseña correcta abre sesión
+    if _, err := e.auth.Login(&models.LoginRequest{Email: ana.Email, Password: passwordPruebas}, "10.0.0.1"); !errors.As(err, &limitado) {
+        t.Fatalf("Login durante el bloqueo = %v, se esperaba un bloqueo", err)
+    }
+}
+
+func TestAuthServiceLoginCuentaDeshabilitada(t *testing.T) {
+    e := nuevoEntorno(t)
+    ana := e.crearUsuario(t, "ana@example.com", policy.RolAlumno)
+    if err := e.usuarios.SetActivo(ana.ID, false); err != nil {
+        t.Fatalf("SetActivo: %v", err)
+    }
+
+    if _, err := e.auth.Login(&models.LoginRequest{Email: ana.Email, Password: passwordPruebas}, "10.0.0.1"); err == nil {
+        t.Fatal("una cuenta deshabilitada pudo iniciar sesión")
+    }
+}
@@ -0,0 +1,308 @@
+package services
+
+import (
+    "cursos-api/models"
+    "cursos-api/policy"
+    "errors"
+    "strings"
+    "unicode/utf8"
+)
+
+// Límites de la taxonomía de un curso
+const (
+    maxCategoriasCurso  = 10
+    maxEtiquetasCurso   = 20
+    maxLongitudEtiqueta = 40
+)
+
+type CategoriaService struct {
+    categoriaRepo CategoriaRepository
+    cursoRepo     CursoRepository
+}
+
+func NewCategoriaService(categoriaRepo CategoriaRepository, cursoRepo CursoRepository) *CategoriaService {
+    return &CategoriaService{
+        categoriaRepo: categoriaRepo,
+        cursoRepo:     cursoRepo,
+    }
+}
+
+// Arbol obtiene las categorías raíz con sus subcategorías anidadas
+func (s *CategoriaService) Arbol() ([]models.Categoria, error) {
+    categorias, err := s.categoriaRepo.GetAll()
+    if err != nil {
+        return nil, err
+    }
+
+    hijas := make(map[int][]models.Categoria)
+    raices := []models.Categoria{}
+    for _, categoria := range categorias {
+        if categoria.PadreID == nil {
+            raices = append(raices, categoria)
+        } else {
+            hijas[*categoria.PadreID] = append(hijas[*categoria.PadreID], categoria)
+        }
+    }
+
+    // anidar recorre el árbol desde las raíces; GetAll ya viene ordenado por
+    // nombre y el orden se conserva en cada nivel
+    var anidar func(nivel []models.Categoria) []models.Categoria
+    anidar = func(nivel []models.Categoria) []models.Categoria {
+        for i := range nivel {
+            if h, ok := hijas[nivel[i].ID]; ok {
+                nivel[i].Hijas = anidar(h)
+            }
+        }
+        return nivel
+    }
+
+    return anidar(raices), nil
+}
+
+// Create crea una categoría
+func (s *CategoriaService) Create(req models.CategoriaRequest, userRol string) (*models.Categoria, error) {
+    if !policy.Puede(userRol, policy.CategoriaManage) {
+        return nil, errors.New("no tienes permiso para gestionar categorías")
+    }
+
+    categoria, err := s.validarCategoria(0, req)
+    if err != nil {
+        return nil, err
+    }
+
+    if err := s.categoriaRepo.Create(categoria); err != nil {
+        return nil, err
+    }
+
+    return categoria, nil
+}
+
+// Update actualiza una categoría. Se puede mover a otro padre siempre que no
+// quede colgando de sí misma o de una de sus subcategorías.
+func (s *CategoriaService) Update(id int, req models.CategoriaRequest, userRol string) (*models.Categoria, error) {
+    if !policy.Puede(userRol, policy.CategoriaManage) {
+        return nil, errors.New("no tienes permiso para gestionar categorías")
+    }
+
+    if _, err := s.categoriaRepo.FindByID(id); err != nil {
+        return nil, err
+    }
+
+    categoria, err := s.validarCategoria(id, req)
+    if err != nil {
+        return nil, err
+    }
+
+    if err := s.categoriaRepo.Update(id, categoria); err != nil {
+        return nil, err
+    }
+
+    return categoria, nil
+}
+
+// Delete elimina una categoría sin subcategorías
+func (s *CategoriaService) Delete(id int, userRol string) error {
+    if !policy.Puede(userRol, policy.CategoriaManage) {
+        return errors.New("no tienes permiso para gestionar categorías")
+    }
+
+    categorias, err := s.categoriaRepo.GetAll()
+    if err != nil {
+        return err
+    }
+
+    for _, categoria := range categorias {
+        if categoria.PadreID != nil && *categoria.PadreID == id {
+            return errors.New("la categoría tiene subcategorías, muévelas o elimínalas antes")
+        }
+    }
+
+    return s.categoriaRepo.Delete(id)
+}
+
+// validarCategoria valida los datos de una categoría nueva (id 0) o existente
+func (s *CategoriaService) validarCategoria(id int, req models.CategoriaRequest) (*models.Categoria, error) {
+    nombre := strings.TrimSpace(req.Nombre)
+    if nombre == "" {
+        return nil, errors.New("el nombre de la categoría es requerido")
+    }
+    if utf8.RuneCountInString(nombre) > 100 {
+        return nil, errors.New("el nombre de la categoría no puede superar 100 caracteres")
+    }
+
+    slug := req.Slug
+    if slug == "" {
+        slug = nombre
+    }
+    slug = slugify(slug)
+    if slug == "" || len(slug) > 100 {
+        return nil, errors.New("slug inválido")
+    }
+
+    if existente, err := s.categoriaRepo.FindBySlug(slug); err == nil && existente.ID != id {
+        return nil, errors.New("ya existe una categoría con ese slug")
+    }
+
+    if req.PadreID != nil {
+        if err := s.validarPadre(id, *req.PadreID); err != nil {
+            return nil, err
+        }
+    }
+
+    return &models.Categoria{
+        Nombre:      nombre,
+        Slug:        slug,
+        Descripcion: strings.TrimSpace(req.Descripcion),
+        PadreID:     req.PadreID,
+    }, nil
+}
+
+// validarPadre comprueba que el padre existe y que colgar de él la categoría
+// id no crea un ciclo. El repositorio repite la comprobación del ciclo con
+// las categorías bloqueadas; esta da el error sin abrir una transacción.
+func (s *CategoriaService) validarPadre(id, padreID int) error {
+    categorias, err := s.categoriaRepo.GetAll()
+    if err != nil {
+        return err
+    }
+
+    padres := make(map[int]*int, len(categorias))
+    for _, categoria := range categorias {
+        padres[categoria.ID] = categoria.PadreID
+    }
+
+    if _, ok := padres[padreID]; !ok {
+        return errors.New("categoría padre no encontrada")
+    }
+
+    // Subir desde el nuevo padre hasta la raíz sin pasar por la propia
+    // categoría. Ningún camino a la raíz tiene más pasos que categorías: si
+    // los hay, los datos ya contienen un ciclo.
+    pasos := 0
+    for actual := &padreID; actual != nil; actual = padres[*actual] {
+        if id != 0 && *actual == id {
+            return errors.New("una categoría no puede colgar de sí misma ni de sus subcategorías")
+        }
+        pasos++
+        if pasos > len(categorias) {
+            return errors.New("la jerarquía de categorías contiene un ciclo")
+        }
+    }
+
+    return nil
+}
+
+// AsignarCategorias reemplaza las categorías de un curso del instructor
+func (s *CategoriaService) AsignarCategorias(cursoID int, categoriaIDs []int, userID int, userRol string) (*models.Curso, error) {
+    if _, err := s.cursoPropio(cursoID, userID, userRol); err != nil {
+        return nil, err
+    }
+
+    if len(categoriaIDs) > maxCategoriasCurso {
+        return nil, errors.New("un curso no puede tener más de 10 categorías")
+    }
+
+    categorias, err := s.categoriaRepo.GetAll()
+    if err != nil {
+        return nil, err
+    }
+    existentes := make(map[int]bool, len(categorias))
+    for _, categoria := range categorias {
+        existentes[categoria.ID] = true
+    }
+
+    ids := []int{}
+    vistas := make(map[int]bool)
+    for _, id := range categoriaIDs {
+        if !existentes[id] {
+            return nil, errors.New("categoría no encontrada")
+        }
+        if !vistas[id] {
+            vistas[id] = true
+            ids = append(ids, id)
+        }
+    }
+
+    if err := s.categoriaRepo.AsignarCategorias(cursoID, ids); err != nil {
+        return nil, err
+    }
+
+    return s.cursoRepo.FindByID(cursoID)
+}
+
+// AsignarEtiquetas reemplaza las etiquetas de un curso del instructor
+func (s *CategoriaService) AsignarEtiquetas(cursoID int, etiquetas []string, userID int, userRol string) (*models.Curso, error) {
+    if _, err := s.cursoPropio(cursoID, userID, userRol); err != nil {
+        return nil, err
+    }
+
+    nombres := []string{}
+    vistas := make(map[string]bool)
+    for _, etiqueta := range etiquetas {
+        nombre := NormalizarEtiqueta(etiqueta)
+        if nombre == "" {
+            return nil, errors.New("las etiquetas no pueden estar vacías")
+        }
+        if utf8.RuneCountInString(nombre) > maxLongitudEtiqueta {
+            return nil, errors.New("las etiquetas no pueden superar 40 caracteres")
+        }
+        if !vistas[nombre] {
+            vistas[nombre] = true
+            nombres = append(nombres, nombre)
+        }
+    }
+
+    if len(nombres) > maxEtiquetasCurso {
+        return nil, errors.New("un curso no puede tener más de 20 etiquetas")
+    }
+
+    if err := s.categoriaRepo.AsignarEtiquetas(cursoID, nombres); err != nil {
+        return nil, err
+    }
+
+    return s.cursoRepo.FindByID(cursoID)
+}
+
+// Etiquetas obtiene las etiquetas en uso en el catálogo
+func (s *CategoriaService) Etiquetas() ([]models.Etiqueta, error) {
+    return s.categoriaRepo.GetEtiquetas()
+}
+
+// cursoPropio obtiene el curso si el usuario puede modificarlo
+func (s *CategoriaService) cursoPropio(cursoID, userID int, userRol string) (*models.Curso, error) {
+    curso, err := s.cursoRepo.FindByID(cursoID)
+    if err != nil || !policy.PuedeSobre(userRol, policy.CursoUpdateOwn, userID, curso.InstructorID) {
+        return nil, errors.New("curso no encontrado o no tienes permiso para modificarlo")
+    }
+    return curso, nil
+}
+
+// NormalizarEtiqueta pasa una etiqueta a minúsculas con espacios simples. Es
+// la forma en que se guardan y se filtran.
+func NormalizarEtiqueta(etiqueta string) string {
+    return strings.Join(strings.Fields(strings.ToLower(etiqueta)), " ")
+}
+
+// sinAcentosSlug quita los acentos del español para construir slugs
+var sinAcentosSlug = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n")
+
+// slugify convierte un texto en un slug: minúsculas sin acentos, con guiones
+// entre las palabras
+func slugify(texto string) string {
+    texto = sinAcentosSlug.Replace(strings.ToLower(texto))
+
+    var b strings.Builder
+    guion := false
+    for _, r := range texto {
+        if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+            if guion && b.Len() > 0 {
+                b.WriteByte('-')
+            }
+            b.WriteRune(r)
+            guion = false
+        } else {
+            guion = true
+        }
+    }
+    return b.String()
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+    "cursos-api/models"
+    "cursos-api/utils"
+    "fmt"
+    "os"
+    "strings"
+)
+
+// Tamaño A4 horizontal en puntos
+const (
+    certificadoAncho = 842.0
+    certificadoAlto  = 595.0
+)
+
+// AppBaseURL es la URL pública de la API (APP_BASE_URL), sin barra final
+func AppBaseURL() string {
+    base := os.Getenv("APP_BASE_URL")
+    if base == "" {
+        base = "http://localhost:8080"
+    }
+
+    return strings.TrimRight(base, "/")
+}
+
+// verificacionURL construye la URL pública de verificación de un certificado
+func verificacionURL(codigo string) string {
+    return AppBaseURL() + "/api/certificados/verify/" + codigo
+}
+
+// renderCertificadoPDF genera el documento PDF de un certificado. Requiere el
+// certificado con su inscripción, alumno, curso e instructor cargados.
+func renderCertificadoPDF(certificado *models.Certificado) ([]byte, error) {
+    inscripcion := certificado.Inscripcion
+    if inscripcion == nil || inscripcion.Usuario == nil || inscripcion.Curso == nil || inscripcion.Curso.Instructor == nil {
+        return nil, fmt.Errorf("datos incompletos para generar el certificado %d", certificado.ID)
+    }
+
+    url := verificacionURL(certificado.CodigoCertificado)
+    qr, err := utils.EncodeQR(url)
+    if err != nil {
+        return nil, err
+    }
+
+    doc := utils.NewPDFDocument(certificadoAncho, certificadoAlto)
+
+    // Marco
+    doc.SetStrokeColor(0.12, 0.23, 0.45)
+    doc.Rect(20, 20, certificadoAncho-40, certificadoAlto-40, false, 4)
+    doc.Rect(30, 30, certificadoAncho-60, certificadoAlto-60, false, 1)
+
+    // Encabezado
+    doc.SetFillColor(0.12, 0.23, 0.45)
+    doc.TextCentered(490, utils.PDFFontBold, 34, "CERTIFICADO DE FINALIZACIÓN")
+
+    doc.SetFillColor(0.2, 0.2, 0.2)
+    doc.TextCentered(440, utils.PDFFontRegular, 16, "Se certifica que")
+
+    doc.SetFillColor(0, 0, 0)
+    doc.TextCentered(395, utils.PDFFontBold, 30, inscripcion.Usuario.Nombre)
+    doc.Line(200, 385, certificadoAncho-200, 385, 0.8)
+
+    doc.SetFillColor(0.2, 0.2, 0.2)
+    doc.TextCentered(350, utils.PDFFontRegular, 16, "ha completado satisfactoriamente el curso")
+
+    doc.SetFillColor(0.12, 0.23, 0.45)
+    doc.TextCentered(310, utils.PDFFontBold, 24, inscripcion.Curso.Nombre)
+
+    doc.SetFillColor(0.2, 0.2, 0.2)
+    doc.TextCentered(275, utils.PDFFontRegular, 14,
+        fmt.Sprintf("con una duración de %d horas", inscripcion.Curso.DuracionHoras))
+
+    // Pie: instructor y fecha a la izquierda, QR a la derecha
+    doc.SetFillColor(0, 0, 0)
+    doc.Line(80, 160, 320, 160, 0.8)
+    doc.Text(80, 143, utils.PDFFontBold, 13, inscripcion.Curso.Instructor.Nombre)
+    doc.Text(80, 127, utils.PDFFontRegular, 11, "Instructor")
+
+    doc.Text(80, 95, utils.PDFFontRegular, 11,
+        "Fecha de emisión: "+certificado.FechaEmision.Format("02/01/2006"))
+    doc.Text(80, 78, utils.PDFFontRegular, 11, "Código: "+certificado.CodigoCertificado)
+
+    doc.QRCode(certificadoAncho-200, 60, 130, qr)
+    doc.SetFillColor(0.3, 0.3, 0.3)
+    doc.Text(certificadoAncho-200, 48, utils.PDFFontRegular, 8, "Escanea para verificar")
+
+    return doc.Bytes()
+}
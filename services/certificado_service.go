@@ -0,0 +1,194 @@
+package services
+
+import (
+    "cursos-api/models"
+    "cursos-api/policy"
+    "cursos-api/storage"
+    "cursos-api/utils"
+    "errors"
+    "io"
+    "log"
+    "strconv"
+    "strings"
+)
+
+// bytesCodigoCertificado define la entropía del código (160 bits)
+const bytesCodigoCertificado = 20
+
+type CertificadoService struct {
+    certificadoRepo CertificadoRepository
+    inscripcionRepo InscripcionRepository
+    evaluacionRepo  EvaluacionRepository
+    store           storage.BlobStore
+}
+
+func NewCertificadoService(
+    certificadoRepo CertificadoRepository,
+    inscripcionRepo InscripcionRepository,
+    evaluacionRepo EvaluacionRepository,
+    store storage.BlobStore,
+) *CertificadoService {
+    return &CertificadoService{
+        certificadoRepo: certificadoRepo,
+        inscripcionRepo: inscripcionRepo,
+        evaluacionRepo:  evaluacionRepo,
+        store:           store,
+    }
+}
+
+// Emitir emite el certificado del alumno en un curso si su inscripción está
+// completada y aprobó todas las evaluaciones requeridas. Si ya fue emitido se
+// devuelve el existente.
+func (s *CertificadoService) Emitir(cursoID int, userID int) (*models.Certificado, error) {
+    inscripcion, err := s.inscripcionRepo.FindByUsuarioAndCurso(userID, cursoID)
+    if err != nil {
+        return nil, errors.New("no estás inscrito en este curso")
+    }
+
+    if existing, err := s.certificadoRepo.FindByInscripcion(inscripcion.ID); err == nil {
+        return existing, nil
+    }
+
+    if inscripcion.Estado != EstadoInscripcionCompletado {
+        return nil, errors.New("debes completar el curso para obtener el certificado")
+    }
+
+    pendientes, err := s.evaluacionRepo.CountRequeridasPendientes(cursoID, userID)
+    if err != nil {
+        return nil, err
+    }
+
+    if pendientes > 0 {
+        return nil, errors.New("debes aprobar todas las evaluaciones requeridas para obtener el certificado")
+    }
+
+    codigo, err := utils.GenerateRandomCode(bytesCodigoCertificado)
+    if err != nil {
+        return nil, errors.New("error al generar el código del certificado")
+    }
+
+    certificado := &models.Certificado{
+        InscripcionID:     inscripcion.ID,
+        CodigoCertificado: codigo,
+    }
+
+    err = s.certificadoRepo.Create(certificado)
+    if err != nil {
+        return nil, err
+    }
+
+    // Si el PDF falla se generará al descargarlo
+    if certificado.URLPDF == "" {
+        if _, err := s.generarPDF(certificado.ID); err != nil {
+            log.Printf("⚠️  No se pudo generar el PDF del certificado %d: %v\n", certificado.ID, err)
+        } else {
+            certificado.URLPDF = urlPDF(certificado.ID)
+        }
+    }
+
+    return certificado, nil
+}
+
+// EmitirSiCorresponde intenta emitir el certificado tras un cambio de progreso
+// o de resultados. No falla la operación original: si el alumno aún no cumple
+// los requisitos no hace nada y los errores inesperados solo se registran.
+func (s *CertificadoService) EmitirSiCorresponde(cursoID int, userID int) *models.Certificado {
+    inscripcion, err := s.inscripcionRepo.FindByUsuarioAndCurso(userID, cursoID)
+    if err != nil || inscripcion.Estado != EstadoInscripcionCompletado {
+        return nil
+    }
+
+    pendientes, err := s.evaluacionRepo.CountRequeridasPendientes(cursoID, userID)
+    if err != nil || pendientes > 0 {
+        return nil
+    }
+
+    certificado, err := s.Emitir(cursoID, userID)
+    if err != nil {
+        log.Printf("⚠️  No se pudo emitir el certificado (curso %d, usuario %d): %v\n", cursoID, userID, err)
+        return nil
+    }
+
+    return certificado
+}
+
+// GetPDF obtiene el PDF de un certificado para su alumno o quien gestiona el curso
+func (s *CertificadoService) GetPDF(id int, userID int, userRol string) ([]byte, *models.Certificado, error) {
+    certificado, err := s.certificadoRepo.FindDetalleByID(id)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    esTitular := certificado.Inscripcion.UsuarioID == userID
+    esInstructor := policy.PuedeSobre(userRol, policy.CursoContentOwn, userID, certificado.Inscripcion.Curso.InstructorID)
+    if !esTitular && !esInstructor {
+        return nil, nil, errors.New("certificado no encontrado o no tienes permiso")
+    }
+
+    file, err := s.store.Get(pdfKey(certificado.CodigoCertificado))
+    if err == storage.ErrNotFound {
+        pdf, err := s.generarPDF(id)
+        return pdf, certificado, err
+    }
+    if err != nil {
+        return nil, nil, err
+    }
+    defer file.Close()
+
+    pdf, err := io.ReadAll(file)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    return pdf, certificado, nil
+}
+
+// generarPDF renderiza el PDF de un certificado, lo guarda y actualiza su URL
+func (s *CertificadoService) generarPDF(id int) ([]byte, error) {
+    certificado, err := s.certificadoRepo.FindDetalleByID(id)
+    if err != nil {
+        return nil, err
+    }
+
+    pdf, err := renderCertificadoPDF(certificado)
+    if err != nil {
+        return nil, err
+    }
+
+    err = s.store.Put(pdfKey(certificado.CodigoCertificado), pdf, "application/pdf")
+    if err != nil {
+        return nil, err
+    }
+
+    err = s.certificadoRepo.UpdateURLPDF(id, urlPDF(id))
+    if err != nil {
+        return nil, err
+    }
+
+    return pdf, nil
+}
+
+// pdfKey es la clave de almacenamiento del PDF de un certificado
+func pdfKey(codigo string) string {
+    return "certificados/" + codigo + ".pdf"
+}
+
+// urlPDF es la ruta autenticada de descarga del PDF de un certificado
+func urlPDF(id int) string {
+    return "/api/certificados/" + strconv.Itoa(id) + "/pdf"
+}
+
+// GetMyCertificados obtiene los certificados del usuario autenticado
+func (s *CertificadoService) GetMyCertificados(userID int) ([]models.Certificado, error) {
+    return s.certificadoRepo.GetByUsuario(userID)
+}
+
+// Verify obtiene los datos públicos de un certificado a partir de su código
+func (s *CertificadoService) Verify(codigo string) (*models.CertificadoVerificacion, error) {
+    codigo = strings.ToUpper(strings.TrimSpace(codigo))
+    if codigo == "" {
+        return nil, errors.New("certificado no encontrado")
+    }
+
+    return s.certificadoRepo.FindVerificacionByCodigo(codigo)
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+    "cursos-api/mailer"
+    "cursos-api/models"
+    "cursos-api/utils"
+    "errors"
+    "fmt"
+    "log"
+    "net/url"
+    "os"
+    "time"
+)
+
+// Tipos de token de un solo uso enviados por email
+const (
+    TokenVerificacionEmail = "verificacion_email"
+    TokenResetPassword     = "reset_password"
+)
+
+// bytesTokenUsuario define la entropía de los tokens enviados por email (256 bits)
+const bytesTokenUsuario = 32
+
+// CuentaService gestiona la verificación de email y el restablecimiento de
+// contraseña mediante tokens de un solo uso enviados por correo
+type CuentaService struct {
+    usuarioRepo   UsuarioRepository
+    tokenRepo     TokenUsuarioRepository
+    sesionService *SesionService
+    mailer        mailer.Mailer
+}
+
+func NewCuentaService(
+    usuarioRepo UsuarioRepository,
+    tokenRepo TokenUsuarioRepository,
+    sesionService *SesionService,
+    mailer mailer.Mailer,
+) *CuentaService {
+    return &CuentaService{
+        usuarioRepo:   usuarioRepo,
+        tokenRepo:     tokenRepo,
+        sesionService: sesionService,
+        mailer:        mailer,
+    }
+}
+
+// VerificacionEmailRequerida indica si el login exige el email verificado
+// (REQUIRE_EMAIL_VERIFICATION=true)
+func VerificacionEmailRequerida() bool {
+    return os.Getenv("REQUIRE_EMAIL_VERIFICATION") == "true"
+}
+
+// EnviarVerificacion envía al usuario un enlace para verificar su email. Los
+// enlaces anteriores dejan de valer.
+func (s *CuentaService) EnviarVerificacion(usuario *models.Usuario) error {
+    if usuario.EmailVerificado {
+        return errors.New("el email ya está verificado")
+    }
+
+    token, err := s.emitirToken(usuario.ID, TokenVerificacionEmail, utils.GetEnvDuration("EMAIL_VERIFICATION_TTL", 48*time.Hour))
+    if err != nil {
+        return err
+    }
+
+    enlace := AppBaseURL() + "/api/auth/verify-email?token=" + url.QueryEscape(token)
+
+    return s.mailer.Send(mailer.Message{
+        To:      usuario.Email,
+        Subject: "Verifica tu correo electrónico",
+        Body: fmt.Sprintf("Hola %s,\n\nPara verificar tu correo abre el siguiente enlace:\n\n%s\n\n"+
+            "Si no creaste una cuenta, ignora este mensaje.\n", usuario.Nombre, enlace),
+    })
+}
+
+// ReenviarVerificacion vuelve a enviar el enlace de verificación al usuario autenticado
+func (s *CuentaService) ReenviarVerificacion(userID int) error {
+    usuario, err := s.usuarioRepo.FindByID(userID)
+    if err != nil {
+        return err
+    }
+
+    return s.EnviarVerificacion(usuario)
+}
+
+// VerificarEmail consume un token de verificación y marca el email como verificado
+func (s *CuentaService) VerificarEmail(token string) error {
+    if token == "" {
+        return errors.New("token requerido")
+    }
+
+    consumido, err := s.tokenRepo.Consumir(TokenVerificacionEmail, utils.HashToken(token))
+    if err != nil {
+        return err
+    }
+
+    return s.usuarioRepo.MarcarEmailVerificado(consumido.UsuarioID)
+}
+
+// SolicitarResetPassword envía un enlace para restablecer la contraseña. No
+// revela si el email existe: siempre termina sin error para el cliente.
+func (s *CuentaService) SolicitarResetPassword(email string) {
+    usuario, err := s.usuarioRepo.FindByEmail(normalizarEmail(email))
+    if err != nil || !usuario.Activo {
+        return
+    }
+
+    token, err := s.emitirToken(usuario.ID, TokenResetPassword, utils.GetEnvDuration("PASSWORD_RESET_TTL", time.Hour))
+    if err != nil {
+        log.Printf("Error al generar el token de restablecimiento (usuario %d): %v\n", usuario.ID, err)
+        return
+    }
+
+    err = s.mailer.Send(mailer.Message{
+        To:      usuario.Email,
+        Subject: "Restablece tu contraseña",
+        Body: fmt.Sprintf("Hola %s,\n\nPara elegir una contraseña nueva abre el siguiente enlace:\n\n%s\n\n"+
+            "El enlace caduca pronto y solo puede usarse una vez. Si no lo pediste, ignora este mensaje.\n",
+            usuario.Nombre, resetPasswordURL(token)),
+    })
+    if err != nil {
+        log.Printf("Error al enviar el correo de restablecimiento (usuario %d): %v\n", usuario.ID, err)
+    }
+}
+
+// ResetPassword consume un token de restablecimiento, cambia la contraseña y
+// cierra todas las sesiones del usuario
+func (s *CuentaService) ResetPassword(req *models.ResetPasswordRequest) error {
+    if req.Token == "" || req.NewPassword == "" {
+        return errors.New("token y nueva contraseña son requeridos")
+    }
+
+    if len(req.NewPassword) < 6 {
+        return errors.New("la nueva contraseña debe tener al menos 6 caracteres")
+    }
+
+    consumido, err := s.tokenRepo.Consumir(TokenResetPassword, utils.HashToken(req.Token))
+    if err != nil {
+        return err
+    }
+
+    newHash, err := utils.HashPassword(req.NewPassword)
+    if err != nil {
+        return errors.New("error al procesar la nueva contraseña")
+    }
+
+    if err := s.usuarioRepo.UpdatePassword(consumido.UsuarioID, newHash); err != nil {
+        return err
+    }
+
+    // Recibir el enlace demuestra que el email es suyo
+    if err := s.usuarioRepo.MarcarEmailVerificado(consumido.UsuarioID); err != nil {
+        return err
+    }
+
+    return s.sesionService.RevocarTodas(consumido.UsuarioID, MotivoResetPassword)
+}
+
+// AnularEnlaces anula los enlaces de verificación y de restablecimiento
+// pendientes del usuario. Se usa al cambiar su email: se enviaron a la
+// dirección anterior y consumirlos verificaría la nueva.
+func (s *CuentaService) AnularEnlaces(usuarioID int) error {
+    for _, tipo := range []string{TokenVerificacionEmail, TokenResetPassword} {
+        if err := s.tokenRepo.InvalidarPorUsuario(usuarioID, tipo); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// emitirToken anula los tokens previos del mismo tipo y guarda uno nuevo
+func (s *CuentaService) emitirToken(usuarioID int, tipo string, ttl time.Duration) (string, error) {
+    token, err := utils.GenerateRandomToken(bytesTokenUsuario)
+    if err != nil {
+        return "", errors.New("error al generar el token")
+    }
+
+    if err := s.tokenRepo.InvalidarPorUsuario(usuarioID, tipo); err != nil {
+        return "", err
+    }
+
+    err = s.tokenRepo.Create(&models.TokenUsuario{
+        UsuarioID: usuarioID,
+        Tipo:      tipo,
+        TokenHash: utils.HashToken(token),
+        ExpiraAt:  time.Now().Add(ttl),
+    })
+    if err != nil {
+        return "", err
+    }
+
+    return token, nil
+}
+
+// resetPasswordURL construye el enlace de restablecimiento. PASSWORD_RESET_URL
+// apunta a la página del frontend que pide la contraseña nueva y la envía a
+// POST /api/auth/reset-password.
+func resetPasswordURL(token string) string {
+    base := os.Getenv("PASSWORD_RESET_URL")
+    if base == "" {
+        base = AppBaseURL() + "/reset-password"
+    }
+
+    return base + "?token=" + url.QueryEscape(token)
+}
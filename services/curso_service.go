@@ -2,19 +2,58 @@ package services
 
 import (
     "cursos-api/models"
-    "cursos-api/repository"
+    "cursos-api/policy"
     "errors"
+    "fmt"
+    "log"
+    "strings"
+    "time"
+    "unicode/utf8"
 )
 
+// Estados del ciclo de publicación de un curso. Solo los publicados son
+// visibles en el catálogo (activo = true).
+const (
+    EstadoCursoBorrador   = "borrador"
+    EstadoCursoEnRevision = "en_revision"
+    EstadoCursoPublicado  = "publicado"
+    EstadoCursoArchivado  = "archivado"
+)
+
+// transicionesCurso define los cambios de estado permitidos y los permisos
+// que habilitan cada uno (basta con uno). Los permisos "own" se comprueban
+// sobre el instructor del curso.
+var transicionesCurso = map[string]map[string][]policy.Permiso{
+    EstadoCursoBorrador: {
+        EstadoCursoEnRevision: {policy.CursoUpdateOwn},
+    },
+    EstadoCursoEnRevision: {
+        EstadoCursoBorrador:  {policy.CursoUpdateOwn, policy.CursoReview},
+        EstadoCursoPublicado: {policy.CursoReview},
+    },
+    EstadoCursoPublicado: {
+        EstadoCursoArchivado: {policy.CursoUpdateOwn},
+    },
+    // Un curso archivado se ha podido editar libremente, así que volver al
+    // catálogo pasa otra vez por revisión
+    EstadoCursoArchivado: {
+        EstadoCursoEnRevision: {policy.CursoUpdateOwn},
+        EstadoCursoPublicado:  {policy.CursoReview},
+        EstadoCursoBorrador:   {policy.CursoUpdateOwn},
+    },
+}
+
 type CursoService struct {
-    cursoRepo   *repository.CursoRepository
-    usuarioRepo *repository.UsuarioRepository
+    cursoRepo   CursoRepository
+    usuarioRepo UsuarioRepository
+    leccionRepo LeccionRepository
 }
 
-func NewCursoService() *CursoService {
+func NewCursoService(cursoRepo CursoRepository, usuarioRepo UsuarioRepository, leccionRepo LeccionRepository) *CursoService {
     return &CursoService{
-        cursoRepo:   repository.NewCursoRepository(),
-        usuarioRepo: repository.NewUsuarioRepository(),
+        cursoRepo:   cursoRepo,
+        usuarioRepo: usuarioRepo,
+        leccionRepo: leccionRepo,
     }
 }
 
@@ -29,8 +68,8 @@ func (s *CursoService) Create(curso *models.Curso, userID int, userRol string) (
         return nil, errors.New("la duración debe ser mayor a 0")
     }
 
-    // Solo instructores pueden crear cursos
-    if userRol != "instructor" {
+    // Solo los roles autores (instructores) pueden crear cursos
+    if !policy.Puede(userRol, policy.CursoCreate) {
         return nil, errors.New("solo los instructores pueden crear cursos")
     }
 
@@ -40,18 +79,19 @@ func (s *CursoService) Create(curso *models.Curso, userID int, userRol string) (
         return nil, errors.New("instructor no encontrado")
     }
 
-    // Verificar que el instructor es realmente un instructor
-    if instructor.Rol != "instructor" {
+    // Verificar que el instructor puede ser autor de cursos
+    if !policy.Puede(instructor.Rol, policy.CursoCreate) {
         return nil, errors.New("el usuario especificado no es un instructor")
     }
 
-    // El instructor solo puede crear cursos para sí mismo (a menos que sea admin en el futuro)
+    // El instructor solo puede crear cursos para sí mismo; un admin puede reasignarlo después
     if curso.InstructorID != userID {
         return nil, errors.New("no puedes crear cursos para otros instructores")
     }
 
-    // Establecer activo por defecto
-    curso.Activo = true
+    // Los cursos nacen como borrador y no son visibles hasta publicarse
+    curso.Estado = EstadoCursoBorrador
+    curso.Activo = false
 
     // Crear curso
     err = s.cursoRepo.Create(curso)
@@ -62,15 +102,117 @@ func (s *CursoService) Create(curso *models.Curso, userID int, userRol string) (
     return curso, nil
 }
 
-// GetAll obtiene todos los cursos
-func (s *CursoService) GetAll(userRol string, userID int) ([]models.Curso, error) {
-    // Los instructores solo ven sus propios cursos
-    if userRol == "instructor" {
-        return s.cursoRepo.GetByInstructor(userID)
+// Campos por los que se puede ordenar el listado de cursos
+const (
+    OrdenCursoCreatedAt = "created_at"
+    OrdenCursoUpdatedAt = "updated_at"
+    OrdenCursoNombre    = "nombre"
+    OrdenCursoDuracion  = "duracion_horas"
+)
+
+// Tamaño de página del listado de cursos
+const (
+    limiteCursosDefecto = 20
+    limiteCursosMaximo  = 100
+)
+
+// List obtiene una página de los cursos visibles para el usuario. El filtro
+// del cliente solo puede acotar ese conjunto.
+func (s *CursoService) List(filtro models.CursoFiltro, userRol string, userID int) (*models.PaginaCursos, error) {
+    soloCatalogo, instructorID, err := visibilidadCursos(userRol, userID, filtro.InstructorID)
+    if err != nil {
+        return nil, err
+    }
+    filtro.SoloCatalogo = soloCatalogo
+    filtro.InstructorID = instructorID
+
+    // Los eliminados solo los ven sus instructores y los admins
+    if filtro.Eliminados && soloCatalogo {
+        return nil, errors.New("no tienes permiso para ver cursos eliminados")
+    }
+
+    switch filtro.Estado {
+    case "", EstadoCursoBorrador, EstadoCursoEnRevision, EstadoCursoPublicado, EstadoCursoArchivado:
+    default:
+        return nil, errors.New("estado no válido (borrador, en_revision, publicado o archivado)")
+    }
+
+    switch filtro.Orden {
+    case "":
+        filtro.Orden = OrdenCursoCreatedAt
+    case OrdenCursoCreatedAt, OrdenCursoUpdatedAt, OrdenCursoNombre, OrdenCursoDuracion:
+    default:
+        return nil, errors.New("orden no válido (created_at, updated_at, nombre o duracion_horas)")
+    }
+
+    if filtro.Limite == 0 {
+        filtro.Limite = limiteCursosDefecto
+    }
+    if filtro.Limite < 0 || filtro.Limite > limiteCursosMaximo {
+        return nil, errors.New("el límite debe estar entre 1 y 100")
+    }
+
+    if filtro.DuracionMin < 0 || filtro.DuracionMax < 0 ||
+        (filtro.DuracionMax > 0 && filtro.DuracionMin > filtro.DuracionMax) {
+        return nil, errors.New("rango de duración inválido")
+    }
+
+    if filtro.CreadoDesde != nil && filtro.CreadoHasta != nil && !filtro.CreadoDesde.Before(*filtro.CreadoHasta) {
+        return nil, errors.New("rango de fechas inválido")
+    }
+
+    return s.cursoRepo.List(filtro)
+}
+
+// Search busca cursos por texto entre los visibles para el usuario
+func (s *CursoService) Search(busqueda models.CursoBusqueda, userRol string, userID int) (*models.PaginaBusquedaCursos, error) {
+    soloCatalogo, instructorID, err := visibilidadCursos(userRol, userID, 0)
+    if err != nil {
+        return nil, err
+    }
+    busqueda.SoloCatalogo = soloCatalogo
+    busqueda.InstructorID = instructorID
+
+    busqueda.Texto = strings.TrimSpace(busqueda.Texto)
+    if busqueda.Texto == "" {
+        return nil, errors.New("el texto de búsqueda es requerido")
+    }
+    if utf8.RuneCountInString(busqueda.Texto) > 200 {
+        return nil, errors.New("el texto de búsqueda no puede superar 200 caracteres")
+    }
+
+    if busqueda.Limite == 0 {
+        busqueda.Limite = limiteCursosDefecto
+    }
+    if busqueda.Limite < 0 || busqueda.Limite > limiteCursosMaximo {
+        return nil, errors.New("el límite debe estar entre 1 y 100")
+    }
+    if busqueda.Offset < 0 {
+        return nil, errors.New("el offset no puede ser negativo")
+    }
+
+    return s.cursoRepo.Search(busqueda)
+}
+
+// visibilidadCursos decide qué cursos puede listar el usuario: los admins
+// todos (o los del instructor pedido), los instructores solo los suyos y los
+// alumnos el catálogo de cursos activos no bloqueados
+func visibilidadCursos(userRol string, userID, instructorPedido int) (bool, int, error) {
+    switch {
+    case policy.Puede(userRol, policy.CursoReadAny):
+        return false, instructorPedido, nil
+
+    case policy.Puede(userRol, policy.CursoReadOwn):
+        if instructorPedido != 0 && instructorPedido != userID {
+            return false, 0, errors.New("solo puedes listar tus propios cursos")
+        }
+        return false, userID, nil
+
+    case policy.Puede(userRol, policy.CursoReadCatalogo):
+        return true, instructorPedido, nil
     }
 
-    // Los alumnos ven todos los cursos activos
-    return s.cursoRepo.GetActivos()
+    return false, 0, errors.New("no tienes permiso para ver cursos")
 }
 
 // GetByID obtiene un curso por ID
@@ -80,13 +222,18 @@ func (s *CursoService) GetByID(id int, userRol string, userID int) (*models.Curs
         return nil, err
     }
 
+    // El dueño (o un admin) ve el curso en cualquier estado
+    if policy.PuedeSobre(userRol, policy.CursoReadOwn, userID, curso.InstructorID) {
+        return curso, nil
+    }
+
     // Los instructores solo pueden ver sus propios cursos
-    if userRol == "instructor" && curso.InstructorID != userID {
+    if policy.Puede(userRol, policy.CursoReadOwn) {
         return nil, errors.New("no tienes permiso para ver este curso")
     }
 
-    // Los alumnos solo pueden ver cursos activos
-    if userRol == "alumno" && !curso.Activo {
+    // Los alumnos solo pueden ver cursos activos y no bloqueados
+    if !policy.Puede(userRol, policy.CursoReadCatalogo) || !curso.Activo || curso.Bloqueado {
         return nil, errors.New("curso no disponible")
     }
 
@@ -109,23 +256,16 @@ func (s *CursoService) Update(id int, curso *models.Curso, userID int, userRol s
         return nil, errors.New("la duración debe ser mayor a 0")
     }
 
-    // Solo instructores pueden actualizar cursos
-    if userRol != "instructor" {
-        return nil, errors.New("solo los instructores pueden actualizar cursos")
-    }
-
-    // Verificar que el curso existe y pertenece al instructor
-    exists, err := s.cursoRepo.VerifyInstructor(id, userID)
-    if err != nil {
-        return nil, err
-    }
-
-    if !exists {
+    // Verificar que el curso existe y el usuario puede modificarlo
+    existing, err := s.cursoRepo.FindByID(id)
+    if err != nil || !policy.PuedeSobre(userRol, policy.CursoUpdateOwn, userID, existing.InstructorID) {
         return nil, errors.New("curso no encontrado o no tienes permiso para modificarlo")
     }
 
-    // El instructor_id no puede cambiar
-    curso.InstructorID = userID
+    // El instructor_id y el estado no cambian al editar
+    curso.InstructorID = existing.InstructorID
+    curso.Activo = existing.Activo
+    curso.Estado = existing.Estado
 
     // Actualizar
     err = s.cursoRepo.Update(id, curso)
@@ -138,55 +278,273 @@ func (s *CursoService) Update(id int, curso *models.Curso, userID int, userRol s
 
 // Delete elimina un curso
 func (s *CursoService) Delete(id int, userID int, userRol string) error {
-    // Solo instructores pueden eliminar cursos
-    if userRol != "instructor" {
-        return errors.New("solo los instructores pueden eliminar cursos")
+    // Verificar que el curso existe y el usuario puede eliminarlo
+    curso, err := s.cursoRepo.FindByID(id)
+    if err != nil || !policy.PuedeSobre(userRol, policy.CursoDeleteOwn, userID, curso.InstructorID) {
+        return errors.New("curso no encontrado o no tienes permiso para eliminarlo")
     }
 
-    // Verificar que el curso existe y pertenece al instructor
-    exists, err := s.cursoRepo.VerifyInstructor(id, userID)
-    if err != nil {
-        return err
+    return s.cursoRepo.Delete(id)
+}
+
+// Restaurar recupera un curso eliminado dentro del plazo de retención. Si se
+// eliminó junto a su instructor hay que restaurar la cuenta del instructor.
+func (s *CursoService) Restaurar(id int, userID int, userRol string) (*models.Curso, error) {
+    curso, err := s.cursoRepo.FindEliminado(id)
+    if err != nil || !policy.PuedeSobre(userRol, policy.CursoDeleteOwn, userID, curso.InstructorID) {
+        return nil, errors.New("curso eliminado no encontrado o no tienes permiso para restaurarlo")
     }
 
-    if !exists {
-        return errors.New("curso no encontrado o no tienes permiso para eliminarlo")
+    if curso.DeletedAt.Before(time.Now().Add(-retencionEliminados())) {
+        return nil, errors.New("el plazo para restaurar el curso ha vencido")
     }
 
-    return s.cursoRepo.Delete(id)
+    if _, err := s.usuarioRepo.FindByID(curso.InstructorID); err != nil {
+        return nil, errors.New("el instructor del curso está eliminado, restaura antes su cuenta")
+    }
+
+    if err := s.cursoRepo.Restore(id); err != nil {
+        return nil, err
+    }
+
+    return s.cursoRepo.FindByID(id)
 }
 
-// ToggleActivo activa o desactiva un curso
+// ToggleActivo retira un curso publicado o reactiva uno archivado: quien
+// revisa cursos lo publica directamente y su instructor lo envía a revisión.
+// El resto de cambios de estado se hacen con CambiarEstado.
 func (s *CursoService) ToggleActivo(id int, userID int, userRol string) (*models.Curso, error) {
-    // Solo instructores pueden cambiar el estado
-    if userRol != "instructor" {
-        return nil, errors.New("solo los instructores pueden cambiar el estado del curso")
+    // Verificar que el curso existe y el usuario puede modificarlo
+    curso, err := s.cursoRepo.FindByID(id)
+    if err != nil || !policy.PuedeSobre(userRol, policy.CursoUpdateOwn, userID, curso.InstructorID) {
+        return nil, errors.New("curso no encontrado o no tienes permiso")
     }
 
-    // Verificar que el curso existe y pertenece al instructor
-    exists, err := s.cursoRepo.VerifyInstructor(id, userID)
-    if err != nil {
+    var nuevo string
+    switch curso.Estado {
+    case EstadoCursoPublicado:
+        nuevo = EstadoCursoArchivado
+    case EstadoCursoArchivado:
+        nuevo = EstadoCursoEnRevision
+        if puedeTransicion(transicionesCurso[EstadoCursoArchivado][EstadoCursoPublicado], curso, userID, userRol) {
+            nuevo = EstadoCursoPublicado
+        }
+    default:
+        return nil, fmt.Errorf("el curso está en estado %s: su publicación pasa por revisión", curso.Estado)
+    }
+
+    return s.CambiarEstado(id, models.CambiarEstadoCursoRequest{Estado: nuevo}, userID, userRol)
+}
+
+// CambiarEstado mueve un curso por su ciclo de publicación y lo anota en el
+// historial. Para enviar a revisión o publicar el curso debe estar completo.
+func (s *CursoService) CambiarEstado(id int, req models.CambiarEstadoCursoRequest, userID int, userRol string) (*models.Curso, error) {
+    curso, err := s.cursoRepo.FindByID(id)
+    if err != nil || !gestionaPublicacion(curso, userID, userRol) {
+        return nil, errors.New("curso no encontrado o no tienes permiso")
+    }
+
+    permisos, ok := transicionesCurso[curso.Estado][req.Estado]
+    if !ok {
+        return nil, fmt.Errorf("no se puede pasar un curso de %s a %s", curso.Estado, req.Estado)
+    }
+    if !puedeTransicion(permisos, curso, userID, userRol) {
+        return nil, errors.New("no tienes permiso para este cambio de estado")
+    }
+
+    motivo := strings.TrimSpace(req.Motivo)
+    if utf8.RuneCountInString(motivo) > 500 {
+        return nil, errors.New("el motivo no puede superar 500 caracteres")
+    }
+    // Quien revisa y devuelve el curso a borrador debe explicar por qué
+    if curso.Estado == EstadoCursoEnRevision && req.Estado == EstadoCursoBorrador &&
+        curso.InstructorID != userID && motivo == "" {
+        return nil, errors.New("el motivo del rechazo es requerido")
+    }
+
+    if req.Estado == EstadoCursoEnRevision || req.Estado == EstadoCursoPublicado {
+        if err := s.validarPublicable(curso); err != nil {
+            return nil, err
+        }
+    }
+
+    cambio := &models.CambioEstadoCurso{
+        CursoID:        id,
+        EstadoAnterior: curso.Estado,
+        EstadoNuevo:    req.Estado,
+        UsuarioID:      &userID,
+        Motivo:         motivo,
+    }
+    if err := s.cursoRepo.CambiarEstado(cambio); err != nil {
         return nil, err
     }
 
-    if !exists {
+    return s.cursoRepo.FindByID(id)
+}
+
+// Programar fija las fechas en que el proceso en segundo plano publicará y
+// retirará el curso. Programar la publicación exige poder publicarlo ahora
+// (para un curso en revisión equivale a aprobarlo con fecha); la retirada la
+// puede programar su instructor.
+func (s *CursoService) Programar(id int, req models.ProgramacionCursoRequest, userID int, userRol string) (*models.Curso, error) {
+    curso, err := s.cursoRepo.FindByID(id)
+    if err != nil || !gestionaPublicacion(curso, userID, userRol) {
         return nil, errors.New("curso no encontrado o no tienes permiso")
     }
 
-    // Obtener curso actual
+    ahora := time.Now()
+
+    if req.PublicarAt != nil && !mismaFecha(req.PublicarAt, curso.PublicarAt) {
+        if !req.PublicarAt.After(ahora) {
+            return nil, errors.New("la fecha de publicación debe ser futura")
+        }
+        permisos, ok := transicionesCurso[curso.Estado][EstadoCursoPublicado]
+        if !ok {
+            return nil, fmt.Errorf("un curso en estado %s no se puede programar para publicarse", curso.Estado)
+        }
+        if !puedeTransicion(permisos, curso, userID, userRol) {
+            return nil, errors.New("no tienes permiso para programar la publicación de este curso")
+        }
+        if err := s.validarPublicable(curso); err != nil {
+            return nil, err
+        }
+    }
+
+    if req.DespublicarAt != nil && !mismaFecha(req.DespublicarAt, curso.DespublicarAt) {
+        if !req.DespublicarAt.After(ahora) {
+            return nil, errors.New("la fecha de retirada debe ser futura")
+        }
+        if req.PublicarAt != nil && !req.DespublicarAt.After(*req.PublicarAt) {
+            return nil, errors.New("la fecha de retirada debe ser posterior a la de publicación")
+        }
+        if curso.Estado != EstadoCursoPublicado && req.PublicarAt == nil {
+            return nil, errors.New("solo se puede programar la retirada de un curso publicado o con publicación programada")
+        }
+        if !policy.PuedeSobre(userRol, policy.CursoUpdateOwn, userID, curso.InstructorID) {
+            return nil, errors.New("no tienes permiso para programar la retirada de este curso")
+        }
+    }
+
+    if err := s.cursoRepo.Programar(id, req.PublicarAt, req.DespublicarAt); err != nil {
+        return nil, err
+    }
+
+    return s.cursoRepo.FindByID(id)
+}
+
+// Historial obtiene los cambios de estado de un curso (su instructor o un admin)
+func (s *CursoService) Historial(id int, userID int, userRol string) ([]models.CambioEstadoCurso, error) {
     curso, err := s.cursoRepo.FindByID(id)
+    if err != nil || !gestionaPublicacion(curso, userID, userRol) {
+        return nil, errors.New("curso no encontrado o no tienes permiso")
+    }
+
+    return s.cursoRepo.GetHistorial(id)
+}
+
+// ProcesarProgramados aplica las publicaciones y retiradas programadas que ya
+// han vencido y devuelve cuántos cambios hizo. Un curso que ya no se puede
+// publicar pierde la programación en lugar de reintentarse. Con varias
+// instancias no hay cambios duplicados: el repositorio solo cambia el estado
+// si sigue siendo el leído.
+func (s *CursoService) ProcesarProgramados(ahora time.Time) (int, error) {
+    cursos, err := s.cursoRepo.GetProgramados(ahora)
     if err != nil {
-        return nil, err
+        return 0, err
     }
 
-    // Cambiar estado
-    curso.Activo = !curso.Activo
+    vencida := func(t *time.Time) bool { return t != nil && !t.After(ahora) }
+
+    cambios := 0
+    for _, curso := range cursos {
+        estado := curso.Estado
+
+        if vencida(curso.PublicarAt) && estado != EstadoCursoPublicado {
+            if err := s.validarPublicable(&curso); err != nil {
+                log.Printf("⚠️  Publicación programada del curso %d cancelada: %v\n", curso.ID, err)
+                if err := s.cursoRepo.Programar(curso.ID, nil, nil); err != nil {
+                    log.Printf("⚠️  No se pudo cancelar la programación del curso %d: %v\n", curso.ID, err)
+                }
+                continue
+            }
+
+            cambio := &models.CambioEstadoCurso{
+                CursoID:        curso.ID,
+                EstadoAnterior: estado,
+                EstadoNuevo:    EstadoCursoPublicado,
+                Motivo:         "publicación programada",
+            }
+            if err := s.cursoRepo.CambiarEstado(cambio); err != nil {
+                log.Printf("⚠️  No se pudo publicar el curso programado %d: %v\n", curso.ID, err)
+                continue
+            }
+            estado = EstadoCursoPublicado
+            cambios++
+        }
+
+        if vencida(curso.DespublicarAt) && estado == EstadoCursoPublicado {
+            cambio := &models.CambioEstadoCurso{
+                CursoID:        curso.ID,
+                EstadoAnterior: estado,
+                EstadoNuevo:    EstadoCursoArchivado,
+                Motivo:         "retirada programada",
+            }
+            if err := s.cursoRepo.CambiarEstado(cambio); err != nil {
+                log.Printf("⚠️  No se pudo retirar el curso programado %d: %v\n", curso.ID, err)
+                continue
+            }
+            cambios++
+        }
+    }
 
-    // Actualizar
-    err = s.cursoRepo.Update(id, curso)
+    return cambios, nil
+}
+
+// validarPublicable comprueba que un curso está listo para el catálogo
+func (s *CursoService) validarPublicable(curso *models.Curso) error {
+    if curso.Bloqueado {
+        return errors.New("el curso está bloqueado por moderación y no puede publicarse")
+    }
+    if strings.TrimSpace(curso.Descripcion) == "" {
+        return errors.New("el curso necesita una descripción para publicarse")
+    }
+
+    lecciones, err := s.leccionRepo.GetByCurso(curso.ID)
     if err != nil {
-        return nil, err
+        return err
+    }
+    if len(lecciones) == 0 {
+        return errors.New("el curso necesita al menos una lección para publicarse")
     }
 
-    return curso, nil
+    return nil
+}
+
+// gestionaPublicacion indica si el usuario interviene en el ciclo de
+// publicación del curso: su instructor, un admin o quien revisa cursos
+func gestionaPublicacion(curso *models.Curso, userID int, userRol string) bool {
+    return policy.PuedeSobre(userRol, policy.CursoUpdateOwn, userID, curso.InstructorID) ||
+        policy.Puede(userRol, policy.CursoReview)
+}
+
+// puedeTransicion indica si el usuario tiene alguno de los permisos del cambio
+func puedeTransicion(permisos []policy.Permiso, curso *models.Curso, userID int, userRol string) bool {
+    for _, permiso := range permisos {
+        if permiso == policy.CursoReview {
+            if policy.Puede(userRol, permiso) {
+                return true
+            }
+        } else if policy.PuedeSobre(userRol, permiso, userID, curso.InstructorID) {
+            return true
+        }
+    }
+    return false
+}
+
+// mismaFecha compara dos fechas opcionales
+func mismaFecha(a, b *time.Time) bool {
+    if a == nil || b == nil {
+        return a == b
+    }
+    return a.Equal(*b)
 }
@@ -0,0 +1,93 @@
+package services_test
+
+import (
+    "cursos-api/models"
+    "cursos-api/policy"
+    "cursos-api/services"
+    "testing"
+)
+
+func TestCursoServiceCreateValidaRol(t *testing.T) {
+    e := nuevoEntorno(t)
+    alumno := e.crearUsuario(t, "ana@example.com", policy.RolAlumno)
+    juan := e.crearUsuario(t, "juan@example.com", policy.RolInstructor)
+    maria := e.crearUsuario(t, "maria@example.com", policy.RolInstructor)
+
+    if _, err := e.curso.Create(&models.Curso{Nombre: "Go", DuracionHoras: 10, InstructorID: alumno.ID}, alumno.ID, alumno.Rol); err == nil {
+        t.Fatal("un alumno pudo crear un curso")
+    }
+
+    if _, err := e.curso.Create(&models.Curso{Nombre: "Go", DuracionHoras: 10, InstructorID: maria.ID}, juan.ID, juan.Rol); err == nil {
+        t.Fatal("un instructor pudo crear un curso a nombre de otro")
+    }
+
+    curso, err := e.curso.Create(&models.Curso{Nombre: "Go", DuracionHoras: 10, InstructorID: juan.ID, Activo: true}, juan.ID, juan.Rol)
+    if err != nil {
+        t.Fatalf("Create: %v", err)
+    }
+    if curso.Estado != services.EstadoCursoBorrador || curso.Activo {
+        t.Fatalf("el curso nuevo quedó %q (activo=%v), se esperaba un borrador inactivo", curso.Estado, curso.Activo)
+    }
+}
+
+func TestCursoServiceUpdateSoloDuenoOAdmin(t *testing.T) {
+    e := nuevoEntorno(t)
+    juan := e.crearUsuario(t, "juan@example.com", policy.RolInstructor)
+    maria := e.crearUsuario(t, "maria@example.com", policy.RolInstructor)
+    admin := e.crearUsuario(t, "admin@example.com", policy.RolAdmin)
+    curso := e.crearCurso(t, juan, "Go")
+
+    cambios := func(nombre string) *models.Curso {
+        return &models.Curso{Nombre: nombre, DuracionHoras: 12, InstructorID: maria.ID}
+    }
+
+    if _, err := e.curso.Update(curso.ID, cambios("Robado"), maria.ID, maria.Rol); err == nil {
+        t.Fatal("otro instructor pudo modificar el curso")
+    }
+
+    actualizado, err := e.curso.Update(curso.ID, cambios("Go avanzado"), juan.ID, juan.Rol)
+    if err != nil {
+        t.Fatalf("Update del dueño: %v", err)
+    }
+    if actualizado.InstructorID != juan.ID {
+        t.Fatalf("Update cambió el instructor a %d", actualizado.InstructorID)
+    }
+
+    if _, err := e.curso.Update(curso.ID, cambios("Go moderado"), admin.ID, admin.Rol); err != nil {
+        t.Fatalf("Update del admin: %v", err)
+    }
+
+    guardado, err := e.cursos.FindByID(curso.ID)
+    if err != nil {
+        t.Fatalf("FindByID: %v", err)
+    }
+    if guardado.Nombre != "Go moderado" || guardado.InstructorID != juan.ID {
+        t.Fatalf("curso guardado = (%q, %d), se esperaba (\"Go moderado\", %d)", guardado.Nombre, guardado.InstructorID, juan.ID)
+    }
+}
+
+func TestCursoServiceDeleteSoloDuenoOAdmin(t *testing.T) {
+    e := nuevoEntorno(t)
+    juan := e.crearUsuario(t, "juan@example.com", policy.RolInstructor)
+    maria := e.crearUsuario(t, "maria@example.com", policy.RolInstructor)
+    alumno := e.crearUsuario(t, "ana@example.com", policy.RolAlumno)
+    admin := e.crearUsuario(t, "admin@example.com", policy.RolAdmin)
+    deJuan := e.crearCurso(t, juan, "Go")
+    otroDeJuan := e.crearCurso(t, juan, "Rust")
+
+    for _, intruso := range []*models.Usuario{maria, alumno} {
+        if err := e.curso.Delete(deJuan.ID, intruso.ID, intruso.Rol); err == nil {
+            t.Fatalf("%s pudo eliminar un curso ajeno", intruso.Rol)
+        }
+    }
+    if _, err := e.cursos.FindByID(deJuan.ID); err != nil {
+        t.Fatalf("el curso desapareció tras los intentos rechazados: %v", err)
+    }
+
+    if err := e.curso.Delete(deJuan.ID, juan.ID, juan.Rol); err != nil {
+        t.Fatalf("Delete del dueño: %v", err)
+    }
+    if err := e.curso.Delete(otroDeJuan.ID, admin.ID, admin.Rol); err != nil {
+        t.Fatalf("Delete del admin: %v", err)
+    }
+}
@@ -0,0 +1,306 @@
+package services
+
+import (
+    "cursos-api/models"
+    "cursos-api/policy"
+    "errors"
+)
+
+type EvaluacionService struct {
+    evaluacionRepo     EvaluacionRepository
+    resultadoRepo      ResultadoRepository
+    preguntaRepo       PreguntaRepository
+    cursoRepo          CursoRepository
+    inscripcionRepo    InscripcionRepository
+    certificadoService *CertificadoService
+}
+
+func NewEvaluacionService(
+    evaluacionRepo EvaluacionRepository,
+    resultadoRepo ResultadoRepository,
+    preguntaRepo PreguntaRepository,
+    cursoRepo CursoRepository,
+    inscripcionRepo InscripcionRepository,
+    certificadoService *CertificadoService,
+) *EvaluacionService {
+    return &EvaluacionService{
+        evaluacionRepo:     evaluacionRepo,
+        resultadoRepo:      resultadoRepo,
+        preguntaRepo:       preguntaRepo,
+        cursoRepo:          cursoRepo,
+        inscripcionRepo:    inscripcionRepo,
+        certificadoService: certificadoService,
+    }
+}
+
+// Create crea una evaluación en un curso del instructor
+func (s *EvaluacionService) Create(cursoID int, evaluacion *models.Evaluacion, userID int, userRol string) (*models.Evaluacion, error) {
+    if err := validarEvaluacion(evaluacion); err != nil {
+        return nil, err
+    }
+
+    if err := s.verificarInstructor(cursoID, userID, userRol); err != nil {
+        return nil, err
+    }
+
+    evaluacion.CursoID = cursoID
+
+    err := s.evaluacionRepo.Create(evaluacion)
+    if err != nil {
+        return nil, err
+    }
+
+    return evaluacion, nil
+}
+
+// GetByCurso obtiene las evaluaciones de un curso
+func (s *EvaluacionService) GetByCurso(cursoID int, userID int, userRol string) ([]models.Evaluacion, error) {
+    if err := s.verificarAcceso(cursoID, userID, userRol); err != nil {
+        return nil, err
+    }
+
+    return s.evaluacionRepo.GetByCurso(cursoID)
+}
+
+// GetByID obtiene una evaluación de un curso
+func (s *EvaluacionService) GetByID(cursoID, id int, userID int, userRol string) (*models.Evaluacion, error) {
+    if err := s.verificarAcceso(cursoID, userID, userRol); err != nil {
+        return nil, err
+    }
+
+    return s.findInCurso(cursoID, id)
+}
+
+// Update actualiza una evaluación de un curso del instructor
+func (s *EvaluacionService) Update(cursoID, id int, evaluacion *models.Evaluacion, userID int, userRol string) (*models.Evaluacion, error) {
+    if err := validarEvaluacion(evaluacion); err != nil {
+        return nil, err
+    }
+
+    if err := s.verificarInstructor(cursoID, userID, userRol); err != nil {
+        return nil, err
+    }
+
+    if _, err := s.findInCurso(cursoID, id); err != nil {
+        return nil, err
+    }
+
+    err := s.evaluacionRepo.Update(id, evaluacion)
+    if err != nil {
+        return nil, err
+    }
+
+    return evaluacion, nil
+}
+
+// Delete elimina una evaluación de un curso del instructor
+func (s *EvaluacionService) Delete(cursoID, id int, userID int, userRol string) error {
+    if err := s.verificarInstructor(cursoID, userID, userRol); err != nil {
+        return err
+    }
+
+    if _, err := s.findInCurso(cursoID, id); err != nil {
+        return err
+    }
+
+    return s.evaluacionRepo.Delete(id)
+}
+
+// SubmitResultado registra un resultado calificado a mano. Un alumno inscrito
+// presenta el suyo en las evaluaciones sin preguntas; el instructor del curso
+// califica a un alumno inscrito en cualquier evaluación y puede corregir una
+// nota anterior. Para el certificado solo cuentan las notas del instructor y
+// las de los intentos.
+func (s *EvaluacionService) SubmitResultado(cursoID, evaluacionID, alumnoID int, calificacion float64, userID int, userRol string) (*models.ResultadoEvaluacion, error) {
+    if policy.Puede(userRol, policy.CursoContentOwn) {
+        return s.calificar(cursoID, evaluacionID, alumnoID, calificacion, userID, userRol)
+    }
+
+    if !policy.Puede(userRol, policy.EvaluacionSubmit) {
+        return nil, errors.New("solo los alumnos pueden enviar resultados")
+    }
+
+    if alumnoID != 0 && alumnoID != userID {
+        return nil, errors.New("solo puedes enviar tus propios resultados")
+    }
+
+    if calificacion < 0 || calificacion > 100 {
+        return nil, errors.New("la calificación debe estar entre 0 y 100")
+    }
+
+    inscripcion, err := s.inscripcionRepo.FindByUsuarioAndCurso(userID, cursoID)
+    if err != nil || inscripcion.Estado == EstadoInscripcionCancelado {
+        return nil, errors.New("debes estar inscrito en el curso para presentar evaluaciones")
+    }
+
+    evaluacion, err := s.findInCurso(cursoID, evaluacionID)
+    if err != nil {
+        return nil, err
+    }
+
+    // Las evaluaciones con preguntas se califican en el servidor mediante intentos
+    preguntas, err := s.preguntaRepo.CountByEvaluacion(evaluacionID)
+    if err != nil {
+        return nil, err
+    }
+
+    if preguntas > 0 {
+        return nil, errors.New("esta evaluación se califica automáticamente, debes presentarla mediante un intento")
+    }
+
+    if evaluacion.MaxIntentos > 0 {
+        count, err := s.resultadoRepo.CountByUsuarioAndEvaluacion(userID, evaluacionID)
+        if err != nil {
+            return nil, err
+        }
+
+        if count >= evaluacion.MaxIntentos {
+            return nil, errors.New("has alcanzado el número máximo de intentos")
+        }
+    }
+
+    return s.registrarResultado(cursoID, evaluacion, userID, calificacion, nil)
+}
+
+// calificar registra la nota que el instructor del curso asigna a un alumno
+// inscrito. No consume intentos del alumno, así que sirve para corregir notas.
+func (s *EvaluacionService) calificar(cursoID, evaluacionID, alumnoID int, calificacion float64, userID int, userRol string) (*models.ResultadoEvaluacion, error) {
+    if err := s.verificarInstructor(cursoID, userID, userRol); err != nil {
+        return nil, err
+    }
+
+    if alumnoID == 0 {
+        return nil, errors.New("el alumno a calificar es requerido")
+    }
+
+    if alumnoID == userID {
+        return nil, errors.New("no puedes calificarte a ti mismo")
+    }
+
+    if calificacion < 0 || calificacion > 100 {
+        return nil, errors.New("la calificación debe estar entre 0 y 100")
+    }
+
+    inscripcion, err := s.inscripcionRepo.FindByUsuarioAndCurso(alumnoID, cursoID)
+    if err != nil || inscripcion.Estado == EstadoInscripcionCancelado {
+        return nil, errors.New("el alumno no está inscrito en el curso")
+    }
+
+    evaluacion, err := s.findInCurso(cursoID, evaluacionID)
+    if err != nil {
+        return nil, err
+    }
+
+    return s.registrarResultado(cursoID, evaluacion, alumnoID, calificacion, &userID)
+}
+
+// registrarResultado guarda el resultado, calcula si aprobó según la
+// calificación mínima y emite el certificado si ya corresponde
+func (s *EvaluacionService) registrarResultado(cursoID int, evaluacion *models.Evaluacion, alumnoID int, calificacion float64, calificadoPor *int) (*models.ResultadoEvaluacion, error) {
+    resultado := &models.ResultadoEvaluacion{
+        EvaluacionID:  evaluacion.ID,
+        UsuarioID:     alumnoID,
+        Calificacion:  calificacion,
+        Aprobado:      calificacion >= evaluacion.CalificacionMinima,
+        CalificadoPor: calificadoPor,
+    }
+
+    if err := s.resultadoRepo.Create(resultado); err != nil {
+        return nil, err
+    }
+
+    if resultado.Aprobado {
+        s.certificadoService.EmitirSiCorresponde(cursoID, alumnoID)
+    }
+
+    resultado.Evaluacion = evaluacion
+    return resultado, nil
+}
+
+// GetResultadosByCurso obtiene los resultados de un curso: todos para el
+// instructor dueño, solo los propios para un alumno inscrito
+func (s *EvaluacionService) GetResultadosByCurso(cursoID int, userID int, userRol string) ([]models.ResultadoEvaluacion, error) {
+    if err := s.verificarAcceso(cursoID, userID, userRol); err != nil {
+        return nil, err
+    }
+
+    if policy.Puede(userRol, policy.CursoContentOwn) {
+        return s.resultadoRepo.GetByCurso(cursoID)
+    }
+
+    return s.resultadoRepo.GetByUsuarioAndCurso(userID, cursoID)
+}
+
+// GetMyResultados obtiene todos los resultados del alumno autenticado
+func (s *EvaluacionService) GetMyResultados(userID int) ([]models.ResultadoEvaluacion, error) {
+    return s.resultadoRepo.GetByUsuario(userID)
+}
+
+// findInCurso busca una evaluación y verifica que pertenezca al curso
+func (s *EvaluacionService) findInCurso(cursoID, id int) (*models.Evaluacion, error) {
+    evaluacion, err := s.evaluacionRepo.FindByID(id)
+    if err != nil {
+        return nil, err
+    }
+
+    if evaluacion.CursoID != cursoID {
+        return nil, errors.New("evaluación no encontrada")
+    }
+
+    return evaluacion, nil
+}
+
+// verificarInstructor comprueba que el usuario puede gestionar el contenido del
+// curso: su instructor o un admin
+func (s *EvaluacionService) verificarInstructor(cursoID int, userID int, userRol string) error {
+    if !policy.Puede(userRol, policy.CursoContentOwn) {
+        return errors.New("solo los instructores pueden gestionar evaluaciones")
+    }
+
+    curso, err := s.cursoRepo.FindByID(cursoID)
+    if err != nil || !policy.PuedeSobre(userRol, policy.CursoContentOwn, userID, curso.InstructorID) {
+        return errors.New("curso no encontrado o no tienes permiso")
+    }
+
+    return nil
+}
+
+// verificarAcceso comprueba que el usuario gestiona el curso o es un alumno inscrito
+func (s *EvaluacionService) verificarAcceso(cursoID int, userID int, userRol string) error {
+    if policy.Puede(userRol, policy.CursoContentOwn) {
+        return s.verificarInstructor(cursoID, userID, userRol)
+    }
+
+    inscripcion, err := s.inscripcionRepo.FindByUsuarioAndCurso(userID, cursoID)
+    if err != nil || inscripcion.Estado == EstadoInscripcionCancelado {
+        return errors.New("debes estar inscrito en el curso para ver sus evaluaciones")
+    }
+
+    return nil
+}
+
+// validarEvaluacion valida los campos editables de una evaluación
+func validarEvaluacion(evaluacion *models.Evaluacion) error {
+    if evaluacion.Titulo == "" {
+        return errors.New("el título de la evaluación es requerido")
+    }
+
+    if evaluacion.CalificacionMinima < 0 || evaluacion.CalificacionMinima > 100 {
+        return errors.New("la calificación mínima debe estar entre 0 y 100")
+    }
+
+    if evaluacion.MaxIntentos < 0 {
+        return errors.New("el número máximo de intentos no puede ser negativo")
+    }
+
+    if evaluacion.TiempoLimiteMinutos < 0 {
+        return errors.New("el tiempo límite no puede ser negativo")
+    }
+
+    if evaluacion.Requerida == nil {
+        requerida := true
+        evaluacion.Requerida = &requerida
+    }
+
+    return nil
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+    "cursos-api/models"
+    "cursos-api/policy"
+    "errors"
+)
+
+// Estados posibles de una inscripción
+const (
+    EstadoInscripcionActivo     = "activo"
+    EstadoInscripcionCompletado = "completado"
+    EstadoInscripcionCancelado  = "cancelado"
+)
+
+// transicionesInscripcion define los cambios de estado que se piden desde la
+// API. El paso a completado no figura: lo decide el progreso, y el
+// repositorio lo aplica al recalcularlo (ProgresoRepository).
+var transicionesInscripcion = map[string][]string{
+    EstadoInscripcionActivo: {EstadoInscripcionCancelado},
+}
+
+type InscripcionService struct {
+    inscripcionRepo InscripcionRepository
+    cursoRepo       CursoRepository
+}
+
+func NewInscripcionService(inscripcionRepo InscripcionRepository, cursoRepo CursoRepository) *InscripcionService {
+    return &InscripcionService{
+        inscripcionRepo: inscripcionRepo,
+        cursoRepo:       cursoRepo,
+    }
+}
+
+// Inscribir inscribe a un alumno en un curso activo
+func (s *InscripcionService) Inscribir(cursoID int, userID int, userRol string) (*models.Inscripcion, error) {
+    // Solo alumnos pueden inscribirse
+    if !policy.Puede(userRol, policy.InscripcionCreate) {
+        return nil, errors.New("solo los alumnos pueden inscribirse en cursos")
+    }
+
+    // Verificar que el curso existe, está activo y no fue bloqueado
+    curso, err := s.cursoRepo.FindByID(cursoID)
+    if err != nil {
+        return nil, err
+    }
+
+    if !curso.Activo || curso.Bloqueado {
+        return nil, errors.New("curso no disponible")
+    }
+
+    // Una inscripción cancelada se reactiva; cualquier otra impide inscribirse de nuevo
+    existing, _ := s.inscripcionRepo.FindByUsuarioAndCurso(userID, cursoID)
+    if existing != nil {
+        if existing.Estado != EstadoInscripcionCancelado {
+            return nil, errors.New("ya existe una inscripción en este curso")
+        }
+
+        if err := s.inscripcionRepo.Reactivar(existing); err != nil {
+            return nil, err
+        }
+
+        existing.Curso = curso
+        return existing, nil
+    }
+
+    inscripcion := &models.Inscripcion{
+        UsuarioID:          userID,
+        CursoID:            cursoID,
+        Estado:             EstadoInscripcionActivo,
+        ProgresoPorcentaje: 0,
+    }
+
+    err = s.inscripcionRepo.Create(inscripcion)
+    if err != nil {
+        return nil, err
+    }
+
+    inscripcion.Curso = curso
+    return inscripcion, nil
+}
+
+// GetMyInscripciones obtiene las inscripciones del alumno autenticado
+func (s *InscripcionService) GetMyInscripciones(userID int) ([]models.Inscripcion, error) {
+    return s.inscripcionRepo.GetByUsuario(userID)
+}
+
+// GetByCurso obtiene los inscritos de un curso del instructor
+func (s *InscripcionService) GetByCurso(cursoID int, userID int, userRol string) ([]models.Inscripcion, error) {
+    // Solo instructores pueden ver los inscritos
+    if !policy.Puede(userRol, policy.CursoContentOwn) {
+        return nil, errors.New("solo los instructores pueden ver los inscritos de un curso")
+    }
+
+    // Verificar que el curso existe y el usuario lo administra
+    curso, err := s.cursoRepo.FindByID(cursoID)
+    if err != nil || !policy.PuedeSobre(userRol, policy.CursoContentOwn, userID, curso.InstructorID) {
+        return nil, errors.New("curso no encontrado o no tienes permiso")
+    }
+
+    return s.inscripcionRepo.GetByCurso(cursoID)
+}
+
+// Cancelar cancela una inscripción del alumno autenticado
+func (s *InscripcionService) Cancelar(id int, userID int) (*models.Inscripcion, error) {
+    inscripcion, err := s.inscripcionRepo.FindByID(id)
+    if err != nil {
+        return nil, err
+    }
+
+    // Un alumno solo puede cancelar sus propias inscripciones
+    if inscripcion.UsuarioID != userID {
+        return nil, errors.New("inscripción no encontrada o no tienes permiso")
+    }
+
+    return s.cambiarEstado(inscripcion, EstadoInscripcionCancelado)
+}
+
+// cambiarEstado valida la transición y persiste el nuevo estado
+func (s *InscripcionService) cambiarEstado(inscripcion *models.Inscripcion, nuevoEstado string) (*models.Inscripcion, error) {
+    if !transicionPermitida(inscripcion.Estado, nuevoEstado) {
+        return nil, errors.New("no se puede cambiar una inscripción de '" + inscripcion.Estado + "' a '" + nuevoEstado + "'")
+    }
+
+    err := s.inscripcionRepo.UpdateEstado(inscripcion.ID, nuevoEstado)
+    if err != nil {
+        return nil, err
+    }
+
+    inscripcion.Estado = nuevoEstado
+    return inscripcion, nil
+}
+
+// transicionPermitida indica si una inscripción puede pasar de un estado a otro
+func transicionPermitida(actual, nuevo string) bool {
+    for _, estado := range transicionesInscripcion[actual] {
+        if estado == nuevo {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+    "cursos-api/models"
+    "cursos-api/policy"
+    "errors"
+    "math"
+    "time"
+)
+
+// margenEnvio tolera la latencia de red al enviar un intento con tiempo límite
+const margenEnvio = 30 * time.Second
+
+type IntentoService struct {
+    intentoRepo        IntentoRepository
+    preguntaRepo       PreguntaRepository
+    evaluacionService  *EvaluacionService
+    certificadoService *CertificadoService
+}
+
+func NewIntentoService(
+    intentoRepo IntentoRepository,
+    preguntaRepo PreguntaRepository,
+    evaluacionService *EvaluacionService,
+    certificadoService *CertificadoService,
+) *IntentoService {
+    return &IntentoService{
+        intentoRepo:        intentoRepo,
+        preguntaRepo:       preguntaRepo,
+        evaluacionService:  evaluacionService,
+        certificadoService: certificadoService,
+    }
+}
+
+// Iniciar comienza un intento de la evaluación para el alumno inscrito. Si ya
+// tiene un intento abierto y vigente se devuelve ese mismo intento.
+func (s *IntentoService) Iniciar(cursoID, evaluacionID int, userID int, userRol string) (*models.IntentoEvaluacion, error) {
+    evaluacion, err := s.verificarAlumno(cursoID, evaluacionID, userID, userRol)
+    if err != nil {
+        return nil, err
+    }
+
+    preguntas, err := s.preguntaRepo.GetByEvaluacion(evaluacionID)
+    if err != nil {
+        return nil, err
+    }
+
+    if len(preguntas) == 0 {
+        return nil, errors.New("la evaluación no tiene preguntas")
+    }
+
+    // Se reanuda el intento abierto si lo hay; Create lo vuelve a comprobar
+    // con la evaluación bloqueada por si otro inicio simultáneo se adelantó
+    intento, err := s.intentoRepo.FindAbierto(userID, evaluacionID)
+    if err != nil {
+        intento = &models.IntentoEvaluacion{
+            EvaluacionID: evaluacionID,
+            UsuarioID:    userID,
+            IniciadoAt:   time.Now(),
+        }
+
+        if evaluacion.TiempoLimiteMinutos > 0 {
+            expira := intento.IniciadoAt.Add(time.Duration(evaluacion.TiempoLimiteMinutos) * time.Minute)
+            intento.ExpiraAt = &expira
+        }
+
+        err = s.intentoRepo.Create(intento, evaluacion.MaxIntentos)
+        if err != nil {
+            return nil, err
+        }
+    }
+
+    intento.Preguntas = ocultarRespuestas(preguntas)
+    return intento, nil
+}
+
+// Enviar califica las respuestas de un intento en el servidor y registra el
+// resultado de la evaluación
+func (s *IntentoService) Enviar(cursoID, evaluacionID, intentoID int, respuestas []models.RespuestaPregunta, userID int, userRol string) (*models.IntentoEvaluacion, *models.ResultadoEvaluacion, error) {
+    evaluacion, err := s.verificarAlumno(cursoID, evaluacionID, userID, userRol)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    intento, err := s.intentoRepo.FindByID(intentoID)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    if intento.UsuarioID != userID || intento.EvaluacionID != evaluacionID {
+        return nil, nil, errors.New("intento no encontrado o no tienes permiso")
+    }
+
+    if intento.EnviadoAt != nil {
+        return nil, nil, errors.New("el intento ya fue enviado")
+    }
+
+    if intento.ExpiraAt != nil && time.Now().After(intento.ExpiraAt.Add(margenEnvio)) {
+        return nil, nil, errors.New("el tiempo límite del intento ha expirado")
+    }
+
+    preguntas, err := s.preguntaRepo.GetByEvaluacion(evaluacionID)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    calificacion := calificarRespuestas(preguntas, respuestas)
+
+    resultado := &models.ResultadoEvaluacion{
+        EvaluacionID: evaluacionID,
+        UsuarioID:    userID,
+        Calificacion: calificacion,
+        Aprobado:     calificacion >= evaluacion.CalificacionMinima,
+    }
+
+    intento.Respuestas = respuestas
+
+    err = s.intentoRepo.Finalizar(intento, resultado)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    if resultado.Aprobado {
+        s.certificadoService.EmitirSiCorresponde(cursoID, userID)
+    }
+
+    resultado.Evaluacion = evaluacion
+    return intento, resultado, nil
+}
+
+// GetMyIntentos obtiene los intentos del alumno en una evaluación
+func (s *IntentoService) GetMyIntentos(cursoID, evaluacionID int, userID int, userRol string) ([]models.IntentoEvaluacion, error) {
+    if _, err := s.verificarAlumno(cursoID, evaluacionID, userID, userRol); err != nil {
+        return nil, err
+    }
+
+    return s.intentoRepo.GetByUsuarioAndEvaluacion(userID, evaluacionID)
+}
+
+// verificarAlumno comprueba que el usuario es un alumno inscrito en el curso
+// y que la evaluación pertenece al curso
+func (s *IntentoService) verificarAlumno(cursoID, evaluacionID int, userID int, userRol string) (*models.Evaluacion, error) {
+    if !policy.Puede(userRol, policy.EvaluacionSubmit) {
+        return nil, errors.New("solo los alumnos pueden presentar evaluaciones")
+    }
+
+    if err := s.evaluacionService.verificarAcceso(cursoID, userID, userRol); err != nil {
+        return nil, err
+    }
+
+    return s.evaluacionService.findInCurso(cursoID, evaluacionID)
+}
+
+// calificarRespuestas calcula la calificación (0-100) ponderada por el peso de
+// cada pregunta. Las preguntas de opción múltiple solo puntúan si se marcan
+// exactamente las opciones correctas.
+func calificarRespuestas(preguntas []models.Pregunta, respuestas []models.RespuestaPregunta) float64 {
+    porPregunta := make(map[int]models.RespuestaPregunta, len(respuestas))
+    for _, respuesta := range respuestas {
+        porPregunta[respuesta.PreguntaID] = respuesta
+    }
+
+    var pesoTotal, obtenido float64
+    for _, pregunta := range preguntas {
+        pesoTotal += pregunta.Peso
+
+        respuesta, ok := porPregunta[pregunta.ID]
+        if ok && respuestaCorrecta(pregunta, respuesta) {
+            obtenido += pregunta.Peso
+        }
+    }
+
+    if pesoTotal == 0 {
+        return 0
+    }
+
+    return math.Round(10000*obtenido/pesoTotal) / 100
+}
+
+// respuestaCorrecta indica si una respuesta acierta la pregunta
+func respuestaCorrecta(pregunta models.Pregunta, respuesta models.RespuestaPregunta) bool {
+    switch pregunta.Tipo {
+    case TipoPreguntaOpcionUnica, TipoPreguntaOpcionMultiple:
+        if pregunta.Tipo == TipoPreguntaOpcionUnica && len(respuesta.Opciones) != 1 {
+            return false
+        }
+
+        marcadas := make(map[int]bool, len(respuesta.Opciones))
+        for _, id := range respuesta.Opciones {
+            marcadas[id] = true
+        }
+
+        correctas := 0
+        for _, opcion := range pregunta.Opciones {
+            if opcion.EsCorrecta != marcadas[opcion.ID] {
+                return false
+            }
+            if opcion.EsCorrecta {
+                correctas++
+            }
+        }
+
+        // Descarta IDs que no pertenecen a la pregunta
+        return len(marcadas) == correctas
+
+    case TipoPreguntaVerdaderoFalso:
+        return respuesta.ValorBooleano != nil && pregunta.RespuestaBooleana != nil &&
+            *respuesta.ValorBooleano == *pregunta.RespuestaBooleana
+
+    case TipoPreguntaNumerica:
+        return respuesta.ValorNumerico != nil && pregunta.RespuestaNumerica != nil &&
+            math.Abs(*respuesta.ValorNumerico-*pregunta.RespuestaNumerica) <= pregunta.Tolerancia
+    }
+
+    return false
+}
+
+// ocultarRespuestas devuelve una copia de las preguntas sin las respuestas correctas
+func ocultarRespuestas(preguntas []models.Pregunta) []models.Pregunta {
+    publicas := make([]models.Pregunta, len(preguntas))
+    for i, pregunta := range preguntas {
+        pregunta.RespuestaBooleana = nil
+        pregunta.RespuestaNumerica = nil
+        pregunta.Tolerancia = 0
+
+        opciones := make([]models.OpcionPregunta, len(pregunta.Opciones))
+        for j, opcion := range pregunta.Opciones {
+            opcion.EsCorrecta = false
+            opciones[j] = opcion
+        }
+        pregunta.Opciones = opciones
+
+        publicas[i] = pregunta
+    }
+    return publicas
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+    "cursos-api/models"
+    "cursos-api/policy"
+    "errors"
+)
+
+type LeccionService struct {
+    leccionRepo     LeccionRepository
+    cursoRepo       CursoRepository
+    inscripcionRepo InscripcionRepository
+}
+
+func NewLeccionService(
+    leccionRepo LeccionRepository,
+    cursoRepo CursoRepository,
+    inscripcionRepo InscripcionRepository,
+) *LeccionService {
+    return &LeccionService{
+        leccionRepo:     leccionRepo,
+        cursoRepo:       cursoRepo,
+        inscripcionRepo: inscripcionRepo,
+    }
+}
+
+// Create crea una lección en un curso del instructor
+func (s *LeccionService) Create(cursoID int, leccion *models.Leccion, userID int, userRol string) (*models.Leccion, error) {
+    if err := validarLeccion(leccion); err != nil {
+        return nil, err
+    }
+
+    if err := s.verificarInstructor(cursoID, userID, userRol); err != nil {
+        return nil, err
+    }
+
+    leccion.CursoID = cursoID
+
+    err := s.leccionRepo.Create(leccion)
+    if err != nil {
+        return nil, err
+    }
+
+    return leccion, nil
+}
+
+// GetByCurso obtiene las lecciones de un curso en orden
+func (s *LeccionService) GetByCurso(cursoID int, userID int, userRol string) ([]models.Leccion, error) {
+    if err := s.verificarAcceso(cursoID, userID, userRol); err != nil {
+        return nil, err
+    }
+
+    return s.leccionRepo.GetByCurso(cursoID)
+}
+
+// GetByID obtiene una lección de un curso
+func (s *LeccionService) GetByID(cursoID, id int, userID int, userRol string) (*models.Leccion, error) {
+    if err := s.verificarAcceso(cursoID, userID, userRol); err != nil {
+        return nil, err
+    }
+
+    leccion, err := s.leccionRepo.FindByID(id)
+    if err != nil {
+        return nil, err
+    }
+
+    if leccion.CursoID != cursoID {
+        return nil, errors.New("lección no encontrada")
+    }
+
+    return leccion, nil
+}
+
+// Update actualiza una lección de un curso del instructor
+func (s *LeccionService) Update(cursoID, id int, leccion *models.Leccion, userID int, userRol string) (*models.Leccion, error) {
+    if err := validarLeccion(leccion); err != nil {
+        return nil, err
+    }
+
+    if err := s.verificarInstructor(cursoID, userID, userRol); err != nil {
+        return nil, err
+    }
+
+    existing, err := s.leccionRepo.FindByID(id)
+    if err != nil {
+        return nil, err
+    }
+
+    if existing.CursoID != cursoID {
+        return nil, errors.New("lección no encontrada")
+    }
+
+    err = s.leccionRepo.Update(id, leccion)
+    if err != nil {
+        return nil, err
+    }
+
+    return leccion, nil
+}
+
+// Delete elimina una lección de un curso del instructor
+func (s *LeccionService) Delete(cursoID, id int, userID int, userRol string) error {
+    if err := s.verificarInstructor(cursoID, userID, userRol); err != nil {
+        return err
+    }
+
+    existing, err := s.leccionRepo.FindByID(id)
+    if err != nil {
+        return err
+    }
+
+    if existing.CursoID != cursoID {
+        return errors.New("lección no encontrada")
+    }
+
+    return s.leccionRepo.Delete(id)
+}
+
+// Reorder reordena las lecciones de un curso. leccionIDs debe contener
+// exactamente todas las lecciones del curso en el nuevo orden.
+func (s *LeccionService) Reorder(cursoID int, leccionIDs []int, userID int, userRol string) ([]models.Leccion, error) {
+    if err := s.verificarInstructor(cursoID, userID, userRol); err != nil {
+        return nil, err
+    }
+
+    actuales, err := s.leccionRepo.GetByCurso(cursoID)
+    if err != nil {
+        return nil, err
+    }
+
+    if len(leccionIDs) != len(actuales) {
+        return nil, errors.New("debes incluir todas las lecciones del curso")
+    }
+
+    pendientes := make(map[int]bool, len(actuales))
+    for _, leccion := range actuales {
+        pendientes[leccion.ID] = true
+    }
+
+    for _, id := range leccionIDs {
+        if !pendientes[id] {
+            return nil, errors.New("lista de lecciones inválida o con duplicados")
+        }
+        delete(pendientes, id)
+    }
+
+    err = s.leccionRepo.Reorder(cursoID, leccionIDs)
+    if err != nil {
+        return nil, err
+    }
+
+    return s.leccionRepo.GetByCurso(cursoID)
+}
+
+// verificarInstructor comprueba que el usuario puede gestionar el contenido del
+// curso: su instructor o un admin
+func (s *LeccionService) verificarInstructor(cursoID int, userID int, userRol string) error {
+    if !policy.Puede(userRol, policy.CursoContentOwn) {
+        return errors.New("solo los instructores pueden gestionar lecciones")
+    }
+
+    curso, err := s.cursoRepo.FindByID(cursoID)
+    if err != nil || !policy.PuedeSobre(userRol, policy.CursoContentOwn, userID, curso.InstructorID) {
+        return errors.New("curso no encontrado o no tienes permiso")
+    }
+
+    return nil
+}
+
+// verificarAcceso comprueba que el usuario puede leer las lecciones del curso:
+// quien gestiona su contenido o un alumno con inscripción vigente
+func (s *LeccionService) verificarAcceso(cursoID int, userID int, userRol string) error {
+    if policy.Puede(userRol, policy.CursoContentOwn) {
+        return s.verificarInstructor(cursoID, userID, userRol)
+    }
+
+    inscripcion, err := s.inscripcionRepo.FindByUsuarioAndCurso(userID, cursoID)
+    if err != nil || inscripcion.Estado == EstadoInscripcionCancelado {
+        return errors.New("debes estar inscrito en el curso para ver sus lecciones")
+    }
+
+    return nil
+}
+
+// validarLeccion valida los campos editables de una lección
+func validarLeccion(leccion *models.Leccion) error {
+    if leccion.Titulo == "" {
+        return errors.New("el título de la lección es requerido")
+    }
+
+    if leccion.DuracionMinutos < 0 {
+        return errors.New("la duración no puede ser negativa")
+    }
+
+    return nil
+}
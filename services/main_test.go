@@ -0,0 +1,364 @@
+package services_test
+
+import (
+    "cursos-api/mailer"
+    "cursos-api/models"
+    "cursos-api/ratelimit"
+    "cursos-api/repository/memory"
+    "cursos-api/services"
+    "cursos-api/utils"
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "sync"
+    "testing"
+    "time"
+)
+
+// TestMain genera una clave de firma temporal para que los servicios puedan
+// emitir tokens sin depender de ./keys
+func TestMain(m *testing.M) {
+    os.Exit(ejecutarConClaves(m))
+}
+
+func ejecutarConClaves(m *testing.M) int {
+    dir, err := os.MkdirTemp("", "cursos-api-keys")
+    if err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        return 1
+    }
+    defer os.RemoveAll(dir)
+
+    clave, err := utils.GenerateJWTKey(utils.AlgEdDSA)
+    if err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        return 1
+    }
+    nombre, err := utils.NombreArchivoClave(time.Now().Add(-time.Hour))
+    if err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        return 1
+    }
+    if err := os.WriteFile(filepath.Join(dir, nombre), clave, 0o600); err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        return 1
+    }
+
+    os.Setenv("JWT_KEYS_DIR", dir)
+    if err := utils.InitJWT(); err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        return 1
+    }
+
+    return m.Run()
+}
+
+// passwordPruebas es la contraseña de los usuarios creados con crearUsuario
+const passwordPruebas = "secreto123"
+
+// entorno reúne los servicios bajo prueba sobre el almacenamiento en memoria
+type entorno struct {
+    usuarios services.UsuarioRepository
+    cursos   services.CursoRepository
+    sesiones *fakeSesionRepo
+    tokens   *fakeTokenRepo
+    mailer   *fakeMailer
+    mfaRepo  *fakeMFARepo
+
+    auth    *services.AuthService
+    cuenta  *services.CuentaService
+    usuario *services.UsuarioService
+    curso   *services.CursoService
+    mfa     *services.MFAService
+}
+
+func nuevoEntorno(t *testing.T) *entorno {
+    t.Helper()
+
+    store := memory.NewStore()
+    e := &entorno{
+        usuarios: store.Usuarios(),
+        cursos:   store.Cursos(),
+        sesiones: newFakeSesionRepo(),
+        tokens:   &fakeTokenRepo{},
+        mailer:   &fakeMailer{},
+        mfaRepo:  newFakeMFARepo(),
+    }
+
+    sesionService := services.NewSesionService(e.sesiones, e.usuarios)
+    e.cuenta = services.NewCuentaService(e.usuarios, e.tokens, sesionService, e.mailer)
+    e.mfa = services.NewMFAService(e.mfaRepo, e.usuarios)
+
+    e.auth = services.NewAuthService(e.usuarios, sesionService, e.cuenta, e.mfa, ratelimit.NewSlidingWindow(1000, time.Minute))
+    e.usuario = services.NewUsuarioService(e.usuarios, sesionService, e.cuenta)
+    e.curso = services.NewCursoService(e.cursos, e.usuarios, nil)
+    return e
+}
+
+// crearUsuario da de alta un usuario activo con passwordPruebas
+func (e *entorno) crearUsuario(t *testing.T, email, rol string) *models.Usuario {
+    t.Helper()
+
+    hash, err := utils.HashPassword(passwordPruebas)
+    if err != nil {
+        t.Fatalf("HashPassword: %v", err)
+    }
+
+    usuario := &models.Usuario{Nombre: email, Email: email, PasswordHash: hash, Rol: rol, Activo: true}
+    if err := e.usuarios.Create(usuario); err != nil {
+        t.Fatalf("Create(%s): %v", email, err)
+    }
+    return usuario
+}
+
+// crearCurso da de alta un curso del instructor indicado
+func (e *entorno) crearCurso(t *testing.T, instructor *models.Usuario, nombre string) *models.Curso {
+    t.Helper()
+
+    curso, err := e.curso.Create(&models.Curso{Nombre: nombre, DuracionHoras: 10, InstructorID: instructor.ID}, instructor.ID, instructor.Rol)
+    if err != nil {
+        t.Fatalf("Create(%s): %v", nombre, err)
+    }
+    return curso
+}
+
+// fakeSesionRepo guarda las sesiones en memoria y anota las revocaciones
+type fakeSesionRepo struct {
+    mu        sync.Mutex
+    sesiones  map[string]*models.Sesion
+    revocadas map[int]string // usuario -> motivo
+}
+
+func newFakeSesionRepo() *fakeSesionRepo {
+    return &fakeSesionRepo{
+        sesiones:  make(map[string]*models.Sesion),
+        revocadas: make(map[int]string),
+    }
+}
+
+func (r *fakeSesionRepo) Create(sesion *models.Sesion, token *models.RefreshToken) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    r.sesiones[sesion.ID] = sesion
+    return nil
+}
+
+func (r *fakeSesionRepo) FindRefreshToken(tokenHash string) (*models.RefreshToken, error) {
+    return nil, errors.New("refresh token no encontrado")
+}
+
+func (r *fakeSesionRepo) Rotar(tokenID int, nuevo *models.RefreshToken) (bool, error) {
+    return false, nil
+}
+
+func (r *fakeSesionRepo) Revocar(sesionID, motivo string) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    delete(r.sesiones, sesionID)
+    return nil
+}
+
+func (r *fakeSesionRepo) RevocarPorUsuario(usuarioID int, motivo string) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    for id, sesion := range r.sesiones {
+        if sesion.UsuarioID == usuarioID {
+            delete(r.sesiones, id)
+        }
+    }
+    r.revocadas[usuarioID] = motivo
+    return nil
+}
+
+func (r *fakeSesionRepo) IsActiva(sesionID string) (bool, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    _, ok := r.sesiones[sesionID]
+    return ok, nil
+}
+
+// fakeTokenRepo guarda los tokens de un solo uso con la misma semántica que
+// el repositorio de Postgres
+type fakeTokenRepo struct {
+    mu     sync.Mutex
+    tokens []*models.TokenUsuario
+}
+
+func (r *fakeTokenRepo) Create(token *models.TokenUsuario) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    token.ID = len(r.tokens) + 1
+    token.CreatedAt = time.Now()
+    copia := *token
+    r.tokens = append(r.tokens, &copia)
+    return nil
+}
+
+func (r *fakeTokenRepo) Consumir(tipo, tokenHash string) (*models.TokenUsuario, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    now := time.Now()
+    for _, token := range r.tokens {
+        if token.TokenHash == tokenHash && token.Tipo == tipo && token.UsadoAt == nil && token.ExpiraAt.After(now) {
+            token.UsadoAt = &now
+            copia := *token
+            return &copia, nil
+        }
+    }
+    return nil, errors.New("token inválido, expirado o ya utilizado")
+}
+
+func (r *fakeTokenRepo) InvalidarPorUsuario(usuarioID int, tipo string) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    now := time.Now()
+    for _, token := range r.tokens {
+        if token.UsuarioID == usuarioID && token.Tipo == tipo && token.UsadoAt == nil {
+            token.UsadoAt = &now
+        }
+    }
+    return nil
+}
+
+// fakeMailer guarda los correos enviados
+type fakeMailer struct {
+    mu       sync.Mutex
+    enviados []mailer.Message
+}
+
+func (m *fakeMailer) Send(msg mailer.Message) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    m.enviados = append(m.enviados, msg)
+    return nil
+}
+
+// fakeMFARepo guarda la configuración TOTP y los códigos de recuperación con
+// la misma semántica de un solo uso que el repositorio de Postgres
+type fakeMFARepo struct {
+    mu      sync.Mutex
+    configs map[int]*models.ConfigTOTP
+    codigos map[int]map[string]bool // hash -> usado
+}
+
+func newFakeMFARepo() *fakeMFARepo {
+    return &fakeMFARepo{
+        configs: make(map[int]*models.ConfigTOTP),
+        codigos: make(map[int]map[string]bool),
+    }
+}
+
+func (r *fakeMFARepo) config(usuarioID int) *models.ConfigTOTP {
+    config, ok := r.configs[usuarioID]
+    if !ok {
+        config = &models.ConfigTOTP{UsuarioID: usuarioID}
+        r.configs[usuarioID] = config
+    }
+    return config
+}
+
+func (r *fakeMFARepo) GetConfigTOTP(usuarioID int) (*models.ConfigTOTP, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    copia := *r.config(usuarioID)
+    return &copia, nil
+}
+
+func (r *fakeMFARepo) IniciarTOTP(usuarioID int, secret string) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    r.config(usuarioID).Secret = secret
+    return nil
+}
+
+func (r *fakeMFARepo) HabilitarTOTP(usuarioID int, paso int64, codigoHashes []string) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    config := r.config(usuarioID)
+    config.Habilitado = true
+    config.UltimoPaso = paso
+    r.reemplazar(usuarioID, codigoHashes)
+    return nil
+}
+
+func (r *fakeMFARepo) DeshabilitarTOTP(usuarioID int) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    r.configs[usuarioID] = &models.ConfigTOTP{UsuarioID: usuarioID}
+    delete(r.codigos, usuarioID)
+    return nil
+}
+
+func (r *fakeMFARepo) RegistrarPasoTOTP(usuarioID int, paso int64) (bool, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    config := r.config(usuarioID)
+    if paso <= config.UltimoPaso {
+        return false, nil
+    }
+    config.UltimoPaso = paso
+    return true, nil
+}
+
+func (r *fakeMFARepo) ReemplazarCodigosRecuperacion(usuarioID int, codigoHashes []string) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    r.reemplazar(usuarioID, codigoHashes)
+    return nil
+}
+
+func (r *fakeMFARepo) reemplazar(usuarioID int, codigoHashes []string) {
+    codigos := make(map[string]bool, len(codigoHashes))
+    for _, hash := range codigoHashes {
+        codigos[hash] = false
+    }
+    r.codigos[usuarioID] = codigos
+}
+
+func (r *fakeMFARepo) ConsumirCodigoRecuperacion(usuarioID int, codigoHash string) (bool, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    usado, ok := r.codigos[usuarioID][codigoHash]
+    if !ok || usado {
+        return false, nil
+    }
+    r.codigos[usuarioID][codigoHash] = true
+    return true, nil
+}
+
+func (r *fakeMFARepo) ContarCodigosRecuperacion(usuarioID int) (int, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    total := 0
+    for _, usado := range r.codigos[usuarioID] {
+        if !usado {
+            total++
+        }
+    }
+    return total, nil
+}
+
+// Los dobles cumplen las interfaces que consumen los servicios
+var (
+    _ services.SesionRepository       = (*fakeSesionRepo)(nil)
+    _ services.TokenUsuarioRepository = (*fakeTokenRepo)(nil)
+    _ services.MFARepository          = (*fakeMFARepo)(nil)
+    _ mailer.Mailer                   = (*fakeMailer)(nil)
+)
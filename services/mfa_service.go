@@ -0,0 +1,200 @@
+package services
+
+import (
+    "cursos-api/models"
+    "cursos-api/utils"
+    "errors"
+    "os"
+    "strings"
+    "time"
+)
+
+// numCodigosRecuperacion es cuántos códigos de recuperación se generan
+const numCodigosRecuperacion = 10
+
+// MFAService gestiona la autenticación en dos pasos con TOTP (RFC 6238) y los
+// códigos de recuperación de un solo uso
+type MFAService struct {
+    mfaRepo     MFARepository
+    usuarioRepo UsuarioRepository
+}
+
+func NewMFAService(mfaRepo MFARepository, usuarioRepo UsuarioRepository) *MFAService {
+    return &MFAService{
+        mfaRepo:     mfaRepo,
+        usuarioRepo: usuarioRepo,
+    }
+}
+
+// totpIssuer es el nombre con el que la cuenta aparece en la app de
+// autenticación (TOTP_ISSUER)
+func totpIssuer() string {
+    if issuer := os.Getenv("TOTP_ISSUER"); issuer != "" {
+        return issuer
+    }
+    return "Cursos API"
+}
+
+// Estado indica si el usuario tiene 2FA y cuántos códigos de recuperación le quedan
+func (s *MFAService) Estado(userID int) (bool, int, error) {
+    config, err := s.mfaRepo.GetConfigTOTP(userID)
+    if err != nil {
+        return false, 0, err
+    }
+
+    if !config.Habilitado {
+        return false, 0, nil
+    }
+
+    restantes, err := s.mfaRepo.ContarCodigosRecuperacion(userID)
+    return true, restantes, err
+}
+
+// IniciarTOTP genera un secreto nuevo para el usuario. El 2FA no se activa
+// hasta confirmarlo con un código (ActivarTOTP).
+func (s *MFAService) IniciarTOTP(userID int) (*models.AltaTOTP, error) {
+    usuario, err := s.usuarioRepo.FindByID(userID)
+    if err != nil {
+        return nil, err
+    }
+
+    secret, err := utils.GenerateTOTPSecret()
+    if err != nil {
+        return nil, err
+    }
+
+    if err := s.mfaRepo.IniciarTOTP(userID, secret); err != nil {
+        return nil, err
+    }
+
+    return &models.AltaTOTP{
+        Secret: secret,
+        URI:    utils.TOTPURI(secret, totpIssuer(), usuario.Email),
+    }, nil
+}
+
+// ActivarTOTP confirma el alta con un código de la app y devuelve los códigos
+// de recuperación, que solo se muestran esta vez
+func (s *MFAService) ActivarTOTP(userID int, codigo string) ([]string, error) {
+    config, err := s.mfaRepo.GetConfigTOTP(userID)
+    if err != nil {
+        return nil, err
+    }
+
+    if config.Habilitado {
+        return nil, errors.New("la autenticación en dos pasos ya está activada")
+    }
+    if config.Secret == "" {
+        return nil, errors.New("no hay un alta de autenticación en dos pasos pendiente")
+    }
+
+    paso, ok := utils.ValidateTOTP(config.Secret, codigo, time.Now())
+    if !ok {
+        return nil, errors.New("código de verificación inválido")
+    }
+
+    codigos, hashes, err := generarCodigosRecuperacion()
+    if err != nil {
+        return nil, err
+    }
+
+    if err := s.mfaRepo.HabilitarTOTP(userID, paso, hashes); err != nil {
+        return nil, err
+    }
+
+    return codigos, nil
+}
+
+// DesactivarTOTP desactiva el 2FA. Exige la contraseña y un código válido.
+func (s *MFAService) DesactivarTOTP(userID int, req *models.DesactivarTOTPRequest) error {
+    usuario, err := s.usuarioRepo.FindByID(userID)
+    if err != nil {
+        return err
+    }
+
+    if !utils.CheckPasswordHash(req.Password, usuario.PasswordHash) {
+        return errors.New("contraseña incorrecta")
+    }
+
+    if err := s.Verificar(userID, req.Codigo); err != nil {
+        return err
+    }
+
+    return s.mfaRepo.DeshabilitarTOTP(userID)
+}
+
+// RegenerarCodigos sustituye los códigos de recuperación por otros nuevos
+func (s *MFAService) RegenerarCodigos(userID int, codigo string) ([]string, error) {
+    if err := s.Verificar(userID, codigo); err != nil {
+        return nil, err
+    }
+
+    codigos, hashes, err := generarCodigosRecuperacion()
+    if err != nil {
+        return nil, err
+    }
+
+    if err := s.mfaRepo.ReemplazarCodigosRecuperacion(userID, hashes); err != nil {
+        return nil, err
+    }
+
+    return codigos, nil
+}
+
+// Verificar comprueba un código TOTP o de recuperación del usuario. Ambos
+// son de un solo uso: un código TOTP no vale dos veces en su intervalo.
+func (s *MFAService) Verificar(userID int, codigo string) error {
+    config, err := s.mfaRepo.GetConfigTOTP(userID)
+    if err != nil {
+        return err
+    }
+
+    if !config.Habilitado {
+        return errors.New("la autenticación en dos pasos no está activada")
+    }
+
+    if paso, ok := utils.ValidateTOTP(config.Secret, codigo, time.Now()); ok {
+        aceptado, err := s.mfaRepo.RegistrarPasoTOTP(userID, paso)
+        if err != nil {
+            return err
+        }
+        if !aceptado {
+            return errors.New("el código ya se ha utilizado, espera al siguiente")
+        }
+        return nil
+    }
+
+    consumido, err := s.mfaRepo.ConsumirCodigoRecuperacion(userID, utils.HashToken(normalizarCodigoRecuperacion(codigo)))
+    if err != nil {
+        return err
+    }
+    if !consumido {
+        return errors.New("código de verificación inválido")
+    }
+
+    return nil
+}
+
+// generarCodigosRecuperacion genera los códigos de recuperación y sus hashes
+func generarCodigosRecuperacion() ([]string, []string, error) {
+    codigos := make([]string, numCodigosRecuperacion)
+    hashes := make([]string, numCodigosRecuperacion)
+
+    for i := range codigos {
+        codigo, err := utils.GenerateRandomCode(5)
+        if err != nil {
+            return nil, nil, err
+        }
+        codigos[i] = codigo
+        hashes[i] = utils.HashToken(normalizarCodigoRecuperacion(codigo))
+    }
+
+    return codigos, hashes, nil
+}
+
+// normalizarCodigoRecuperacion ignora guiones, espacios y mayúsculas al
+// comparar códigos de recuperación
+func normalizarCodigoRecuperacion(codigo string) string {
+    codigo = strings.ToUpper(codigo)
+    return strings.NewReplacer("-", "", " ", "").Replace(codigo)
+}
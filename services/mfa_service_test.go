@@ -0,0 +1,136 @@
+package services_test
+
+import (
+    "cursos-api/policy"
+    "cursos-api/utils"
+    "strings"
+    "testing"
+    "time"
+)
+
+// activarTOTP da de alta el 2FA del usuario con el código del paso actual y
+// devuelve el secreto, el paso usado y los códigos de recuperación
+func (e *entorno) activarTOTP(t *testing.T, userID int) (string, int64, []string) {
+    t.Helper()
+
+    alta, err := e.mfa.IniciarTOTP(userID)
+    if err != nil {
+        t.Fatalf("IniciarTOTP: %v", err)
+    }
+
+    paso := utils.TOTPStep(time.Now())
+    codigos, err := e.mfa.ActivarTOTP(userID, codigoTOTP(t, alta.Secret, paso))
+    if err != nil {
+        t.Fatalf("ActivarTOTP: %v", err)
+    }
+    return alta.Secret, paso, codigos
+}
+
+func codigoTOTP(t *testing.T, secret string, paso int64) string {
+    t.Helper()
+
+    codigo, err := utils.TOTPCode(secret, paso)
+    if err != nil {
+        t.Fatalf("TOTPCode: %v", err)
+    }
+    return codigo
+}
+
+func TestMFAServiceRechazaReutilizarUnPaso(t *testing.T) {
+    e := nuevoEntorno(t)
+    ana := e.crearUsuario(t, "ana@example.com", policy.RolAlumno)
+    secret, paso, _ := e.activarTOTP(t, ana.ID)
+
+    // El código con el que se activó ya está gastado
+    if err := e.mfa.Verificar(ana.ID, codigoTOTP(t, secret, paso)); err == nil {
+        t.Fatal("Verificar aceptó el código usado en la activación")
+    }
+
+    // El del paso siguiente entra en la ventana y solo vale una vez
+    siguiente := codigoTOTP(t, secret, paso+1)
+    if err := e.mfa.Verificar(ana.ID, siguiente); err != nil {
+        t.Fatalf("Verificar del paso siguiente: %v", err)
+    }
+    if err := e.mfa.Verificar(ana.ID, siguiente); err == nil {
+        t.Fatal("Verificar aceptó dos veces el mismo código")
+    }
+}
+
+func TestMFAServiceVentanaDeDesfase(t *testing.T) {
+    e := nuevoEntorno(t)
+    ana := e.crearUsuario(t, "ana@example.com", policy.RolAlumno)
+    secret, paso, _ := e.activarTOTP(t, ana.ID)
+
+    // Dos pasos por delante queda fuera de la ventana de ±1
+    if err := e.mfa.Verificar(ana.ID, codigoTOTP(t, secret, paso+3)); err == nil {
+        t.Fatal("Verificar aceptó un código fuera de la ventana de desfase")
+    }
+    if err := e.mfa.Verificar(ana.ID, codigoTOTP(t, secret, paso-2)); err == nil {
+        t.Fatal("Verificar aceptó un código antiguo fuera de la ventana")
+    }
+}
+
+func TestMFAServiceActivarExigeCodigoValido(t *testing.T) {
+    e := nuevoEntorno(t)
+    ana := e.crearUsuario(t, "ana@example.com", policy.RolAlumno)
+
+    if _, err := e.mfa.ActivarTOTP(ana.ID, "123456"); err == nil {
+        t.Fatal("ActivarTOTP funcionó sin un alta iniciada")
+    }
+
+    alta, err := e.mfa.IniciarTOTP(ana.ID)
+    if err != nil {
+        t.Fatalf("IniciarTOTP: %v", err)
+    }
+    if !strings.HasPrefix(alta.URI, "otpauth://totp/") || !strings.Contains(alta.URI, "secret="+alta.Secret) {
+        t.Fatalf("URI de alta inesperada: %s", alta.URI)
+    }
+
+    incorrecto := codigoTOTP(t, alta.Secret, utils.TOTPStep(time.Now())+5)
+    if _, err := e.mfa.ActivarTOTP(ana.ID, incorrecto); err == nil {
+        t.Fatal("ActivarTOTP aceptó un código inválido")
+    }
+
+    if habilitado, _, err := e.mfa.Estado(ana.ID); err != nil || habilitado {
+        t.Fatalf("Estado = (%v, %v), el 2FA no debía quedar activo", habilitado, err)
+    }
+}
+
+func TestMFAServiceCodigosDeRecuperacionDeUnSoloUso(t *testing.T) {
+    e := nuevoEntorno(t)
+    ana := e.crearUsuario(t, "ana@example.com", policy.RolAlumno)
+    secret, paso, codigos := e.activarTOTP(t, ana.ID)
+
+    if len(codigos) != 10 {
+        t.Fatalf("ActivarTOTP devolvió %d códigos de recuperación, se esperaban 10", len(codigos))
+    }
+
+    if err := e.mfa.Verificar(ana.ID, codigos[0]); err != nil {
+        t.Fatalf("Verificar con un código de recuperación: %v", err)
+    }
+    if err := e.mfa.Verificar(ana.ID, codigos[0]); err == nil {
+        t.Fatal("un código de recuperación se pudo usar dos veces")
+    }
+
+    // Se comparan sin guiones, espacios ni mayúsculas
+    variante := strings.ToLower(strings.ReplaceAll(codigos[1], "-", " "))
+    if err := e.mfa.Verificar(ana.ID, variante); err != nil {
+        t.Fatalf("Verificar con %q: %v", variante, err)
+    }
+
+    if _, restantes, err := e.mfa.Estado(ana.ID); err != nil || restantes != 8 {
+        t.Fatalf("Estado = (%d, %v), se esperaban 8 códigos restantes", restantes, err)
+    }
+
+    // Regenerar invalida los anteriores
+    nuevos, err := e.mfa.RegenerarCodigos(ana.ID, codigoTOTP(t, secret, paso+1))
+    if err != nil {
+        t.Fatalf("RegenerarCodigos: %v", err)
+    }
+    if err := e.mfa.Verificar(ana.ID, codigos[2]); err == nil {
+        t.Fatal("un código anterior a la regeneración siguió siendo válido")
+    }
+    if err := e.mfa.Verificar(ana.ID, nuevos[0]); err != nil {
+        t.Fatalf("Verificar con un código regenerado: %v", err)
+    }
+}
@@ -0,0 +1,288 @@
+package services
+
+import (
+    "cursos-api/models"
+    "cursos-api/oidc"
+    "cursos-api/policy"
+    "cursos-api/utils"
+    "errors"
+    "log"
+    "sort"
+    "strings"
+    "time"
+)
+
+// TokenOIDCLogin es el tipo del código de un solo uso con el que el frontend
+// canjea un login OIDC por los tokens de sesión
+const TokenOIDCLogin = "oidc_login"
+
+const (
+    // estadoOIDCTTL es cuánto tiene el usuario para volver del proveedor
+    estadoOIDCTTL = 10 * time.Minute
+    // codigoCanjeTTL es cuánto vale el código de canje entregado al frontend
+    codigoCanjeTTL = time.Minute
+)
+
+// OIDCService gestiona el login con proveedores de identidad externos
+// (authorization code + PKCE). Las cuentas externas se vinculan a usuarios
+// por (proveedor, subject); en el primer login se vinculan al usuario con el
+// mismo email verificado o se crea un alumno nuevo.
+type OIDCService struct {
+    proveedores   map[string]*oidc.Provider
+    identidadRepo IdentidadRepository
+    usuarioRepo   UsuarioRepository
+    tokenRepo     TokenUsuarioRepository
+    authService   *AuthService
+    cuentaService *CuentaService
+}
+
+func NewOIDCService(
+    proveedores map[string]*oidc.Provider,
+    identidadRepo IdentidadRepository,
+    usuarioRepo UsuarioRepository,
+    tokenRepo TokenUsuarioRepository,
+    authService *AuthService,
+    cuentaService *CuentaService,
+) *OIDCService {
+    return &OIDCService{
+        proveedores:   proveedores,
+        identidadRepo: identidadRepo,
+        usuarioRepo:   usuarioRepo,
+        tokenRepo:     tokenRepo,
+        authService:   authService,
+        cuentaService: cuentaService,
+    }
+}
+
+// Proveedores devuelve los nombres de los proveedores configurados
+func (s *OIDCService) Proveedores() []string {
+    nombres := make([]string, 0, len(s.proveedores))
+    for nombre := range s.proveedores {
+        nombres = append(nombres, nombre)
+    }
+    sort.Strings(nombres)
+    return nombres
+}
+
+// IniciarLogin prepara un login con el proveedor y devuelve la URL a la que
+// redirigir al usuario y el state que debe volver en el callback
+func (s *OIDCService) IniciarLogin(nombre string) (string, string, error) {
+    proveedor, err := s.proveedor(nombre)
+    if err != nil {
+        return "", "", err
+    }
+
+    state, err := utils.GenerateRandomToken(32)
+    if err != nil {
+        return "", "", err
+    }
+    nonce, err := utils.GenerateRandomToken(32)
+    if err != nil {
+        return "", "", err
+    }
+    verifier, err := utils.GenerateRandomToken(32)
+    if err != nil {
+        return "", "", err
+    }
+
+    authURL, err := proveedor.AuthCodeURL(state, nonce, verifier)
+    if err != nil {
+        return "", "", err
+    }
+
+    err = s.identidadRepo.CreateEstado(&models.EstadoOIDC{
+        StateHash:    utils.HashToken(state),
+        Proveedor:    proveedor.Nombre,
+        Nonce:        nonce,
+        CodeVerifier: verifier,
+        ExpiraAt:     time.Now().Add(estadoOIDCTTL),
+    })
+    if err != nil {
+        return "", "", err
+    }
+
+    return authURL, state, nil
+}
+
+// CompletarLogin procesa el callback del proveedor: canjea el código, valida
+// el id_token y devuelve el usuario vinculado, creándolo si hace falta
+func (s *OIDCService) CompletarLogin(nombre, state, code string) (*models.Usuario, error) {
+    proveedor, err := s.proveedor(nombre)
+    if err != nil {
+        return nil, err
+    }
+
+    if state == "" || code == "" {
+        return nil, errors.New("faltan state o code en la respuesta del proveedor")
+    }
+
+    estado, err := s.identidadRepo.ConsumirEstado(utils.HashToken(state))
+    if err != nil {
+        return nil, err
+    }
+    if estado.Proveedor != proveedor.Nombre {
+        return nil, errors.New("login expirado o inválido, vuelve a intentarlo")
+    }
+
+    identidad, err := proveedor.Exchange(code, estado.CodeVerifier, estado.Nonce)
+    if err != nil {
+        return nil, err
+    }
+
+    return s.resolverUsuario(proveedor.Nombre, identidad)
+}
+
+// resolverUsuario busca el usuario de una identidad externa. En el primer
+// login la vincula al alumno con el mismo email (solo si el proveedor lo da
+// por verificado) o da de alta un alumno nuevo. Las cuentas de instructor y
+// admin nunca se vinculan solas: el proveedor bastaría para entrar en ellas.
+func (s *OIDCService) resolverUsuario(proveedor string, identidad *oidc.Identity) (*models.Usuario, error) {
+    existente, err := s.identidadRepo.FindByProveedorSubject(proveedor, identidad.Subject)
+    if err == nil {
+        if err := s.identidadRepo.RegistrarLogin(existente.ID); err != nil {
+            log.Printf("⚠️  No se pudo registrar el login OIDC (identidad %d): %v\n", existente.ID, err)
+        }
+        return s.usuarioRepo.FindByID(existente.UsuarioID)
+    }
+
+    if identidad.Email == "" {
+        return nil, errors.New("el proveedor no facilitó el email de la cuenta")
+    }
+
+    vinculo := &models.IdentidadExterna{
+        Proveedor: proveedor,
+        Subject:   identidad.Subject,
+        Email:     identidad.Email,
+    }
+
+    usuario, err := s.usuarioRepo.FindByEmail(identidad.Email)
+    if err == nil {
+        // Sin email verificado cualquiera podría apropiarse de la cuenta, y en
+        // las de instructor o admin el proveedor sustituiría a la contraseña
+        if !identidad.EmailVerified || usuario.Rol != policy.RolAlumno {
+            return nil, errors.New("ya existe una cuenta con ese email; inicia sesión con tu contraseña")
+        }
+
+        vinculo.UsuarioID = usuario.ID
+        if err := s.identidadRepo.Vincular(vinculo); err != nil {
+            return nil, err
+        }
+
+        if !usuario.EmailVerificado {
+            if err := s.usuarioRepo.MarcarEmailVerificado(usuario.ID); err != nil {
+                return nil, err
+            }
+            usuario.EmailVerificado = true
+        }
+
+        return usuario, nil
+    }
+
+    return s.crearUsuario(vinculo, identidad)
+}
+
+// crearUsuario da de alta un alumno para la identidad externa. Su contraseña
+// es aleatoria: si quiere entrar también con email y contraseña puede fijarla
+// con el restablecimiento de contraseña.
+func (s *OIDCService) crearUsuario(vinculo *models.IdentidadExterna, identidad *oidc.Identity) (*models.Usuario, error) {
+    password, err := utils.GenerateRandomToken(32)
+    if err != nil {
+        return nil, err
+    }
+    hashedPassword, err := utils.HashPassword(password)
+    if err != nil {
+        return nil, errors.New("error al procesar la contraseña")
+    }
+
+    nombre := strings.TrimSpace(identidad.Nombre)
+    if nombre == "" {
+        nombre = strings.SplitN(identidad.Email, "@", 2)[0]
+    }
+
+    usuario := &models.Usuario{
+        Nombre:          nombre,
+        Email:           identidad.Email,
+        PasswordHash:    hashedPassword,
+        Rol:             policy.RolRegistro,
+        EmailVerificado: identidad.EmailVerified,
+    }
+
+    if err := s.identidadRepo.CrearConUsuario(usuario, vinculo); err != nil {
+        return nil, err
+    }
+
+    if !usuario.EmailVerificado {
+        if err := s.cuentaService.EnviarVerificacion(usuario); err != nil {
+            log.Printf("⚠️  No se pudo enviar la verificación de email (usuario %d): %v\n", usuario.ID, err)
+        }
+    }
+
+    return usuario, nil
+}
+
+// Login abre la sesión del usuario autenticado por el proveedor (o devuelve
+// el desafío 2FA)
+func (s *OIDCService) Login(usuario *models.Usuario) (*models.LoginResponse, error) {
+    return s.authService.LoginExterno(usuario)
+}
+
+// EmitirCodigoCanje genera el código de un solo uso con el que el frontend
+// obtiene los tokens tras la redirección, sin que viajen en la URL
+func (s *OIDCService) EmitirCodigoCanje(usuario *models.Usuario) (string, error) {
+    codigo, err := utils.GenerateRandomToken(bytesTokenUsuario)
+    if err != nil {
+        return "", err
+    }
+
+    err = s.tokenRepo.Create(&models.TokenUsuario{
+        UsuarioID: usuario.ID,
+        Tipo:      TokenOIDCLogin,
+        TokenHash: utils.HashToken(codigo),
+        ExpiraAt:  time.Now().Add(codigoCanjeTTL),
+    })
+    if err != nil {
+        return "", err
+    }
+
+    return codigo, nil
+}
+
+// Canjear consume el código de canje y abre la sesión del usuario
+func (s *OIDCService) Canjear(req *models.OIDCCanjeRequest) (*models.LoginResponse, error) {
+    if req.Codigo == "" {
+        return nil, errors.New("el código es requerido")
+    }
+
+    token, err := s.tokenRepo.Consumir(TokenOIDCLogin, utils.HashToken(req.Codigo))
+    if err != nil {
+        return nil, err
+    }
+
+    usuario, err := s.usuarioRepo.FindByID(token.UsuarioID)
+    if err != nil {
+        return nil, err
+    }
+
+    return s.Login(usuario)
+}
+
+// Identidades devuelve las cuentas externas vinculadas al usuario
+func (s *OIDCService) Identidades(userID int) ([]models.IdentidadExterna, error) {
+    identidades, err := s.identidadRepo.GetByUsuario(userID)
+    if err != nil {
+        return nil, err
+    }
+    if identidades == nil {
+        identidades = []models.IdentidadExterna{}
+    }
+    return identidades, nil
+}
+
+// proveedor devuelve el proveedor configurado con ese nombre
+func (s *OIDCService) proveedor(nombre string) (*oidc.Provider, error) {
+    proveedor, ok := s.proveedores[strings.ToLower(nombre)]
+    if !ok {
+        return nil, errors.New("proveedor de identidad no encontrado")
+    }
+    return proveedor, nil
+}
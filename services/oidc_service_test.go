@@ -0,0 +1,464 @@
+package services_test
+
+import (
+    "bytes"
+    "cursos-api/handlers"
+    "cursos-api/models"
+    "cursos-api/oidc"
+    "cursos-api/policy"
+    "cursos-api/services"
+    "encoding/json"
+    "errors"
+    "net/http"
+    "net/http/cookiejar"
+    "net/http/httptest"
+    "net/url"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/gorilla/mux"
+)
+
+// Las pruebas recorren el login OIDC de punta a punta: la API y el proveedor
+// simulado (oidc.MockIdP) corren en servidores httptest y un navegador con
+// cookies sigue las redirecciones paso a paso.
+
+// escenarioOIDC es la API con el proveedor "mock" configurado
+type escenarioOIDC struct {
+    *entorno
+    identidades *fakeIdentidadRepo
+    api         *httptest.Server
+}
+
+func nuevoEscenarioOIDC(t *testing.T) *escenarioOIDC {
+    t.Helper()
+
+    // El issuer debe ser la URL del servidor, así que se asigna el handler
+    // después de arrancarlo
+    idpServer := httptest.NewServer(nil)
+    t.Cleanup(idpServer.Close)
+    idp, err := oidc.NewMockIdP(idpServer.URL)
+    if err != nil {
+        t.Fatalf("NewMockIdP: %v", err)
+    }
+    idpServer.Config.Handler = idp.Handler()
+
+    api := httptest.NewServer(nil)
+    t.Cleanup(api.Close)
+
+    e := nuevoEntorno(t)
+    s := &escenarioOIDC{entorno: e, identidades: newFakeIdentidadRepo(e.usuarios), api: api}
+
+    proveedores := map[string]*oidc.Provider{
+        "mock": oidc.NewProvider("mock", idpServer.URL, "cursos-api", "", api.URL+"/api/auth/oidc/mock/callback"),
+    }
+    h := handlers.NewOIDCHandler(services.NewOIDCService(proveedores, s.identidades, e.usuarios, e.tokens, e.auth, e.cuenta))
+
+    router := mux.NewRouter()
+    router.HandleFunc("/api/auth/oidc/{proveedor}/login", h.Iniciar).Methods("GET")
+    router.HandleFunc("/api/auth/oidc/{proveedor}/callback", h.Callback).Methods("GET")
+    router.HandleFunc("/api/auth/oidc/exchange", h.Canjear).Methods("POST")
+    api.Config.Handler = router
+
+    return s
+}
+
+// navegador guarda las cookies y no sigue las redirecciones solo
+type navegador struct {
+    client *http.Client
+}
+
+func nuevoNavegador(t *testing.T) *navegador {
+    t.Helper()
+
+    jar, err := cookiejar.New(nil)
+    if err != nil {
+        t.Fatalf("cookiejar: %v", err)
+    }
+    return &navegador{client: &http.Client{
+        Jar: jar,
+        CheckRedirect: func(req *http.Request, via []*http.Request) error {
+            return http.ErrUseLastResponse
+        },
+    }}
+}
+
+// redireccion comprueba que la respuesta es un 302 y devuelve su destino
+func redireccion(t *testing.T, resp *http.Response) *url.URL {
+    t.Helper()
+
+    defer resp.Body.Close()
+    if resp.StatusCode != http.StatusFound {
+        t.Fatalf("status %d, se esperaba una redirección", resp.StatusCode)
+    }
+    destino, err := resp.Location()
+    if err != nil {
+        t.Fatalf("Location: %v", err)
+    }
+    return destino
+}
+
+// iniciar abre /login y devuelve la URL de autorización del proveedor
+func (s *escenarioOIDC) iniciar(t *testing.T, nav *navegador) *url.URL {
+    t.Helper()
+
+    resp, err := nav.client.Get(s.api.URL + "/api/auth/oidc/mock/login")
+    if err != nil {
+        t.Fatalf("GET login: %v", err)
+    }
+    return redireccion(t, resp)
+}
+
+// autorizar envía el formulario de login del proveedor y devuelve la URL de
+// vuelta al callback
+func autorizar(t *testing.T, nav *navegador, authURL *url.URL, email, nombre string) *url.URL {
+    t.Helper()
+
+    form := authURL.Query()
+    form.Set("email", email)
+    form.Set("name", nombre)
+
+    destino := *authURL
+    destino.RawQuery = ""
+    resp, err := nav.client.PostForm(destino.String(), form)
+    if err != nil {
+        t.Fatalf("POST authorize: %v", err)
+    }
+    return redireccion(t, resp)
+}
+
+// login recorre el flujo completo y devuelve la respuesta del callback
+func (s *escenarioOIDC) login(t *testing.T, nav *navegador, email, nombre string) *http.Response {
+    t.Helper()
+
+    callback := autorizar(t, nav, s.iniciar(t, nav), email, nombre)
+    resp, err := nav.client.Get(callback.String())
+    if err != nil {
+        t.Fatalf("GET callback: %v", err)
+    }
+    return resp
+}
+
+// respuestaLogin decodifica un login correcto
+func respuestaLogin(t *testing.T, resp *http.Response) *models.LoginResponse {
+    t.Helper()
+
+    defer resp.Body.Close()
+    if resp.StatusCode != http.StatusOK {
+        var cuerpo map[string]string
+        json.NewDecoder(resp.Body).Decode(&cuerpo)
+        t.Fatalf("status %d (%s), se esperaba 200", resp.StatusCode, cuerpo["error"])
+    }
+
+    login := &models.LoginResponse{}
+    if err := json.NewDecoder(resp.Body).Decode(login); err != nil {
+        t.Fatalf("respuesta inválida: %v", err)
+    }
+    if login.TokenPair == nil || login.Token == "" || login.Usuario == nil {
+        t.Fatal("el login no devolvió tokens ni usuario")
+    }
+    return login
+}
+
+// errorLogin comprueba que la respuesta es un error con el status indicado
+func errorLogin(t *testing.T, resp *http.Response, status int, mensaje string) {
+    t.Helper()
+
+    defer resp.Body.Close()
+    var cuerpo map[string]string
+    json.NewDecoder(resp.Body).Decode(&cuerpo)
+    if resp.StatusCode != status || !strings.Contains(cuerpo["error"], mensaje) {
+        t.Fatalf("respuesta %d %q, se esperaba %d con %q", resp.StatusCode, cuerpo["error"], status, mensaje)
+    }
+}
+
+func TestOIDCLoginCreaAlumno(t *testing.T) {
+    s := nuevoEscenarioOIDC(t)
+    nav := nuevoNavegador(t)
+
+    login := respuestaLogin(t, s.login(t, nav, "Nuevo@Example.com", "Nuevo Alumno"))
+    if login.Usuario.Email != "nuevo@example.com" || login.Usuario.Nombre != "Nuevo Alumno" || login.Usuario.Rol != policy.RolRegistro {
+        t.Fatalf("usuario creado = (%q, %q, %q)", login.Usuario.Email, login.Usuario.Nombre, login.Usuario.Rol)
+    }
+
+    usuario, err := s.usuarios.FindByID(login.Usuario.ID)
+    if err != nil {
+        t.Fatalf("FindByID: %v", err)
+    }
+    if !usuario.EmailVerificado {
+        t.Fatal("el email verificado por el proveedor no quedó verificado")
+    }
+
+    // El segundo login entra por la identidad ya vinculada
+    otra := respuestaLogin(t, s.login(t, nav, "nuevo@example.com", ""))
+    if otra.Usuario.ID != usuario.ID {
+        t.Fatalf("el segundo login entró como %d, se esperaba %d", otra.Usuario.ID, usuario.ID)
+    }
+    if identidades, _ := s.identidades.GetByUsuario(usuario.ID); len(identidades) != 1 {
+        t.Fatalf("%d identidades vinculadas, se esperaba 1", len(identidades))
+    }
+}
+
+func TestOIDCVinculaCuentaPorEmailVerificado(t *testing.T) {
+    s := nuevoEscenarioOIDC(t)
+    existente := s.crearUsuario(t, "ana@example.com", policy.RolAlumno)
+
+    login := respuestaLogin(t, s.login(t, nuevoNavegador(t), "ana@example.com", "Ana"))
+    if login.Usuario.ID != existente.ID {
+        t.Fatalf("el login entró como %d, se esperaba la cuenta existente %d", login.Usuario.ID, existente.ID)
+    }
+
+    identidades, err := s.identidades.GetByUsuario(existente.ID)
+    if err != nil || len(identidades) != 1 || identidades[0].Proveedor != "mock" || identidades[0].Email != "ana@example.com" {
+        t.Fatalf("identidades vinculadas = %+v (%v)", identidades, err)
+    }
+
+    usuario, _ := s.usuarios.FindByID(existente.ID)
+    if !usuario.EmailVerificado {
+        t.Fatal("vincular con un email verificado no marcó el email de la cuenta")
+    }
+}
+
+func TestOIDCNoVinculaCuentasPrivilegiadas(t *testing.T) {
+    s := nuevoEscenarioOIDC(t)
+
+    for _, rol := range []string{policy.RolInstructor, policy.RolAdmin} {
+        email := rol + "@example.com"
+        existente := s.crearUsuario(t, email, rol)
+
+        errorLogin(t, s.login(t, nuevoNavegador(t), email, rol), http.StatusUnauthorized, "inicia sesión con tu contraseña")
+
+        if identidades, _ := s.identidades.GetByUsuario(existente.ID); len(identidades) != 0 {
+            t.Fatalf("la cuenta de %s quedó vinculada al proveedor: %+v", rol, identidades)
+        }
+        if otro, err := s.usuarios.FindByEmail(email); err != nil || otro.ID != existente.ID {
+            t.Fatalf("el login de %s creó otra cuenta con el mismo email", rol)
+        }
+    }
+}
+
+func TestOIDCCallbackRechazaCookieDeOtroNavegador(t *testing.T) {
+    s := nuevoEscenarioOIDC(t)
+    victima, atacante := nuevoNavegador(t), nuevoNavegador(t)
+
+    // El atacante inicia su propio login para tener una cookie de state
+    s.iniciar(t, atacante)
+
+    callback := autorizar(t, victima, s.iniciar(t, victima), "ana@example.com", "Ana")
+
+    resp, err := atacante.client.Get(callback.String())
+    if err != nil {
+        t.Fatalf("GET callback: %v", err)
+    }
+    errorLogin(t, resp, http.StatusBadRequest, "login expirado o inválido")
+
+    // Sin cookie tampoco vale
+    resp, err = nuevoNavegador(t).client.Get(callback.String())
+    if err != nil {
+        t.Fatalf("GET callback: %v", err)
+    }
+    errorLogin(t, resp, http.StatusBadRequest, "login expirado o inválido")
+
+    // El rechazo no consume el state: el navegador que inició el login lo termina
+    resp, err = victima.client.Get(callback.String())
+    if err != nil {
+        t.Fatalf("GET callback: %v", err)
+    }
+    respuestaLogin(t, resp)
+
+    // Y el state ya no sirve una segunda vez
+    resp, err = victima.client.Get(callback.String())
+    if err != nil {
+        t.Fatalf("GET callback: %v", err)
+    }
+    errorLogin(t, resp, http.StatusBadRequest, "login expirado o inválido")
+}
+
+func TestOIDCCallbackRechazaNonceAjeno(t *testing.T) {
+    s := nuevoEscenarioOIDC(t)
+    nav := nuevoNavegador(t)
+
+    // El proveedor firma el id_token con el nonce que recibe en la
+    // autorización; si no es el guardado al iniciar, el token no vale
+    authURL := s.iniciar(t, nav)
+    params := authURL.Query()
+    params.Set("nonce", "nonce-de-otro-login")
+    authURL.RawQuery = params.Encode()
+
+    callback := autorizar(t, nav, authURL, "ana@example.com", "Ana")
+    resp, err := nav.client.Get(callback.String())
+    if err != nil {
+        t.Fatalf("GET callback: %v", err)
+    }
+    errorLogin(t, resp, http.StatusUnauthorized, "nonce incorrecto")
+
+    if _, err := s.usuarios.FindByEmail("ana@example.com"); err == nil {
+        t.Fatal("un id_token con nonce ajeno dio de alta la cuenta")
+    }
+}
+
+func TestOIDCCallbackExigeElVerifierPKCE(t *testing.T) {
+    s := nuevoEscenarioOIDC(t)
+    nav := nuevoNavegador(t)
+
+    authURL := s.iniciar(t, nav)
+    params := authURL.Query()
+    if params.Get("code_challenge_method") != "S256" || params.Get("code_challenge") == "" {
+        t.Fatalf("la autorización no usa PKCE S256: %s", authURL.RawQuery)
+    }
+
+    // Un código emitido para otro challenge no se canjea con nuestro verifier
+    params.Set("code_challenge", oidc.CodeChallenge("verifier-de-otro-login"))
+    authURL.RawQuery = params.Encode()
+
+    callback := autorizar(t, nav, authURL, "ana@example.com", "Ana")
+    resp, err := nav.client.Get(callback.String())
+    if err != nil {
+        t.Fatalf("GET callback: %v", err)
+    }
+    errorLogin(t, resp, http.StatusUnauthorized, "invalid_grant")
+}
+
+func TestOIDCCodigoCanjeDeUnSoloUso(t *testing.T) {
+    t.Setenv("OIDC_FRONTEND_REDIRECT_URL", "http://frontend.test/login/sso")
+    s := nuevoEscenarioOIDC(t)
+    nav := nuevoNavegador(t)
+
+    destino := redireccion(t, s.login(t, nav, "ana@example.com", "Ana"))
+    codigo := destino.Query().Get("codigo")
+    if destino.Host != "frontend.test" || codigo == "" {
+        t.Fatalf("el callback redirigió a %s, se esperaba el frontend con el código", destino)
+    }
+    if strings.Contains(destino.RawQuery, "token") {
+        t.Fatalf("los tokens viajan en la URL: %s", destino)
+    }
+
+    canjear := func() *http.Response {
+        cuerpo, _ := json.Marshal(models.OIDCCanjeRequest{Codigo: codigo})
+        resp, err := nav.client.Post(s.api.URL+"/api/auth/oidc/exchange", "application/json", bytes.NewReader(cuerpo))
+        if err != nil {
+            t.Fatalf("POST exchange: %v", err)
+        }
+        return resp
+    }
+
+    login := respuestaLogin(t, canjear())
+    if login.Usuario.Email != "ana@example.com" {
+        t.Fatalf("el canje entró como %q", login.Usuario.Email)
+    }
+
+    errorLogin(t, canjear(), http.StatusUnauthorized, "ya utilizado")
+
+    // Los errores también vuelven al frontend, sin tokens
+    resp, err := nav.client.Get(s.api.URL + "/api/auth/oidc/mock/callback?state=falso&code=falso")
+    if err != nil {
+        t.Fatalf("GET callback: %v", err)
+    }
+    if destino := redireccion(t, resp); destino.Query().Get("error") == "" || destino.Query().Get("codigo") != "" {
+        t.Fatalf("el error redirigió a %s", destino)
+    }
+}
+
+// fakeIdentidadRepo guarda los logins en curso y las identidades vinculadas
+type fakeIdentidadRepo struct {
+    mu          sync.Mutex
+    usuarios    services.UsuarioRepository
+    estados     map[string]models.EstadoOIDC
+    identidades []models.IdentidadExterna
+}
+
+func newFakeIdentidadRepo(usuarios services.UsuarioRepository) *fakeIdentidadRepo {
+    return &fakeIdentidadRepo{usuarios: usuarios, estados: make(map[string]models.EstadoOIDC)}
+}
+
+func (r *fakeIdentidadRepo) CreateEstado(estado *models.EstadoOIDC) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    r.estados[estado.StateHash] = *estado
+    return nil
+}
+
+func (r *fakeIdentidadRepo) ConsumirEstado(stateHash string) (*models.EstadoOIDC, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    estado, ok := r.estados[stateHash]
+    delete(r.estados, stateHash)
+    if !ok || !estado.ExpiraAt.After(time.Now()) {
+        return nil, errors.New("login expirado o inválido, vuelve a intentarlo")
+    }
+    return &estado, nil
+}
+
+func (r *fakeIdentidadRepo) FindByProveedorSubject(proveedor, subject string) (*models.IdentidadExterna, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    for _, identidad := range r.identidades {
+        if identidad.Proveedor == proveedor && identidad.Subject == subject {
+            return &identidad, nil
+        }
+    }
+    return nil, errors.New("identidad no encontrada")
+}
+
+func (r *fakeIdentidadRepo) GetByUsuario(usuarioID int) ([]models.IdentidadExterna, error) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    var identidades []models.IdentidadExterna
+    for _, identidad := range r.identidades {
+        if identidad.UsuarioID == usuarioID {
+            identidades = append(identidades, identidad)
+        }
+    }
+    return identidades, nil
+}
+
+func (r *fakeIdentidadRepo) Vincular(identidad *models.IdentidadExterna) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    for _, existente := range r.identidades {
+        if existente.Proveedor == identidad.Proveedor && existente.Subject == identidad.Subject {
+            return errors.New("la identidad ya está vinculada")
+        }
+    }
+
+    now := time.Now()
+    identidad.ID = len(r.identidades) + 1
+    identidad.CreatedAt = now
+    identidad.UltimoLoginAt = &now
+    r.identidades = append(r.identidades, *identidad)
+    return nil
+}
+
+func (r *fakeIdentidadRepo) CrearConUsuario(usuario *models.Usuario, identidad *models.IdentidadExterna) error {
+    verificado := usuario.EmailVerificado
+    if err := r.usuarios.Create(usuario); err != nil {
+        return err
+    }
+    if verificado {
+        if err := r.usuarios.MarcarEmailVerificado(usuario.ID); err != nil {
+            return err
+        }
+        usuario.EmailVerificado = true
+    }
+
+    identidad.UsuarioID = usuario.ID
+    return r.Vincular(identidad)
+}
+
+func (r *fakeIdentidadRepo) RegistrarLogin(id int) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    now := time.Now()
+    r.identidades[id-1].UltimoLoginAt = &now
+    return nil
+}
+
+var _ services.IdentidadRepository = (*fakeIdentidadRepo)(nil)
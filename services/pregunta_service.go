@@ -0,0 +1,186 @@
+package services
+
+import (
+    "cursos-api/models"
+    "errors"
+)
+
+// Tipos de pregunta soportados
+const (
+    TipoPreguntaOpcionUnica    = "opcion_unica"
+    TipoPreguntaOpcionMultiple = "opcion_multiple"
+    TipoPreguntaVerdaderoFalso = "verdadero_falso"
+    TipoPreguntaNumerica       = "numerica"
+)
+
+type PreguntaService struct {
+    preguntaRepo      PreguntaRepository
+    evaluacionService *EvaluacionService
+}
+
+func NewPreguntaService(preguntaRepo PreguntaRepository, evaluacionService *EvaluacionService) *PreguntaService {
+    return &PreguntaService{
+        preguntaRepo:      preguntaRepo,
+        evaluacionService: evaluacionService,
+    }
+}
+
+// Create agrega una pregunta a una evaluación de un curso del instructor
+func (s *PreguntaService) Create(cursoID, evaluacionID int, pregunta *models.Pregunta, userID int, userRol string) (*models.Pregunta, error) {
+    if err := validarPregunta(pregunta); err != nil {
+        return nil, err
+    }
+
+    if err := s.verificarEvaluacion(cursoID, evaluacionID, userID, userRol); err != nil {
+        return nil, err
+    }
+
+    pregunta.EvaluacionID = evaluacionID
+
+    err := s.preguntaRepo.Create(pregunta)
+    if err != nil {
+        return nil, err
+    }
+
+    return pregunta, nil
+}
+
+// GetByEvaluacion obtiene las preguntas de una evaluación con sus respuestas correctas
+func (s *PreguntaService) GetByEvaluacion(cursoID, evaluacionID int, userID int, userRol string) ([]models.Pregunta, error) {
+    if err := s.verificarEvaluacion(cursoID, evaluacionID, userID, userRol); err != nil {
+        return nil, err
+    }
+
+    return s.preguntaRepo.GetByEvaluacion(evaluacionID)
+}
+
+// Update actualiza una pregunta de una evaluación del instructor
+func (s *PreguntaService) Update(cursoID, evaluacionID, id int, pregunta *models.Pregunta, userID int, userRol string) (*models.Pregunta, error) {
+    if err := validarPregunta(pregunta); err != nil {
+        return nil, err
+    }
+
+    if err := s.verificarEvaluacion(cursoID, evaluacionID, userID, userRol); err != nil {
+        return nil, err
+    }
+
+    if _, err := s.findInEvaluacion(evaluacionID, id); err != nil {
+        return nil, err
+    }
+
+    err := s.preguntaRepo.Update(id, pregunta)
+    if err != nil {
+        return nil, err
+    }
+
+    return pregunta, nil
+}
+
+// Delete elimina una pregunta de una evaluación del instructor
+func (s *PreguntaService) Delete(cursoID, evaluacionID, id int, userID int, userRol string) error {
+    if err := s.verificarEvaluacion(cursoID, evaluacionID, userID, userRol); err != nil {
+        return err
+    }
+
+    if _, err := s.findInEvaluacion(evaluacionID, id); err != nil {
+        return err
+    }
+
+    return s.preguntaRepo.Delete(id)
+}
+
+// verificarEvaluacion comprueba que el usuario es el instructor del curso y que
+// la evaluación pertenece a ese curso
+func (s *PreguntaService) verificarEvaluacion(cursoID, evaluacionID int, userID int, userRol string) error {
+    if err := s.evaluacionService.verificarInstructor(cursoID, userID, userRol); err != nil {
+        return err
+    }
+
+    _, err := s.evaluacionService.findInCurso(cursoID, evaluacionID)
+    return err
+}
+
+// findInEvaluacion busca una pregunta y verifica que pertenezca a la evaluación
+func (s *PreguntaService) findInEvaluacion(evaluacionID, id int) (*models.Pregunta, error) {
+    pregunta, err := s.preguntaRepo.FindByID(id)
+    if err != nil {
+        return nil, err
+    }
+
+    if pregunta.EvaluacionID != evaluacionID {
+        return nil, errors.New("pregunta no encontrada")
+    }
+
+    return pregunta, nil
+}
+
+// validarPregunta valida una pregunta según su tipo y descarta los campos que
+// no aplican a ese tipo
+func validarPregunta(pregunta *models.Pregunta) error {
+    if pregunta.Enunciado == "" {
+        return errors.New("el enunciado de la pregunta es requerido")
+    }
+
+    if pregunta.Peso == 0 {
+        pregunta.Peso = 1
+    }
+
+    if pregunta.Peso < 0 {
+        return errors.New("el peso de la pregunta debe ser mayor a 0")
+    }
+
+    switch pregunta.Tipo {
+    case TipoPreguntaOpcionUnica, TipoPreguntaOpcionMultiple:
+        if len(pregunta.Opciones) < 2 {
+            return errors.New("la pregunta debe tener al menos 2 opciones")
+        }
+
+        correctas := 0
+        for _, opcion := range pregunta.Opciones {
+            if opcion.Texto == "" {
+                return errors.New("el texto de las opciones es requerido")
+            }
+            if opcion.EsCorrecta {
+                correctas++
+            }
+        }
+
+        if pregunta.Tipo == TipoPreguntaOpcionUnica && correctas != 1 {
+            return errors.New("una pregunta de opción única debe tener exactamente una opción correcta")
+        }
+
+        if correctas == 0 {
+            return errors.New("la pregunta debe tener al menos una opción correcta")
+        }
+
+        pregunta.RespuestaBooleana = nil
+        pregunta.RespuestaNumerica = nil
+        pregunta.Tolerancia = 0
+
+    case TipoPreguntaVerdaderoFalso:
+        if pregunta.RespuestaBooleana == nil {
+            return errors.New("la respuesta_booleana es requerida")
+        }
+
+        pregunta.Opciones = nil
+        pregunta.RespuestaNumerica = nil
+        pregunta.Tolerancia = 0
+
+    case TipoPreguntaNumerica:
+        if pregunta.RespuestaNumerica == nil {
+            return errors.New("la respuesta_numerica es requerida")
+        }
+
+        if pregunta.Tolerancia < 0 {
+            return errors.New("la tolerancia no puede ser negativa")
+        }
+
+        pregunta.Opciones = nil
+        pregunta.RespuestaBooleana = nil
+
+    default:
+        return errors.New("tipo de pregunta inválido, debe ser 'opcion_unica', 'opcion_multiple', 'verdadero_falso' o 'numerica'")
+    }
+
+    return nil
+}
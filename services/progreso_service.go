@@ -0,0 +1,85 @@
+package services
+
+import (
+    "cursos-api/models"
+    "cursos-api/policy"
+    "errors"
+)
+
+type ProgresoService struct {
+    progresoRepo       ProgresoRepository
+    inscripcionRepo    InscripcionRepository
+    leccionRepo        LeccionRepository
+    certificadoService *CertificadoService
+}
+
+func NewProgresoService(
+    progresoRepo ProgresoRepository,
+    inscripcionRepo InscripcionRepository,
+    leccionRepo LeccionRepository,
+    certificadoService *CertificadoService,
+) *ProgresoService {
+    return &ProgresoService{
+        progresoRepo:       progresoRepo,
+        inscripcionRepo:    inscripcionRepo,
+        leccionRepo:        leccionRepo,
+        certificadoService: certificadoService,
+    }
+}
+
+// MarcarLeccion marca una lección como completada o no completada para el
+// alumno inscrito y devuelve la inscripción con el progreso recalculado
+func (s *ProgresoService) MarcarLeccion(cursoID, leccionID int, completada bool, userID int, userRol string) (*models.ProgresoLeccion, *models.Inscripcion, error) {
+    if !policy.Puede(userRol, policy.InscripcionManageOwn) {
+        return nil, nil, errors.New("solo los alumnos pueden registrar su progreso")
+    }
+
+    inscripcion, err := s.inscripcionRepo.FindByUsuarioAndCurso(userID, cursoID)
+    if err != nil {
+        return nil, nil, errors.New("no estás inscrito en este curso")
+    }
+
+    // Solo las inscripciones activas registran progreso; completado y cancelado son finales
+    if inscripcion.Estado != EstadoInscripcionActivo {
+        return nil, nil, errors.New("la inscripción está '" + inscripcion.Estado + "' y no admite cambios de progreso")
+    }
+
+    leccion, err := s.leccionRepo.FindByID(leccionID)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    if leccion.CursoID != cursoID {
+        return nil, nil, errors.New("lección no encontrada")
+    }
+
+    progreso, inscripcion, err := s.progresoRepo.MarcarLeccion(inscripcion.ID, leccionID, completada)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    if inscripcion.Estado == EstadoInscripcionCompletado {
+        s.certificadoService.EmitirSiCorresponde(cursoID, userID)
+    }
+
+    return progreso, inscripcion, nil
+}
+
+// GetProgreso obtiene el progreso por lección de una inscripción del alumno
+func (s *ProgresoService) GetProgreso(inscripcionID int, userID int) (*models.Inscripcion, []models.ProgresoLeccion, error) {
+    inscripcion, err := s.inscripcionRepo.FindByID(inscripcionID)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    if inscripcion.UsuarioID != userID {
+        return nil, nil, errors.New("inscripción no encontrada o no tienes permiso")
+    }
+
+    progresos, err := s.progresoRepo.GetByInscripcion(inscripcionID)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    return inscripcion, progresos, nil
+}
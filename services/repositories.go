@@ -0,0 +1,175 @@
+package services
+
+import (
+    "cursos-api/models"
+    "time"
+)
+
+// Interfaces de los repositorios que usan los servicios. Las implementaciones
+// de Postgres están en el paquete repository; los servicios solo dependen de
+// estos contratos para poder sustituirlas.
+
+type UsuarioRepository interface {
+    Create(usuario *models.Usuario) error
+    FindByEmail(email string) (*models.Usuario, error)
+    FindByID(id int) (*models.Usuario, error)
+    GetAll() ([]models.Usuario, error)
+    Update(id int, usuario *models.Usuario) error
+    Delete(id int) error
+    UpdatePassword(id int, newPasswordHash string) error
+    Search(filtro models.UsuarioFiltro) ([]models.Usuario, error)
+    SetActivo(id int, activo bool) error
+    MarcarEmailVerificado(id int) error
+    RegistrarLoginFallido(id int, ventana, decaimiento time.Duration) (intentos int, bloqueos int, err error)
+    BloquearLogin(id int, hasta time.Time, bloqueos int) error
+    ResetLoginFallidos(id int) error
+    FindEliminado(id int) (*models.Usuario, error)
+    Restore(id int) error
+    Purgar(antes time.Time) (int64, error)
+}
+
+type CursoRepository interface {
+    Create(curso *models.Curso) error
+    FindByID(id int) (*models.Curso, error)
+    GetAll() ([]models.Curso, error)
+    GetByInstructor(instructorID int) ([]models.Curso, error)
+    GetActivos() ([]models.Curso, error)
+    List(filtro models.CursoFiltro) (*models.PaginaCursos, error)
+    Search(busqueda models.CursoBusqueda) (*models.PaginaBusquedaCursos, error)
+    Update(id int, curso *models.Curso) error
+    Delete(id int) error
+    VerifyInstructor(cursoID, instructorID int) (bool, error)
+    UpdateInstructor(id, instructorID int) error
+    SetBloqueo(id int, bloqueado bool, motivo string) error
+    CambiarEstado(cambio *models.CambioEstadoCurso) error
+    Programar(id int, publicarAt, despublicarAt *time.Time) error
+    GetProgramados(hasta time.Time) ([]models.Curso, error)
+    GetHistorial(cursoID int) ([]models.CambioEstadoCurso, error)
+    FindEliminado(id int) (*models.Curso, error)
+    Restore(id int) error
+    Purgar(antes time.Time) (int64, error)
+}
+
+type InscripcionRepository interface {
+    Create(inscripcion *models.Inscripcion) error
+    FindByID(id int) (*models.Inscripcion, error)
+    FindByUsuarioAndCurso(usuarioID, cursoID int) (*models.Inscripcion, error)
+    GetByUsuario(usuarioID int) ([]models.Inscripcion, error)
+    GetByCurso(cursoID int) ([]models.Inscripcion, error)
+    Reactivar(inscripcion *models.Inscripcion) error
+    UpdateEstado(id int, estado string) error
+}
+
+type LeccionRepository interface {
+    Create(leccion *models.Leccion) error
+    FindByID(id int) (*models.Leccion, error)
+    GetByCurso(cursoID int) ([]models.Leccion, error)
+    Update(id int, leccion *models.Leccion) error
+    Delete(id int) error
+    Reorder(cursoID int, leccionIDs []int) error
+}
+
+type ProgresoRepository interface {
+    MarcarLeccion(inscripcionID, leccionID int, completada bool) (*models.ProgresoLeccion, *models.Inscripcion, error)
+    GetByInscripcion(inscripcionID int) ([]models.ProgresoLeccion, error)
+}
+
+type EvaluacionRepository interface {
+    Create(evaluacion *models.Evaluacion) error
+    FindByID(id int) (*models.Evaluacion, error)
+    GetByCurso(cursoID int) ([]models.Evaluacion, error)
+    Update(id int, evaluacion *models.Evaluacion) error
+    Delete(id int) error
+    CountRequeridasPendientes(cursoID, usuarioID int) (int, error)
+}
+
+type ResultadoRepository interface {
+    Create(resultado *models.ResultadoEvaluacion) error
+    CountByUsuarioAndEvaluacion(usuarioID, evaluacionID int) (int, error)
+    GetByCurso(cursoID int) ([]models.ResultadoEvaluacion, error)
+    GetByUsuarioAndCurso(usuarioID, cursoID int) ([]models.ResultadoEvaluacion, error)
+    GetByUsuario(usuarioID int) ([]models.ResultadoEvaluacion, error)
+}
+
+type PreguntaRepository interface {
+    Create(pregunta *models.Pregunta) error
+    FindByID(id int) (*models.Pregunta, error)
+    GetByEvaluacion(evaluacionID int) ([]models.Pregunta, error)
+    CountByEvaluacion(evaluacionID int) (int, error)
+    Update(id int, pregunta *models.Pregunta) error
+    Delete(id int) error
+}
+
+type IntentoRepository interface {
+    Create(intento *models.IntentoEvaluacion, maxIntentos int) error
+    FindByID(id int) (*models.IntentoEvaluacion, error)
+    FindAbierto(usuarioID, evaluacionID int) (*models.IntentoEvaluacion, error)
+    GetByUsuarioAndEvaluacion(usuarioID, evaluacionID int) ([]models.IntentoEvaluacion, error)
+    Finalizar(intento *models.IntentoEvaluacion, resultado *models.ResultadoEvaluacion) error
+}
+
+type CertificadoRepository interface {
+    Create(certificado *models.Certificado) error
+    FindByInscripcion(inscripcionID int) (*models.Certificado, error)
+    FindDetalleByID(id int) (*models.Certificado, error)
+    UpdateURLPDF(id int, url string) error
+    FindVerificacionByCodigo(codigo string) (*models.CertificadoVerificacion, error)
+    GetByUsuario(usuarioID int) ([]models.Certificado, error)
+}
+
+type SesionRepository interface {
+    Create(sesion *models.Sesion, token *models.RefreshToken) error
+    FindRefreshToken(tokenHash string) (*models.RefreshToken, error)
+    Rotar(tokenID int, nuevo *models.RefreshToken) (bool, error)
+    Revocar(sesionID, motivo string) error
+    RevocarPorUsuario(usuarioID int, motivo string) error
+    IsActiva(sesionID string) (bool, error)
+}
+
+type SolicitudInstructorRepository interface {
+    Create(solicitud *models.SolicitudInstructor) error
+    FindByID(id int) (*models.SolicitudInstructor, error)
+    HasPendiente(usuarioID int) (bool, error)
+    GetByUsuario(usuarioID int) ([]models.SolicitudInstructor, error)
+    GetByEstado(estado string) ([]models.SolicitudInstructor, error)
+    Resolver(solicitud *models.SolicitudInstructor, nuevoRol string) error
+}
+
+type TokenUsuarioRepository interface {
+    Create(token *models.TokenUsuario) error
+    Consumir(tipo, tokenHash string) (*models.TokenUsuario, error)
+    InvalidarPorUsuario(usuarioID int, tipo string) error
+}
+
+type MFARepository interface {
+    GetConfigTOTP(usuarioID int) (*models.ConfigTOTP, error)
+    IniciarTOTP(usuarioID int, secret string) error
+    HabilitarTOTP(usuarioID int, paso int64, codigoHashes []string) error
+    DeshabilitarTOTP(usuarioID int) error
+    RegistrarPasoTOTP(usuarioID int, paso int64) (bool, error)
+    ReemplazarCodigosRecuperacion(usuarioID int, codigoHashes []string) error
+    ConsumirCodigoRecuperacion(usuarioID int, codigoHash string) (bool, error)
+    ContarCodigosRecuperacion(usuarioID int) (int, error)
+}
+
+type IdentidadRepository interface {
+    CreateEstado(estado *models.EstadoOIDC) error
+    ConsumirEstado(stateHash string) (*models.EstadoOIDC, error)
+    FindByProveedorSubject(proveedor, subject string) (*models.IdentidadExterna, error)
+    GetByUsuario(usuarioID int) ([]models.IdentidadExterna, error)
+    Vincular(identidad *models.IdentidadExterna) error
+    CrearConUsuario(usuario *models.Usuario, identidad *models.IdentidadExterna) error
+    RegistrarLogin(id int) error
+}
+
+type CategoriaRepository interface {
+    GetAll() ([]models.Categoria, error)
+    FindByID(id int) (*models.Categoria, error)
+    FindBySlug(slug string) (*models.Categoria, error)
+    Create(categoria *models.Categoria) error
+    Update(id int, categoria *models.Categoria) error
+    Delete(id int) error
+    AsignarCategorias(cursoID int, categoriaIDs []int) error
+    AsignarEtiquetas(cursoID int, nombres []string) error
+    GetEtiquetas() ([]models.Etiqueta, error)
+}
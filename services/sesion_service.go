@@ -0,0 +1,169 @@
+package services
+
+import (
+    "cursos-api/models"
+    "cursos-api/utils"
+    "errors"
+    "log"
+    "time"
+)
+
+// Motivos de revocación de una sesión
+const (
+    MotivoLogout         = "logout"
+    MotivoCambioPassword = "cambio_password"
+    MotivoReutilizacion  = "reutilizacion_refresh_token"
+    MotivoDeshabilitado  = "usuario_deshabilitado"
+    MotivoResetPassword  = "reset_password"
+    MotivoEliminado      = "usuario_eliminado"
+)
+
+// Bytes aleatorios de los identificadores de sesión y de los refresh tokens
+const (
+    bytesSesionID     = 24
+    bytesRefreshToken = 32
+)
+
+type SesionService struct {
+    sesionRepo  SesionRepository
+    usuarioRepo UsuarioRepository
+}
+
+func NewSesionService(sesionRepo SesionRepository, usuarioRepo UsuarioRepository) *SesionService {
+    return &SesionService{
+        sesionRepo:  sesionRepo,
+        usuarioRepo: usuarioRepo,
+    }
+}
+
+// refreshTokenTTL es la vida de cada refresh token (REFRESH_TOKEN_TTL, 30 días por defecto)
+func refreshTokenTTL() time.Duration {
+    return utils.GetEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+}
+
+// Crear abre una sesión nueva para el usuario y emite su primer par de tokens
+func (s *SesionService) Crear(usuario *models.Usuario) (*models.TokenPair, error) {
+    sesionID, err := utils.GenerateRandomToken(bytesSesionID)
+    if err != nil {
+        return nil, errors.New("error al crear la sesión")
+    }
+
+    refreshToken, token, err := nuevoRefreshToken(sesionID)
+    if err != nil {
+        return nil, err
+    }
+
+    sesion := &models.Sesion{ID: sesionID, UsuarioID: usuario.ID}
+    if err := s.sesionRepo.Create(sesion, token); err != nil {
+        return nil, errors.New("error al crear la sesión")
+    }
+
+    return emitirTokens(usuario, sesionID, refreshToken)
+}
+
+// Refresh canjea un refresh token por un par nuevo. Presentar un token ya
+// usado se considera robo: se revoca la sesión completa.
+func (s *SesionService) Refresh(refreshToken string) (*models.TokenPair, error) {
+    if refreshToken == "" {
+        return nil, errors.New("refresh token requerido")
+    }
+
+    actual, err := s.sesionRepo.FindRefreshToken(utils.HashToken(refreshToken))
+    if err != nil {
+        return nil, errors.New("refresh token inválido")
+    }
+
+    if actual.Sesion.RevocadaAt != nil {
+        return nil, errors.New("la sesión fue revocada")
+    }
+
+    if actual.UsadoAt != nil {
+        return nil, s.revocarPorReutilizacion(actual.SesionID)
+    }
+
+    if time.Now().After(actual.ExpiraAt) {
+        return nil, errors.New("refresh token expirado")
+    }
+
+    nuevoToken, nuevo, err := nuevoRefreshToken(actual.SesionID)
+    if err != nil {
+        return nil, err
+    }
+
+    rotado, err := s.sesionRepo.Rotar(actual.ID, nuevo)
+    if err != nil {
+        return nil, errors.New("error al renovar la sesión")
+    }
+    if !rotado {
+        // Otro canje del mismo token ganó la carrera
+        return nil, s.revocarPorReutilizacion(actual.SesionID)
+    }
+
+    usuario, err := s.usuarioRepo.FindByID(actual.Sesion.UsuarioID)
+    if err != nil {
+        return nil, errors.New("usuario no encontrado")
+    }
+
+    if !usuario.Activo {
+        return nil, errors.New("la cuenta está deshabilitada")
+    }
+
+    return emitirTokens(usuario, actual.SesionID, nuevoToken)
+}
+
+// Logout revoca la sesión indicada
+func (s *SesionService) Logout(sesionID string) error {
+    return s.sesionRepo.Revocar(sesionID, MotivoLogout)
+}
+
+// RevocarTodas revoca todas las sesiones abiertas de un usuario
+func (s *SesionService) RevocarTodas(usuarioID int, motivo string) error {
+    return s.sesionRepo.RevocarPorUsuario(usuarioID, motivo)
+}
+
+// SesionActiva indica si la sesión citada por un token de acceso sigue vigente
+func (s *SesionService) SesionActiva(sesionID string) (bool, error) {
+    if sesionID == "" {
+        return false, nil
+    }
+    return s.sesionRepo.IsActiva(sesionID)
+}
+
+// revocarPorReutilizacion revoca la familia de un token reutilizado
+func (s *SesionService) revocarPorReutilizacion(sesionID string) error {
+    log.Printf("⚠️  Refresh token reutilizado en la sesión %s, revocando la sesión\n", sesionID)
+
+    if err := s.sesionRepo.Revocar(sesionID, MotivoReutilizacion); err != nil {
+        log.Printf("Error al revocar la sesión %s: %v\n", sesionID, err)
+    }
+
+    return errors.New("refresh token reutilizado, la sesión fue revocada")
+}
+
+// nuevoRefreshToken genera un refresh token opaco y su registro con hash
+func nuevoRefreshToken(sesionID string) (string, *models.RefreshToken, error) {
+    token, err := utils.GenerateRandomToken(bytesRefreshToken)
+    if err != nil {
+        return "", nil, errors.New("error al generar el refresh token")
+    }
+
+    return token, &models.RefreshToken{
+        SesionID:  sesionID,
+        TokenHash: utils.HashToken(token),
+        ExpiraAt:  time.Now().Add(refreshTokenTTL()),
+    }, nil
+}
+
+// emitirTokens firma el token de acceso y lo empaqueta con el refresh token
+func emitirTokens(usuario *models.Usuario, sesionID, refreshToken string) (*models.TokenPair, error) {
+    token, err := utils.GenerateJWT(usuario.ID, usuario.Email, usuario.Rol, sesionID)
+    if err != nil {
+        return nil, errors.New("error al generar el token")
+    }
+
+    return &models.TokenPair{
+        Token:        token,
+        RefreshToken: refreshToken,
+        ExpiresIn:    int(utils.AccessTokenTTL().Seconds()),
+    }, nil
+}
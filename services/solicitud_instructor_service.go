@@ -0,0 +1,125 @@
+package services
+
+import (
+    "cursos-api/models"
+    "cursos-api/policy"
+    "errors"
+    "strings"
+)
+
+// Estados posibles de una solicitud de instructor
+const (
+    EstadoSolicitudPendiente = "pendiente"
+    EstadoSolicitudAprobada  = "aprobada"
+    EstadoSolicitudRechazada = "rechazada"
+)
+
+type SolicitudInstructorService struct {
+    solicitudRepo SolicitudInstructorRepository
+    usuarioRepo   UsuarioRepository
+}
+
+func NewSolicitudInstructorService(solicitudRepo SolicitudInstructorRepository, usuarioRepo UsuarioRepository) *SolicitudInstructorService {
+    return &SolicitudInstructorService{
+        solicitudRepo: solicitudRepo,
+        usuarioRepo:   usuarioRepo,
+    }
+}
+
+// Solicitar registra la petición del usuario autenticado para ser instructor
+func (s *SolicitudInstructorService) Solicitar(motivo string, userID int, userRol string) (*models.SolicitudInstructor, error) {
+    if !policy.Puede(userRol, policy.InstructorApply) {
+        return nil, errors.New("tu cuenta no puede solicitar ser instructor")
+    }
+
+    motivo = strings.TrimSpace(motivo)
+    if motivo == "" {
+        return nil, errors.New("el motivo de la solicitud es requerido")
+    }
+
+    pendiente, err := s.solicitudRepo.HasPendiente(userID)
+    if err != nil {
+        return nil, err
+    }
+
+    if pendiente {
+        return nil, errors.New("ya tienes una solicitud pendiente")
+    }
+
+    solicitud := &models.SolicitudInstructor{
+        UsuarioID: userID,
+        Motivo:    motivo,
+    }
+
+    if err := s.solicitudRepo.Create(solicitud); err != nil {
+        return nil, err
+    }
+
+    return solicitud, nil
+}
+
+// GetMisSolicitudes obtiene las solicitudes del usuario autenticado
+func (s *SolicitudInstructorService) GetMisSolicitudes(userID int) ([]models.SolicitudInstructor, error) {
+    return s.solicitudRepo.GetByUsuario(userID)
+}
+
+// GetByEstado lista las solicitudes en un estado (pendientes por defecto)
+func (s *SolicitudInstructorService) GetByEstado(estado string, userRol string) ([]models.SolicitudInstructor, error) {
+    if !policy.Puede(userRol, policy.InstructorReview) {
+        return nil, errors.New("solo los administradores pueden revisar solicitudes")
+    }
+
+    if estado == "" {
+        estado = EstadoSolicitudPendiente
+    }
+
+    if estado != EstadoSolicitudPendiente && estado != EstadoSolicitudAprobada && estado != EstadoSolicitudRechazada {
+        return nil, errors.New("estado inválido")
+    }
+
+    return s.solicitudRepo.GetByEstado(estado)
+}
+
+// Resolver aprueba o rechaza una solicitud pendiente. Al aprobarla el
+// solicitante pasa a ser instructor; el nuevo rol entra en sus tokens en el
+// siguiente login o renovación de sesión.
+func (s *SolicitudInstructorService) Resolver(id int, aprobar bool, comentario string, userID int, userRol string) (*models.SolicitudInstructor, error) {
+    if !policy.Puede(userRol, policy.InstructorReview) {
+        return nil, errors.New("solo los administradores pueden revisar solicitudes")
+    }
+
+    solicitud, err := s.solicitudRepo.FindByID(id)
+    if err != nil {
+        return nil, err
+    }
+
+    if solicitud.Estado != EstadoSolicitudPendiente {
+        return nil, errors.New("la solicitud ya fue revisada")
+    }
+
+    nuevoRol := ""
+    solicitud.Estado = EstadoSolicitudRechazada
+    if aprobar {
+        // El solicitante pudo cambiar de rol o ser deshabilitado desde que la envió
+        usuario, err := s.usuarioRepo.FindByID(solicitud.UsuarioID)
+        if err != nil {
+            return nil, err
+        }
+
+        if !policy.Puede(usuario.Rol, policy.InstructorApply) || !usuario.Activo {
+            return nil, errors.New("el solicitante ya no puede pasar a ser instructor")
+        }
+
+        nuevoRol = policy.RolInstructor
+        solicitud.Estado = EstadoSolicitudAprobada
+    }
+
+    solicitud.RevisadoPor = &userID
+    solicitud.ComentarioRevision = strings.TrimSpace(comentario)
+
+    if err := s.solicitudRepo.Resolver(solicitud, nuevoRol); err != nil {
+        return nil, err
+    }
+
+    return solicitud, nil
+}
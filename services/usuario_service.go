@@ -2,18 +2,23 @@ package services
 
 import (
     "cursos-api/models"
-    "cursos-api/repository"
     "cursos-api/utils"
     "errors"
+    "log"
+    "strings"
 )
 
 type UsuarioService struct {
-    usuarioRepo *repository.UsuarioRepository
+    usuarioRepo   UsuarioRepository
+    sesionService *SesionService
+    cuentaService *CuentaService
 }
 
-func NewUsuarioService() *UsuarioService {
+func NewUsuarioService(usuarioRepo UsuarioRepository, sesionService *SesionService, cuentaService *CuentaService) *UsuarioService {
     return &UsuarioService{
-        usuarioRepo: repository.NewUsuarioRepository(),
+        usuarioRepo:   usuarioRepo,
+        sesionService: sesionService,
+        cuentaService: cuentaService,
     }
 }
 
@@ -46,26 +51,35 @@ func (s *UsuarioService) GetByID(id int) (*models.Usuario, error) {
 // Update actualiza un usuario
 func (s *UsuarioService) Update(id int, usuario *models.Usuario) (*models.Usuario, error) {
     // Validaciones
+    usuario.Email = normalizarEmail(usuario.Email)
     if usuario.Nombre == "" || usuario.Email == "" {
         return nil, errors.New("nombre y email son requeridos")
     }
 
-    if usuario.Rol != "instructor" && usuario.Rol != "alumno" {
-        return nil, errors.New("rol inválido")
-    }
-
     // Verificar que el usuario existe
     existing, err := s.usuarioRepo.FindByID(id)
     if err != nil {
         return nil, err
     }
 
-    // Verificar si el email cambió y si ya existe
-    if usuario.Email != existing.Email {
+    // El rol no se cambia desde el perfil: solo mediante una solicitud de
+    // instructor aprobada por un admin
+    usuario.Rol = existing.Rol
+
+    // Verificar si el email cambió y si ya existe. Pasar a minúsculas un
+    // email guardado con mayúsculas no es un cambio.
+    emailCambiado := !strings.EqualFold(usuario.Email, existing.Email)
+    if emailCambiado {
         emailExists, _ := s.usuarioRepo.FindByEmail(usuario.Email)
         if emailExists != nil {
             return nil, errors.New("el email ya está registrado")
         }
+
+        // Los enlaces ya enviados al email anterior no deben servir después
+        // del cambio
+        if err := s.cuentaService.AnularEnlaces(id); err != nil {
+            return nil, err
+        }
     }
 
     // Actualizar
@@ -74,13 +88,26 @@ func (s *UsuarioService) Update(id int, usuario *models.Usuario) (*models.Usuari
         return nil, err
     }
 
+    // Un email nuevo debe volver a verificarse
+    if emailCambiado {
+        if err := s.cuentaService.EnviarVerificacion(usuario); err != nil {
+            log.Printf("⚠️  No se pudo enviar la verificación de email (usuario %d): %v\n", id, err)
+        }
+    }
+
     usuario.PasswordHash = ""
     return usuario, nil
 }
 
-// Delete elimina un usuario
+// Delete elimina lógicamente un usuario y revoca sus sesiones, por lo que
+// los tokens emitidos dejan de ser aceptados. El borrado no es en cascada:
+// las sesiones se revocan aquí de forma explícita.
 func (s *UsuarioService) Delete(id int) error {
-    return s.usuarioRepo.Delete(id)
+    if err := s.usuarioRepo.Delete(id); err != nil {
+        return err
+    }
+
+    return s.sesionService.RevocarTodas(id, MotivoEliminado)
 }
 
 // ChangePassword cambia la contraseña de un usuario
@@ -112,5 +139,10 @@ func (s *UsuarioService) ChangePassword(id int, oldPassword, newPassword string)
     }
 
     // Actualizar
-    return s.usuarioRepo.UpdatePassword(id, newHash)
+    if err := s.usuarioRepo.UpdatePassword(id, newHash); err != nil {
+        return err
+    }
+
+    // Cerrar todas las sesiones: los tokens emitidos con la contraseña anterior dejan de valer
+    return s.sesionService.RevocarTodas(id, MotivoCambioPassword)
 }
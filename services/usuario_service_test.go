@@ -0,0 +1,115 @@
+package services_test
+
+import (
+    "cursos-api/models"
+    "cursos-api/policy"
+    "cursos-api/services"
+    "net/url"
+    "regexp"
+    "testing"
+)
+
+func TestUsuarioServiceUpdateRechazaEmailEnUso(t *testing.T) {
+    e := nuevoEntorno(t)
+    e.crearUsuario(t, "ana@example.com", policy.RolAlumno)
+    luis := e.crearUsuario(t, "luis@example.com", policy.RolAlumno)
+
+    if _, err := e.usuario.Update(luis.ID, &models.Usuario{Nombre: "Luis", Email: "ana@example.com"}); err == nil {
+        t.Fatal("Update aceptó el email de otro usuario")
+    }
+    if _, err := e.usuario.Update(luis.ID, &models.Usuario{Nombre: "Luis", Email: "ANA@example.com"}); err == nil {
+        t.Fatal("Update aceptó el email de otro usuario escrito con mayúsculas")
+    }
+
+    // Cambiar solo las mayúsculas no es un email nuevo
+    mismo, err := e.usuario.Update(luis.ID, &models.Usuario{Nombre: "Luis", Email: "Luis@Example.com"})
+    if err != nil {
+        t.Fatalf("Update con el mismo email en mayúsculas: %v", err)
+    }
+    if mismo.Email != "luis@example.com" || len(e.mailer.enviados) != 0 {
+        t.Fatalf("Update guardó %q y envió %d correos", mismo.Email, len(e.mailer.enviados))
+    }
+
+    // Conservar el propio email no es un conflicto
+    if _, err := e.usuario.Update(luis.ID, &models.Usuario{Nombre: "Luis Pérez", Email: "luis@example.com"}); err != nil {
+        t.Fatalf("Update con el mismo email: %v", err)
+    }
+
+    actualizado, err := e.usuario.Update(luis.ID, &models.Usuario{Nombre: "Luis", Email: "luis.perez@example.com"})
+    if err != nil {
+        t.Fatalf("Update con un email libre: %v", err)
+    }
+    if actualizado.PasswordHash != "" {
+        t.Fatal("Update devolvió el hash de la contraseña")
+    }
+    if len(e.mailer.enviados) != 1 || e.mailer.enviados[0].To != "luis.perez@example.com" {
+        t.Fatalf("cambiar el email no envió una verificación al nuevo: %+v", e.mailer.enviados)
+    }
+}
+
+func TestUsuarioServiceUpdateAnulaEnlacesDelEmailAnterior(t *testing.T) {
+    e := nuevoEntorno(t)
+    luis := e.crearUsuario(t, "luis@example.com", policy.RolAlumno)
+
+    e.cuenta.SolicitarResetPassword("luis@example.com")
+    if len(e.mailer.enviados) != 1 {
+        t.Fatalf("se enviaron %d correos, se esperaba el de restablecimiento", len(e.mailer.enviados))
+    }
+    token := regexp.MustCompile(`\?token=(\S+)`).FindStringSubmatch(e.mailer.enviados[0].Body)
+    if token == nil {
+        t.Fatal("el correo no contiene el enlace de restablecimiento")
+    }
+    valor, _ := url.QueryUnescape(token[1])
+
+    if _, err := e.usuario.Update(luis.ID, &models.Usuario{Nombre: "Luis", Email: "luis.perez@example.com"}); err != nil {
+        t.Fatalf("Update: %v", err)
+    }
+
+    if err := e.cuenta.ResetPassword(&models.ResetPasswordRequest{Token: valor, NewPassword: "otra-clave"}); err == nil {
+        t.Fatal("el enlace enviado al email anterior sigue sirviendo")
+    }
+    if actualizado, _ := e.usuarios.FindByID(luis.ID); actualizado.EmailVerificado {
+        t.Fatal("el email nuevo quedó verificado sin consumir su enlace")
+    }
+}
+
+func TestUsuarioServiceUpdateNoCambiaElRol(t *testing.T) {
+    e := nuevoEntorno(t)
+    ana := e.crearUsuario(t, "ana@example.com", policy.RolAlumno)
+
+    actualizado, err := e.usuario.Update(ana.ID, &models.Usuario{Nombre: "Ana", Email: ana.Email, Rol: policy.RolAdmin})
+    if err != nil {
+        t.Fatalf("Update: %v", err)
+    }
+    if actualizado.Rol != policy.RolAlumno {
+        t.Fatalf("Update devolvió el rol %q", actualizado.Rol)
+    }
+
+    guardado, err := e.usuarios.FindByID(ana.ID)
+    if err != nil {
+        t.Fatalf("FindByID: %v", err)
+    }
+    if guardado.Rol != policy.RolAlumno {
+        t.Fatalf("el perfil permitió cambiar el rol a %q", guardado.Rol)
+    }
+}
+
+func TestUsuarioServiceDeleteRevocaSesiones(t *testing.T) {
+    e := nuevoEntorno(t)
+    ana := e.crearUsuario(t, "ana@example.com", policy.RolAlumno)
+
+    if _, err := e.auth.Login(&models.LoginRequest{Email: ana.Email, Password: passwordPruebas}, "10.0.0.1"); err != nil {
+        t.Fatalf("Login: %v", err)
+    }
+
+    if err := e.usuario.Delete(ana.ID); err != nil {
+        t.Fatalf("Delete: %v", err)
+    }
+
+    if motivo := e.sesiones.revocadas[ana.ID]; motivo != services.MotivoEliminado {
+        t.Fatalf("las sesiones se revocaron con motivo %q, se esperaba %q", motivo, services.MotivoEliminado)
+    }
+    if _, err := e.usuarios.FindByID(ana.ID); err == nil {
+        t.Fatal("el usuario eliminado sigue visible")
+    }
+}
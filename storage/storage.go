@@ -0,0 +1,109 @@
+package storage
+
+import (
+    "errors"
+    "io"
+    "os"
+    "path/filepath"
+    "strings"
+)
+
+// ErrNotFound indica que el objeto solicitado no existe en el almacenamiento
+var ErrNotFound = errors.New("archivo no encontrado")
+
+// BlobStore abstrae el almacenamiento de archivos generados (PDFs, etc.) para
+// poder sustituir el disco local por otro backend sin tocar los servicios
+type BlobStore interface {
+    Put(key string, data []byte, contentType string) error
+    Get(key string) (io.ReadCloser, error)
+    Delete(key string) error
+}
+
+// NewFromEnv crea el almacenamiento configurado por variables de entorno.
+// Por ahora solo existe el backend local; STORAGE_LOCAL_DIR define su
+// directorio base (./storage_data por defecto).
+func NewFromEnv() BlobStore {
+    dir := os.Getenv("STORAGE_LOCAL_DIR")
+    if dir == "" {
+        dir = "./storage_data"
+    }
+
+    return NewLocalStore(dir)
+}
+
+// LocalStore guarda los archivos en un directorio del disco local
+type LocalStore struct {
+    dir string
+}
+
+func NewLocalStore(dir string) *LocalStore {
+    return &LocalStore{dir: dir}
+}
+
+// Put guarda un archivo de forma atómica (escritura temporal + rename)
+func (s *LocalStore) Put(key string, data []byte, contentType string) error {
+    path, err := s.path(key)
+    if err != nil {
+        return err
+    }
+
+    if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+        return err
+    }
+
+    tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+    if err != nil {
+        return err
+    }
+    defer os.Remove(tmp.Name())
+
+    if _, err := tmp.Write(data); err != nil {
+        tmp.Close()
+        return err
+    }
+    if err := tmp.Close(); err != nil {
+        return err
+    }
+
+    return os.Rename(tmp.Name(), path)
+}
+
+// Get abre un archivo guardado
+func (s *LocalStore) Get(key string) (io.ReadCloser, error) {
+    path, err := s.path(key)
+    if err != nil {
+        return nil, err
+    }
+
+    file, err := os.Open(path)
+    if os.IsNotExist(err) {
+        return nil, ErrNotFound
+    }
+
+    return file, err
+}
+
+// Delete elimina un archivo guardado
+func (s *LocalStore) Delete(key string) error {
+    path, err := s.path(key)
+    if err != nil {
+        return err
+    }
+
+    err = os.Remove(path)
+    if os.IsNotExist(err) {
+        return ErrNotFound
+    }
+
+    return err
+}
+
+// path resuelve la ruta de una clave impidiendo salir del directorio base
+func (s *LocalStore) path(key string) (string, error) {
+    clean := filepath.Clean("/" + key)
+    if clean == "/" || strings.Contains(key, "\x00") {
+        return "", errors.New("clave de archivo inválida")
+    }
+
+    return filepath.Join(s.dir, clean), nil
+}
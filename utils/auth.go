@@ -1,8 +1,10 @@
 package utils
 
 import (
+    "crypto/sha256"
+    "encoding/hex"
     "errors"
-    "os"
+    "strconv"
     "time"
 
     "github.com/golang-jwt/jwt/v5"
@@ -10,12 +12,18 @@ import (
 )
 
 type Claims struct {
-    UserID int    `json:"user_id"`
-    Email  string `json:"email"`
-    Rol    string `json:"rol"`
+    UserID   int    `json:"user_id"`
+    Email    string `json:"email"`
+    Rol      string `json:"rol"`
+    SesionID string `json:"sid"`
     jwt.RegisteredClaims
 }
 
+// AccessTokenTTL es la vida de los tokens de acceso (ACCESS_TOKEN_TTL, 15m por defecto)
+func AccessTokenTTL() time.Duration {
+    return GetEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute)
+}
+
 // HashPassword genera un hash de la contraseña
 func HashPassword(password string) (string, error) {
     bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -28,37 +36,166 @@ func CheckPasswordHash(password, hash string) bool {
     return err == nil
 }
 
-// GenerateJWT genera un token JWT
-func GenerateJWT(userID int, email, rol string) (string, error) {
+// jwtConfig es la configuración de los JWT, cargada una vez al arrancar (InitJWT)
+var jwtConfig struct {
+    keys     *KeyRing
+    issuer   string
+    audience string
+}
+
+// InitJWT carga el llavero de claves de firma (JWT_KEYS_DIR) y el emisor
+// (JWT_ISSUER) y la audiencia (JWT_AUDIENCE) de los tokens. Devuelve error si
+// no hay ninguna clave activa: la API no debe arrancar sin poder firmar.
+func InitJWT() error {
+    keys, err := LoadKeyRing(
+        GetEnv("JWT_KEYS_DIR", "./keys"),
+        GetEnvDuration("JWT_KEY_OVERLAP", 24*time.Hour),
+        GetEnvDuration("JWT_KEYS_RELOAD", 5*time.Minute),
+    )
+    if err != nil {
+        return err
+    }
+
+    jwtConfig.keys = keys
+    jwtConfig.issuer = GetEnv("JWT_ISSUER", "cursos-api")
+    jwtConfig.audience = GetEnv("JWT_AUDIENCE", "cursos-api")
+    return nil
+}
+
+// JWTKeyRing devuelve el llavero cargado por InitJWT
+func JWTKeyRing() *KeyRing {
+    return jwtConfig.keys
+}
+
+// GenerateJWT genera un token de acceso JWT de corta duración ligado a una sesión
+func GenerateJWT(userID int, email, rol, sesionID string) (string, error) {
     claims := &Claims{
-        UserID: userID,
-        Email:  email,
-        Rol:    rol,
+        UserID:   userID,
+        Email:    email,
+        Rol:      rol,
+        SesionID: sesionID,
         RegisteredClaims: jwt.RegisteredClaims{
-            ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+            Issuer:    jwtConfig.issuer,
+            Audience:  jwt.ClaimStrings{jwtConfig.audience},
+            ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL())),
             IssuedAt:  jwt.NewNumericDate(time.Now()),
         },
     }
 
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-    return token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+    return firmarJWT(claims)
 }
 
-// ValidateJWT valida un token JWT
+// ValidateJWT valida un token de acceso: firma, algoritmo, emisor, audiencia
+// y caducidad
 func ValidateJWT(tokenString string) (*Claims, error) {
     claims := &Claims{}
 
+    if err := verificarJWT(tokenString, claims, jwtConfig.audience); err != nil {
+        return nil, err
+    }
+
+    // Los tokens de acceso siempre van ligados a una sesión
+    if claims.SesionID == "" {
+        return nil, errors.New("token inválido")
+    }
+
+    return claims, nil
+}
+
+// audienciaMFA es la audiencia de los tokens de desafío MFA, distinta de la
+// de los tokens de acceso para que no sirvan como tales
+func audienciaMFA() string {
+    return jwtConfig.audience + ":mfa"
+}
+
+// MFAChallengeTTL es la vida del token de desafío MFA (MFA_CHALLENGE_TTL, 5m por defecto)
+func MFAChallengeTTL() time.Duration {
+    return GetEnvDuration("MFA_CHALLENGE_TTL", 5*time.Minute)
+}
+
+// GenerateMFAToken genera el token de desafío que se entrega tras validar la
+// contraseña de un usuario con 2FA. Solo sirve para completar el login.
+func GenerateMFAToken(userID int) (string, error) {
+    claims := &jwt.RegisteredClaims{
+        Issuer:    jwtConfig.issuer,
+        Subject:   strconv.Itoa(userID),
+        Audience:  jwt.ClaimStrings{audienciaMFA()},
+        ExpiresAt: jwt.NewNumericDate(time.Now().Add(MFAChallengeTTL())),
+        IssuedAt:  jwt.NewNumericDate(time.Now()),
+    }
+
+    return firmarJWT(claims)
+}
+
+// ValidateMFAToken valida un token de desafío MFA y devuelve el ID del usuario
+func ValidateMFAToken(tokenString string) (int, error) {
+    claims := &jwt.RegisteredClaims{}
+
+    if err := verificarJWT(tokenString, claims, audienciaMFA()); err != nil {
+        return 0, errors.New("token de verificación inválido o expirado")
+    }
+
+    userID, err := strconv.Atoi(claims.Subject)
+    if err != nil {
+        return 0, errors.New("token de verificación inválido o expirado")
+    }
+
+    return userID, nil
+}
+
+// firmarJWT firma los claims con la clave activa del llavero e indica su kid
+func firmarJWT(claims jwt.Claims) (string, error) {
+    if jwtConfig.keys == nil {
+        return "", errors.New("JWT no configurado")
+    }
+
+    clave := jwtConfig.keys.Firmante()
+    if clave == nil {
+        return "", errors.New("no hay ninguna clave JWT activa")
+    }
+
+    token := jwt.NewWithClaims(jwt.GetSigningMethod(clave.Alg), claims)
+    token.Header["kid"] = clave.ID
+    return token.SignedString(clave.privada)
+}
+
+// verificarJWT valida la firma con la clave del kid del token, exigiendo que el
+// algoritmo sea el de esa clave, y comprueba emisor, audiencia y caducidad
+func verificarJWT(tokenString string, claims jwt.Claims, audiencia string) error {
+    if jwtConfig.keys == nil {
+        return errors.New("JWT no configurado")
+    }
+
     token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-        return []byte(os.Getenv("JWT_SECRET")), nil
-    })
+        kid, _ := token.Header["kid"].(string)
+        clave, ok := jwtConfig.keys.Buscar(kid)
+        if !ok {
+            return nil, errors.New("clave de firma desconocida")
+        }
+        if token.Method.Alg() != clave.Alg {
+            return nil, errors.New("algoritmo de firma inesperado")
+        }
+        return clave.publica, nil
+    },
+        jwt.WithValidMethods([]string{AlgRS256, AlgEdDSA}),
+        jwt.WithIssuer(jwtConfig.issuer),
+        jwt.WithAudience(audiencia),
+        jwt.WithExpirationRequired(),
+    )
 
     if err != nil {
-        return nil, err
+        return err
     }
 
     if !token.Valid {
-        return nil, errors.New("token inválido")
+        return errors.New("token inválido")
     }
 
-    return claims, nil
+    return nil
+}
+
+// HashToken calcula el hash SHA-256 (hex) con el que se guardan los tokens opacos
+func HashToken(token string) string {
+    sum := sha256.Sum256([]byte(token))
+    return hex.EncodeToString(sum[:])
 }
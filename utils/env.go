@@ -0,0 +1,50 @@
+package utils
+
+import (
+    "log"
+    "os"
+    "strconv"
+    "time"
+)
+
+// GetEnv lee una variable de entorno, usando el valor por defecto si está vacía
+func GetEnv(key, def string) string {
+    if valor := os.Getenv(key); valor != "" {
+        return valor
+    }
+    return def
+}
+
+// GetEnvDuration lee una duración (por ejemplo "15m" o "720h") de una variable
+// de entorno, usando el valor por defecto si no está definida o es inválida
+func GetEnvDuration(key string, def time.Duration) time.Duration {
+    valor := os.Getenv(key)
+    if valor == "" {
+        return def
+    }
+
+    d, err := time.ParseDuration(valor)
+    if err != nil || d <= 0 {
+        log.Printf("⚠️  Valor inválido para %s (%q), usando %s\n", key, valor, def)
+        return def
+    }
+
+    return d
+}
+
+// GetEnvInt lee un entero positivo de una variable de entorno, usando el
+// valor por defecto si no está definida o es inválida
+func GetEnvInt(key string, def int) int {
+    valor := os.Getenv(key)
+    if valor == "" {
+        return def
+    }
+
+    n, err := strconv.Atoi(valor)
+    if err != nil || n <= 0 {
+        log.Printf("⚠️  Valor inválido para %s (%q), usando %d\n", key, valor, def)
+        return def
+    }
+
+    return n
+}
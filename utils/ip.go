@@ -0,0 +1,28 @@
+package utils
+
+import (
+    "net"
+    "net/http"
+    "os"
+    "strings"
+)
+
+// ClientIP devuelve la IP del cliente. Con TRUST_PROXY_HEADERS=true (la API
+// detrás de un proxy inverso) usa la última entrada de X-Forwarded-For, que es
+// la que añade el propio proxy y el cliente no puede falsificar.
+func ClientIP(r *http.Request) string {
+    if os.Getenv("TRUST_PROXY_HEADERS") == "true" {
+        if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+            partes := strings.Split(strings.Join(forwarded, ","), ",")
+            if ip := strings.TrimSpace(partes[len(partes)-1]); ip != "" {
+                return ip
+            }
+        }
+    }
+
+    host, _, err := net.SplitHostPort(r.RemoteAddr)
+    if err != nil {
+        return r.RemoteAddr
+    }
+    return host
+}
@@ -0,0 +1,304 @@
+package utils
+
+import (
+    "crypto"
+    "crypto/ed25519"
+    "crypto/rand"
+    "crypto/rsa"
+    "crypto/x509"
+    "encoding/base64"
+    "encoding/pem"
+    "errors"
+    "fmt"
+    "log"
+    "math/big"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+)
+
+// Algoritmos de firma de JWT admitidos
+const (
+    AlgRS256 = "RS256"
+    AlgEdDSA = "EdDSA"
+)
+
+// formatoFechaClave es el prefijo del nombre de cada archivo de clave: la
+// fecha (UTC) desde la que la clave firma tokens
+const formatoFechaClave = "2006-01-02"
+
+// JWTKey es una clave del llavero. Su ID (kid) es el nombre del archivo sin
+// la extensión, y empieza por la fecha de activación.
+type JWTKey struct {
+    ID       string
+    Alg      string
+    ActivaAt time.Time
+    privada  crypto.Signer
+    publica  crypto.PublicKey
+}
+
+// KeyRing es el llavero de claves de firma de JWT, leído de los archivos PEM
+// de un directorio. La clave activa más reciente firma los tokens; las
+// anteriores siguen validando durante el solapamiento y las futuras ya se
+// publican en el JWKS para que los demás servicios las tengan antes de usarse.
+// El directorio se relee periódicamente, así que rotar consiste en añadir un
+// archivo con una fecha futura y borrar más tarde los retirados.
+type KeyRing struct {
+    mu          sync.RWMutex
+    dir         string
+    solape      time.Duration
+    intervalo   time.Duration
+    claves      []*JWTKey // ordenadas por fecha de activación
+    ultimaCarga time.Time
+}
+
+// LoadKeyRing lee las claves de dir. solape es cuánto siguen valiendo para
+// validar las claves reemplazadas y intervalo cada cuánto se relee dir.
+func LoadKeyRing(dir string, solape, intervalo time.Duration) (*KeyRing, error) {
+    ring := &KeyRing{dir: dir, solape: solape, intervalo: intervalo}
+
+    claves, err := leerClaves(dir)
+    if err != nil {
+        return nil, err
+    }
+    ring.claves = claves
+    ring.ultimaCarga = time.Now()
+
+    if ring.Firmante() == nil {
+        return nil, fmt.Errorf("no hay ninguna clave JWT activa en %s", dir)
+    }
+
+    return ring, nil
+}
+
+// Firmante devuelve la clave con la que se firman los tokens nuevos
+func (k *KeyRing) Firmante() *JWTKey {
+    k.recargar()
+
+    k.mu.RLock()
+    defer k.mu.RUnlock()
+
+    now := time.Now()
+    var firmante *JWTKey
+    for _, clave := range k.claves {
+        if !clave.ActivaAt.After(now) {
+            firmante = clave
+        }
+    }
+    return firmante
+}
+
+// Publicadas devuelve las claves vigentes para validar: las futuras, la
+// activa y las reemplazadas hace menos del solapamiento
+func (k *KeyRing) Publicadas() []*JWTKey {
+    k.recargar()
+
+    k.mu.RLock()
+    defer k.mu.RUnlock()
+
+    now := time.Now()
+    var publicadas []*JWTKey
+    for i, clave := range k.claves {
+        // Una clave se retira cuando la siguiente lleva firmando más que el solape
+        if i+1 < len(k.claves) && k.claves[i+1].ActivaAt.Add(k.solape).Before(now) {
+            continue
+        }
+        publicadas = append(publicadas, clave)
+    }
+    return publicadas
+}
+
+// Buscar devuelve la clave vigente con el kid indicado
+func (k *KeyRing) Buscar(kid string) (*JWTKey, bool) {
+    for _, clave := range k.Publicadas() {
+        if clave.ID == kid {
+            return clave, true
+        }
+    }
+    return nil, false
+}
+
+// recargar vuelve a leer el directorio si pasó el intervalo. Si falla se
+// conservan las claves cargadas.
+func (k *KeyRing) recargar() {
+    k.mu.RLock()
+    pendiente := time.Since(k.ultimaCarga) >= k.intervalo
+    k.mu.RUnlock()
+    if !pendiente {
+        return
+    }
+
+    k.mu.Lock()
+    defer k.mu.Unlock()
+
+    if time.Since(k.ultimaCarga) < k.intervalo {
+        return
+    }
+    k.ultimaCarga = time.Now()
+
+    claves, err := leerClaves(k.dir)
+    if err != nil {
+        log.Printf("⚠️  No se pudieron recargar las claves JWT, se mantienen las actuales: %v\n", err)
+        return
+    }
+    k.claves = claves
+}
+
+// JWK es una clave pública en formato JSON Web Key (RFC 7517)
+type JWK struct {
+    Kty string `json:"kty"`
+    Kid string `json:"kid"`
+    Use string `json:"use"`
+    Alg string `json:"alg"`
+    N   string `json:"n,omitempty"`   // RSA
+    E   string `json:"e,omitempty"`   // RSA
+    Crv string `json:"crv,omitempty"` // OKP
+    X   string `json:"x,omitempty"`   // OKP
+}
+
+// JWKS devuelve las claves públicas vigentes para /.well-known/jwks.json
+func (k *KeyRing) JWKS() map[string][]JWK {
+    keys := []JWK{}
+    for _, clave := range k.Publicadas() {
+        jwk := JWK{Kid: clave.ID, Use: "sig", Alg: clave.Alg}
+
+        switch pub := clave.publica.(type) {
+        case *rsa.PublicKey:
+            jwk.Kty = "RSA"
+            jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+            jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+        case ed25519.PublicKey:
+            jwk.Kty = "OKP"
+            jwk.Crv = "Ed25519"
+            jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+        }
+
+        keys = append(keys, jwk)
+    }
+
+    return map[string][]JWK{"keys": keys}
+}
+
+// leerClaves lee los archivos <fecha>[_sufijo].pem de dir
+func leerClaves(dir string) ([]*JWTKey, error) {
+    archivos, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+    if err != nil {
+        return nil, err
+    }
+
+    var claves []*JWTKey
+    for _, archivo := range archivos {
+        clave, err := leerClave(archivo)
+        if err != nil {
+            return nil, fmt.Errorf("%s: %w", filepath.Base(archivo), err)
+        }
+        claves = append(claves, clave)
+    }
+
+    if len(claves) == 0 {
+        return nil, fmt.Errorf("no hay claves JWT (*.pem) en %s", dir)
+    }
+
+    sort.Slice(claves, func(i, j int) bool {
+        if claves[i].ActivaAt.Equal(claves[j].ActivaAt) {
+            return claves[i].ID < claves[j].ID
+        }
+        return claves[i].ActivaAt.Before(claves[j].ActivaAt)
+    })
+
+    return claves, nil
+}
+
+// leerClave lee una clave privada PEM (PKCS#8, o PKCS#1 para RSA)
+func leerClave(archivo string) (*JWTKey, error) {
+    kid := strings.TrimSuffix(filepath.Base(archivo), ".pem")
+    if len(kid) < len(formatoFechaClave) {
+        return nil, errors.New("el nombre debe empezar por la fecha de activación (AAAA-MM-DD)")
+    }
+
+    activaAt, err := time.Parse(formatoFechaClave, kid[:len(formatoFechaClave)])
+    if err != nil {
+        return nil, errors.New("el nombre debe empezar por la fecha de activación (AAAA-MM-DD)")
+    }
+
+    data, err := os.ReadFile(archivo)
+    if err != nil {
+        return nil, err
+    }
+
+    block, _ := pem.Decode(data)
+    if block == nil {
+        return nil, errors.New("no es un archivo PEM")
+    }
+
+    var privada interface{}
+    switch block.Type {
+    case "PRIVATE KEY":
+        privada, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+    case "RSA PRIVATE KEY":
+        privada, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+    default:
+        return nil, fmt.Errorf("tipo de PEM no admitido: %s", block.Type)
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    clave := &JWTKey{ID: kid, ActivaAt: activaAt}
+    switch priv := privada.(type) {
+    case *rsa.PrivateKey:
+        if priv.N.BitLen() < 2048 {
+            return nil, errors.New("las claves RSA deben tener al menos 2048 bits")
+        }
+        clave.Alg = AlgRS256
+        clave.privada = priv
+        clave.publica = &priv.PublicKey
+    case ed25519.PrivateKey:
+        clave.Alg = AlgEdDSA
+        clave.privada = priv
+        clave.publica = priv.Public()
+    default:
+        return nil, errors.New("solo se admiten claves RSA (RS256) y Ed25519 (EdDSA)")
+    }
+
+    return clave, nil
+}
+
+// GenerateJWTKey genera una clave privada nueva en PEM (PKCS#8) para el
+// algoritmo indicado
+func GenerateJWTKey(alg string) ([]byte, error) {
+    var privada interface{}
+    var err error
+
+    switch alg {
+    case AlgRS256:
+        privada, err = rsa.GenerateKey(rand.Reader, 3072)
+    case AlgEdDSA:
+        _, privada, err = ed25519.GenerateKey(rand.Reader)
+    default:
+        return nil, fmt.Errorf("algoritmo no admitido: %s (usa %s o %s)", alg, AlgRS256, AlgEdDSA)
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    der, err := x509.MarshalPKCS8PrivateKey(privada)
+    if err != nil {
+        return nil, err
+    }
+
+    return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// NombreArchivoClave devuelve el nombre de archivo para una clave que se
+// activa en la fecha indicada, con un sufijo aleatorio para no pisar otras
+func NombreArchivoClave(activaAt time.Time) (string, error) {
+    sufijo, err := GenerateRandomToken(4)
+    if err != nil {
+        return "", err
+    }
+    return activaAt.UTC().Format(formatoFechaClave) + "_" + sufijo + ".pem", nil
+}
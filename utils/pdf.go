@@ -0,0 +1,201 @@
+package utils
+
+import (
+    "bytes"
+    "compress/zlib"
+    "fmt"
+    "strings"
+)
+
+// PDFDocument es un generador mínimo de PDF de una página con las fuentes
+// estándar Helvetica y Helvetica-Bold (no requiere incrustar fuentes)
+type PDFDocument struct {
+    Width   float64
+    Height  float64
+    content bytes.Buffer
+}
+
+// Fuentes estándar disponibles
+const (
+    PDFFontRegular = "F1"
+    PDFFontBold    = "F2"
+)
+
+// NewPDFDocument crea un documento con el tamaño de página indicado en puntos
+func NewPDFDocument(width, height float64) *PDFDocument {
+    return &PDFDocument{Width: width, Height: height}
+}
+
+// SetFillColor establece el color de relleno (componentes RGB entre 0 y 1)
+func (d *PDFDocument) SetFillColor(r, g, b float64) {
+    fmt.Fprintf(&d.content, "%.3f %.3f %.3f rg\n", r, g, b)
+}
+
+// SetStrokeColor establece el color de línea (componentes RGB entre 0 y 1)
+func (d *PDFDocument) SetStrokeColor(r, g, b float64) {
+    fmt.Fprintf(&d.content, "%.3f %.3f %.3f RG\n", r, g, b)
+}
+
+// Rect dibuja un rectángulo con origen en la esquina inferior izquierda
+func (d *PDFDocument) Rect(x, y, w, h float64, fill bool, lineWidth float64) {
+    if fill {
+        fmt.Fprintf(&d.content, "%.2f %.2f %.2f %.2f re f\n", x, y, w, h)
+        return
+    }
+    fmt.Fprintf(&d.content, "%.2f w %.2f %.2f %.2f %.2f re S\n", lineWidth, x, y, w, h)
+}
+
+// Line dibuja una línea recta
+func (d *PDFDocument) Line(x1, y1, x2, y2, lineWidth float64) {
+    fmt.Fprintf(&d.content, "%.2f w %.2f %.2f m %.2f %.2f l S\n", lineWidth, x1, y1, x2, y2)
+}
+
+// Text escribe texto con la línea base en (x, y)
+func (d *PDFDocument) Text(x, y float64, font string, size float64, text string) {
+    fmt.Fprintf(&d.content, "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfEscape(text))
+}
+
+// TextCentered escribe texto centrado horizontalmente en la página
+func (d *PDFDocument) TextCentered(y float64, font string, size float64, text string) {
+    x := (d.Width - PDFTextWidth(font, size, text)) / 2
+    d.Text(x, y, font, size, text)
+}
+
+// QRCode dibuja una matriz QR con su esquina inferior izquierda en (x, y)
+func (d *PDFDocument) QRCode(x, y, size float64, modulos [][]bool) {
+    if len(modulos) == 0 {
+        return
+    }
+
+    // Zona de silencio de 4 módulos alrededor del código
+    n := float64(len(modulos) + 8)
+    modulo := size / n
+
+    d.SetFillColor(1, 1, 1)
+    d.Rect(x, y, size, size, true, 0)
+    d.SetFillColor(0, 0, 0)
+
+    for fila, columnas := range modulos {
+        for col, oscuro := range columnas {
+            if !oscuro {
+                continue
+            }
+            mx := x + float64(col+4)*modulo
+            my := y + size - float64(fila+5)*modulo
+            d.Rect(mx, my, modulo, modulo, true, 0)
+        }
+    }
+}
+
+// Bytes serializa el documento
+func (d *PDFDocument) Bytes() ([]byte, error) {
+    var stream bytes.Buffer
+    zw := zlib.NewWriter(&stream)
+    if _, err := zw.Write(d.content.Bytes()); err != nil {
+        return nil, err
+    }
+    if err := zw.Close(); err != nil {
+        return nil, err
+    }
+
+    objetos := []string{
+        "<< /Type /Catalog /Pages 2 0 R >>",
+        "<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+        fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Contents 4 0 R "+
+            "/Resources << /Font << /F1 5 0 R /F2 6 0 R >> >> >>", d.Width, d.Height),
+        fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", stream.Len(), stream.String()),
+        "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+        "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+    }
+
+    var out bytes.Buffer
+    out.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+    offsets := make([]int, len(objetos))
+    for i, objeto := range objetos {
+        offsets[i] = out.Len()
+        fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, objeto)
+    }
+
+    xref := out.Len()
+    fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objetos)+1)
+    for _, offset := range offsets {
+        fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+    }
+    fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objetos)+1, xref)
+
+    return out.Bytes(), nil
+}
+
+// PDFTextWidth calcula el ancho en puntos de un texto con una fuente estándar
+func PDFTextWidth(font string, size float64, text string) float64 {
+    anchos := helveticaWidths
+    if font == PDFFontBold {
+        anchos = helveticaBoldWidths
+    }
+
+    total := 0
+    for _, r := range text {
+        r = pdfBaseRune(r)
+        if r >= 32 && r <= 126 {
+            total += anchos[r-32]
+        } else {
+            total += 556
+        }
+    }
+
+    return float64(total) * size / 1000
+}
+
+// pdfEscape convierte el texto a WinAnsiEncoding y escapa los caracteres especiales
+func pdfEscape(text string) string {
+    var b strings.Builder
+    for _, r := range text {
+        switch {
+        case r == '(' || r == ')' || r == '\\':
+            b.WriteByte('\\')
+            b.WriteRune(r)
+        case r >= 32 && r <= 126:
+            b.WriteRune(r)
+        case r >= 0xA0 && r <= 0xFF:
+            // Latin-1 coincide con WinAnsi en este rango
+            fmt.Fprintf(&b, "\\%03o", r)
+        default:
+            b.WriteByte('?')
+        }
+    }
+    return b.String()
+}
+
+// pdfBaseRune aproxima letras acentuadas a su letra base para calcular anchos
+func pdfBaseRune(r rune) rune {
+    if base, ok := pdfAcentos[r]; ok {
+        return base
+    }
+    return r
+}
+
+var pdfAcentos = map[rune]rune{
+    'á': 'a', 'é': 'e', 'í': 'i', 'ó': 'o', 'ú': 'u', 'ü': 'u', 'ñ': 'n',
+    'Á': 'A', 'É': 'E', 'Í': 'I', 'Ó': 'O', 'Ú': 'U', 'Ü': 'U', 'Ñ': 'N',
+    'à': 'a', 'è': 'e', 'ì': 'i', 'ò': 'o', 'ù': 'u', 'ç': 'c', 'Ç': 'C',
+}
+
+// Anchos AFM de los caracteres 32-126 (unidades de 1/1000 em)
+var helveticaWidths = []int{
+    278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+    556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+    1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+    667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+    333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+    556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = []int{
+    278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+    556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+    975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+    667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+    333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+    611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
@@ -0,0 +1,147 @@
+package utils
+
+import (
+    "bytes"
+    "compress/zlib"
+    "fmt"
+    "io"
+    "regexp"
+    "strconv"
+    "strings"
+    "testing"
+)
+
+// documentoPrueba arma un documento con todas las primitivas, incluidos
+// caracteres que deben escaparse y un código QR
+func documentoPrueba(t *testing.T) []byte {
+    t.Helper()
+
+    qr, err := EncodeQR("https://cursos.example.com")
+    if err != nil {
+        t.Fatalf("EncodeQR: %v", err)
+    }
+
+    doc := NewPDFDocument(842, 595)
+    doc.SetStrokeColor(0.1, 0.2, 0.3)
+    doc.Rect(20, 20, 802, 555, false, 2)
+    doc.Line(100, 100, 742, 100, 1)
+    doc.SetFillColor(0, 0, 0)
+    doc.TextCentered(490, PDFFontBold, 30, "CERTIFICADO DE FINALIZACIÓN")
+    doc.Text(100, 300, PDFFontRegular, 12, "Señales (y paréntesis) con \\ barra")
+    doc.QRCode(700, 40, 100, qr)
+
+    salida, err := doc.Bytes()
+    if err != nil {
+        t.Fatalf("Bytes: %v", err)
+    }
+    return salida
+}
+
+func TestPDFTablaXref(t *testing.T) {
+    salida := documentoPrueba(t)
+
+    if !bytes.HasPrefix(salida, []byte("%PDF-1.4\n")) {
+        t.Fatalf("encabezado inválido: %q", salida[:16])
+    }
+    if !bytes.HasSuffix(salida, []byte("%%EOF\n")) {
+        t.Fatal("el documento no termina con el marcador de fin")
+    }
+
+    // startxref apunta al inicio de la tabla xref
+    fin := bytes.LastIndex(salida, []byte("startxref\n"))
+    if fin < 0 {
+        t.Fatal("falta startxref")
+    }
+    var xref int
+    if _, err := fmt.Sscanf(string(salida[fin:]), "startxref\n%d\n", &xref); err != nil {
+        t.Fatalf("startxref ilegible: %v", err)
+    }
+    if xref <= 0 || xref >= len(salida) || !bytes.HasPrefix(salida[xref:], []byte("xref\n")) {
+        t.Fatalf("startxref %d no apunta a la tabla xref", xref)
+    }
+
+    // Subsección única desde el objeto 0
+    var inicio, cantidad int
+    if _, err := fmt.Sscanf(string(salida[xref:]), "xref\n%d %d\n", &inicio, &cantidad); err != nil {
+        t.Fatalf("subsección xref ilegible: %v", err)
+    }
+    if inicio != 0 || cantidad < 2 {
+        t.Fatalf("subsección xref %d %d inválida", inicio, cantidad)
+    }
+
+    // Cada entrada ocupa exactamente 20 bytes
+    entradas := salida[xref+len(fmt.Sprintf("xref\n%d %d\n", inicio, cantidad)):]
+    if string(entradas[:20]) != "0000000000 65535 f \n" {
+        t.Fatalf("entrada libre inválida: %q", entradas[:20])
+    }
+    for i := 1; i < cantidad; i++ {
+        entrada := string(entradas[i*20 : (i+1)*20])
+        if !regexp.MustCompile(`^\d{10} 00000 n \n$`).MatchString(entrada) {
+            t.Fatalf("entrada %d inválida: %q", i, entrada)
+        }
+        offset, _ := strconv.Atoi(entrada[:10])
+        if !bytes.HasPrefix(salida[offset:], []byte(fmt.Sprintf("%d 0 obj\n", i))) {
+            t.Fatalf("la entrada %d apunta a %d, que no es el inicio del objeto", i, offset)
+        }
+    }
+
+    // El trailer sigue a la última entrada
+    trailer := string(entradas[cantidad*20:])
+    esperado := fmt.Sprintf("trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", cantidad, xref)
+    if trailer != esperado {
+        t.Fatalf("trailer %q, se esperaba %q", trailer, esperado)
+    }
+
+    // Cantidad de objetos coincide con /Size - 1 y /Root es el catálogo
+    if n := len(regexp.MustCompile(`(?m)^\d+ 0 obj$`).FindAll(salida, -1)); n != cantidad-1 {
+        t.Fatalf("%d objetos, /Size indica %d", n, cantidad-1)
+    }
+    if !bytes.Contains(salida, []byte("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>")) {
+        t.Fatal("el objeto 1 no es el catálogo")
+    }
+}
+
+func TestPDFStreamContenido(t *testing.T) {
+    salida := documentoPrueba(t)
+
+    m := regexp.MustCompile(`<< /Length (\d+) /Filter /FlateDecode >>\nstream\n`).FindSubmatchIndex(salida)
+    if m == nil {
+        t.Fatal("falta el stream de contenido")
+    }
+    longitud, _ := strconv.Atoi(string(salida[m[2]:m[3]]))
+    datos := salida[m[1] : m[1]+longitud]
+    if !bytes.HasPrefix(salida[m[1]+longitud:], []byte("\nendstream")) {
+        t.Fatal("/Length no coincide con el largo del stream")
+    }
+
+    zr, err := zlib.NewReader(bytes.NewReader(datos))
+    if err != nil {
+        t.Fatalf("zlib: %v", err)
+    }
+    contenido, err := io.ReadAll(zr)
+    if err != nil {
+        t.Fatalf("zlib: %v", err)
+    }
+
+    for _, esperado := range []string{
+        "0.100 0.200 0.300 RG",
+        "2.00 w 20.00 20.00 802.00 555.00 re S",
+        "(CERTIFICADO DE FINALIZACI\\323N) Tj",
+        "(Se\\361ales \\(y par\\351ntesis\\) con \\\\ barra) Tj",
+    } {
+        if !strings.Contains(string(contenido), esperado) {
+            t.Fatalf("el contenido no incluye %q", esperado)
+        }
+    }
+}
+
+func TestPDFTextWidth(t *testing.T) {
+    // "Hola" en Helvetica: H=722 o=556 l=222 a=556 → 2056/1000 em
+    if ancho := PDFTextWidth(PDFFontRegular, 10, "Hola"); ancho != 20.56 {
+        t.Fatalf("ancho %v, se esperaba 20.56", ancho)
+    }
+    // Las letras acentuadas usan el ancho de su letra base
+    if PDFTextWidth(PDFFontBold, 12, "AÑO") != PDFTextWidth(PDFFontBold, 12, "ANO") {
+        t.Fatal("Ñ no usa el ancho de N")
+    }
+}
@@ -0,0 +1,491 @@
+package utils
+
+import (
+    "errors"
+)
+
+// Implementación mínima de códigos QR (ISO/IEC 18004) en modo byte con nivel
+// de corrección M, suficiente para URLs de verificación (hasta 213 bytes).
+
+// qrVersion describe la estructura de bloques de una versión para el nivel M
+type qrVersion struct {
+    ecPorBloque  int
+    bloques1     int
+    datosBloque1 int
+    bloques2     int
+    datosBloque2 int
+    alineacion   []int
+    restoBits    int
+}
+
+var qrVersiones = []qrVersion{
+    1:  {10, 1, 16, 0, 0, nil, 0},
+    2:  {16, 1, 28, 0, 0, []int{6, 18}, 7},
+    3:  {26, 1, 44, 0, 0, []int{6, 22}, 7},
+    4:  {18, 2, 32, 0, 0, []int{6, 26}, 7},
+    5:  {24, 2, 43, 0, 0, []int{6, 30}, 7},
+    6:  {16, 4, 27, 0, 0, []int{6, 34}, 7},
+    7:  {18, 4, 31, 0, 0, []int{6, 22, 38}, 0},
+    8:  {22, 2, 38, 2, 39, []int{6, 24, 42}, 0},
+    9:  {22, 3, 36, 2, 37, []int{6, 26, 46}, 0},
+    10: {26, 4, 43, 1, 44, []int{6, 28, 50}, 0},
+}
+
+func (v qrVersion) capacidadDatos() int {
+    return v.bloques1*v.datosBloque1 + v.bloques2*v.datosBloque2
+}
+
+// EncodeQR genera la matriz de módulos (true = oscuro) del código QR de un texto
+func EncodeQR(text string) ([][]bool, error) {
+    data := []byte(text)
+
+    version := 0
+    for v := 1; v < len(qrVersiones); v++ {
+        bitsCount := 8
+        if v >= 10 {
+            bitsCount = 16
+        }
+        if 4+bitsCount+8*len(data) <= 8*qrVersiones[v].capacidadDatos() {
+            version = v
+            break
+        }
+    }
+
+    if version == 0 {
+        return nil, errors.New("texto demasiado largo para el código QR")
+    }
+
+    codewords := qrCodificarDatos(data, version)
+    codewords = qrAgregarCorreccion(codewords, qrVersiones[version])
+
+    q := newQRMatriz(version)
+    q.dibujarPatrones()
+    q.dibujarDatos(codewords)
+
+    mejorMascara, mejorPenalizacion := 0, -1
+    for mascara := 0; mascara < 8; mascara++ {
+        q.aplicarMascara(mascara)
+        q.dibujarFormato(mascara)
+        penalizacion := q.penalizacion()
+        if mejorPenalizacion < 0 || penalizacion < mejorPenalizacion {
+            mejorMascara, mejorPenalizacion = mascara, penalizacion
+        }
+        q.aplicarMascara(mascara) // XOR: deshace la máscara
+    }
+
+    q.aplicarMascara(mejorMascara)
+    q.dibujarFormato(mejorMascara)
+
+    return q.modulos, nil
+}
+
+// qrCodificarDatos arma el flujo de bits en modo byte con relleno
+func qrCodificarDatos(data []byte, version int) []byte {
+    capacidad := qrVersiones[version].capacidadDatos()
+
+    var bits []bool
+    agregar := func(valor, n int) {
+        for i := n - 1; i >= 0; i-- {
+            bits = append(bits, (valor>>uint(i))&1 == 1)
+        }
+    }
+
+    bitsCount := 8
+    if version >= 10 {
+        bitsCount = 16
+    }
+
+    agregar(0x4, 4) // modo byte
+    agregar(len(data), bitsCount)
+    for _, b := range data {
+        agregar(int(b), 8)
+    }
+
+    // Terminador y alineación a byte
+    for i := 0; i < 4 && len(bits) < capacidad*8; i++ {
+        bits = append(bits, false)
+    }
+    for len(bits)%8 != 0 {
+        bits = append(bits, false)
+    }
+
+    codewords := make([]byte, 0, capacidad)
+    for i := 0; i < len(bits); i += 8 {
+        var b byte
+        for j := 0; j < 8; j++ {
+            if bits[i+j] {
+                b |= 1 << uint(7-j)
+            }
+        }
+        codewords = append(codewords, b)
+    }
+
+    for relleno := byte(0xEC); len(codewords) < capacidad; relleno ^= 0xEC ^ 0x11 {
+        codewords = append(codewords, relleno)
+    }
+
+    return codewords
+}
+
+// qrAgregarCorreccion divide en bloques, calcula Reed-Solomon e intercala
+func qrAgregarCorreccion(data []byte, v qrVersion) []byte {
+    var bloques, correcciones [][]byte
+
+    divisor := qrGeneradorRS(v.ecPorBloque)
+    offset := 0
+    for i := 0; i < v.bloques1+v.bloques2; i++ {
+        n := v.datosBloque1
+        if i >= v.bloques1 {
+            n = v.datosBloque2
+        }
+        bloque := data[offset : offset+n]
+        offset += n
+
+        bloques = append(bloques, bloque)
+        correcciones = append(correcciones, qrRestoRS(bloque, divisor))
+    }
+
+    var resultado []byte
+    maxDatos := v.datosBloque1
+    if v.datosBloque2 > maxDatos {
+        maxDatos = v.datosBloque2
+    }
+    for i := 0; i < maxDatos; i++ {
+        for _, bloque := range bloques {
+            if i < len(bloque) {
+                resultado = append(resultado, bloque[i])
+            }
+        }
+    }
+    for i := 0; i < v.ecPorBloque; i++ {
+        for _, correccion := range correcciones {
+            resultado = append(resultado, correccion[i])
+        }
+    }
+
+    return resultado
+}
+
+// qrMultiplicar multiplica en GF(256) con el polinomio 0x11D
+func qrMultiplicar(x, y byte) byte {
+    var z byte
+    for i := 7; i >= 0; i-- {
+        alto := z & 0x80
+        z <<= 1
+        if alto != 0 {
+            z ^= 0x1D
+        }
+        if (y>>uint(i))&1 != 0 {
+            z ^= x
+        }
+    }
+    return z
+}
+
+// qrGeneradorRS calcula el polinomio generador de grado n
+func qrGeneradorRS(n int) []byte {
+    resultado := make([]byte, n)
+    resultado[n-1] = 1
+
+    raiz := byte(1)
+    for i := 0; i < n; i++ {
+        for j := 0; j < n; j++ {
+            resultado[j] = qrMultiplicar(resultado[j], raiz)
+            if j+1 < n {
+                resultado[j] ^= resultado[j+1]
+            }
+        }
+        raiz = qrMultiplicar(raiz, 0x02)
+    }
+
+    return resultado
+}
+
+// qrRestoRS calcula los codewords de corrección de un bloque
+func qrRestoRS(data, divisor []byte) []byte {
+    resultado := make([]byte, len(divisor))
+    for _, b := range data {
+        factor := b ^ resultado[0]
+        copy(resultado, resultado[1:])
+        resultado[len(resultado)-1] = 0
+        for i := range resultado {
+            resultado[i] ^= qrMultiplicar(divisor[i], factor)
+        }
+    }
+    return resultado
+}
+
+// qrMatriz mantiene los módulos y cuáles pertenecen a patrones fijos
+type qrMatriz struct {
+    version   int
+    tamano    int
+    modulos   [][]bool
+    funciones [][]bool
+}
+
+func newQRMatriz(version int) *qrMatriz {
+    tamano := 17 + 4*version
+    q := &qrMatriz{version: version, tamano: tamano}
+    q.modulos = make([][]bool, tamano)
+    q.funciones = make([][]bool, tamano)
+    for i := range q.modulos {
+        q.modulos[i] = make([]bool, tamano)
+        q.funciones[i] = make([]bool, tamano)
+    }
+    return q
+}
+
+func (q *qrMatriz) fijar(x, y int, oscuro bool) {
+    q.modulos[y][x] = oscuro
+    q.funciones[y][x] = true
+}
+
+func (q *qrMatriz) dibujarPatrones() {
+    // Patrones de sincronización
+    for i := 0; i < q.tamano; i++ {
+        q.fijar(6, i, i%2 == 0)
+        q.fijar(i, 6, i%2 == 0)
+    }
+
+    // Patrones de posición con sus separadores
+    q.dibujarBuscador(3, 3)
+    q.dibujarBuscador(q.tamano-4, 3)
+    q.dibujarBuscador(3, q.tamano-4)
+
+    // Patrones de alineación
+    posiciones := qrVersiones[q.version].alineacion
+    n := len(posiciones)
+    for i := 0; i < n; i++ {
+        for j := 0; j < n; j++ {
+            if (i == 0 && j == 0) || (i == 0 && j == n-1) || (i == n-1 && j == 0) {
+                continue
+            }
+            q.dibujarAlineacion(posiciones[i], posiciones[j])
+        }
+    }
+
+    // Reserva las áreas de formato y versión
+    q.dibujarFormato(0)
+    q.dibujarVersion()
+}
+
+func (q *qrMatriz) dibujarBuscador(x, y int) {
+    for dy := -4; dy <= 4; dy++ {
+        for dx := -4; dx <= 4; dx++ {
+            xx, yy := x+dx, y+dy
+            if xx < 0 || xx >= q.tamano || yy < 0 || yy >= q.tamano {
+                continue
+            }
+            distancia := qrMax(qrAbs(dx), qrAbs(dy))
+            q.fijar(xx, yy, distancia != 2 && distancia != 4)
+        }
+    }
+}
+
+func (q *qrMatriz) dibujarAlineacion(x, y int) {
+    for dy := -2; dy <= 2; dy++ {
+        for dx := -2; dx <= 2; dx++ {
+            q.fijar(x+dx, y+dy, qrMax(qrAbs(dx), qrAbs(dy)) != 1)
+        }
+    }
+}
+
+// dibujarFormato escribe el nivel de corrección (M) y la máscara con BCH(15,5)
+func (q *qrMatriz) dibujarFormato(mascara int) {
+    datos := 0<<3 | mascara // nivel M = 00
+    resto := datos
+    for i := 0; i < 10; i++ {
+        resto = (resto << 1) ^ ((resto >> 9) * 0x537)
+    }
+    bits := (datos<<10 | resto) ^ 0x5412
+
+    bit := func(i int) bool { return (bits>>uint(i))&1 != 0 }
+
+    for i := 0; i <= 5; i++ {
+        q.fijar(8, i, bit(i))
+    }
+    q.fijar(8, 7, bit(6))
+    q.fijar(8, 8, bit(7))
+    q.fijar(7, 8, bit(8))
+    for i := 9; i < 15; i++ {
+        q.fijar(14-i, 8, bit(i))
+    }
+
+    for i := 0; i < 8; i++ {
+        q.fijar(q.tamano-1-i, 8, bit(i))
+    }
+    for i := 8; i < 15; i++ {
+        q.fijar(8, q.tamano-15+i, bit(i))
+    }
+    q.fijar(8, q.tamano-8, true) // módulo oscuro fijo
+}
+
+// dibujarVersion escribe la información de versión (versiones 7 y superiores)
+func (q *qrMatriz) dibujarVersion() {
+    if q.version < 7 {
+        return
+    }
+
+    resto := q.version
+    for i := 0; i < 12; i++ {
+        resto = (resto << 1) ^ ((resto >> 11) * 0x1F25)
+    }
+    bits := q.version<<12 | resto
+
+    for i := 0; i < 18; i++ {
+        oscuro := (bits>>uint(i))&1 != 0
+        a := q.tamano - 11 + i%3
+        b := i / 3
+        q.fijar(a, b, oscuro)
+        q.fijar(b, a, oscuro)
+    }
+}
+
+// dibujarDatos coloca los codewords en zigzag desde la esquina inferior derecha
+func (q *qrMatriz) dibujarDatos(data []byte) {
+    i := 0
+    for derecha := q.tamano - 1; derecha >= 1; derecha -= 2 {
+        if derecha == 6 {
+            derecha = 5
+        }
+        for vertical := 0; vertical < q.tamano; vertical++ {
+            for j := 0; j < 2; j++ {
+                x := derecha - j
+                subiendo := (derecha+1)&2 == 0
+                y := vertical
+                if subiendo {
+                    y = q.tamano - 1 - vertical
+                }
+                if !q.funciones[y][x] && i < len(data)*8 {
+                    q.modulos[y][x] = (data[i>>3]>>uint(7-(i&7)))&1 != 0
+                    i++
+                }
+            }
+        }
+    }
+}
+
+// aplicarMascara invierte los módulos de datos según el patrón indicado
+func (q *qrMatriz) aplicarMascara(mascara int) {
+    for y := 0; y < q.tamano; y++ {
+        for x := 0; x < q.tamano; x++ {
+            if q.funciones[y][x] {
+                continue
+            }
+            var invertir bool
+            switch mascara {
+            case 0:
+                invertir = (x+y)%2 == 0
+            case 1:
+                invertir = y%2 == 0
+            case 2:
+                invertir = x%3 == 0
+            case 3:
+                invertir = (x+y)%3 == 0
+            case 4:
+                invertir = (x/3+y/2)%2 == 0
+            case 5:
+                invertir = x*y%2+x*y%3 == 0
+            case 6:
+                invertir = (x*y%2+x*y%3)%2 == 0
+            case 7:
+                invertir = ((x+y)%2+x*y%3)%2 == 0
+            }
+            if invertir {
+                q.modulos[y][x] = !q.modulos[y][x]
+            }
+        }
+    }
+}
+
+// penalizacion evalúa la legibilidad de la matriz según las reglas del estándar
+func (q *qrMatriz) penalizacion() int {
+    n := q.tamano
+    total := 0
+
+    celda := func(x, y int, horizontal bool) bool {
+        if horizontal {
+            return q.modulos[y][x]
+        }
+        return q.modulos[x][y]
+    }
+
+    patron1 := []bool{true, false, true, true, true, false, true, false, false, false, false}
+    patron2 := []bool{false, false, false, false, true, false, true, true, true, false, true}
+
+    for _, horizontal := range []bool{true, false} {
+        for y := 0; y < n; y++ {
+            // Regla 1: rachas de 5 o más módulos del mismo color
+            racha := 1
+            for x := 1; x < n; x++ {
+                if celda(x, y, horizontal) == celda(x-1, y, horizontal) {
+                    racha++
+                    continue
+                }
+                if racha >= 5 {
+                    total += 3 + racha - 5
+                }
+                racha = 1
+            }
+            if racha >= 5 {
+                total += 3 + racha - 5
+            }
+
+            // Regla 3: patrones similares a los de posición
+            for x := 0; x+len(patron1) <= n; x++ {
+                coincide1, coincide2 := true, true
+                for k := range patron1 {
+                    valor := celda(x+k, y, horizontal)
+                    if valor != patron1[k] {
+                        coincide1 = false
+                    }
+                    if valor != patron2[k] {
+                        coincide2 = false
+                    }
+                }
+                if coincide1 {
+                    total += 40
+                }
+                if coincide2 {
+                    total += 40
+                }
+            }
+        }
+    }
+
+    // Regla 2: bloques de 2x2 del mismo color
+    oscuros := 0
+    for y := 0; y < n; y++ {
+        for x := 0; x < n; x++ {
+            if q.modulos[y][x] {
+                oscuros++
+            }
+            if x+1 < n && y+1 < n {
+                c := q.modulos[y][x]
+                if c == q.modulos[y][x+1] && c == q.modulos[y+1][x] && c == q.modulos[y+1][x+1] {
+                    total += 3
+                }
+            }
+        }
+    }
+
+    // Regla 4: proporción de módulos oscuros
+    porcentaje := oscuros * 100 / (n * n)
+    total += qrAbs(porcentaje-50) / 5 * 10
+
+    return total
+}
+
+func qrAbs(x int) int {
+    if x < 0 {
+        return -x
+    }
+    return x
+}
+
+func qrMax(a, b int) int {
+    if a > b {
+        return a
+    }
+    return b
+}
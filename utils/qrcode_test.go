@@ -0,0 +1,391 @@
+package utils
+
+import (
+    "bytes"
+    "strings"
+    "testing"
+)
+
+// Las pruebas decodifican las matrices con un lector propio (zigzag, máscara y
+// bloques según ISO/IEC 18004) en lugar de confiar en las funciones del
+// codificador, salvo el mapa de patrones fijos.
+
+// qrFormatoM son los 15 bits de formato del nivel M ya enmascarados con
+// 0x5412, indexados por máscara (tabla C.1 de ISO/IEC 18004)
+var qrFormatoM = []int{0x5412, 0x5125, 0x5E7C, 0x5B4B, 0x45F9, 0x40CE, 0x4F97, 0x4AA0}
+
+// qrInfoVersion son los 18 bits de versión (tabla D.1 de ISO/IEC 18004)
+var qrInfoVersion = map[int]int{7: 0x07C94, 8: 0x085BC, 9: 0x09A99, 10: 0x0A4D3}
+
+// leerFormato devuelve las dos copias de la información de formato
+func leerFormato(m [][]bool) (int, int) {
+    n := len(m)
+    bit := func(oscuro bool, i int) int {
+        if oscuro {
+            return 1 << uint(i)
+        }
+        return 0
+    }
+
+    // Copia junto al buscador superior izquierdo: columna 8 hacia abajo
+    // (saltando la fila de sincronización) y fila 8 hacia la izquierda
+    primera := 0
+    for i, fila := range []int{0, 1, 2, 3, 4, 5, 7, 8} {
+        primera |= bit(m[fila][8], i)
+    }
+    primera |= bit(m[8][7], 8)
+    for i := 9; i < 15; i++ {
+        primera |= bit(m[8][14-i], i)
+    }
+
+    // Copia repartida entre la fila 8 a la derecha y la columna 8 abajo
+    segunda := 0
+    for i := 0; i < 8; i++ {
+        segunda |= bit(m[8][n-1-i], i)
+    }
+    for i := 8; i < 15; i++ {
+        segunda |= bit(m[n-15+i][8], i)
+    }
+
+    return primera, segunda
+}
+
+// leerVersion devuelve las dos copias de la información de versión
+func leerVersion(m [][]bool) (int, int) {
+    n := len(m)
+    arriba, izquierda := 0, 0
+    for i := 0; i < 18; i++ {
+        if m[i/3][n-11+i%3] {
+            arriba |= 1 << uint(i)
+        }
+        if m[n-11+i%3][i/3] {
+            izquierda |= 1 << uint(i)
+        }
+    }
+    return arriba, izquierda
+}
+
+// enmascarado indica si la máscara invierte el módulo de la fila y columna dadas
+func enmascarado(mascara, fila, col int) bool {
+    switch mascara {
+    case 0:
+        return (fila+col)%2 == 0
+    case 1:
+        return fila%2 == 0
+    case 2:
+        return col%3 == 0
+    case 3:
+        return (fila+col)%3 == 0
+    case 4:
+        return (fila/2+col/3)%2 == 0
+    case 5:
+        return (fila*col)%2+(fila*col)%3 == 0
+    case 6:
+        return ((fila*col)%2+(fila*col)%3)%2 == 0
+    default:
+        return ((fila+col)%2+(fila*col)%3)%2 == 0
+    }
+}
+
+// leerCodewords recorre la zona de datos en zigzag y quita la máscara
+func leerCodewords(t *testing.T, m [][]bool, version, mascara int) []byte {
+    t.Helper()
+
+    patrones := newQRMatriz(version)
+    patrones.dibujarPatrones()
+
+    n := len(m)
+    var bits []bool
+    subiendo := true
+    for col := n - 1; col > 0; col -= 2 {
+        if col == 6 {
+            col--
+        }
+        for k := 0; k < n; k++ {
+            fila := k
+            if subiendo {
+                fila = n - 1 - k
+            }
+            for _, c := range []int{col, col - 1} {
+                if patrones.funciones[fila][c] {
+                    continue
+                }
+                bits = append(bits, m[fila][c] != enmascarado(mascara, fila, c))
+            }
+        }
+        subiendo = !subiendo
+    }
+
+    codewords := make([]byte, len(bits)/8)
+    for i := range codewords {
+        for j := 0; j < 8; j++ {
+            if bits[i*8+j] {
+                codewords[i] |= 1 << uint(7-j)
+            }
+        }
+    }
+    return codewords
+}
+
+// desintercalar separa los codewords en bloques de datos y de corrección
+func desintercalar(codewords []byte, v qrVersion) (datos, correccion [][]byte) {
+    total := v.bloques1 + v.bloques2
+    datos = make([][]byte, total)
+    correccion = make([][]byte, total)
+
+    i := 0
+    for k := 0; k < v.datosBloque2 || k < v.datosBloque1; k++ {
+        for b := 0; b < total; b++ {
+            tamano := v.datosBloque1
+            if b >= v.bloques1 {
+                tamano = v.datosBloque2
+            }
+            if k < tamano {
+                datos[b] = append(datos[b], codewords[i])
+                i++
+            }
+        }
+    }
+    for k := 0; k < v.ecPorBloque; k++ {
+        for b := 0; b < total; b++ {
+            correccion[b] = append(correccion[b], codewords[i])
+            i++
+        }
+    }
+    return datos, correccion
+}
+
+// decodificarQR lee una matriz completa y devuelve el texto en modo byte
+func decodificarQR(t *testing.T, m [][]bool) (string, int) {
+    t.Helper()
+
+    n := len(m)
+    if (n-17)%4 != 0 {
+        t.Fatalf("tamaño de matriz inválido: %d", n)
+    }
+    version := (n - 17) / 4
+
+    primera, segunda := leerFormato(m)
+    if primera != segunda {
+        t.Fatalf("las copias de formato difieren: %015b != %015b", primera, segunda)
+    }
+    mascara := -1
+    for i, f := range qrFormatoM {
+        if f == primera {
+            mascara = i
+        }
+    }
+    if mascara < 0 {
+        t.Fatalf("formato %015b no corresponde al nivel M", primera)
+    }
+
+    if !m[n-8][8] {
+        t.Fatal("falta el módulo oscuro fijo")
+    }
+
+    if esperado, ok := qrInfoVersion[version]; ok {
+        arriba, izquierda := leerVersion(m)
+        if arriba != esperado || izquierda != esperado {
+            t.Fatalf("información de versión %018b/%018b, se esperaba %018b", arriba, izquierda, esperado)
+        }
+    }
+
+    v := qrVersiones[version]
+    datos, correccion := desintercalar(leerCodewords(t, m, version, mascara), v)
+    for b := range datos {
+        if rs := qrRestoRS(datos[b], qrGeneradorRS(v.ecPorBloque)); !bytes.Equal(rs, correccion[b]) {
+            t.Fatalf("bloque %d: corrección % X, se esperaba % X", b, correccion[b], rs)
+        }
+    }
+
+    flujo := bytes.Join(datos, nil)
+    if flujo[0]>>4 != 0x4 {
+        t.Fatalf("modo %04b, se esperaba byte (0100)", flujo[0]>>4)
+    }
+
+    // Lee los bits a partir del indicador de modo
+    pos := 4
+    leer := func(cantidad int) int {
+        valor := 0
+        for i := 0; i < cantidad; i++ {
+            valor = valor<<1 | int(flujo[pos/8]>>uint(7-pos%8)&1)
+            pos++
+        }
+        return valor
+    }
+
+    bitsCount := 8
+    if version >= 10 {
+        bitsCount = 16
+    }
+    longitud := leer(bitsCount)
+    texto := make([]byte, longitud)
+    for i := range texto {
+        texto[i] = byte(leer(8))
+    }
+
+    if fin := leer(4); fin != 0 {
+        t.Fatalf("terminador %04b, se esperaba 0000", fin)
+    }
+    pos = (pos + 7) / 8 * 8
+    for i, relleno := pos/8, byte(0xEC); i < len(flujo); i, relleno = i+1, relleno^0xEC^0x11 {
+        if flujo[i] != relleno {
+            t.Fatalf("relleno %02X en la posición %d, se esperaba %02X", flujo[i], i, relleno)
+        }
+    }
+
+    return string(texto), version
+}
+
+func TestQRRestoRSEjemploISO(t *testing.T) {
+    // Ejemplo del anexo I de ISO/IEC 18004: "01234567" en versión 1-M
+    datos := []byte{0x10, 0x20, 0x0C, 0x56, 0x61, 0x80, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11}
+    esperado := []byte{0xA5, 0x24, 0xD4, 0xC1, 0xED, 0x36, 0xC7, 0x87, 0x2C, 0x55}
+
+    if rs := qrRestoRS(datos, qrGeneradorRS(10)); !bytes.Equal(rs, esperado) {
+        t.Fatalf("corrección % X, se esperaba % X", rs, esperado)
+    }
+}
+
+func TestQRFormatoPorMascara(t *testing.T) {
+    for mascara, esperado := range qrFormatoM {
+        q := newQRMatriz(1)
+        q.dibujarFormato(mascara)
+
+        primera, segunda := leerFormato(q.modulos)
+        if primera != esperado || segunda != esperado {
+            t.Fatalf("máscara %d: formato %015b/%015b, se esperaba %015b", mascara, primera, segunda, esperado)
+        }
+    }
+}
+
+func TestQRInfoVersion(t *testing.T) {
+    for version, esperado := range qrInfoVersion {
+        q := newQRMatriz(version)
+        q.dibujarVersion()
+
+        arriba, izquierda := leerVersion(q.modulos)
+        if arriba != esperado || izquierda != esperado {
+            t.Fatalf("versión %d: %018b/%018b, se esperaba %018b", version, arriba, izquierda, esperado)
+        }
+    }
+}
+
+func TestEncodeQRPatronesFijos(t *testing.T) {
+    m, err := EncodeQR("https://cursos.example.com")
+    if err != nil {
+        t.Fatalf("EncodeQR: %v", err)
+    }
+
+    n := len(m)
+    buscador := []string{
+        "#######",
+        "#.....#",
+        "#.###.#",
+        "#.###.#",
+        "#.###.#",
+        "#.....#",
+        "#######",
+    }
+    for _, esquina := range [][2]int{{0, 0}, {0, n - 7}, {n - 7, 0}} {
+        for dy, fila := range buscador {
+            for dx, c := range fila {
+                if m[esquina[0]+dy][esquina[1]+dx] != (c == '#') {
+                    t.Fatalf("buscador en %v difiere en (%d, %d)", esquina, dy, dx)
+                }
+            }
+        }
+    }
+
+    for i := 8; i < n-8; i++ {
+        if m[6][i] != (i%2 == 0) || m[i][6] != (i%2 == 0) {
+            t.Fatalf("patrón de sincronización incorrecto en %d", i)
+        }
+    }
+}
+
+func TestEncodeQRDecodifica(t *testing.T) {
+    casos := []struct {
+        texto   string
+        version int
+    }{
+        {"https://cursos.example.com", 2},
+        {"http://localhost:8080/api/certificados/verify/CERT-2024-000123-4F7A9C", 5},
+        {"https://cursos.example.com/api/certificados/verify/" + strings.Repeat("a1b2c3d4", 8), 7},
+        {"https://cursos.example.com/api/certificados/verify/" + strings.Repeat("a1b2c3d4", 20), 10},
+        {strings.Repeat("x", 213), 10},
+    }
+
+    for _, c := range casos {
+        m, err := EncodeQR(c.texto)
+        if err != nil {
+            t.Fatalf("EncodeQR(%d bytes): %v", len(c.texto), err)
+        }
+        if len(m) != 17+4*c.version {
+            t.Fatalf("%d bytes: matriz de %d, se esperaba versión %d (%d)", len(c.texto), len(m), c.version, 17+4*c.version)
+        }
+
+        texto, _ := decodificarQR(t, m)
+        if texto != c.texto {
+            t.Fatalf("se decodificó %q, se esperaba %q", texto, c.texto)
+        }
+    }
+}
+
+func TestEncodeQRDemasiadoLargo(t *testing.T) {
+    if _, err := EncodeQR(strings.Repeat("x", 214)); err == nil {
+        t.Fatal("EncodeQR aceptó un texto que no cabe en la versión 10")
+    }
+}
+
+func TestEncodeQRMatrizConocida(t *testing.T) {
+    // Versión 2-M con máscara 2; la matriz se verificó con decodificarQR y
+    // fija la elección de máscara y la ubicación de los módulos
+    esperada := []string{
+        "#######...#.....#.#######",
+        "#.....#....#...#..#.....#",
+        "#.###.#.#...#.##..#.###.#",
+        "#.###.#.#..####.#.#.###.#",
+        "#.###.#.####.#..#.#.###.#",
+        "#.....#.#.....###.#.....#",
+        "#######.#.#.#.#.#.#######",
+        "........###.#.###........",
+        "#.#####....#.###..#####..",
+        "#...##.####.#.#.##.#...#.",
+        "#.#.#.##.##....#.#####.##",
+        "##.#...#...##...###.....#",
+        "....####..#####..##.#.###",
+        "#..#.#..#.#.##...#.#.#.#.",
+        "#.##.#####....###.####.##",
+        "#.##...##.#........##...#",
+        "#..##.#.#############.#..",
+        "........#.##....#...##...",
+        "#######...#...#.#.#.#.###",
+        "#.....#.#..#..#.#...##...",
+        "#.###.#.#..###.######.#..",
+        "#.###.#.#..#.#..###.#####",
+        "#.###.#.###..##.##...##.#",
+        "#.....#...###..##.####..#",
+        "#######.#.#.###..#.######",
+    }
+
+    m, err := EncodeQR("https://cursos.example.com")
+    if err != nil {
+        t.Fatalf("EncodeQR: %v", err)
+    }
+    if len(m) != len(esperada) {
+        t.Fatalf("matriz de %d filas, se esperaban %d", len(m), len(esperada))
+    }
+
+    for y, fila := range esperada {
+        for x, c := range fila {
+            if m[y][x] != (c == '#') {
+                t.Fatalf("módulo (%d, %d) difiere de la matriz conocida", x, y)
+            }
+        }
+    }
+
+    if primera, _ := leerFormato(m); primera != qrFormatoM[2] {
+        t.Fatalf("formato %015b, se esperaba la máscara 2 (%015b)", primera, qrFormatoM[2])
+    }
+}
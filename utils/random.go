@@ -0,0 +1,38 @@
+package utils
+
+import (
+    "crypto/rand"
+    "encoding/base32"
+    "encoding/base64"
+    "strings"
+)
+
+// GenerateRandomToken genera un token aleatorio seguro de n bytes codificado en base64 URL
+func GenerateRandomToken(n int) (string, error) {
+    bytes := make([]byte, n)
+    if _, err := rand.Read(bytes); err != nil {
+        return "", err
+    }
+    return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// GenerateRandomCode genera un código aleatorio seguro de n bytes en base32
+// (mayúsculas y dígitos, sin caracteres ambiguos de base64), agrupado en
+// bloques de 4 caracteres separados por guiones
+func GenerateRandomCode(n int) (string, error) {
+    bytes := make([]byte, n)
+    if _, err := rand.Read(bytes); err != nil {
+        return "", err
+    }
+
+    code := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(bytes)
+
+    var grupos []string
+    for len(code) > 4 {
+        grupos = append(grupos, code[:4])
+        code = code[4:]
+    }
+    grupos = append(grupos, code)
+
+    return strings.Join(grupos, "-"), nil
+}
@@ -0,0 +1,94 @@
+package utils
+
+import (
+    "crypto/hmac"
+    "crypto/rand"
+    "crypto/sha1"
+    "crypto/subtle"
+    "encoding/base32"
+    "encoding/binary"
+    "fmt"
+    "net/url"
+    "strings"
+    "time"
+)
+
+// Parámetros TOTP (RFC 6238) compatibles con las apps de autenticación
+// habituales: HMAC-SHA1, pasos de 30 segundos y códigos de 6 dígitos
+const (
+    totpPeriodo = 30
+    totpDigitos = 6
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret genera un secreto TOTP aleatorio de 160 bits en base32
+func GenerateTOTPSecret() (string, error) {
+    bytes := make([]byte, 20)
+    if _, err := rand.Read(bytes); err != nil {
+        return "", err
+    }
+    return totpEncoding.EncodeToString(bytes), nil
+}
+
+// TOTPURI construye la URI otpauth:// que las apps de autenticación leen
+// (normalmente desde un QR) para dar de alta el secreto
+func TOTPURI(secret, issuer, cuenta string) string {
+    params := url.Values{}
+    params.Set("secret", secret)
+    params.Set("issuer", issuer)
+    params.Set("algorithm", "SHA1")
+    params.Set("digits", fmt.Sprint(totpDigitos))
+    params.Set("period", fmt.Sprint(totpPeriodo))
+
+    label := url.PathEscape(issuer + ":" + cuenta)
+    return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// TOTPStep devuelve el paso de tiempo TOTP que corresponde a t
+func TOTPStep(t time.Time) int64 {
+    return t.Unix() / totpPeriodo
+}
+
+// TOTPCode calcula el código TOTP de un secreto para un paso de tiempo
+func TOTPCode(secret string, paso int64) (string, error) {
+    key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+    if err != nil {
+        return "", err
+    }
+
+    var msg [8]byte
+    binary.BigEndian.PutUint64(msg[:], uint64(paso))
+
+    mac := hmac.New(sha1.New, key)
+    mac.Write(msg[:])
+    sum := mac.Sum(nil)
+
+    // Truncado dinámico (RFC 4226, sección 5.3)
+    offset := sum[len(sum)-1] & 0x0f
+    valor := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+    return fmt.Sprintf("%0*d", totpDigitos, valor%1000000), nil
+}
+
+// ValidateTOTP comprueba un código admitiendo un paso de desfase en cada
+// sentido y devuelve el paso que coincide, para poder rechazar su reutilización
+func ValidateTOTP(secret, codigo string, t time.Time) (int64, bool) {
+    codigo = strings.TrimSpace(codigo)
+    if len(codigo) != totpDigitos {
+        return 0, false
+    }
+
+    actual := TOTPStep(t)
+    for paso := actual - 1; paso <= actual+1; paso++ {
+        esperado, err := TOTPCode(secret, paso)
+        if err != nil {
+            return 0, false
+        }
+        if subtle.ConstantTimeCompare([]byte(esperado), []byte(codigo)) == 1 {
+            return paso, true
+        }
+    }
+
+    return 0, false
+}
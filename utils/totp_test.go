@@ -0,0 +1,91 @@
+package utils
+
+import (
+    "encoding/base32"
+    "testing"
+    "time"
+)
+
+// secretoRFC6238 es la clave SHA1 de los vectores del apéndice B del RFC 6238
+// ("12345678901234567890") codificada en base32
+var secretoRFC6238 = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCodeVectoresRFC6238(t *testing.T) {
+    // El RFC publica códigos de 8 dígitos; los de 6 son sus últimos 6 dígitos
+    vectores := []struct {
+        unix   int64
+        codigo string
+    }{
+        {59, "287082"},          // 94287082
+        {1111111109, "081804"},  // 07081804
+        {1111111111, "050471"},  // 14050471
+        {1234567890, "005924"},  // 89005924
+        {2000000000, "279037"},  // 69279037
+        {20000000000, "353130"}, // 65353130
+    }
+
+    for _, v := range vectores {
+        codigo, err := TOTPCode(secretoRFC6238, TOTPStep(time.Unix(v.unix, 0)))
+        if err != nil {
+            t.Fatalf("TOTPCode(%d): %v", v.unix, err)
+        }
+        if codigo != v.codigo {
+            t.Fatalf("TOTPCode(%d) = %s, se esperaba %s", v.unix, codigo, v.codigo)
+        }
+    }
+}
+
+func TestTOTPCodeSecretoInvalido(t *testing.T) {
+    if _, err := TOTPCode("no es base32!", 1); err == nil {
+        t.Fatal("TOTPCode aceptó un secreto que no es base32")
+    }
+}
+
+func TestValidateTOTPVentanaDeDesfase(t *testing.T) {
+    ahora := time.Unix(1111111111, 0)
+    actual := TOTPStep(ahora)
+
+    for desfase := int64(-2); desfase <= 2; desfase++ {
+        codigo, err := TOTPCode(secretoRFC6238, actual+desfase)
+        if err != nil {
+            t.Fatalf("TOTPCode: %v", err)
+        }
+
+        paso, ok := ValidateTOTP(secretoRFC6238, codigo, ahora)
+        dentro := desfase >= -1 && desfase <= 1
+        if ok != dentro {
+            t.Fatalf("desfase %d: ValidateTOTP = %v, se esperaba %v", desfase, ok, dentro)
+        }
+        if ok && paso != actual+desfase {
+            t.Fatalf("desfase %d: ValidateTOTP devolvió el paso %d, se esperaba %d", desfase, paso, actual+desfase)
+        }
+    }
+}
+
+func TestValidateTOTPFormato(t *testing.T) {
+    ahora := time.Unix(59, 0)
+
+    if _, ok := ValidateTOTP(secretoRFC6238, " 287082 ", ahora); !ok {
+        t.Fatal("ValidateTOTP rechazó un código válido con espacios alrededor")
+    }
+    for _, codigo := range []string{"", "28708", "2870820", "94287082", "abcdef"} {
+        if _, ok := ValidateTOTP(secretoRFC6238, codigo, ahora); ok {
+            t.Fatalf("ValidateTOTP aceptó %q", codigo)
+        }
+    }
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+    secreto, err := GenerateTOTPSecret()
+    if err != nil {
+        t.Fatalf("GenerateTOTPSecret: %v", err)
+    }
+    if _, err := TOTPCode(secreto, 1); err != nil {
+        t.Fatalf("el secreto generado no es utilizable: %v", err)
+    }
+
+    otro, _ := GenerateTOTPSecret()
+    if secreto == otro {
+        t.Fatal("GenerateTOTPSecret repitió el secreto")
+    }
+}